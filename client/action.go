@@ -34,6 +34,8 @@ import (
 
 import (
 	"dubbo.apache.org/dubbo-go/v3/cluster/directory/static"
+	"dubbo.apache.org/dubbo-go/v3/cluster/healthcheck"
+	"dubbo.apache.org/dubbo-go/v3/cluster/outlier"
 	"dubbo.apache.org/dubbo-go/v3/common"
 	commonCfg "dubbo.apache.org/dubbo-go/v3/common/config"
 	"dubbo.apache.org/dubbo-go/v3/common/constant"
@@ -283,7 +285,8 @@ func buildInvoker(urls []*common.URL, ref *global.ReferenceConfig) (base.Invoker
 			if err != nil {
 				return nil, err
 			}
-			resInvoker = cluster.Join(static.NewDirectory(invokers))
+			dir := healthcheck.NewDirectory(static.NewDirectory(invokers), resInvoker.GetURL())
+			resInvoker = cluster.Join(outlier.NewDirectory(dir, resInvoker.GetURL()))
 		}
 		return resInvoker, nil
 	}
@@ -303,7 +306,14 @@ func buildInvoker(urls []*common.URL, ref *global.ReferenceConfig) (base.Invoker
 	if err != nil {
 		return nil, err
 	}
-	resInvoker = cluster.Join(static.NewDirectory(invokers))
+	dir := static.NewDirectory(invokers)
+	if regURL == nil {
+		// the outlier detection and health checking of registry invokers are done by their own directories
+		ivkURL := invokers[0].GetURL()
+		resInvoker = cluster.Join(outlier.NewDirectory(healthcheck.NewDirectory(dir, ivkURL), ivkURL))
+	} else {
+		resInvoker = cluster.Join(dir)
+	}
 
 	return resInvoker, nil
 }
@@ -375,6 +385,7 @@ func (refOpts *ReferenceOptions) getURLMap() url.Values {
 	// getty invoke async or sync
 	urlMap.Set(constant.AsyncKey, strconv.FormatBool(ref.Async))
 	urlMap.Set(constant.StickyKey, strconv.FormatBool(ref.Sticky))
+	setRetryPolicy(urlMap, "", ref.RetryBudget, ref.RetryBackoff, ref.RetryMaxBackoff, ref.RetryableCodes)
 
 	// applicationConfig info
 	if app != nil {
@@ -407,11 +418,26 @@ func (refOpts *ReferenceOptions) getURLMap() url.Values {
 		if len(v.RequestTimeout) != 0 {
 			urlMap.Set("methods."+v.Name+"."+constant.TimeoutKey, v.RequestTimeout)
 		}
+		setRetryPolicy(urlMap, "methods."+v.Name+".", v.RetryBudget, v.RetryBackoff, v.RetryMaxBackoff, v.RetryableCodes)
 	}
 
 	return urlMap
 }
 
+// setRetryPolicy sets the non-empty retry policy params of failover cluster
+func setRetryPolicy(urlMap url.Values, prefix, budget, backoff, maxBackoff, codes string) {
+	for k, v := range map[string]string{
+		constant.RetryBudgetKey:     budget,
+		constant.RetryBackoffKey:    backoff,
+		constant.RetryMaxBackoffKey: maxBackoff,
+		constant.RetryableCodesKey:  codes,
+	} {
+		if len(v) != 0 {
+			urlMap.Set(prefix+k, v)
+		}
+	}
+}
+
 // todo: figure this out
 //// GenericLoad ...
 //func (opts *ReferenceOptions) GenericLoad(id string) {
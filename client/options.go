@@ -19,6 +19,7 @@ package client
 
 import (
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -249,6 +250,12 @@ func WithClusterAdaptiveService() ReferenceOption {
 	}
 }
 
+func WithClusterHedging() ReferenceOption {
+	return func(opts *ReferenceOptions) {
+		opts.Reference.Cluster = constant.ClusterKeyHedging
+	}
+}
+
 func WithCluster(cluster string) ReferenceOption {
 	return func(opts *ReferenceOptions) {
 		opts.Reference.Cluster = cluster
@@ -287,6 +294,18 @@ func WithLoadBalanceP2C() ReferenceOption {
 	}
 }
 
+func WithLoadBalancePeakEWMA() ReferenceOption {
+	return func(opts *ReferenceOptions) {
+		opts.Reference.Loadbalance = constant.LoadBalanceKeyPeakEWMA
+	}
+}
+
+func WithLoadBalanceLocality() ReferenceOption {
+	return func(opts *ReferenceOptions) {
+		opts.Reference.Loadbalance = constant.LoadBalanceKeyLocality
+	}
+}
+
 func WithLoadBalance(lb string) ReferenceOption {
 	return func(opts *ReferenceOptions) {
 		opts.Reference.Loadbalance = lb
@@ -299,6 +318,29 @@ func WithRetries(retries int) ReferenceOption {
 	}
 }
 
+// WithRetryBudget limits the retries of failover cluster to the ratio of recent successful calls,
+// e.g. 0.2 means retries can be at most 20% of successful calls.
+func WithRetryBudget(ratio float64) ReferenceOption {
+	return func(opts *ReferenceOptions) {
+		opts.Reference.RetryBudget = strconv.FormatFloat(ratio, 'f', -1, 64)
+	}
+}
+
+// WithRetryBackoff sets the exponential backoff with jitter between retries of failover cluster.
+func WithRetryBackoff(backoff, maxBackoff time.Duration) ReferenceOption {
+	return func(opts *ReferenceOptions) {
+		opts.Reference.RetryBackoff = backoff.String()
+		opts.Reference.RetryMaxBackoff = maxBackoff.String()
+	}
+}
+
+// WithRetryableCodes sets the triple codes which could be retried by failover cluster, such as unavailable.
+func WithRetryableCodes(codes ...string) ReferenceOption {
+	return func(opts *ReferenceOptions) {
+		opts.Reference.RetryableCodes = strings.Join(codes, ",")
+	}
+}
+
 func WithGroup(group string) ReferenceOption {
 	return func(opts *ReferenceOptions) {
 		opts.Reference.Group = group
@@ -697,6 +739,12 @@ func WithClientClusterAdaptiveService() ClientOption {
 	}
 }
 
+func WithClientClusterHedging() ClientOption {
+	return func(opts *ClientOptions) {
+		opts.overallReference.Cluster = constant.ClusterKeyHedging
+	}
+}
+
 func WithClientClusterStrategy(strategy string) ClientOption {
 	return func(opts *ClientOptions) {
 		opts.overallReference.Cluster = strategy
@@ -760,6 +808,18 @@ func WithClientLoadBalanceP2C() ClientOption {
 	}
 }
 
+func WithClientLoadBalancePeakEWMA() ClientOption {
+	return func(opts *ClientOptions) {
+		opts.overallReference.Loadbalance = constant.LoadBalanceKeyPeakEWMA
+	}
+}
+
+func WithClientLoadBalanceLocality() ClientOption {
+	return func(opts *ClientOptions) {
+		opts.overallReference.Loadbalance = constant.LoadBalanceKeyLocality
+	}
+}
+
 func WithClientLoadBalance(lb string) ClientOption {
 	return func(opts *ClientOptions) {
 		opts.overallReference.Loadbalance = lb
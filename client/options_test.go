@@ -284,6 +284,16 @@ func TestWithClientCluster(t *testing.T) {
 				assert.Equal(t, constant.ClusterKeyForking, cli.cliOpts.overallReference.Cluster)
 			},
 		},
+		{
+			desc: "config Hedging Cluster strategy",
+			opts: []ClientOption{
+				WithClientClusterHedging(),
+			},
+			verify: func(t *testing.T, cli *Client, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, constant.ClusterKeyHedging, cli.cliOpts.overallReference.Cluster)
+			},
+		},
 		{
 			desc: "config ZoneAware Cluster strategy",
 			opts: []ClientOption{
@@ -369,6 +379,26 @@ func TestWithClientLoadBalance(t *testing.T) {
 				assert.Equal(t, constant.LoadBalanceKeyP2C, cli.cliOpts.overallReference.Loadbalance)
 			},
 		},
+		{
+			desc: "config PeakEWMA LoadBalance strategy",
+			opts: []ClientOption{
+				WithClientLoadBalancePeakEWMA(),
+			},
+			verify: func(t *testing.T, cli *Client, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, constant.LoadBalanceKeyPeakEWMA, cli.cliOpts.overallReference.Loadbalance)
+			},
+		},
+		{
+			desc: "config Locality LoadBalance strategy",
+			opts: []ClientOption{
+				WithClientLoadBalanceLocality(),
+			},
+			verify: func(t *testing.T, cli *Client, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, constant.LoadBalanceKeyLocality, cli.cliOpts.overallReference.Loadbalance)
+			},
+		},
 	}
 	processNewClientCases(t, cases)
 }
@@ -825,6 +855,16 @@ func TestWithCluster(t *testing.T) {
 				assert.Equal(t, constant.ClusterKeyForking, refOpts.Reference.Cluster)
 			},
 		},
+		{
+			desc: "config Hedging Cluster strategy",
+			opts: []ReferenceOption{
+				WithClusterHedging(),
+			},
+			verify: func(t *testing.T, refOpts *ReferenceOptions, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, constant.ClusterKeyHedging, refOpts.Reference.Cluster)
+			},
+		},
 		{
 			desc: "config ZoneAware Cluster strategy",
 			opts: []ReferenceOption{
@@ -910,6 +950,26 @@ func TestWithLoadBalance(t *testing.T) {
 				assert.Equal(t, constant.LoadBalanceKeyP2C, refOpts.Reference.Loadbalance)
 			},
 		},
+		{
+			desc: "config PeakEWMA LoadBalance strategy",
+			opts: []ReferenceOption{
+				WithLoadBalancePeakEWMA(),
+			},
+			verify: func(t *testing.T, refOpts *ReferenceOptions, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, constant.LoadBalanceKeyPeakEWMA, refOpts.Reference.Loadbalance)
+			},
+		},
+		{
+			desc: "config Locality LoadBalance strategy",
+			opts: []ReferenceOption{
+				WithLoadBalanceLocality(),
+			},
+			verify: func(t *testing.T, refOpts *ReferenceOptions, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, constant.LoadBalanceKeyLocality, refOpts.Reference.Loadbalance)
+			},
+		},
 	}
 	processReferenceOptionsInitCases(t, cases)
 }
@@ -22,6 +22,7 @@ import (
 )
 
 import (
+	"dubbo.apache.org/dubbo-go/v3/common"
 	"dubbo.apache.org/dubbo-go/v3/protocol/base"
 	"dubbo.apache.org/dubbo-go/v3/protocol/result"
 )
@@ -30,3 +31,11 @@ type Interceptor interface {
 	// Invoke is the core function of a cluster interceptor, it determines the process of the interceptor
 	Invoke(context.Context, base.Invoker, base.Invocation) result.Result
 }
+
+// InitializableInterceptor is the Interceptor which is initialized with the url of the cluster invoker when the
+// interceptor chain is built, i.e. when the reference is set up.
+type InitializableInterceptor interface {
+	Interceptor
+	// Init is called once before the interceptor invokes
+	Init(url *common.URL)
+}
@@ -21,6 +21,7 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"sync"
 )
 
 import (
@@ -41,6 +42,8 @@ import (
 
 type failoverClusterInvoker struct {
 	base.BaseClusterInvoker
+	// budgets stores the retry budget of each method, method name -> *retryBudget
+	budgets sync.Map
 }
 
 func newFailoverClusterInvoker(directory directory.Directory) protocolbase.Invoker {
@@ -65,11 +68,19 @@ func (invoker *failoverClusterInvoker) Invoke(ctx context.Context, invocation pr
 	methodName := invocation.ActualMethodName()
 	retries := getRetries(invokers, methodName, invocation)
 	loadBalance := base.GetLoadBalance(invokers[0], methodName)
+	policy := getRetryPolicy(invokers[0].GetURL(), methodName)
 
 	for i := 0; i <= retries; i++ {
 		// Reselect before retry to avoid a change of candidate `invokers`.
 		// NOTE: if `invokers` changed, then `invoked` also lose accuracy.
 		if i > 0 {
+			if res != nil && !invoker.acquireRetry(invokers[0].GetURL(), methodName, policy) {
+				logger.Warnf("The retry budget of the method %s is exhausted, stop retrying.", methodName)
+				break
+			}
+			if err := policy.wait(ctx, i); err != nil {
+				return &result.RPCResult{Err: err}
+			}
 			if err := invoker.CheckWhetherDestroyed(); err != nil {
 				return &result.RPCResult{Err: err}
 			}
@@ -88,8 +99,12 @@ func (invoker *failoverClusterInvoker) Invoke(ctx context.Context, invocation pr
 		res = ivk.Invoke(ctx, invocation)
 		if res.Error() != nil && !isBizError(res.Error()) {
 			providers = append(providers, ivk.GetURL().Key())
+			if !policy.isRetryable(res.Error()) {
+				break
+			}
 			continue
 		}
+		invoker.onSuccess(methodName, policy)
 		return res
 	}
 	ip := common.GetLocalIp()
@@ -0,0 +1,238 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package failover
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+import (
+	"github.com/dubbogo/gost/log/logger"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/metrics"
+	clusterMetrics "dubbo.apache.org/dubbo-go/v3/metrics/cluster"
+	"dubbo.apache.org/dubbo-go/v3/metrics/util/aggregate"
+	"dubbo.apache.org/dubbo-go/v3/protocol/triple/triple_protocol"
+)
+
+const (
+	retryBudgetPaneCount     = 10
+	retryBudgetWindowSeconds = 10
+	// retryBudgetMinRetries keeps a few retries available in the window, otherwise a service
+	// with low traffic could never retry.
+	retryBudgetMinRetries = 3
+)
+
+// retryBudget limits the retries in a sliding window to a ratio of the successful calls in the same window.
+type retryBudget struct {
+	// ratio is the bits of the float64 ratio, it is updated once the config is changed
+	ratio     atomic.Uint64
+	successes *aggregate.TimeWindowCounter
+	retries   *aggregate.TimeWindowCounter
+}
+
+func newRetryBudget(ratio float64) *retryBudget {
+	b := &retryBudget{
+		successes: aggregate.NewTimeWindowCounter(retryBudgetPaneCount, retryBudgetWindowSeconds),
+		retries:   aggregate.NewTimeWindowCounter(retryBudgetPaneCount, retryBudgetWindowSeconds),
+	}
+	b.setRatio(ratio)
+	return b
+}
+
+func (b *retryBudget) setRatio(ratio float64) {
+	if bits := math.Float64bits(ratio); b.ratio.Load() != bits {
+		b.ratio.Store(bits)
+	}
+}
+
+func (b *retryBudget) limit() float64 {
+	return math.Max(math.Float64frombits(b.ratio.Load())*b.successes.Count(), retryBudgetMinRetries)
+}
+
+// acquire returns true and consumes the budget if a retry is allowed, the usage of the budget is returned as well.
+// It is not strictly atomic, a few concurrent retries may exceed the limit slightly, which is acceptable.
+func (b *retryBudget) acquire() (bool, float64) {
+	limit := b.limit()
+	retries := b.retries.Count()
+	if retries+1 > limit {
+		return false, retries / limit
+	}
+	b.retries.Inc()
+	return true, (retries + 1) / limit
+}
+
+func (b *retryBudget) onSuccess() {
+	b.successes.Inc()
+}
+
+// retryPolicy is the retry configuration of a method.
+type retryPolicy struct {
+	budgetRatio float64
+	backoff     time.Duration
+	maxBackoff  time.Duration
+	// retryableCodes is nil if all the errors are retryable.
+	retryableCodes map[triple_protocol.Code]struct{}
+}
+
+func getRetryPolicy(url *common.URL, methodName string) *retryPolicy {
+	param := func(key string) string {
+		return url.GetMethodParam(methodName, key, url.GetParam(key, ""))
+	}
+
+	policy := &retryPolicy{}
+	if v := param(constant.RetryBudgetKey); v != "" {
+		if ratio, err := strconv.ParseFloat(v, 64); err == nil && ratio >= 0 {
+			policy.budgetRatio = ratio
+		} else {
+			logger.Warnf("Illegal retry budget %s of the method %s, the retry budget is disabled.", v, methodName)
+		}
+	}
+	policy.backoff = parseDuration(param(constant.RetryBackoffKey), methodName)
+	policy.maxBackoff = parseDuration(param(constant.RetryMaxBackoffKey), methodName)
+	if v := param(constant.RetryableCodesKey); v != "" {
+		policy.retryableCodes = make(map[triple_protocol.Code]struct{})
+		for _, name := range strings.Split(v, ",") {
+			var code triple_protocol.Code
+			if err := code.UnmarshalText([]byte(strings.TrimSpace(name))); err != nil {
+				logger.Warnf("Illegal retryable code %s of the method %s, it is ignored.", name, methodName)
+				continue
+			}
+			policy.retryableCodes[code] = struct{}{}
+		}
+	}
+	return policy
+}
+
+func parseDuration(v, methodName string) time.Duration {
+	if v == "" {
+		return 0
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		logger.Warnf("Illegal retry backoff %s of the method %s, the backoff is disabled.", v, methodName)
+		return 0
+	}
+	return d
+}
+
+// isRetryable returns whether the error could be retried according to the retryable codes.
+func (p *retryPolicy) isRetryable(err error) bool {
+	if p.retryableCodes == nil {
+		return true
+	}
+	_, ok := p.retryableCodes[codeOf(err)]
+	return ok
+}
+
+// codeOf returns the triple code of the error. The errors of the other protocols are mapped by their causes, the
+// timeouts are deadline_exceeded and the connection errors are unavailable, and the rest of them, such as the
+// exceptions thrown by the dubbo providers, are unknown.
+func codeOf(err error) triple_protocol.Code {
+	var tripleErr *triple_protocol.Error
+	if errors.As(err, &tripleErr) {
+		return tripleErr.Code()
+	}
+	if errors.Is(err, context.Canceled) {
+		return triple_protocol.CodeCanceled
+	}
+	var timeoutErr interface{ Timeout() bool }
+	if errors.Is(err, context.DeadlineExceeded) || errors.As(err, &timeoutErr) && timeoutErr.Timeout() {
+		return triple_protocol.CodeDeadlineExceeded
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE) {
+		return triple_protocol.CodeUnavailable
+	}
+	return triple_protocol.CodeUnknown
+}
+
+// backoffDuration returns an exponential backoff with full jitter before the n-th retry.
+func (p *retryPolicy) backoffDuration(retry int) time.Duration {
+	if p.backoff <= 0 {
+		return 0
+	}
+	backoff := float64(p.backoff) * math.Pow(2, float64(retry-1))
+	if p.maxBackoff > 0 && backoff > float64(p.maxBackoff) {
+		backoff = float64(p.maxBackoff)
+	}
+	return time.Duration(rand.Int63n(int64(backoff) + 1))
+}
+
+// wait blocks for the backoff before the n-th retry, it returns an error if the context is done.
+func (p *retryPolicy) wait(ctx context.Context, retry int) error {
+	d := p.backoffDuration(retry)
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// acquireRetry checks the retry budget of the method and publishes the usage of the budget.
+func (invoker *failoverClusterInvoker) acquireRetry(url *common.URL, methodName string, policy *retryPolicy) bool {
+	if policy.budgetRatio <= 0 {
+		return true
+	}
+	allowed, usage := invoker.getRetryBudget(methodName, policy.budgetRatio).acquire()
+	metrics.Publish(clusterMetrics.NewRetryBudgetEvent(url.Service(), methodName,
+		url.GetParam(constant.GroupKey, ""), url.GetParam(constant.VersionKey, ""), allowed, usage))
+	return allowed
+}
+
+func (invoker *failoverClusterInvoker) onSuccess(methodName string, policy *retryPolicy) {
+	if policy.budgetRatio <= 0 {
+		return
+	}
+	invoker.getRetryBudget(methodName, policy.budgetRatio).onSuccess()
+}
+
+// getRetryBudget returns the retry budget of the method, the ratio of it is updated to the current config.
+func (invoker *failoverClusterInvoker) getRetryBudget(methodName string, ratio float64) *retryBudget {
+	if b, ok := invoker.budgets.Load(methodName); ok {
+		budget := b.(*retryBudget)
+		budget.setRatio(ratio)
+		return budget
+	}
+	b, loaded := invoker.budgets.LoadOrStore(methodName, newRetryBudget(ratio))
+	if loaded {
+		b.(*retryBudget).setRatio(ratio)
+	}
+	return b.(*retryBudget)
+}
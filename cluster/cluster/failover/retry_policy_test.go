@@ -0,0 +1,149 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package failover
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"syscall"
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	clusterpkg "dubbo.apache.org/dubbo-go/v3/cluster/cluster"
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/protocol/triple/triple_protocol"
+)
+
+func TestRetryBudget(t *testing.T) {
+	budget := newRetryBudget(0.5)
+	// the min retries are always available
+	for i := 0; i < retryBudgetMinRetries; i++ {
+		allowed, _ := budget.acquire()
+		assert.True(t, allowed)
+	}
+	allowed, usage := budget.acquire()
+	assert.False(t, allowed)
+	assert.Equal(t, float64(1), usage)
+
+	for i := 0; i < 10; i++ {
+		budget.onSuccess()
+	}
+	allowed, usage = budget.acquire()
+	assert.True(t, allowed)
+	assert.Equal(t, 0.8, usage)
+}
+
+func TestRetryBudgetRatioChanged(t *testing.T) {
+	invoker := &failoverClusterInvoker{}
+	budget := invoker.getRetryBudget("test", 0.5)
+	for i := 0; i < 10; i++ {
+		budget.onSuccess()
+	}
+	assert.Equal(t, float64(5), budget.limit())
+
+	// the budget follows the ratio of the current config
+	assert.Same(t, budget, invoker.getRetryBudget("test", 1))
+	assert.Equal(t, float64(10), budget.limit())
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string { return "timeout" }
+
+func (timeoutError) Timeout() bool { return true }
+
+func TestCodeOf(t *testing.T) {
+	connErr := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	cases := []struct {
+		err  error
+		code triple_protocol.Code
+	}{
+		{triple_protocol.NewError(triple_protocol.CodeInternal, errors.New("internal")), triple_protocol.CodeInternal},
+		{fmt.Errorf("wrapped: %w", context.DeadlineExceeded), triple_protocol.CodeDeadlineExceeded},
+		{context.Canceled, triple_protocol.CodeCanceled},
+		{timeoutError{}, triple_protocol.CodeDeadlineExceeded},
+		{connErr, triple_protocol.CodeUnavailable},
+		{fmt.Errorf("wrapped: %w", syscall.ECONNREFUSED), triple_protocol.CodeUnavailable},
+		{io.EOF, triple_protocol.CodeUnavailable},
+		{errors.New("java exception"), triple_protocol.CodeUnknown},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.code, codeOf(c.err), c.err.Error())
+	}
+
+	u, _ := common.NewURL("dubbo://127.0.0.1:20000/com.ikurento.user.UserProvider",
+		common.WithParamsValue(constant.RetryableCodesKey, "unavailable,deadline_exceeded"))
+	policy := getRetryPolicy(u, "test")
+	assert.True(t, policy.isRetryable(timeoutError{}))
+	assert.True(t, policy.isRetryable(connErr))
+	assert.False(t, policy.isRetryable(errors.New("java exception")))
+}
+
+func TestGetRetryPolicy(t *testing.T) {
+	u, _ := common.NewURL("dubbo://127.0.0.1:20000/com.ikurento.user.UserProvider",
+		common.WithParamsValue(constant.RetryBudgetKey, "0.2"),
+		common.WithParamsValue(constant.RetryBackoffKey, "10ms"),
+		common.WithParamsValue(constant.RetryMaxBackoffKey, "30ms"),
+		common.WithParamsValue("methods.test."+constant.RetryableCodesKey, "unavailable, deadline_exceeded"))
+
+	policy := getRetryPolicy(u, "test")
+	assert.Equal(t, 0.2, policy.budgetRatio)
+	assert.Equal(t, 10*time.Millisecond, policy.backoff)
+	assert.Equal(t, 30*time.Millisecond, policy.maxBackoff)
+	assert.True(t, policy.isRetryable(triple_protocol.NewError(triple_protocol.CodeUnavailable, errors.New("unavailable"))))
+	assert.False(t, policy.isRetryable(triple_protocol.NewError(triple_protocol.CodeInternal, errors.New("internal"))))
+	assert.False(t, policy.isRetryable(errors.New("error")))
+	for i := 1; i < 5; i++ {
+		assert.LessOrEqual(t, policy.backoffDuration(i), 30*time.Millisecond)
+	}
+
+	policy = getRetryPolicy(u, "other")
+	assert.Nil(t, policy.retryableCodes)
+	assert.True(t, policy.isRetryable(errors.New("error")))
+}
+
+// nolint
+func TestFailoverInvokeNotRetryable(t *testing.T) {
+	urlParams := url.Values{}
+	urlParams.Set(constant.RetryableCodesKey, "unavailable")
+	result := normalInvoke(2, urlParams)
+	assert.Error(t, result.Error())
+	assert.Equal(t, 1, clusterpkg.Count)
+	clusterpkg.Count = 0
+}
+
+// nolint
+func TestFailoverInvokeWithBackoff(t *testing.T) {
+	urlParams := url.Values{}
+	urlParams.Set(constant.RetryBackoffKey, "1ms")
+	urlParams.Set(constant.RetryMaxBackoffKey, "5ms")
+	result := normalInvoke(3, urlParams)
+	assert.NoError(t, result.Error())
+	clusterpkg.Count = 0
+}
@@ -0,0 +1,46 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package hedging
+
+import (
+	clusterpkg "dubbo.apache.org/dubbo-go/v3/cluster/cluster"
+	"dubbo.apache.org/dubbo-go/v3/cluster/directory"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/common/extension"
+	"dubbo.apache.org/dubbo-go/v3/protocol/base"
+)
+
+func init() {
+	extension.SetCluster(constant.ClusterKeyHedging, newHedgingCluster)
+}
+
+type hedgingCluster struct{}
+
+// newHedgingCluster returns a hedgingCluster instance.
+//
+// The first attempt is sent immediately, and if no successful result arrives within the hedging delay,
+// another attempt is sent to a different server, until the max attempts is reached. The first successful
+// result wins and the others are canceled. Usually it is used for reducing tail latency of idempotent calls.
+func newHedgingCluster() clusterpkg.Cluster {
+	return &hedgingCluster{}
+}
+
+// Join returns a baseClusterInvoker instance
+func (cluster *hedgingCluster) Join(directory directory.Directory) base.Invoker {
+	return clusterpkg.BuildInterceptorChain(newHedgingClusterInvoker(directory))
+}
@@ -0,0 +1,194 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package hedging
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+import (
+	"github.com/dubbogo/gost/log/logger"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/cluster/cluster/base"
+	"dubbo.apache.org/dubbo-go/v3/cluster/directory"
+	"dubbo.apache.org/dubbo-go/v3/cluster/metrics"
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/metrics/util/aggregate"
+	protocolbase "dubbo.apache.org/dubbo-go/v3/protocol/base"
+	"dubbo.apache.org/dubbo-go/v3/protocol/result"
+	"dubbo.apache.org/dubbo-go/v3/protocol/triple/triple_protocol"
+)
+
+const (
+	// hedgingDelayQuantile is used as the hedging delay if it is not configured explicitly.
+	hedgingDelayQuantile = 0.95
+
+	quantileCompression = 100
+	quantilePaneCount   = 10
+	quantileWindow      = 60
+)
+
+// quantileLock protects the creation of latency quantiles stored in metrics.LocalMetrics.
+var quantileLock sync.Mutex
+
+type hedgingClusterInvoker struct {
+	base.BaseClusterInvoker
+}
+
+func newHedgingClusterInvoker(directory directory.Directory) protocolbase.Invoker {
+	return &hedgingClusterInvoker{
+		BaseClusterInvoker: base.NewBaseClusterInvoker(directory),
+	}
+}
+
+func (invoker *hedgingClusterInvoker) Invoke(ctx context.Context, invocation protocolbase.Invocation) result.Result {
+	if err := invoker.CheckWhetherDestroyed(); err != nil {
+		return &result.RPCResult{Err: err}
+	}
+
+	invokers := invoker.Directory.List(invocation)
+	if err := invoker.CheckInvokers(invokers, invocation); err != nil {
+		return &result.RPCResult{Err: err}
+	}
+
+	methodName := invocation.ActualMethodName()
+	maxAttempts := getMaxAttempts(invokers[0].GetURL(), methodName)
+	loadBalance := base.GetLoadBalance(invokers[0], methodName)
+
+	// all the attempts share one context, so the losing calls are canceled once the invocation returns.
+	hedgingCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		invoked  []protocolbase.Invoker
+		inflight int
+		lastRes  result.Result
+	)
+	resCh := make(chan result.Result, maxAttempts)
+	attempt := func() bool {
+		if len(invoked) >= maxAttempts {
+			return false
+		}
+		ivk := invoker.DoSelect(loadBalance, invocation, invokers, invoked)
+		if ivk == nil || isInvoked(ivk, invoked) {
+			return false
+		}
+		invoked = append(invoked, ivk)
+		inflight++
+		go func() {
+			start := time.Now()
+			res := ivk.Invoke(hedgingCtx, invocation)
+			if res.Error() == nil {
+				recordLatency(ivk.GetURL(), methodName, time.Since(start))
+			}
+			resCh <- res
+		}()
+		return true
+	}
+
+	if !attempt() {
+		return &result.RPCResult{Err: fmt.Errorf("failed to hedging invoke the method %s of the service %s, "+
+			"no provider is available", methodName, invoker.GetURL().Service())}
+	}
+	delay := getDelay(invoked[0].GetURL(), methodName)
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	for inflight > 0 {
+		select {
+		case res := <-resCh:
+			inflight--
+			if res.Error() == nil || isBizError(res.Error()) {
+				return res
+			}
+			lastRes = res
+			// the attempt failed, so there is no reason to wait for the hedging delay
+			if attempt() {
+				timer.Reset(delay)
+			}
+		case <-timer.C:
+			if attempt() {
+				timer.Reset(delay)
+			}
+		case <-ctx.Done():
+			return &result.RPCResult{Err: ctx.Err()}
+		}
+	}
+
+	logger.Errorf("Failed to hedging invoke the method %s in the service %s. Tried %d times of the providers %v, "+
+		"last error is %v.", methodName, invoker.GetURL().Service(), len(invoked), invoked, lastRes.Error())
+	return lastRes
+}
+
+func isBizError(err error) bool {
+	return triple_protocol.IsWireError(err) && triple_protocol.CodeOf(err) == triple_protocol.CodeBizError
+}
+
+func isInvoked(selectedInvoker protocolbase.Invoker, invoked []protocolbase.Invoker) bool {
+	for _, i := range invoked {
+		if i == selectedInvoker {
+			return true
+		}
+	}
+	return false
+}
+
+func getMaxAttempts(url *common.URL, methodName string) int {
+	attempts := url.GetMethodParamIntValue(methodName, constant.HedgingMaxAttemptsKey,
+		url.GetParamByIntValue(constant.HedgingMaxAttemptsKey, constant.DefaultHedgingMaxAttempts))
+	if attempts < 1 {
+		return constant.DefaultHedgingMaxAttempts
+	}
+	return attempts
+}
+
+// getDelay returns the configured hedging delay, or the p95 latency of the provider from cluster metrics
+// if the delay is not configured.
+func getDelay(url *common.URL, methodName string) time.Duration {
+	delayStr := url.GetMethodParam(methodName, constant.HedgingDelayKey, url.GetParam(constant.HedgingDelayKey, ""))
+	if delayStr != "" {
+		if delay, err := time.ParseDuration(delayStr); err == nil && delay >= 0 {
+			return delay
+		}
+		logger.Warnf("Illegal hedging delay %s of the method %s, the default value is used.", delayStr, methodName)
+	}
+	if q, err := metrics.LocalMetrics.GetMethodMetrics(url, methodName, metrics.LatencyQuantile); err == nil {
+		if p := q.(*aggregate.TimeWindowQuantile).Quantile(hedgingDelayQuantile); p > 0 {
+			return time.Duration(p * float64(time.Millisecond))
+		}
+	}
+	delay, _ := time.ParseDuration(constant.DefaultHedgingDelay)
+	return delay
+}
+
+func recordLatency(url *common.URL, methodName string, latency time.Duration) {
+	quantileLock.Lock()
+	q, err := metrics.LocalMetrics.GetMethodMetrics(url, methodName, metrics.LatencyQuantile)
+	if err != nil {
+		q = aggregate.NewTimeWindowQuantile(quantileCompression, quantilePaneCount, quantileWindow)
+		_ = metrics.LocalMetrics.SetMethodMetrics(url, methodName, metrics.LatencyQuantile, q)
+	}
+	quantileLock.Unlock()
+	q.(*aggregate.TimeWindowQuantile).Add(float64(latency) / float64(time.Millisecond))
+}
@@ -0,0 +1,148 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package hedging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/golang/mock/gomock"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.uber.org/atomic"
+)
+
+import (
+	clusterpkg "dubbo.apache.org/dubbo-go/v3/cluster/cluster"
+	"dubbo.apache.org/dubbo-go/v3/cluster/directory/static"
+	"dubbo.apache.org/dubbo-go/v3/cluster/loadbalance/roundrobin"
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/common/extension"
+	"dubbo.apache.org/dubbo-go/v3/protocol/base"
+	"dubbo.apache.org/dubbo-go/v3/protocol/invocation"
+	"dubbo.apache.org/dubbo-go/v3/protocol/mock"
+	"dubbo.apache.org/dubbo-go/v3/protocol/result"
+)
+
+func newHedgingURL(delay string, maxAttempts int) *common.URL {
+	url, _ := common.NewURL(
+		fmt.Sprintf("dubbo://%s:%d/com.ikurento.user.UserProvider", constant.LocalHostValue, constant.DefaultPort))
+	url.AddParam(constant.HedgingDelayKey, delay)
+	url.AddParam(constant.HedgingMaxAttemptsKey, fmt.Sprint(maxAttempts))
+	url.AddParam(constant.LoadbalanceKey, constant.LoadBalanceKeyRoundRobin)
+	return url
+}
+
+func registerHedging(url *common.URL, mockInvokers ...*mock.MockInvoker) base.Invoker {
+	extension.SetLoadbalance(constant.LoadBalanceKeyRoundRobin, roundrobin.NewRRLoadBalance)
+
+	var invokers []base.Invoker
+	for _, ivk := range mockInvokers {
+		invokers = append(invokers, ivk)
+		ivk.EXPECT().GetURL().Return(url).AnyTimes()
+		ivk.EXPECT().IsAvailable().Return(true).AnyTimes()
+	}
+	staticDir := static.NewDirectory(invokers)
+
+	return newHedgingCluster().Join(staticDir)
+}
+
+func TestHedgingInvokeFastPrimary(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockResult := &result.RPCResult{Rest: clusterpkg.Rest{Tried: 0, Success: true}}
+	var calls atomic.Int32
+	invokers := make([]*mock.MockInvoker, 0)
+	for i := 0; i < 2; i++ {
+		invoker := mock.NewMockInvoker(ctrl)
+		invoker.EXPECT().Invoke(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(context.Context, base.Invocation) result.Result {
+				calls.Inc()
+				return mockResult
+			}).MaxTimes(1)
+		invokers = append(invokers, invoker)
+	}
+
+	clusterInvoker := registerHedging(newHedgingURL("500ms", 2), invokers...)
+	res := clusterInvoker.Invoke(context.Background(), &invocation.RPCInvocation{})
+	assert.Equal(t, mockResult, res)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestHedgingInvokeSlowPrimary(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockResult := &result.RPCResult{Rest: clusterpkg.Rest{Tried: 0, Success: true}}
+	var canceled atomic.Int32
+	invokers := make([]*mock.MockInvoker, 0)
+	for i := 0; i < 2; i++ {
+		invoker := mock.NewMockInvoker(ctrl)
+		invoker.EXPECT().Invoke(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, _ base.Invocation) result.Result {
+				if canceled.Load() == 0 {
+					// the first attempt blocks until it is canceled
+					canceled.Inc()
+					<-ctx.Done()
+					return &result.RPCResult{Err: ctx.Err()}
+				}
+				return mockResult
+			}).MaxTimes(1)
+		invokers = append(invokers, invoker)
+	}
+
+	clusterInvoker := registerHedging(newHedgingURL("20ms", 2), invokers...)
+	start := time.Now()
+	res := clusterInvoker.Invoke(context.Background(), &invocation.RPCInvocation{})
+	assert.Equal(t, mockResult, res)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestHedgingInvokeAllFailed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var calls atomic.Int32
+	invokers := make([]*mock.MockInvoker, 0)
+	for i := 0; i < 3; i++ {
+		invoker := mock.NewMockInvoker(ctrl)
+		invoker.EXPECT().Invoke(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(context.Context, base.Invocation) result.Result {
+				calls.Inc()
+				return &result.RPCResult{Err: errors.New("error")}
+			}).MaxTimes(1)
+		invokers = append(invokers, invoker)
+	}
+
+	// max attempts caps the invocations even though there are more invokers
+	clusterInvoker := registerHedging(newHedgingURL("1s", 2), invokers...)
+	start := time.Now()
+	res := clusterInvoker.Invoke(context.Background(), &invocation.RPCInvocation{})
+	assert.NotNil(t, res.Error())
+	assert.Equal(t, int32(2), calls.Load())
+	// a failed attempt triggers the next one without waiting for the delay
+	assert.Less(t, time.Since(start), time.Second)
+}
@@ -94,6 +94,7 @@ func BuildInterceptorChain(invoker base.Invoker, builtins ...Interceptor) base.I
 	interceptors := GetClusterInterceptors()
 	if len(interceptors) != 0 {
 		for i := len(interceptors) - 1; i >= 0; i-- {
+			initInterceptor(interceptors[i], invoker)
 			v := &InterceptorInvoker{next: next, interceptor: interceptors[i]}
 			next = v
 		}
@@ -101,6 +102,7 @@ func BuildInterceptorChain(invoker base.Invoker, builtins ...Interceptor) base.I
 
 	if len(builtins) > 0 {
 		for i := len(builtins) - 1; i >= 0; i-- {
+			initInterceptor(builtins[i], invoker)
 			v := &InterceptorInvoker{next: next, interceptor: builtins[i]}
 			next = v
 		}
@@ -108,3 +110,9 @@ func BuildInterceptorChain(invoker base.Invoker, builtins ...Interceptor) base.I
 
 	return next
 }
+
+func initInterceptor(interceptor Interceptor, invoker base.Invoker) {
+	if i, ok := interceptor.(InitializableInterceptor); ok {
+		i.Init(invoker.GetURL())
+	}
+}
@@ -0,0 +1,200 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package healthcheck
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+import (
+	"github.com/dubbogo/gost/log/logger"
+
+	"go.uber.org/atomic"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/cluster/directory"
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/protocol/base"
+)
+
+// expiration is the minimum time after which the invokers not listed anymore stop being probed.
+const expiration = time.Minute
+
+// target is the health state of an invoker. The counters are only accessed by the probe of
+// the invoker, and the probes of an invoker never overlap.
+type target struct {
+	lastSeen  atomic.Int64
+	unhealthy atomic.Bool
+	failures  int
+	successes int
+}
+
+// healthCheckDirectory probes the invokers of the wrapped directory periodically and hides
+// the unhealthy ones.
+type healthCheckDirectory struct {
+	directory.Directory
+	service          string
+	prober           prober
+	interval         time.Duration
+	timeout          time.Duration
+	failureThreshold int
+	successThreshold int
+
+	targets sync.Map // base.Invoker -> *target
+	done    chan struct{}
+	once    sync.Once
+}
+
+// NewDirectory returns a directory with active health checking if it is enabled by the url,
+// otherwise the given directory is returned.
+func NewDirectory(dir directory.Directory, url *common.URL) directory.Directory {
+	if url == nil || !url.GetParamBool(constant.HealthCheckEnabledKey, false) {
+		return dir
+	}
+	p, err := newProber(url)
+	if err != nil {
+		logger.Warnf("Health check of the service %s is disabled: %v", url.Service(), err)
+		return dir
+	}
+	d := &healthCheckDirectory{
+		Directory:        dir,
+		service:          url.Service(),
+		prober:           p,
+		interval:         getDuration(url, constant.HealthCheckIntervalKey, constant.DefaultHealthCheckInterval),
+		timeout:          getDuration(url, constant.HealthCheckTimeoutKey, constant.DefaultHealthCheckTimeout),
+		failureThreshold: getThreshold(url, constant.HealthCheckFailureThresholdKey, constant.DefaultHealthCheckFailureThreshold),
+		successThreshold: getThreshold(url, constant.HealthCheckSuccessThresholdKey, constant.DefaultHealthCheckSuccessThreshold),
+		done:             make(chan struct{}),
+	}
+	go d.run()
+	return d
+}
+
+func getDuration(url *common.URL, key, defaultValue string) time.Duration {
+	v := url.GetParam(key, defaultValue)
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		logger.Warnf("Illegal %s %s, the default value %s is used.", key, v, defaultValue)
+		d, _ = time.ParseDuration(defaultValue)
+	}
+	return d
+}
+
+func getThreshold(url *common.URL, key string, defaultValue int) int {
+	v := url.GetParamByIntValue(key, defaultValue)
+	if v <= 0 {
+		logger.Warnf("Illegal %s %d, the default value %d is used.", key, v, defaultValue)
+		v = defaultValue
+	}
+	return v
+}
+
+// List returns the healthy invokers. All the invokers are returned if none of them is
+// healthy, since it is better than no provider available.
+func (d *healthCheckDirectory) List(invocation base.Invocation) []base.Invoker {
+	invokers := d.Directory.List(invocation)
+	now := time.Now().UnixNano()
+	healthy := make([]base.Invoker, 0, len(invokers))
+	for _, ivk := range invokers {
+		if !d.getTarget(ivk, now).unhealthy.Load() {
+			healthy = append(healthy, ivk)
+		}
+	}
+	if len(healthy) == 0 {
+		return invokers
+	}
+	return healthy
+}
+
+// Destroy stops the health checking and destroys the wrapped directory.
+func (d *healthCheckDirectory) Destroy() {
+	d.once.Do(func() {
+		close(d.done)
+	})
+	d.Directory.Destroy()
+}
+
+func (d *healthCheckDirectory) getTarget(invoker base.Invoker, now int64) *target {
+	t, ok := d.targets.Load(invoker)
+	if !ok {
+		t, _ = d.targets.LoadOrStore(invoker, &target{})
+	}
+	t.(*target).lastSeen.Store(now)
+	return t.(*target)
+}
+
+func (d *healthCheckDirectory) run() {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-d.done:
+			return
+		case <-ticker.C:
+			d.probeAll()
+		}
+	}
+}
+
+// probeAll probes all the invokers listed recently at the same time, and forgets the others.
+func (d *healthCheckDirectory) probeAll() {
+	ttl := expiration
+	if ttl < 3*d.interval {
+		ttl = 3 * d.interval
+	}
+	expired := time.Now().Add(-ttl).UnixNano()
+	var wg sync.WaitGroup
+	d.targets.Range(func(key, value any) bool {
+		ivk, t := key.(base.Invoker), value.(*target)
+		if t.lastSeen.Load() < expired {
+			d.targets.Delete(key)
+			return true
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d.probe(ivk, t)
+		}()
+		return true
+	})
+	wg.Wait()
+}
+
+func (d *healthCheckDirectory) probe(invoker base.Invoker, t *target) {
+	ctx, cancel := context.WithTimeout(context.Background(), d.timeout)
+	defer cancel()
+	if err := d.prober(ctx, invoker); err != nil {
+		t.successes = 0
+		t.failures++
+		if t.failures >= d.failureThreshold && t.unhealthy.CAS(false, true) {
+			logger.Warnf("The provider %s of the service %s is unhealthy after %d failed health checks: %v",
+				invoker.GetURL().Location, d.service, t.failures, err)
+		}
+		return
+	}
+	t.failures = 0
+	t.successes++
+	if t.successes >= d.successThreshold && t.unhealthy.CAS(true, false) {
+		logger.Infof("The provider %s of the service %s is healthy again after %d successful health checks.",
+			invoker.GetURL().Location, d.service, t.successes)
+	}
+}
@@ -0,0 +1,336 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package healthcheck
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/stretchr/testify/assert"
+
+	"go.uber.org/atomic"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/cluster/directory/static"
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	_ "dubbo.apache.org/dubbo-go/v3/filter/active"
+	"dubbo.apache.org/dubbo-go/v3/global"
+	"dubbo.apache.org/dubbo-go/v3/protocol/base"
+	"dubbo.apache.org/dubbo-go/v3/protocol/invocation"
+	"dubbo.apache.org/dubbo-go/v3/protocol/protocolwrapper"
+	"dubbo.apache.org/dubbo-go/v3/protocol/result"
+)
+
+const testService = "com.ikurento.user.UserProvider"
+
+type testInvoker struct {
+	*base.BaseInvoker
+	lock    sync.Mutex
+	methods []string
+	fail    atomic.Bool
+}
+
+func newTestInvoker(t *testing.T, rawURL string) *testInvoker {
+	url, err := common.NewURL(rawURL)
+	assert.Nil(t, err)
+	return &testInvoker{BaseInvoker: base.NewBaseInvoker(url)}
+}
+
+func (ivk *testInvoker) Invoke(_ context.Context, inv base.Invocation) result.Result {
+	ivk.lock.Lock()
+	ivk.methods = append(ivk.methods, inv.MethodName())
+	ivk.lock.Unlock()
+	if ivk.fail.Load() {
+		return &result.RPCResult{Err: errors.New("error")}
+	}
+	return &result.RPCResult{Rest: inv.Arguments()[0]}
+}
+
+func TestNewDirectoryDisabled(t *testing.T) {
+	ivk := newTestInvoker(t, "dubbo://127.0.0.1:20000/"+testService)
+	dir := static.NewDirectory([]base.Invoker{ivk})
+	assert.Equal(t, dir, NewDirectory(dir, ivk.GetURL()))
+	assert.Equal(t, dir, NewDirectory(dir, nil))
+
+	url := ivk.GetURL().Clone()
+	url.SetParam(constant.HealthCheckEnabledKey, "true")
+	url.SetParam(constant.HealthCheckProberKey, "unknown")
+	assert.Equal(t, dir, NewDirectory(dir, url))
+}
+
+func TestNewProber(t *testing.T) {
+	for _, rawURL := range []string{
+		"dubbo://127.0.0.1:20000/" + testService,
+		"tri://127.0.0.1:20000/" + testService,
+		"jsonrpc://127.0.0.1:20000/" + testService,
+	} {
+		url, _ := common.NewURL(rawURL)
+		p, err := newProber(url)
+		assert.Nil(t, err)
+		assert.NotNil(t, p)
+	}
+
+	url, _ := common.NewURL("tri://127.0.0.1:20000/" + testService)
+	url.SetParam(constant.HealthCheckProberKey, "unknown")
+	_, err := newProber(url)
+	assert.NotNil(t, err)
+}
+
+func TestProbeTCP(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	ivk := newTestInvoker(t, "jsonrpc://"+l.Addr().String()+"/"+testService)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.Nil(t, probeTCP(ctx, ivk))
+
+	assert.Nil(t, l.Close())
+	assert.NotNil(t, probeTCP(ctx, ivk))
+}
+
+func TestProbeEcho(t *testing.T) {
+	ivk := newTestInvoker(t, "dubbo://127.0.0.1:20000/"+testService)
+	assert.Nil(t, probeEcho(context.Background(), ivk))
+	assert.Equal(t, []string{constant.Echo}, ivk.methods)
+
+	ivk.fail.Store(true)
+	assert.NotNil(t, probeEcho(context.Background(), ivk))
+}
+
+func TestProbeEchoBelowFilters(t *testing.T) {
+	ivk := newTestInvoker(t, "dubbo://127.0.0.1:20001/"+testService+"?"+
+		constant.ReferenceFilterKey+"="+constant.ActiveFilterKey)
+	chain := protocolwrapper.BuildInvokerChain(ivk, constant.ReferenceFilterKey)
+	assert.NotEqual(t, base.Invoker(ivk), chain)
+
+	assert.Nil(t, probeEcho(context.Background(), chain))
+	assert.Equal(t, []string{constant.Echo}, ivk.methods)
+	// the probe is not counted by the active filter
+	assert.Equal(t, int32(0), base.GetURLStatus(ivk.GetURL()).GetTotal())
+	assert.Equal(t, int32(0), base.GetMethodStatus(ivk.GetURL(), constant.Echo).GetTotal())
+}
+
+func TestProbeTriple(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	hs := health.NewServer()
+	srv := grpc.NewServer()
+	grpc_health_v1.RegisterHealthServer(srv, hs)
+	go func() {
+		_ = srv.Serve(l)
+	}()
+	defer srv.Stop()
+
+	ivk := newTestInvoker(t, "tri://"+l.Addr().String()+"/"+testService)
+	probe, err := newTripleProber(ivk.GetURL())
+	assert.Nil(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	// the service is unknown to the health server
+	assert.NotNil(t, probe(ctx, ivk))
+	hs.SetServingStatus(testService, grpc_health_v1.HealthCheckResponse_SERVING)
+	assert.Nil(t, probe(ctx, ivk))
+	hs.SetServingStatus(testService, grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+	assert.NotNil(t, probe(ctx, ivk))
+}
+
+func TestProbeTripleUnimplemented(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	srv := grpc.NewServer()
+	go func() {
+		_ = srv.Serve(l)
+	}()
+	defer srv.Stop()
+
+	ivk := newTestInvoker(t, "tri://"+l.Addr().String()+"/"+testService)
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	probe, err := newTripleProber(ivk.GetURL())
+	assert.Nil(t, err)
+	assert.Nil(t, probe(ctx, ivk))
+
+	srv.Stop()
+	assert.NotNil(t, probe(ctx, ivk))
+}
+
+func TestProbeTripleTLS(t *testing.T) {
+	cert, caFile := newTestCert(t)
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	hs := health.NewServer()
+	hs.SetServingStatus(testService, grpc_health_v1.HealthCheckResponse_SERVING)
+	srv := grpc.NewServer(grpc.Creds(credentials.NewServerTLSFromCert(&cert)))
+	grpc_health_v1.RegisterHealthServer(srv, hs)
+	go func() {
+		_ = srv.Serve(l)
+	}()
+	defer srv.Stop()
+
+	ivk := newTestInvoker(t, "tri://"+l.Addr().String()+"/"+testService)
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	// the h2c prober fails to talk to the TLS provider
+	probe, err := newTripleProber(ivk.GetURL())
+	assert.Nil(t, err)
+	assert.NotNil(t, probe(ctx, ivk))
+
+	url := ivk.GetURL().Clone()
+	url.SetAttribute(constant.TLSConfigKey, &global.TLSConfig{CACertFile: caFile, TLSServerName: "127.0.0.1"})
+	probe, err = newTripleProber(url)
+	assert.Nil(t, err)
+	assert.Nil(t, probe(ctx, ivk))
+
+	url.SetAttribute(constant.TLSConfigKey, &global.TLSConfig{CACertFile: caFile + ".missing"})
+	_, err = newTripleProber(url)
+	assert.NotNil(t, err)
+}
+
+// newTestCert returns a self-signed certificate of 127.0.0.1 and the file of it as the CA.
+func newTestCert(t *testing.T) (tls.Certificate, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	assert.Nil(t, err)
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	assert.Nil(t, err)
+	cert, err := tls.X509KeyPair(certPEM, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+	assert.Nil(t, err)
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	assert.Nil(t, os.WriteFile(caFile, certPEM, 0o600))
+	return cert, caFile
+}
+
+func TestDirectory(t *testing.T) {
+	var invokers []base.Invoker
+	var testInvokers []*testInvoker
+	for i := 0; i < 3; i++ {
+		ivk := newTestInvoker(t, fmt.Sprintf("dubbo://192.168.1.%d:20000/%s", i, testService))
+		invokers = append(invokers, ivk)
+		testInvokers = append(testInvokers, ivk)
+	}
+	url := invokers[0].GetURL().Clone()
+	url.SetParam(constant.HealthCheckEnabledKey, "true")
+	url.SetParam(constant.HealthCheckIntervalKey, "10ms")
+	url.SetParam(constant.HealthCheckFailureThresholdKey, "2")
+	url.SetParam(constant.HealthCheckSuccessThresholdKey, "3")
+	dir, ok := NewDirectory(static.NewDirectory(invokers), url).(*healthCheckDirectory)
+	assert.True(t, ok)
+	defer dir.Destroy()
+	assert.Equal(t, 2, dir.failureThreshold)
+	assert.Equal(t, 3, dir.successThreshold)
+
+	inv := invocation.NewRPCInvocationWithOptions(invocation.WithMethodName("test"))
+	assert.Len(t, dir.List(inv), 3)
+
+	testInvokers[1].fail.Store(true)
+	assert.Eventually(t, func() bool {
+		return len(dir.List(inv)) == 2
+	}, time.Second, 10*time.Millisecond)
+	assert.NotContains(t, dir.List(inv), invokers[1])
+
+	// all the invokers are returned when none of them is healthy
+	testInvokers[0].fail.Store(true)
+	testInvokers[2].fail.Store(true)
+	assert.Eventually(t, func() bool {
+		t0, _ := dir.targets.Load(invokers[0])
+		t2, _ := dir.targets.Load(invokers[2])
+		return t0.(*target).unhealthy.Load() && t2.(*target).unhealthy.Load()
+	}, time.Second, 10*time.Millisecond)
+	assert.Len(t, dir.List(inv), 3)
+
+	testInvokers[0].fail.Store(false)
+	testInvokers[1].fail.Store(false)
+	testInvokers[2].fail.Store(false)
+	assert.Eventually(t, func() bool {
+		return len(dir.List(inv)) == 3
+	}, time.Second, 10*time.Millisecond)
+	for _, ivk := range dir.List(inv) {
+		_, ok := dir.targets.Load(ivk)
+		assert.True(t, ok)
+	}
+}
+
+func TestProbeThreshold(t *testing.T) {
+	ivk := newTestInvoker(t, "dubbo://127.0.0.1:20000/"+testService)
+	d := &healthCheckDirectory{
+		prober:           probeEcho,
+		timeout:          time.Second,
+		failureThreshold: 2,
+		successThreshold: 2,
+	}
+	tg := &target{}
+
+	ivk.fail.Store(true)
+	d.probe(ivk, tg)
+	assert.False(t, tg.unhealthy.Load())
+	d.probe(ivk, tg)
+	assert.True(t, tg.unhealthy.Load())
+
+	ivk.fail.Store(false)
+	d.probe(ivk, tg)
+	assert.True(t, tg.unhealthy.Load())
+	// a failure resets the successes
+	ivk.fail.Store(true)
+	d.probe(ivk, tg)
+	ivk.fail.Store(false)
+	d.probe(ivk, tg)
+	assert.True(t, tg.unhealthy.Load())
+	d.probe(ivk, tg)
+	assert.False(t, tg.unhealthy.Load())
+}
@@ -0,0 +1,171 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package healthcheck
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+import (
+	"golang.org/x/net/http2"
+
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/global"
+	"dubbo.apache.org/dubbo-go/v3/protocol/base"
+	"dubbo.apache.org/dubbo-go/v3/protocol/invocation"
+	"dubbo.apache.org/dubbo-go/v3/protocol/triple/triple_protocol"
+	dubbotls "dubbo.apache.org/dubbo-go/v3/tls"
+)
+
+const (
+	proberTriple = "triple"
+	proberEcho   = "echo"
+	proberTCP    = "tcp"
+)
+
+// prober probes the provider of the invoker, a nil error means the provider is healthy.
+type prober func(ctx context.Context, invoker base.Invoker) error
+
+// newProber returns the prober configured by the url, or the prober chosen by the protocol
+// of the url if it is not configured.
+func newProber(url *common.URL) (prober, error) {
+	name := url.GetParam(constant.HealthCheckProberKey, "")
+	if name == "" {
+		switch url.Protocol {
+		case constant.TriProtocol:
+			name = proberTriple
+		case constant.DubboProtocol:
+			name = proberEcho
+		default:
+			name = proberTCP
+		}
+	}
+	switch name {
+	case proberTriple:
+		return newTripleProber(url)
+	case proberEcho:
+		return probeEcho, nil
+	case proberTCP:
+		return probeTCP, nil
+	default:
+		return nil, fmt.Errorf("unknown health check prober %s", name)
+	}
+}
+
+// probeTCP checks whether the address of the provider is connectable.
+func probeTCP(ctx context.Context, invoker base.Invoker) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", invoker.GetURL().Location)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// probeEcho invokes the $echo method of the provider, which is answered by the echo filter.
+// The provider is invoked below the consumer filter chain, so that the probes are not counted
+// by the filters collecting the statistics of the invokers for the load balancers.
+func probeEcho(ctx context.Context, invoker base.Invoker) error {
+	var reply any
+	inv := invocation.NewRPCInvocationWithOptions(
+		invocation.WithMethodName(constant.Echo),
+		invocation.WithArguments([]any{"OK"}),
+		invocation.WithReply(&reply),
+	)
+	return unwrap(invoker).Invoke(ctx, inv).Error()
+}
+
+// unwrap returns the innermost invoker of the invoker wrapped by the filter chain.
+func unwrap(invoker base.Invoker) base.Invoker {
+	for {
+		w, ok := invoker.(interface{ Unwrap() base.Invoker })
+		if !ok {
+			return invoker
+		}
+		invoker = w.Unwrap()
+	}
+}
+
+// newTripleProber returns a prober calling the gRPC health service of the triple provider, over TLS if the
+// reference of the url is configured with TLS, or over h2c otherwise.
+// The provider is regarded as healthy if it does not serve the health service at all, since
+// it is connectable and responds.
+func newTripleProber(url *common.URL) (prober, error) {
+	cfg, err := tripleTLSConfig(url)
+	if err != nil {
+		return nil, err
+	}
+	scheme := "http://"
+	transport := &http2.Transport{
+		DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, network, addr)
+		},
+		AllowHTTP: true,
+	}
+	if cfg != nil {
+		scheme = "https://"
+		transport = &http2.Transport{TLSClientConfig: cfg}
+	}
+	httpClient := &http.Client{Transport: transport}
+	return func(ctx context.Context, invoker base.Invoker) error {
+		url := invoker.GetURL()
+		cli := triple_protocol.NewClient(httpClient, scheme+url.Location+"/"+constant.HealthCheckServiceInterface+"/Check")
+		resp := new(grpc_health_v1.HealthCheckResponse)
+		err := cli.CallUnary(ctx,
+			triple_protocol.NewRequest(&grpc_health_v1.HealthCheckRequest{Service: url.Service()}),
+			triple_protocol.NewResponse(resp))
+		if err != nil {
+			if triple_protocol.CodeOf(err) == triple_protocol.CodeUnimplemented {
+				return nil
+			}
+			return err
+		}
+		if resp.Status != grpc_health_v1.HealthCheckResponse_SERVING {
+			return errors.New("the service is " + resp.Status.String())
+		}
+		return nil
+	}, nil
+}
+
+// tripleTLSConfig returns the client TLS config of the reference the same as the triple protocol does, it returns
+// nil if TLS is not configured.
+func tripleTLSConfig(url *common.URL) (*tls.Config, error) {
+	raw, ok := url.GetAttribute(constant.TLSConfigKey)
+	if !ok {
+		return nil, nil
+	}
+	tlsConf, ok := raw.(*global.TLSConfig)
+	if !ok {
+		return nil, fmt.Errorf("the tls config of the service %s is a %T", url.Service(), raw)
+	}
+	if !dubbotls.IsClientTLSValid(tlsConf) {
+		return nil, nil
+	}
+	return dubbotls.GetClientTlSConfig(tlsConf)
+}
@@ -20,6 +20,7 @@ package aliasmethod // weighted random with alias-method algorithm
 
 import (
 	"math/rand"
+	"slices"
 )
 
 import (
@@ -29,6 +30,7 @@ import (
 
 type aliasMethodPicker struct {
 	invokers []base.Invoker // Instance
+	weights  []int64
 
 	weightSum int64
 	alias     []int
@@ -36,17 +38,22 @@ type aliasMethodPicker struct {
 }
 
 func NewAliasMethodPicker(invokers []base.Invoker, invocation base.Invocation) *aliasMethodPicker {
+	return newAliasMethodPicker(invokers, loadbalance.GetWeights(invokers, invocation))
+}
+
+func newAliasMethodPicker(invokers []base.Invoker, weights []int64) *aliasMethodPicker {
 	am := &aliasMethodPicker{
 		invokers: invokers,
+		weights:  weights,
 	}
-	am.init(invocation)
+	am.init()
 	return am
 }
 
 // Alias Method: https://en.wikipedia.org/wiki/Alias_method
-func (am *aliasMethodPicker) init(invocation base.Invocation) {
+func (am *aliasMethodPicker) init() {
 	n := len(am.invokers)
-	weights := make([]int64, n)
+	weights := am.weights
 	am.alias = make([]int, n)
 	am.prob = make([]float64, n)
 
@@ -56,9 +63,7 @@ func (am *aliasMethodPicker) init(invocation base.Invocation) {
 	small := make([]int, 0, n)
 	large := make([]int, 0, n)
 
-	for i, invoker := range am.invokers {
-		weight := loadbalance.GetWeight(invoker, invocation)
-		weights[i] = weight
+	for _, weight := range weights {
 		totalWeight += weight
 	}
 	// when invoker weight all zero
@@ -106,6 +111,11 @@ func (am *aliasMethodPicker) init(invocation base.Invocation) {
 	}
 }
 
+// isStale reports whether the invokers or their weights have changed since the picker was built.
+func (am *aliasMethodPicker) isStale(invokers []base.Invoker, weights []int64) bool {
+	return !slices.Equal(am.invokers, invokers) || !slices.Equal(am.weights, weights)
+}
+
 func (am *aliasMethodPicker) Pick() base.Invoker {
 	i := rand.Intn(len(am.invokers)) //NOSONAR
 	if rand.Float64() < am.prob[i] { //NOSONAR
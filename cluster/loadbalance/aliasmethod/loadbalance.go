@@ -18,6 +18,10 @@
 // Package aliasmethod implements alias-method algorithm load balance strategy.
 package aliasmethod // weighted random with alias-method algorithm
 
+import (
+	"sync"
+)
+
 import (
 	"dubbo.apache.org/dubbo-go/v3/cluster/loadbalance"
 	"dubbo.apache.org/dubbo-go/v3/common/constant"
@@ -29,11 +33,26 @@ func init() {
 	extension.SetLoadbalance(constant.LoadBalanceKeyAliasMethod, newWeightedRandomWithAliasMethodBalance)
 }
 
-type weightedRandomWithAliasMethodBalance struct{}
+var (
+	once     sync.Once
+	instance loadbalance.LoadBalance
+)
+
+type weightedRandomWithAliasMethodBalance struct {
+	// pickers caches the picker of each service and method, "{service key}.{method}" -> *aliasMethodPicker
+	pickers sync.Map
+}
 
 // newWeightedRandomWithAliasMethodBalance returns a loadbalancer using alias-method algorithm..
+//
+// The picker is cached and rebuilt when the invokers or their weights change, e.g. during the slow start.
 func newWeightedRandomWithAliasMethodBalance() loadbalance.LoadBalance {
-	return &weightedRandomWithAliasMethodBalance{}
+	if instance == nil {
+		once.Do(func() {
+			instance = &weightedRandomWithAliasMethodBalance{}
+		})
+	}
+	return instance
 }
 
 // Select gets invoker based on interleaved weighted round robine load balancing strategy
@@ -46,6 +65,12 @@ func (lb *weightedRandomWithAliasMethodBalance) Select(invokers []base.Invoker,
 		return invokers[0]
 	}
 
-	wramp := NewAliasMethodPicker(invokers, invocation)
+	key := invokers[0].GetURL().ServiceKey() + "." + invocation.MethodName()
+	weights := loadbalance.GetWeights(invokers, invocation)
+	if cached, ok := lb.pickers.Load(key); ok && !cached.(*aliasMethodPicker).isStale(invokers, weights) {
+		return cached.(*aliasMethodPicker).Pick()
+	}
+	wramp := newAliasMethodPicker(invokers, weights)
+	lb.pickers.Store(key, wramp)
 	return wramp.Pick()
 }
@@ -18,9 +18,8 @@
 package consistenthashing
 
 import (
-	"encoding/json"
-	"hash/crc32"
 	"regexp"
+	"sync"
 )
 
 import (
@@ -35,10 +34,15 @@ const (
 	HashNodes = "hash.nodes"
 	// HashArguments key of hash arguments in url
 	HashArguments = "hash.arguments"
+	// HashAttachment key of the attachment which is hashed instead of the arguments
+	HashAttachment = "hash.attachment"
+	// HashBalanceFactor key of the bounded load factor, the in-flight requests of an invoker
+	// are bounded by the factor times the average. The bounded load is disabled if it is not set.
+	HashBalanceFactor = "hash.balance-factor"
 )
 
 var (
-	selectors = make(map[string]*selector)
+	selectors sync.Map // "{service key}.{method}" -> *selector
 	re        = regexp.MustCompile(constant.CommaSplitPattern)
 )
 
@@ -51,30 +55,29 @@ type conshashLoadBalance struct{}
 
 // newConshashLoadBalance creates NewConsistentHashLoadBalance
 //
-// The same parameters of the request is always sent to the same provider.
+// The same parameters of the request is always sent to the same provider. If the bounded load is enabled,
+// the request walks to the next provider on the ring when the provider is overloaded.
 func newConshashLoadBalance() loadbalance.LoadBalance {
 	return &conshashLoadBalance{}
 }
 
 // Select gets invoker based on load balancing strategy
 func (lb *conshashLoadBalance) Select(invokers []base.Invoker, invocation base.Invocation) base.Invoker {
+	if len(invokers) == 0 {
+		return nil
+	}
 	methodName := invocation.MethodName()
 	key := invokers[0].GetURL().ServiceKey() + "." + methodName
 
-	// hash the invokers
-	var bs []byte
-	for _, invoker := range invokers {
-		b, err := json.Marshal(invoker)
-		if err != nil {
-			return nil
+	if v, ok := selectors.Load(key); ok {
+		if selector, available, ok := v.(*selector).match(invokers); ok {
+			return selector.Select(invocation, available)
 		}
-		bs = append(bs, b...)
 	}
-	hashCode := crc32.ChecksumIEEE(bs)
-	selector, ok := selectors[key]
-	if !ok || selector.hashCode != hashCode {
-		selectors[key] = newSelector(invokers, methodName, hashCode)
-		selector = selectors[key]
+	selector := newSelector(invokers, methodName)
+	if selector == nil {
+		return nil
 	}
-	return selector.Select(invocation)
+	selectors.Store(key, selector)
+	return selector.Select(invocation, nil)
 }
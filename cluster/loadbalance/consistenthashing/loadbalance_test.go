@@ -59,7 +59,7 @@ func (s *consistentHashSelectorSuite) SetupTest() {
 	var invokers []base.Invoker
 	url, _ := common.NewURL(url20000)
 	invokers = append(invokers, base.NewBaseInvoker(url))
-	s.selector = newSelector(invokers, "echo", 999944)
+	s.selector = newSelector(invokers, "echo")
 }
 
 func (s *consistentHashSelectorSuite) TestToKey() {
@@ -107,7 +107,7 @@ func (s *consistentHashLoadBalanceSuite) SetupTest() {
 	s.invoker2 = base.NewBaseInvoker(s.url2)
 	s.invoker3 = base.NewBaseInvoker(s.url3)
 
-	s.invokers = append(s.invokers, s.invoker1, s.invoker2, s.invoker3)
+	s.invokers = []base.Invoker{s.invoker1, s.invoker2, s.invoker3}
 	s.lb = newConshashLoadBalance()
 }
 
@@ -120,3 +120,91 @@ func (s *consistentHashLoadBalanceSuite) TestSelect() {
 	invoker = s.lb.Select(s.invokers, invocation.NewRPCInvocation("echo", args, nil))
 	s.Equal(fmt.Sprintf("%s:%d", ip, port8080), invoker.GetURL().Location)
 }
+
+func (s *consistentHashLoadBalanceSuite) TestSelectByAttachment() {
+	var invokers []base.Invoker
+	for _, port := range []int{8080, 8081, 8082} {
+		url, err := common.NewURL(fmt.Sprintf("dubbo://%s:%d/org.apache.demo.HelloService?methods.echo.hash.attachment=userId", ip, port))
+		s.NoError(err)
+		invokers = append(invokers, base.NewBaseInvoker(url))
+	}
+
+	inv := invocation.NewRPCInvocation("echo", []any{"a"}, map[string]any{"userId": "10086"})
+	expected := s.lb.Select(invokers, inv)
+	for _, args := range [][]any{{"b"}, {"c", "d"}, nil} {
+		inv = invocation.NewRPCInvocation("echo", args, map[string]any{"userId": "10086"})
+		s.Equal(expected, s.lb.Select(invokers, inv))
+	}
+}
+
+func (s *consistentHashLoadBalanceSuite) TestSelectWithBoundedLoad() {
+	defer base.CleanAllStatus()
+	var invokers []base.Invoker
+	for _, port := range []int{8080, 8081, 8082} {
+		url, err := common.NewURL(fmt.Sprintf("dubbo://%s:%d/org.apache.demo.HelloService?methods.echo.hash.balance-factor=1.25", ip, port))
+		s.NoError(err)
+		invokers = append(invokers, base.NewBaseInvoker(url))
+	}
+
+	args := []any{"name", "password", "age"}
+	expected := s.lb.Select(invokers, invocation.NewRPCInvocation("echo", args, nil))
+	s.NotNil(expected)
+
+	// overload the selected invoker, so the request walks to the next invoker on the ring
+	for i := 0; i < 3; i++ {
+		base.BeginCount(expected.GetURL(), "echo")
+	}
+	invoker := s.lb.Select(invokers, invocation.NewRPCInvocation("echo", args, nil))
+	s.NotEqual(expected, invoker)
+
+	for i := 0; i < 3; i++ {
+		base.EndCount(expected.GetURL(), "echo", 1, true)
+	}
+	invoker = s.lb.Select(invokers, invocation.NewRPCInvocation("echo", args, nil))
+	s.Equal(expected, invoker)
+}
+
+func (s *consistentHashLoadBalanceSuite) TestSelectorRebuiltOnInvokersChanged() {
+	inv := invocation.NewRPCInvocation("echo", []any{"a"}, map[string]any{"userId": "10086"})
+	s.lb.Select(s.invokers, inv)
+	v, ok := selectors.Load(s.url1.ServiceKey() + ".echo")
+	s.True(ok)
+	s.Equal("", v.(*selector).attachmentKey)
+
+	// the invokers are referred again once their urls are changed
+	invokers := make([]base.Invoker, 0, len(s.invokers))
+	for _, invoker := range s.invokers {
+		url := invoker.GetURL().Clone()
+		url.SetParam("methods.echo."+HashAttachment, "userId")
+		invokers = append(invokers, base.NewBaseInvoker(url))
+	}
+	s.lb.Select(invokers, inv)
+	v, _ = selectors.Load(s.url1.ServiceKey() + ".echo")
+	s.Equal("userId", v.(*selector).attachmentKey)
+}
+
+func (s *consistentHashLoadBalanceSuite) TestSelectFromPartOfInvokers() {
+	args := []any{"name", "password", "age"}
+	expected := s.lb.Select(s.invokers, invocation.NewRPCInvocation("echo", args, nil))
+	cached, _ := selectors.Load(s.url1.ServiceKey() + ".echo")
+
+	// the invoker tried is excluded, e.g. by the failover cluster
+	var others []base.Invoker
+	for _, invoker := range s.invokers {
+		if invoker != expected {
+			others = append(others, invoker)
+		}
+	}
+	invoker := s.lb.Select(others, invocation.NewRPCInvocation("echo", args, nil))
+	s.NotNil(invoker)
+	s.NotEqual(expected, invoker)
+	v, _ := selectors.Load(s.url1.ServiceKey() + ".echo")
+	s.Same(cached, v)
+	s.Equal(expected, s.lb.Select(s.invokers, invocation.NewRPCInvocation("echo", args, nil)))
+
+	// the selector is rebuilt once the invoker excluded is destroyed
+	expected.Destroy()
+	s.Equal(invoker, s.lb.Select(others, invocation.NewRPCInvocation("echo", args, nil)))
+	v, _ = selectors.Load(s.url1.ServiceKey() + ".echo")
+	s.NotSame(cached, v)
+}
@@ -20,12 +20,14 @@ package consistenthashing
 import (
 	"crypto/md5"
 	"fmt"
+	"math"
 	"sort"
 	"strconv"
 	"strings"
 )
 
 import (
+	"github.com/dubbogo/gost/log/logger"
 	gxsort "github.com/dubbogo/gost/sort"
 )
 
@@ -35,21 +37,39 @@ import (
 
 // selector implementation of Selector:get invoker based on load balancing strategy
 type selector struct {
-	hashCode        uint32
 	replicaNum      int
 	virtualInvokers map[uint32]base.Invoker
 	keys            gxsort.Uint32Slice
 	argumentIndex   []int
+	attachmentKey   string
+	methodName      string
+	invokers        []base.Invoker
+	members         map[base.Invoker]struct{}
+	// balanceFactor bounds the load of each invoker, 0 means the bounded load is disabled.
+	balanceFactor float64
 }
 
-func newSelector(invokers []base.Invoker, methodName string,
-	hashCode uint32) *selector {
-
+func newSelector(invokers []base.Invoker, methodName string) *selector {
 	selector := &selector{}
 	selector.virtualInvokers = make(map[uint32]base.Invoker)
-	selector.hashCode = hashCode
+	selector.methodName = methodName
+	selector.invokers = invokers
+	selector.members = make(map[base.Invoker]struct{}, len(invokers))
+	for _, invoker := range invokers {
+		selector.members[invoker] = struct{}{}
+	}
 	url := invokers[0].GetURL()
 	selector.replicaNum = url.GetMethodParamIntValue(methodName, HashNodes, 160)
+	selector.attachmentKey = url.GetMethodParam(methodName, HashAttachment, "")
+	if factor := url.GetMethodParam(methodName, HashBalanceFactor, ""); factor != "" {
+		f, err := strconv.ParseFloat(factor, 64)
+		if err != nil || f < 1 {
+			logger.Warnf("Illegal hash balance factor %s of the method %s, it should be a number not less than 1, "+
+				"the bounded load is disabled.", factor, methodName)
+		} else {
+			selector.balanceFactor = f
+		}
+	}
 	indices := re.Split(url.GetMethodParam(methodName, HashArguments, "0"), -1)
 	for _, index := range indices {
 		i, err := strconv.Atoi(index)
@@ -74,10 +94,52 @@ func newSelector(invokers []base.Invoker, methodName string,
 	return selector
 }
 
-// Select gets invoker based on load balancing strategy
-func (c *selector) Select(invocation base.Invocation) base.Invoker {
-	key := c.toKey(invocation.Arguments())
+// match returns whether the selector can select from the invokers, it is keyed on the identities of the
+// invokers, since the registry directory refers a new invoker once the url of a provider is changed.
+// The invokers may be a part of the invokers of the selector, e.g. the invokers already tried are
+// excluded by the failover cluster, the returned set holds the available ones in that case. But the
+// selector is not used anymore once any of the excluded invokers is destroyed.
+func (c *selector) match(invokers []base.Invoker) (*selector, map[base.Invoker]struct{}, bool) {
+	if len(invokers) > len(c.invokers) {
+		return nil, nil, false
+	}
+	for _, invoker := range invokers {
+		if _, ok := c.members[invoker]; !ok {
+			return nil, nil, false
+		}
+	}
+	if len(invokers) == len(c.invokers) {
+		return c, nil, true
+	}
+	available := make(map[base.Invoker]struct{}, len(invokers))
+	for _, invoker := range invokers {
+		available[invoker] = struct{}{}
+	}
+	for _, invoker := range c.invokers {
+		if _, ok := available[invoker]; !ok && !invoker.IsAvailable() {
+			return nil, nil, false
+		}
+	}
+	return c, available, true
+}
+
+// Select gets invoker based on load balancing strategy, only the available invokers are selected
+// if they are not nil.
+func (c *selector) Select(invocation base.Invocation, available map[base.Invoker]struct{}) base.Invoker {
+	var key string
+	if c.attachmentKey != "" {
+		key, _ = invocation.GetAttachment(c.attachmentKey)
+	}
+	if key == "" {
+		key = c.toKey(invocation.Arguments())
+	}
 	digest := md5.Sum([]byte(key))
+	if c.balanceFactor > 0 {
+		return c.selectForKeyWithBoundedLoad(c.hash(digest, 0), available)
+	}
+	if available != nil {
+		return c.selectAvailableForKey(c.hash(digest, 0), available)
+	}
 	return c.selectForKey(c.hash(digest, 0))
 }
 
@@ -101,6 +163,56 @@ func (c *selector) selectForKey(hash uint32) base.Invoker {
 	return c.virtualInvokers[c.keys[idx]]
 }
 
+// selectAvailableForKey walks the ring from the hash, and selects the first available invoker.
+func (c *selector) selectAvailableForKey(hash uint32, available map[base.Invoker]struct{}) base.Invoker {
+	idx := sort.Search(len(c.keys), func(i int) bool {
+		return c.keys[i] >= hash
+	})
+	for i := 0; i < len(c.keys); i++ {
+		invoker := c.virtualInvokers[c.keys[(idx+i)%len(c.keys)]]
+		if _, ok := available[invoker]; ok {
+			return invoker
+		}
+	}
+	return nil
+}
+
+// selectForKeyWithBoundedLoad walks the ring from the hash, and selects the first invoker whose in-flight
+// requests don't exceed the balance factor times the average, see "Consistent Hashing with Bounded Loads".
+// The in-flight requests are counted by the active filter. Only the available invokers are selected if they
+// are not nil.
+func (c *selector) selectForKeyWithBoundedLoad(hash uint32, available map[base.Invoker]struct{}) base.Invoker {
+	if len(c.keys) == 0 {
+		return nil
+	}
+	var total int64
+	loads := make(map[base.Invoker]int64, len(c.invokers))
+	for _, invoker := range c.invokers {
+		if _, ok := available[invoker]; available != nil && !ok {
+			continue
+		}
+		load := int64(base.GetMethodStatus(invoker.GetURL(), c.methodName).GetActive())
+		loads[invoker] = load
+		total += load
+	}
+	// the request being selected is counted as well
+	capacity := int64(math.Ceil(c.balanceFactor * float64(total+1) / float64(len(loads))))
+
+	idx := sort.Search(len(c.keys), func(i int) bool {
+		return c.keys[i] >= hash
+	})
+	for i := 0; i < len(c.keys); i++ {
+		invoker := c.virtualInvokers[c.keys[(idx+i)%len(c.keys)]]
+		if load, ok := loads[invoker]; ok && load+1 <= capacity {
+			return invoker
+		}
+	}
+	if available != nil {
+		return c.selectAvailableForKey(hash, available)
+	}
+	return c.selectForKey(hash)
+}
+
 func (c *selector) hash(digest [16]byte, i int) uint32 {
 	return (uint32(digest[3+i*4]&0xFF) << 24) | (uint32(digest[2+i*4]&0xFF) << 16) |
 		(uint32(digest[1+i*4]&0xFF) << 8) | uint32(digest[i*4]&0xFF)&0xFFFFFFF
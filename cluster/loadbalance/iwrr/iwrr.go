@@ -19,6 +19,7 @@ package iwrr
 
 import (
 	"math/rand"
+	"slices"
 	"sync"
 )
 
@@ -71,14 +72,22 @@ func (item *iwrrQueue) empty() bool {
 
 // InterleavedweightedRoundRobin struct
 type interleavedweightedRoundRobin struct {
-	current *iwrrQueue
-	next    *iwrrQueue
-	step    int64
-	mu      sync.Mutex
+	invokers []base.Invoker
+	weights  []int64
+	current  *iwrrQueue
+	next     *iwrrQueue
+	step     int64
+	mu       sync.Mutex
 }
 
 func NewInterleavedweightedRoundRobin(invokers []base.Invoker, invocation base.Invocation) *interleavedweightedRoundRobin {
+	return newInterleavedweightedRoundRobin(invokers, loadbalance.GetWeights(invokers, invocation))
+}
+
+func newInterleavedweightedRoundRobin(invokers []base.Invoker, weights []int64) *interleavedweightedRoundRobin {
 	iwrrp := new(interleavedweightedRoundRobin)
+	iwrrp.invokers = invokers
+	iwrrp.weights = weights
 	iwrrp.current = NewIwrrQueue()
 	iwrrp.next = NewIwrrQueue()
 
@@ -87,7 +96,7 @@ func NewInterleavedweightedRoundRobin(invokers []base.Invoker, invocation base.I
 	step := int64(0)
 	for idx := uint64(0); idx < size; idx++ {
 		invoker := invokers[(idx+offset)%size]
-		weight := loadbalance.GetWeight(invoker, invocation)
+		weight := weights[(idx+offset)%size]
 		step = gcdInt(step, weight)
 		iwrrp.current.push(&iwrrEntry{
 			invoker: invoker,
@@ -99,6 +108,11 @@ func NewInterleavedweightedRoundRobin(invokers []base.Invoker, invocation base.I
 	return iwrrp
 }
 
+// isStale reports whether the invokers or their weights have changed since the picker was built.
+func (iwrr *interleavedweightedRoundRobin) isStale(invokers []base.Invoker, weights []int64) bool {
+	return !slices.Equal(iwrr.invokers, invokers) || !slices.Equal(iwrr.weights, weights)
+}
+
 func (iwrr *interleavedweightedRoundRobin) Pick(invocation base.Invocation) base.Invoker {
 	iwrr.mu.Lock()
 	defer iwrr.mu.Unlock()
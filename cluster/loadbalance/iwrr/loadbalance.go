@@ -17,6 +17,10 @@
 
 package iwrr
 
+import (
+	"sync"
+)
+
 import (
 	"dubbo.apache.org/dubbo-go/v3/cluster/loadbalance"
 	"dubbo.apache.org/dubbo-go/v3/common/constant"
@@ -28,11 +32,26 @@ func init() {
 	extension.SetLoadbalance(constant.LoadBalanceKeyInterleavedWeightedRoundRobin, newInterleavedWeightedRoundRobinBalance)
 }
 
-type interleavedWeightedRoundRobinBalance struct{}
+var (
+	once     sync.Once
+	instance loadbalance.LoadBalance
+)
+
+type interleavedWeightedRoundRobinBalance struct {
+	// pickers caches the picker of each service and method, "{service key}.{method}" -> *interleavedweightedRoundRobin
+	pickers sync.Map
+}
 
 // newInterleavedWeightedRoundRobinBalance returns a interleaved weighted round robin load balance.
+//
+// The picker is cached and rebuilt when the invokers or their weights change, e.g. during the slow start.
 func newInterleavedWeightedRoundRobinBalance() loadbalance.LoadBalance {
-	return &interleavedWeightedRoundRobinBalance{}
+	if instance == nil {
+		once.Do(func() {
+			instance = &interleavedWeightedRoundRobinBalance{}
+		})
+	}
+	return instance
 }
 
 // Select gets invoker based on interleaved weighted round robine load balancing strategy
@@ -45,6 +64,12 @@ func (lb *interleavedWeightedRoundRobinBalance) Select(invokers []base.Invoker,
 		return invokers[0]
 	}
 
-	iwrrp := NewInterleavedweightedRoundRobin(invokers, invocation)
+	key := invokers[0].GetURL().ServiceKey() + "." + invocation.MethodName()
+	weights := loadbalance.GetWeights(invokers, invocation)
+	if cached, ok := lb.pickers.Load(key); ok && !cached.(*interleavedweightedRoundRobin).isStale(invokers, weights) {
+		return cached.(*interleavedweightedRoundRobin).Pick(invocation)
+	}
+	iwrrp := newInterleavedweightedRoundRobin(invokers, weights)
+	lb.pickers.Store(key, iwrrp)
 	return iwrrp.Pick(invocation)
 }
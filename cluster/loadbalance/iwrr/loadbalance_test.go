@@ -19,7 +19,9 @@ package iwrr
 
 import (
 	"fmt"
+	"strconv"
 	"testing"
+	"time"
 )
 
 import (
@@ -75,4 +77,31 @@ func TestIWrrRoundRobinByWeight(t *testing.T) {
 	}
 
 	assert.Equal(t, loop, sum)
+	// the picker is cached, so every invoker is selected exactly as many times as its weight in a round
+	for i, invoker := range invokers {
+		assert.Equal(t, i+1, selected[invoker])
+	}
+}
+
+func TestIWrrRoundRobinRebuildByWeight(t *testing.T) {
+	loadBalance := newInterleavedWeightedRoundRobinBalance()
+	inv := invocation.NewRPCInvocationWithOptions(invocation.WithMethodName("TestIWrrRoundRobinRebuildByWeight"))
+
+	var invokers []base.Invoker
+	for i := 1; i <= 2; i++ {
+		url, _ := common.NewURL(fmt.Sprintf("dubbo://192.168.1.%v:20000/org.apache.demo.HelloService?weight=100", i))
+		invokers = append(invokers, base.NewBaseInvoker(url))
+	}
+	loadBalance.Select(invokers, inv)
+
+	// invoker 0 restarts warm-up and its weight drops, so the picker is rebuilt
+	invokers[0].GetURL().SetParam(constant.RemoteTimestampKey, strconv.FormatInt(time.Now().Unix(), 10))
+	invokers[0].GetURL().SetParam(constant.WarmupKey, "100s")
+	invokers[0].GetURL().SetParam(constant.WarmupMinWeightPercentKey, "10")
+	selected := make(map[base.Invoker]int)
+	for i := 0; i < 110; i++ {
+		selected[loadBalance.Select(invokers, inv)]++
+	}
+	assert.Equal(t, 10, selected[invokers[0]])
+	assert.Equal(t, 100, selected[invokers[1]])
 }
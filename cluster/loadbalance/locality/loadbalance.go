@@ -0,0 +1,196 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package locality
+
+import (
+	"math/rand"
+	"strconv"
+)
+
+import (
+	"github.com/dubbogo/gost/log/logger"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/cluster/loadbalance"
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/common/extension"
+	"dubbo.apache.org/dubbo-go/v3/protocol/base"
+)
+
+const (
+	// the priorities of the providers, from the nearest to the farthest
+	localZone = iota
+	localRegion
+	remoteRegion
+	priorityCount
+)
+
+func init() {
+	extension.SetLoadbalance(constant.LoadBalanceKeyLocality, newLocalityLoadBalance)
+}
+
+type localityLoadBalance struct {
+	// inner selects the invoker among the providers of the chosen priority. If it is nil,
+	// the inner load balance is specified by the url of the invokers.
+	inner loadbalance.LoadBalance
+}
+
+// newLocalityLoadBalance returns a locality load balance whose inner load balance is specified by
+// the "locality.loadbalance" parameter, random load balance is used by default.
+func newLocalityLoadBalance() loadbalance.LoadBalance {
+	return &localityLoadBalance{}
+}
+
+// NewLocalityLoadBalance wraps the inner load balance with locality awareness.
+//
+// The region and zone of a provider are read from the "region" and "zone" parameters of its url, which
+// come from the metadata of the service instance in the application level service discovery. The
+// locality of the consumer is specified by the "locality.region" and "locality.zone" parameters or
+// attachments. The providers are prioritized as the same zone, the same region and the other regions.
+// The traffic is kept in the nearest priority, and spills over to the farther priorities in proportion
+// when the weighted ratio of the available providers falls below the "locality.spillover-threshold",
+// like the locality weighted load balancing of Envoy.
+func NewLocalityLoadBalance(inner loadbalance.LoadBalance) loadbalance.LoadBalance {
+	return &localityLoadBalance{inner: inner}
+}
+
+// Select gets invoker based on locality load balancing strategy
+func (lb *localityLoadBalance) Select(invokers []base.Invoker, invocation base.Invocation) base.Invoker {
+	if len(invokers) == 0 {
+		return nil
+	}
+	url := invokers[0].GetURL()
+	methodName := invocation.MethodName()
+	inner := lb.getInner(url, methodName)
+	region := invocation.GetAttachmentWithDefaultValue(constant.LocalityLocalRegionKey,
+		url.GetParam(constant.LocalityLocalRegionKey, ""))
+	zone := invocation.GetAttachmentWithDefaultValue(constant.LocalityLocalZoneKey,
+		url.GetParam(constant.LocalityLocalZoneKey, ""))
+	if len(invokers) == 1 || (region == "" && zone == "") {
+		return inner.Select(invokers, invocation)
+	}
+
+	var (
+		available [priorityCount][]base.Invoker
+		shares    [priorityCount]float64
+		total     float64
+	)
+	threshold := getSpilloverThreshold(url, methodName)
+	grouped := groupByPriority(invokers, region, zone)
+	// every priority takes the traffic in proportion to its health, and the rest spills over
+	// to the farther priorities.
+	remaining := 1.0
+	for p, group := range grouped {
+		if len(group) == 0 {
+			continue
+		}
+		var health float64
+		available[p], health = healthyInvokers(group, invocation)
+		share := remaining * min(1, health/threshold)
+		shares[p] = share
+		total += share
+		remaining -= share
+	}
+	if total == 0 {
+		// none of the providers is available, leave it to the inner load balance
+		return inner.Select(invokers, invocation)
+	}
+
+	// the shares are normalized if the spillover of the farthest priority is not taken by anyone
+	r := rand.Float64() * total
+	chosen := 0
+	for p, share := range shares {
+		if share == 0 {
+			continue
+		}
+		chosen = p
+		if r < share {
+			break
+		}
+		r -= share
+	}
+	return inner.Select(available[chosen], invocation)
+}
+
+func (lb *localityLoadBalance) getInner(url *common.URL, methodName string) loadbalance.LoadBalance {
+	if lb.inner != nil {
+		return lb.inner
+	}
+	name := url.GetMethodParam(methodName, constant.LocalityLoadBalanceKey,
+		url.GetParam(constant.LocalityLoadBalanceKey, constant.LoadBalanceKeyRandom))
+	if name == constant.LoadBalanceKeyLocality {
+		logger.Warnf("The inner load balance of locality load balance should not be %s, random load balance is used.", name)
+		name = constant.LoadBalanceKeyRandom
+	}
+	return extension.GetLoadbalance(name)
+}
+
+func groupByPriority(invokers []base.Invoker, region, zone string) [priorityCount][]base.Invoker {
+	var grouped [priorityCount][]base.Invoker
+	for _, invoker := range invokers {
+		url := invoker.GetURL()
+		sameRegion := region == "" || url.GetParam(constant.LocalityRegionKey, "") == region
+		sameZone := zone == "" || url.GetParam(constant.LocalityZoneKey, "") == zone
+		switch {
+		case sameRegion && sameZone:
+			grouped[localZone] = append(grouped[localZone], invoker)
+		case sameRegion:
+			grouped[localRegion] = append(grouped[localRegion], invoker)
+		default:
+			grouped[remoteRegion] = append(grouped[remoteRegion], invoker)
+		}
+	}
+	return grouped
+}
+
+// healthyInvokers returns the available invokers and the weighted ratio of them.
+func healthyInvokers(invokers []base.Invoker, invocation base.Invocation) ([]base.Invoker, float64) {
+	var (
+		available                  []base.Invoker
+		totalWeight, healthyWeight int64
+	)
+	for _, invoker := range invokers {
+		weight := loadbalance.GetWeight(invoker, invocation)
+		totalWeight += weight
+		if invoker.IsAvailable() {
+			available = append(available, invoker)
+			healthyWeight += weight
+		}
+	}
+	if totalWeight == 0 {
+		return available, float64(len(available)) / float64(len(invokers))
+	}
+	return available, float64(healthyWeight) / float64(totalWeight)
+}
+
+func getSpilloverThreshold(url *common.URL, methodName string) float64 {
+	thresholdStr := url.GetMethodParam(methodName, constant.LocalitySpilloverThresholdKey,
+		url.GetParam(constant.LocalitySpilloverThresholdKey, ""))
+	if thresholdStr == "" {
+		return constant.DefaultLocalitySpilloverThreshold
+	}
+	threshold, err := strconv.ParseFloat(thresholdStr, 64)
+	if err != nil || threshold <= 0 || threshold > 1 {
+		logger.Warnf("Illegal locality spillover threshold %s of the method %s, the default value is used.",
+			thresholdStr, methodName)
+		return constant.DefaultLocalitySpilloverThreshold
+	}
+	return threshold
+}
@@ -0,0 +1,144 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package locality
+
+import (
+	"fmt"
+	"testing"
+)
+
+import (
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/protocol/base"
+	"dubbo.apache.org/dubbo-go/v3/protocol/invocation"
+)
+
+// firstLoadBalance always selects the first invoker, and records the candidates.
+type firstLoadBalance struct {
+	candidates []base.Invoker
+}
+
+func (lb *firstLoadBalance) Select(invokers []base.Invoker, _ base.Invocation) base.Invoker {
+	lb.candidates = invokers
+	if len(invokers) == 0 {
+		return nil
+	}
+	return invokers[0]
+}
+
+// unavailableInvoker is an invoker which is not available but keeps its url, unlike a destroyed invoker.
+type unavailableInvoker struct {
+	*base.BaseInvoker
+}
+
+func (i *unavailableInvoker) IsAvailable() bool {
+	return false
+}
+
+func newInvokers(t *testing.T, region, zone string, count int, params string) []base.Invoker {
+	var invokers []base.Invoker
+	for i := 0; i < count; i++ {
+		url, err := common.NewURL(fmt.Sprintf("dubbo://192.168.1.%d:20000/org.apache.demo.HelloService?region=%s&zone=%s%s",
+			i, region, zone, params))
+		assert.Nil(t, err)
+		invokers = append(invokers, base.NewBaseInvoker(url))
+	}
+	return invokers
+}
+
+func TestLocalitySelectWithoutLocality(t *testing.T) {
+	inner := &firstLoadBalance{}
+	lb := NewLocalityLoadBalance(inner)
+	inv := invocation.NewRPCInvocation("echo", nil, nil)
+
+	assert.Nil(t, lb.Select(nil, inv))
+
+	invokers := append(newInvokers(t, "hangzhou", "a", 2, ""), newInvokers(t, "beijing", "b", 2, "")...)
+	assert.Equal(t, invokers[0], lb.Select(invokers, inv))
+	assert.Equal(t, invokers, inner.candidates)
+}
+
+func TestLocalitySelectLocalZone(t *testing.T) {
+	inner := &firstLoadBalance{}
+	lb := NewLocalityLoadBalance(inner)
+	remote := newInvokers(t, "beijing", "b", 2, "&locality.region=hangzhou&locality.zone=a")
+	neighbour := newInvokers(t, "hangzhou", "b", 2, "&locality.region=hangzhou&locality.zone=a")
+	local := newInvokers(t, "hangzhou", "a", 2, "&locality.region=hangzhou&locality.zone=a")
+	invokers := append(append(remote, neighbour...), local...)
+
+	inv := invocation.NewRPCInvocation("echo", nil, nil)
+	for i := 0; i < 100; i++ {
+		assert.Equal(t, local[0], lb.Select(invokers, inv))
+		assert.Equal(t, local, inner.candidates)
+	}
+
+	// the locality of the consumer is overridden by the attachments
+	inv = invocation.NewRPCInvocation("echo", nil, map[string]any{
+		constant.LocalityLocalRegionKey: "beijing",
+		constant.LocalityLocalZoneKey:   "b",
+	})
+	for i := 0; i < 100; i++ {
+		assert.Equal(t, remote[0], lb.Select(invokers, inv))
+	}
+}
+
+func TestLocalitySelectSpillover(t *testing.T) {
+	inner := &firstLoadBalance{}
+	lb := NewLocalityLoadBalance(inner)
+	params := "&locality.region=hangzhou&locality.zone=a&locality.spillover-threshold=0.8"
+	local := newInvokers(t, "hangzhou", "a", 4, params)
+	neighbour := newInvokers(t, "hangzhou", "b", 2, params)
+	inv := invocation.NewRPCInvocation("echo", nil, nil)
+
+	// 2 of 4 providers in the local zone are unavailable, so 0.5 / 0.8 of the traffic stays in the local zone
+	local[0] = &unavailableInvoker{BaseInvoker: local[0].(*base.BaseInvoker)}
+	local[1] = &unavailableInvoker{BaseInvoker: local[1].(*base.BaseInvoker)}
+	invokers := append(local, neighbour...)
+	var localCount int
+	for i := 0; i < 10000; i++ {
+		switch lb.Select(invokers, inv) {
+		case local[2]:
+			localCount++
+		case neighbour[0]:
+		default:
+			assert.Fail(t, "unexpected invoker is selected")
+		}
+	}
+	assert.InDelta(t, 0.625, float64(localCount)/10000, 0.05)
+
+	// all the providers are unavailable, the inner load balance decides
+	for i, invoker := range invokers {
+		if baseInvoker, ok := invoker.(*base.BaseInvoker); ok {
+			invokers[i] = &unavailableInvoker{BaseInvoker: baseInvoker}
+		}
+	}
+	assert.Equal(t, invokers[0], lb.Select(invokers, inv))
+	assert.Equal(t, invokers, inner.candidates)
+}
+
+func TestGetSpilloverThreshold(t *testing.T) {
+	url, _ := common.NewURL("dubbo://192.168.1.0:20000/org.apache.demo.HelloService?" +
+		"locality.spillover-threshold=0.5&methods.echo.locality.spillover-threshold=2")
+	assert.Equal(t, 0.5, getSpilloverThreshold(url, "hello"))
+	assert.Equal(t, constant.DefaultLocalitySpilloverThreshold, getSpilloverThreshold(url, "echo"))
+}
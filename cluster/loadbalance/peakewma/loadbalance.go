@@ -0,0 +1,187 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package peakewma
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+import (
+	"go.uber.org/atomic"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/cluster/loadbalance"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/common/extension"
+	"dubbo.apache.org/dubbo-go/v3/protocol/base"
+)
+
+const (
+	// decayTime is the time constant of the moving average, the weight of a latency sample
+	// decays to 1/e after decayTime.
+	decayTime = 10 * time.Second
+	// penalty is the cost of an invoker which has requests in flight but no latency sample yet,
+	// in milliseconds.
+	penalty = float64(time.Minute / time.Millisecond)
+	// statExpiry is the time after which the statistics not updated are dropped, e.g. the ones of the
+	// invokers which have gone. The moving average has decayed to less than 1% by then.
+	statExpiry = 5 * decayTime
+	// expireInterval is the interval to check the expired statistics.
+	expireInterval = time.Minute
+)
+
+func init() {
+	extension.SetLoadbalance(constant.LoadBalanceKeyPeakEWMA, newPeakEWMALoadBalance)
+}
+
+var (
+	once     sync.Once
+	instance loadbalance.LoadBalance
+)
+
+type peakEWMALoadBalance struct {
+	// stats stores the latency statistics of each invoker and method, "{invoker key}.{method}" -> *ewmaStat
+	stats      sync.Map
+	lastExpire atomic.Int64
+}
+
+// newPeakEWMALoadBalance returns a peak EWMA load balance.
+//
+// Two invokers are picked randomly, and the one with the lower cost is selected. The cost is the
+// peak-sensitive moving average of latency multiplied by the requests in flight, and divided by
+// the weight of the invoker. The latency statistics are collected by the active filter, so the
+// active filter should be configured as well as leastactive load balance.
+func newPeakEWMALoadBalance() loadbalance.LoadBalance {
+	if instance == nil {
+		once.Do(func() {
+			instance = &peakEWMALoadBalance{}
+		})
+	}
+	return instance
+}
+
+// Select gets invoker based on peak EWMA load balancing strategy
+func (lb *peakEWMALoadBalance) Select(invokers []base.Invoker, invocation base.Invocation) base.Invoker {
+	count := len(invokers)
+	if count == 0 {
+		return nil
+	}
+	if count == 1 {
+		return invokers[0]
+	}
+
+	// picks two nodes randomly
+	var i, j int
+	if count == 2 {
+		i, j = 0, 1
+	} else {
+		i = rand.Intn(count)
+		j = rand.Intn(count - 1)
+		if j >= i {
+			j++
+		}
+	}
+
+	now := time.Now()
+	lb.tryExpire(now)
+	costI := lb.cost(invokers[i], invocation, now)
+	costJ := lb.cost(invokers[j], invocation, now)
+	if costI < costJ || (costI == costJ && rand.Intn(2) == 0) {
+		return invokers[i]
+	}
+	return invokers[j]
+}
+
+func (lb *peakEWMALoadBalance) cost(invoker base.Invoker, invocation base.Invocation, now time.Time) float64 {
+	url := invoker.GetURL()
+	methodName := invocation.MethodName()
+	key := url.Key() + "." + methodName
+	s, ok := lb.stats.Load(key)
+	if !ok {
+		s, _ = lb.stats.LoadOrStore(key, &ewmaStat{stamp: now})
+	}
+
+	status := base.GetMethodStatus(url, methodName)
+	cost := s.(*ewmaStat).update(status, now)
+	if weight := loadbalance.GetWeight(invoker, invocation); weight > 0 {
+		return cost / float64(weight)
+	}
+	return math.Inf(1)
+}
+
+// tryExpire drops the statistics which are not updated for statExpiry, at most once per expireInterval.
+func (lb *peakEWMALoadBalance) tryExpire(now time.Time) {
+	last := lb.lastExpire.Load()
+	if now.Sub(time.Unix(0, last)) < expireInterval || !lb.lastExpire.CAS(last, now.UnixNano()) {
+		return
+	}
+	lb.stats.Range(func(key, value any) bool {
+		if value.(*ewmaStat).expired(now) {
+			lb.stats.Delete(key)
+		}
+		return true
+	})
+}
+
+// ewmaStat is the peak-sensitive moving average of latency of an invoker's method.
+type ewmaStat struct {
+	mu          sync.Mutex
+	ewma        float64 // in milliseconds
+	lastTotal   int32
+	lastElapsed int64
+	stamp       time.Time
+}
+
+// update updates the moving average by the requests finished since the last update, and returns the cost.
+func (s *ewmaStat) update(status *base.RPCStatus, now time.Time) float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	total, elapsed := status.GetTotal(), status.GetTotalElapsed()
+	w := math.Exp(-float64(now.Sub(s.stamp)) / float64(decayTime))
+	if total > s.lastTotal {
+		sample := float64(elapsed-s.lastElapsed) / float64(total-s.lastTotal)
+		if sample > s.ewma {
+			// peak sensitive, the latency goes up immediately
+			s.ewma = sample
+		} else {
+			s.ewma = s.ewma*w + sample*(1-w)
+		}
+	} else {
+		// no request is finished since the last update, decay the penalty of latency,
+		// so that the invoker which has not been chosen in a while could be chosen again.
+		s.ewma *= w
+	}
+	s.lastTotal, s.lastElapsed, s.stamp = total, elapsed, now
+
+	active := status.GetActive()
+	if s.ewma == 0 && active > 0 {
+		return penalty + float64(active)
+	}
+	return s.ewma * float64(active+1)
+}
+
+func (s *ewmaStat) expired(now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return now.Sub(s.stamp) > statExpiry
+}
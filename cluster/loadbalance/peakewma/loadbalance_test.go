@@ -0,0 +1,113 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package peakewma
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/protocol/base"
+	"dubbo.apache.org/dubbo-go/v3/protocol/invocation"
+)
+
+func TestPeakEWMASelect(t *testing.T) {
+	loadBalance := newPeakEWMALoadBalance()
+	inv := invocation.NewRPCInvocationWithOptions(invocation.WithMethodName("TestPeakEWMASelect"))
+
+	assert.Nil(t, loadBalance.Select(nil, inv))
+
+	var invokers []base.Invoker
+	for i := 0; i < 2; i++ {
+		url, _ := common.NewURL(fmt.Sprintf("dubbo://192.168.2.%v:20000/org.apache.demo.HelloService", i))
+		invokers = append(invokers, base.NewBaseInvoker(url))
+	}
+	assert.Equal(t, invokers[0], loadBalance.Select(invokers[:1], inv))
+
+	// invoker 0 is slow, and invoker 1 is fast
+	base.BeginCount(invokers[0].GetURL(), inv.MethodName())
+	base.EndCount(invokers[0].GetURL(), inv.MethodName(), 100, true)
+	base.BeginCount(invokers[1].GetURL(), inv.MethodName())
+	base.EndCount(invokers[1].GetURL(), inv.MethodName(), 10, true)
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, invokers[1], loadBalance.Select(invokers, inv))
+	}
+
+	// the requests in flight raise the cost of invoker 1
+	for i := 0; i < 20; i++ {
+		base.BeginCount(invokers[1].GetURL(), inv.MethodName())
+	}
+	assert.Equal(t, invokers[0], loadBalance.Select(invokers, inv))
+}
+
+func TestEWMAStatUpdate(t *testing.T) {
+	url, _ := common.NewURL("dubbo://192.168.2.10:20000/org.apache.demo.HelloService")
+	methodName := "TestEWMAStatUpdate"
+	status := base.GetMethodStatus(url, methodName)
+	now := time.Now()
+	stat := &ewmaStat{stamp: now}
+
+	// no sample and no request in flight
+	assert.Equal(t, float64(0), stat.update(status, now))
+
+	base.BeginCount(url, methodName)
+	assert.Equal(t, penalty+1, stat.update(status, now))
+
+	// peak sensitive
+	base.EndCount(url, methodName, 100, true)
+	assert.Equal(t, float64(100), stat.update(status, now))
+
+	// the lower latency is averaged
+	base.BeginCount(url, methodName)
+	base.EndCount(url, methodName, 10, true)
+	cost := stat.update(status, now.Add(decayTime))
+	assert.Less(t, cost, float64(100))
+	assert.Greater(t, cost, float64(10))
+
+	// the penalty decays if no request is finished
+	assert.Less(t, stat.update(status, now.Add(10*decayTime)), cost/100)
+}
+
+func TestExpireStats(t *testing.T) {
+	lb := &peakEWMALoadBalance{}
+	now := time.Now()
+	lb.stats.Store("gone.test", &ewmaStat{stamp: now.Add(-statExpiry - time.Second)})
+	lb.stats.Store("alive.test", &ewmaStat{stamp: now})
+
+	lb.tryExpire(now)
+	_, ok := lb.stats.Load("gone.test")
+	assert.False(t, ok)
+	_, ok = lb.stats.Load("alive.test")
+	assert.True(t, ok)
+
+	// the statistics are checked at most once per interval
+	lb.stats.Store("gone.test", &ewmaStat{stamp: now.Add(-statExpiry - time.Second)})
+	lb.tryExpire(now.Add(expireInterval / 2))
+	_, ok = lb.stats.Load("gone.test")
+	assert.True(t, ok)
+	lb.tryExpire(now.Add(expireInterval))
+	_, ok = lb.stats.Load("gone.test")
+	assert.False(t, ok)
+}
@@ -18,12 +18,19 @@
 package loadbalance
 
 import (
+	"math"
+	"strconv"
+	"sync"
 	"time"
 
+	"dubbo.apache.org/dubbo-go/v3/common"
 	"dubbo.apache.org/dubbo-go/v3/common/constant"
 	"dubbo.apache.org/dubbo-go/v3/protocol/base"
 )
 
+// warmupRestarts stores the time when the slow start of an invoker restarts, url key -> time.Time
+var warmupRestarts sync.Map
+
 // GetWeight returns the weight for the load‑balancing strategy.
 func GetWeight(invoker base.Invoker, invocation base.Invocation) int64 {
 
@@ -42,21 +49,8 @@ func GetWeight(invoker base.Invoker, invocation base.Invocation) int64 {
 		weight = constant.DefaultWeight
 	}
 
-	// Warm‑up adjustment (same logic as before).
 	if weight > 0 {
-		now := time.Now().Unix()
-		ts := url.GetParamInt(constant.RemoteTimestampKey, now)
-		if uptime := now - ts; uptime > 0 {
-			warm := url.GetParamInt(constant.WarmupKey, constant.DefaultWarmup)
-			if uptime < warm {
-				calc := float64(uptime) * float64(weight) / float64(warm)
-				if calc < 1 {
-					weight = 1
-				} else if int64(calc) <= weight {
-					weight = int64(calc)
-				}
-			}
-		}
+		weight = warmupWeight(url, weight, time.Now())
 	}
 
 	if weight < 0 {
@@ -64,3 +58,80 @@ func GetWeight(invoker base.Invoker, invocation base.Invocation) int64 {
 	}
 	return weight
 }
+
+// GetWeights returns the weights of the invokers. The weights change during the slow start, so the load
+// balances which cache the structures built from the weights should rebuild them once the weights change.
+func GetWeights(invokers []base.Invoker, invocation base.Invocation) []int64 {
+	weights := make([]int64, len(invokers))
+	for i, invoker := range invokers {
+		weights[i] = GetWeight(invoker, invocation)
+	}
+	return weights
+}
+
+// RestartWarmup restarts the slow start of the invoker, so that its weight ramps up again from the minimum
+// weight, e.g. when the invoker recovers from outlier ejection.
+func RestartWarmup(url *common.URL) {
+	warmupRestarts.Store(url.Key(), time.Now())
+}
+
+// warmupWeight returns the weight during the slow start. The weight ramps from the minimum weight to the
+// full weight along the configured curve in the warm-up window, which starts when the provider starts or
+// the slow start restarts.
+func warmupWeight(url *common.URL, weight int64, now time.Time) int64 {
+	warmup := getWarmup(url)
+	if warmup <= 0 {
+		return weight
+	}
+	var start time.Time
+	if ts := url.GetParamInt(constant.RemoteTimestampKey, 0); ts > 0 {
+		start = time.Unix(ts, 0)
+	}
+	if restart, ok := warmupRestarts.Load(url.Key()); ok {
+		if restartTime := restart.(time.Time); now.Sub(restartTime) >= warmup {
+			warmupRestarts.CompareAndDelete(url.Key(), restart)
+		} else if restartTime.After(start) {
+			start = restartTime
+		}
+	}
+	if start.IsZero() {
+		return weight
+	}
+	uptime := now.Sub(start)
+	if uptime <= 0 || uptime >= warmup {
+		return weight
+	}
+
+	progress := float64(uptime) / float64(warmup)
+	if url.GetParam(constant.WarmupCurveKey, constant.WarmupCurveLinear) == constant.WarmupCurveAggressive {
+		// the aggressive curve gives the provider more traffic at the beginning of the warm-up
+		progress = math.Sqrt(progress)
+	}
+	minPercent := url.GetParamInt(constant.WarmupMinWeightPercentKey, 0)
+	if minPercent < 0 || minPercent > 100 {
+		minPercent = 0
+	}
+	calc := math.Max(float64(weight)*progress, float64(weight*minPercent)/100)
+	if calc < 1 {
+		return 1
+	}
+	if int64(calc) <= weight {
+		return int64(calc)
+	}
+	return weight
+}
+
+// getWarmup returns the warm-up window, which is seconds or a duration string like "10m".
+func getWarmup(url *common.URL) time.Duration {
+	warmup := url.GetParam(constant.WarmupKey, "")
+	if warmup == "" {
+		return constant.DefaultWarmup * time.Second
+	}
+	if seconds, err := strconv.ParseInt(warmup, 10, 64); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if d, err := time.ParseDuration(warmup); err == nil {
+		return d
+	}
+	return constant.DefaultWarmup * time.Second
+}
@@ -0,0 +1,74 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package loadbalance
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/protocol/base"
+	"dubbo.apache.org/dubbo-go/v3/protocol/invocation"
+)
+
+func TestGetWeightDuringWarmup(t *testing.T) {
+	inv := invocation.NewRPCInvocationWithOptions(invocation.WithMethodName("echo"))
+	started := time.Now().Add(-25 * time.Second).Unix()
+
+	tests := []struct {
+		params string
+		weight int64
+	}{
+		{params: "", weight: 100},
+		{params: fmt.Sprintf("remote.timestamp=%d&warmup=100", started), weight: 25},
+		{params: fmt.Sprintf("remote.timestamp=%d&warmup=100s", started), weight: 25},
+		{params: fmt.Sprintf("remote.timestamp=%d&warmup=10s", started), weight: 100},
+		{params: fmt.Sprintf("remote.timestamp=%d&warmup=100&warmup.curve=aggressive", started), weight: 50},
+		{params: fmt.Sprintf("remote.timestamp=%d&warmup=100&warmup.min-weight-percent=40", started), weight: 40},
+		{params: fmt.Sprintf("remote.timestamp=%d&warmup=100&weight=2", started), weight: 1},
+	}
+	for _, tt := range tests {
+		url, err := common.NewURL("dubbo://192.168.1.1:20000/org.apache.demo.HelloService?" + tt.params)
+		assert.Nil(t, err)
+		assert.Equal(t, tt.weight, GetWeight(base.NewBaseInvoker(url), inv), tt.params)
+	}
+}
+
+func TestRestartWarmup(t *testing.T) {
+	inv := invocation.NewRPCInvocationWithOptions(invocation.WithMethodName("echo"))
+	url, err := common.NewURL("dubbo://192.168.1.2:20000/org.apache.demo.HelloService?warmup=100&warmup.min-weight-percent=10")
+	assert.Nil(t, err)
+	invoker := base.NewBaseInvoker(url)
+	assert.Equal(t, int64(100), GetWeight(invoker, inv))
+
+	RestartWarmup(url)
+	assert.Equal(t, int64(10), GetWeight(invoker, inv))
+
+	// the restart expires after the warm-up window
+	warmupRestarts.Store(url.Key(), time.Now().Add(-200*time.Second))
+	assert.Equal(t, int64(100), GetWeight(invoker, inv))
+	_, ok := warmupRestarts.Load(url.Key())
+	assert.False(t, ok)
+}
@@ -19,4 +19,6 @@ package metrics
 
 const (
 	HillClimbing = "hill-climbing"
+	// LatencyQuantile is the sliding window quantile of successful invocation latency in milliseconds
+	LatencyQuantile = "latency-quantile"
 )
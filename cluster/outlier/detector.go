@@ -0,0 +1,285 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package outlier
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+)
+
+import (
+	"github.com/dubbogo/gost/log/logger"
+
+	"go.uber.org/atomic"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/cluster/loadbalance"
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/metrics"
+	clusterMetrics "dubbo.apache.org/dubbo-go/v3/metrics/cluster"
+	"dubbo.apache.org/dubbo-go/v3/metrics/util/aggregate"
+	"dubbo.apache.org/dubbo-go/v3/protocol/triple/triple_protocol"
+)
+
+const (
+	statPaneCount     = 10
+	statWindowSeconds = 10
+)
+
+// errProbing is returned for the invocations to a half-open invoker whose probe is in flight
+var errProbing = errors.New("the invoker is half-open and a probe to it is in flight")
+
+type state int32
+
+const (
+	stateHealthy state = iota
+	stateEjected
+	stateHalfOpen
+)
+
+// invokerStat is the statistics and the ejection state of an invoker.
+type invokerStat struct {
+	mu                  sync.Mutex
+	state               state
+	ejectedUntil        time.Time
+	ejectionTimes       int
+	consecutiveFailures int
+	// probing is true while the single probe to the half-open invoker is in flight
+	probing  bool
+	requests *aggregate.TimeWindowCounter
+	failures *aggregate.TimeWindowCounter
+}
+
+func newInvokerStat() *invokerStat {
+	return &invokerStat{
+		requests: aggregate.NewTimeWindowCounter(statPaneCount, statWindowSeconds),
+		failures: aggregate.NewTimeWindowCounter(statPaneCount, statWindowSeconds),
+	}
+}
+
+// detector tracks the invokers of a service and decides which of them should be ejected.
+type detector struct {
+	service string
+
+	consecutiveFailures int
+	errorRate           float64
+	minRequests         int
+	baseEjectionTime    time.Duration
+	maxEjectionTime     time.Duration
+	maxEjectionPercent  int
+
+	stats    sync.Map // invoker key -> *invokerStat
+	ejected  atomic.Int32
+	poolSize atomic.Int32
+}
+
+func newDetector(url *common.URL) *detector {
+	d := &detector{
+		service:             url.Service(),
+		consecutiveFailures: url.GetParamByIntValue(constant.OutlierConsecutiveFailuresKey, constant.DefaultOutlierConsecutiveFailures),
+		errorRate:           constant.DefaultOutlierErrorRate,
+		minRequests:         url.GetParamByIntValue(constant.OutlierMinRequestsKey, constant.DefaultOutlierMinRequests),
+		baseEjectionTime:    getDuration(url, constant.OutlierBaseEjectionTimeKey, constant.DefaultOutlierBaseEjectionTime),
+		maxEjectionTime:     getDuration(url, constant.OutlierMaxEjectionTimeKey, constant.DefaultOutlierMaxEjectionTime),
+		maxEjectionPercent:  url.GetParamByIntValue(constant.OutlierMaxEjectionPercentKey, constant.DefaultOutlierMaxEjectionPercent),
+	}
+	if v := url.GetParam(constant.OutlierErrorRateKey, ""); v != "" {
+		if rate, err := strconv.ParseFloat(v, 64); err == nil && rate > 0 {
+			d.errorRate = rate
+		} else {
+			logger.Warnf("Illegal outlier error rate %s of the service %s, the default value is used.", v, d.service)
+		}
+	}
+	return d
+}
+
+func getDuration(url *common.URL, key, defaultValue string) time.Duration {
+	v := url.GetParam(key, defaultValue)
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		logger.Warnf("Illegal %s %s, the default value %s is used.", key, v, defaultValue)
+		d, _ = time.ParseDuration(defaultValue)
+	}
+	return d
+}
+
+func (d *detector) getStat(url *common.URL) *invokerStat {
+	key := url.Key()
+	if s, ok := d.stats.Load(key); ok {
+		return s.(*invokerStat)
+	}
+	s, _ := d.stats.LoadOrStore(key, newInvokerStat())
+	return s.(*invokerStat)
+}
+
+// isEjected returns whether the invoker is ejected now. The invoker turns to half-open
+// once its ejection time is over, so that the next invocation is a probe, and it is
+// hidden again until the probe finishes.
+func (d *detector) isEjected(url *common.URL, now time.Time) bool {
+	s, ok := d.stats.Load(url.Key())
+	if !ok {
+		return false
+	}
+	stat := s.(*invokerStat)
+	stat.mu.Lock()
+	defer stat.mu.Unlock()
+	if stat.state == stateHalfOpen {
+		return stat.probing
+	}
+	if stat.state != stateEjected {
+		return false
+	}
+	if now.Before(stat.ejectedUntil) {
+		return true
+	}
+	stat.state = stateHalfOpen
+	d.ejected.Dec()
+	logger.Infof("The invoker %s of the service %s turns to half-open after ejection.", url.Location, d.service)
+	return false
+}
+
+// admit returns whether an invocation can be sent to the invoker, and whether it is the probe
+// of the half-open invoker. Only one probe is admitted until it finishes, so that a bad invoker
+// doesn't take a burst of traffic every time its ejection is over.
+func (d *detector) admit(url *common.URL) (admitted bool, probe bool) {
+	s, ok := d.stats.Load(url.Key())
+	if !ok {
+		return true, false
+	}
+	stat := s.(*invokerStat)
+	stat.mu.Lock()
+	defer stat.mu.Unlock()
+	if stat.state != stateHalfOpen {
+		return true, false
+	}
+	if stat.probing {
+		return false, false
+	}
+	stat.probing = true
+	return true, true
+}
+
+// onResult records the result of an invocation and ejects or recovers the invoker.
+func (d *detector) onResult(url *common.URL, err error, probe bool) {
+	stat := d.getStat(url)
+	stat.mu.Lock()
+	defer stat.mu.Unlock()
+	if probe {
+		stat.probing = false
+	}
+	// the canceled invocations, e.g. the losing calls of hedging, are not the fault of the provider
+	if errors.Is(err, context.Canceled) || triple_protocol.CodeOf(err) == triple_protocol.CodeCanceled {
+		return
+	}
+	failed := err != nil && !isBizError(err)
+
+	switch stat.state {
+	case stateEjected:
+		// the result of an invocation started before the ejection
+		return
+	case stateHalfOpen:
+		if !probe {
+			// the result of an invocation started before the invoker turns to half-open
+			return
+		}
+		if failed {
+			d.eject(url, stat, true)
+			return
+		}
+		stat.state = stateHealthy
+		stat.consecutiveFailures = 0
+		if stat.ejectionTimes > 0 {
+			stat.ejectionTimes--
+		}
+		stat.requests = aggregate.NewTimeWindowCounter(statPaneCount, statWindowSeconds)
+		stat.failures = aggregate.NewTimeWindowCounter(statPaneCount, statWindowSeconds)
+		// the recovered invoker takes the traffic gradually
+		loadbalance.RestartWarmup(url)
+		logger.Infof("The invoker %s of the service %s is recovered after a successful probe.", url.Location, d.service)
+		metrics.Publish(clusterMetrics.NewOutlierRecoveryEvent(d.service, url.Location, int(d.ejected.Load())))
+		return
+	}
+
+	stat.requests.Inc()
+	if !failed {
+		stat.consecutiveFailures = 0
+		return
+	}
+	stat.failures.Inc()
+	stat.consecutiveFailures++
+
+	if d.consecutiveFailures > 0 && stat.consecutiveFailures >= d.consecutiveFailures {
+		d.eject(url, stat, false)
+		return
+	}
+	if requests := stat.requests.Count(); requests >= float64(d.minRequests) && stat.failures.Count()/requests >= d.errorRate {
+		d.eject(url, stat, false)
+	}
+}
+
+// eject ejects the invoker if the max ejection percent is not reached. The probing invoker is always
+// ejected again since it has been counted in the pool.
+func (d *detector) eject(url *common.URL, stat *invokerStat, probing bool) {
+	if !probing && d.reachMaxEjection() {
+		logger.Warnf("The invoker %s of the service %s should be ejected, but the max ejection percent %d%% is reached.",
+			url.Location, d.service, d.maxEjectionPercent)
+		return
+	}
+	stat.ejectionTimes++
+	ejectionTime := d.baseEjectionTime * time.Duration(stat.ejectionTimes)
+	if ejectionTime > d.maxEjectionTime {
+		ejectionTime = d.maxEjectionTime
+	}
+	stat.state = stateEjected
+	stat.ejectedUntil = time.Now().Add(ejectionTime)
+	stat.consecutiveFailures = 0
+	ejected := d.ejected.Inc()
+	logger.Warnf("The invoker %s of the service %s is ejected for %v.", url.Location, d.service, ejectionTime)
+	metrics.Publish(clusterMetrics.NewOutlierEjectionEvent(d.service, url.Location, int(ejected)))
+}
+
+func (d *detector) reachMaxEjection() bool {
+	pool := d.poolSize.Load()
+	return pool <= 0 || int(d.ejected.Load()+1)*100 > int(pool)*d.maxEjectionPercent
+}
+
+// retain removes the statistics of invokers which are not in the keys any more.
+func (d *detector) retain(keys map[string]struct{}) {
+	d.stats.Range(func(key, value any) bool {
+		if _, ok := keys[key.(string)]; ok {
+			return true
+		}
+		stat := value.(*invokerStat)
+		stat.mu.Lock()
+		if stat.state == stateEjected {
+			d.ejected.Dec()
+		}
+		stat.mu.Unlock()
+		d.stats.Delete(key)
+		return true
+	})
+}
+
+func isBizError(err error) bool {
+	return triple_protocol.IsWireError(err) && triple_protocol.CodeOf(err) == triple_protocol.CodeBizError
+}
@@ -0,0 +1,129 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package outlier
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+import (
+	"go.uber.org/atomic"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/cluster/directory"
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/protocol/base"
+	"dubbo.apache.org/dubbo-go/v3/protocol/result"
+)
+
+const cleanupInterval = time.Minute
+
+// outlierDirectory hides the ejected invokers of the wrapped directory.
+type outlierDirectory struct {
+	directory.Directory
+	detector *detector
+	// invokers caches the wrapped invoker of each invoker, so that the identity of
+	// an invoker keeps the same between two List calls.
+	invokers    sync.Map // base.Invoker -> *outlierInvoker
+	lastCleanup atomic.Int64
+}
+
+// NewDirectory returns a directory with outlier detection if it is enabled by the url,
+// otherwise the given directory is returned.
+func NewDirectory(dir directory.Directory, url *common.URL) directory.Directory {
+	if url == nil || !url.GetParamBool(constant.OutlierEnabledKey, false) {
+		return dir
+	}
+	d := &outlierDirectory{
+		Directory: dir,
+		detector:  newDetector(url),
+	}
+	d.lastCleanup.Store(time.Now().UnixNano())
+	return d
+}
+
+// List returns the invokers which are not ejected. All the invokers are returned
+// if all of them are ejected, since it is better than no provider available.
+func (d *outlierDirectory) List(invocation base.Invocation) []base.Invoker {
+	invokers := d.Directory.List(invocation)
+	d.detector.poolSize.Store(int32(len(invokers)))
+	d.tryCleanup(invokers)
+
+	now := time.Now()
+	available := make([]base.Invoker, 0, len(invokers))
+	for _, ivk := range invokers {
+		if !d.detector.isEjected(ivk.GetURL(), now) {
+			available = append(available, d.wrap(ivk))
+		}
+	}
+	if len(available) == 0 && len(invokers) > 0 {
+		for _, ivk := range invokers {
+			available = append(available, d.wrap(ivk))
+		}
+	}
+	return available
+}
+
+func (d *outlierDirectory) wrap(invoker base.Invoker) base.Invoker {
+	if ivk, ok := d.invokers.Load(invoker); ok {
+		return ivk.(*outlierInvoker)
+	}
+	ivk, _ := d.invokers.LoadOrStore(invoker, &outlierInvoker{Invoker: invoker, detector: d.detector})
+	return ivk.(*outlierInvoker)
+}
+
+// tryCleanup removes the statistics and the wrappers of the invokers which have gone.
+func (d *outlierDirectory) tryCleanup(invokers []base.Invoker) {
+	last := d.lastCleanup.Load()
+	if time.Since(time.Unix(0, last)) < cleanupInterval || !d.lastCleanup.CAS(last, time.Now().UnixNano()) {
+		return
+	}
+	current := make(map[base.Invoker]struct{}, len(invokers))
+	keys := make(map[string]struct{}, len(invokers))
+	for _, ivk := range invokers {
+		current[ivk] = struct{}{}
+		keys[ivk.GetURL().Key()] = struct{}{}
+	}
+	d.invokers.Range(func(key, _ any) bool {
+		if _, ok := current[key.(base.Invoker)]; !ok {
+			d.invokers.Delete(key)
+		}
+		return true
+	})
+	d.detector.retain(keys)
+}
+
+// outlierInvoker reports the result of each invocation to the detector.
+type outlierInvoker struct {
+	base.Invoker
+	detector *detector
+}
+
+func (ivk *outlierInvoker) Invoke(ctx context.Context, invocation base.Invocation) result.Result {
+	admitted, probe := ivk.detector.admit(ivk.GetURL())
+	if !admitted {
+		return &result.RPCResult{Err: errProbing}
+	}
+	res := ivk.Invoker.Invoke(ctx, invocation)
+	ivk.detector.onResult(ivk.GetURL(), res.Error(), probe)
+	return res
+}
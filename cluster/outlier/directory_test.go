@@ -0,0 +1,202 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package outlier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/cluster/directory/static"
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/protocol/base"
+	"dubbo.apache.org/dubbo-go/v3/protocol/invocation"
+	"dubbo.apache.org/dubbo-go/v3/protocol/result"
+)
+
+type testInvoker struct {
+	*base.BaseInvoker
+	fail  bool
+	block chan struct{}
+}
+
+func (ivk *testInvoker) Invoke(context.Context, base.Invocation) result.Result {
+	if ivk.block != nil {
+		<-ivk.block
+	}
+	if ivk.fail {
+		return &result.RPCResult{Err: errors.New("error")}
+	}
+	return &result.RPCResult{}
+}
+
+func newTestDirectory(t *testing.T, count int, params map[string]string) (*outlierDirectory, []*testInvoker) {
+	var (
+		invokers     []base.Invoker
+		testInvokers []*testInvoker
+	)
+	for i := 0; i < count; i++ {
+		url, _ := common.NewURL(fmt.Sprintf("dubbo://192.168.1.%d:20000/com.ikurento.user.UserProvider", i))
+		url.SetParam(constant.OutlierEnabledKey, "true")
+		for k, v := range params {
+			url.SetParam(k, v)
+		}
+		ivk := &testInvoker{BaseInvoker: base.NewBaseInvoker(url)}
+		invokers = append(invokers, ivk)
+		testInvokers = append(testInvokers, ivk)
+	}
+	dir, ok := NewDirectory(static.NewDirectory(invokers), invokers[0].GetURL()).(*outlierDirectory)
+	assert.True(t, ok)
+	return dir, testInvokers
+}
+
+func invokeAll(dir *outlierDirectory, times int) {
+	inv := invocation.NewRPCInvocationWithOptions(invocation.WithMethodName("test"))
+	for i := 0; i < times; i++ {
+		for _, ivk := range dir.List(inv) {
+			ivk.Invoke(context.Background(), inv)
+		}
+	}
+}
+
+func TestNewDirectoryDisabled(t *testing.T) {
+	url, _ := common.NewURL("dubbo://192.168.1.1:20000/com.ikurento.user.UserProvider")
+	dir := static.NewDirectory([]base.Invoker{base.NewBaseInvoker(url)})
+	assert.Equal(t, dir, NewDirectory(dir, url))
+}
+
+func TestEjectConsecutiveFailures(t *testing.T) {
+	dir, invokers := newTestDirectory(t, 4, map[string]string{
+		constant.OutlierConsecutiveFailuresKey: "3",
+	})
+	invokers[0].fail = true
+
+	invokeAll(dir, 2)
+	assert.Len(t, dir.List(&invocation.RPCInvocation{}), 4)
+
+	invokeAll(dir, 1)
+	listed := dir.List(&invocation.RPCInvocation{})
+	assert.Len(t, listed, 3)
+	for _, ivk := range listed {
+		assert.NotEqual(t, invokers[0].GetURL().Key(), ivk.GetURL().Key())
+	}
+	assert.Equal(t, int32(1), dir.detector.ejected.Load())
+}
+
+func TestEjectErrorRate(t *testing.T) {
+	dir, invokers := newTestDirectory(t, 4, map[string]string{
+		constant.OutlierConsecutiveFailuresKey: "0",
+		constant.OutlierErrorRateKey:           "0.5",
+		constant.OutlierMinRequestsKey:         "4",
+	})
+	invokers[1].fail = true
+
+	invokeAll(dir, 3)
+	assert.Len(t, dir.List(&invocation.RPCInvocation{}), 4)
+	invokeAll(dir, 1)
+	assert.Len(t, dir.List(&invocation.RPCInvocation{}), 3)
+}
+
+func TestMaxEjectionPercent(t *testing.T) {
+	dir, invokers := newTestDirectory(t, 4, map[string]string{
+		constant.OutlierConsecutiveFailuresKey: "1",
+		constant.OutlierMaxEjectionPercentKey:  "50",
+	})
+	for _, ivk := range invokers {
+		ivk.fail = true
+	}
+
+	invokeAll(dir, 3)
+	assert.Len(t, dir.List(&invocation.RPCInvocation{}), 2)
+	assert.Equal(t, int32(2), dir.detector.ejected.Load())
+}
+
+func TestHalfOpenProbe(t *testing.T) {
+	dir, invokers := newTestDirectory(t, 2, map[string]string{
+		constant.OutlierConsecutiveFailuresKey: "1",
+		constant.OutlierBaseEjectionTimeKey:    "50ms",
+	})
+	invokers[0].fail = true
+	invokeAll(dir, 1)
+	assert.Len(t, dir.List(&invocation.RPCInvocation{}), 1)
+
+	// the failed probe ejects the invoker again for a longer time
+	time.Sleep(60 * time.Millisecond)
+	invokeAll(dir, 1)
+	assert.Len(t, dir.List(&invocation.RPCInvocation{}), 1)
+	time.Sleep(60 * time.Millisecond)
+	assert.Len(t, dir.List(&invocation.RPCInvocation{}), 1)
+
+	// the successful probe brings the invoker back
+	invokers[0].fail = false
+	time.Sleep(50 * time.Millisecond)
+	invokeAll(dir, 1)
+	assert.Len(t, dir.List(&invocation.RPCInvocation{}), 2)
+	assert.Equal(t, int32(0), dir.detector.ejected.Load())
+}
+
+func TestHalfOpenSingleProbe(t *testing.T) {
+	dir, invokers := newTestDirectory(t, 2, map[string]string{
+		constant.OutlierConsecutiveFailuresKey: "1",
+		constant.OutlierBaseEjectionTimeKey:    "50ms",
+	})
+	invokers[0].fail = true
+	invokeAll(dir, 1)
+	assert.Len(t, dir.List(&invocation.RPCInvocation{}), 1)
+
+	time.Sleep(60 * time.Millisecond)
+	invokers[0].fail = false
+	invokers[0].block = make(chan struct{})
+	inv := invocation.NewRPCInvocationWithOptions(invocation.WithMethodName("test"))
+	listed := dir.List(inv)
+	assert.Len(t, listed, 2)
+	var probing base.Invoker
+	for _, ivk := range listed {
+		if ivk.GetURL().Key() == invokers[0].GetURL().Key() {
+			probing = ivk
+		}
+	}
+	assert.NotNil(t, probing)
+
+	done := make(chan result.Result)
+	go func() {
+		done <- probing.Invoke(context.Background(), inv)
+	}()
+	assert.Eventually(t, func() bool {
+		return len(dir.List(inv)) == 1
+	}, time.Second, 5*time.Millisecond)
+
+	// the other invocations are rejected while the probe is in flight
+	for i := 0; i < 3; i++ {
+		assert.ErrorIs(t, probing.Invoke(context.Background(), inv).Error(), errProbing)
+	}
+
+	close(invokers[0].block)
+	assert.Nil(t, (<-done).Error())
+	assert.Len(t, dir.List(inv), 2)
+	assert.Nil(t, probing.Invoke(context.Background(), inv).Error())
+}
@@ -124,6 +124,21 @@ func doPatternMatch(pattern string, value string, url *common.URL, invocation ba
 	return valuePattern.Match(pattern, value, url, invocation, isWhenCondition)
 }
 
+// CompilePattern validates the pattern with the value matcher that matches it, and caches the compiled
+// pattern if the value matcher supports compiling, so that an illegal pattern is rejected when parsing the rule.
+func CompilePattern(pattern string) error {
+	once.Do(initValueMatchers)
+	for _, valueMatcher := range valueMatchers {
+		if valueMatcher.ShouldMatch(pattern) {
+			if compilable, ok := valueMatcher.(pattern_value.Compilable); ok {
+				return compilable.Compile(pattern)
+			}
+			return nil
+		}
+	}
+	return nil
+}
+
 // GetSampleValueFromURL returns the value of the conditionKey in the URL
 func GetSampleValueFromURL(conditionKey string, sample map[string]string, param *common.URL, invocation base.Invocation) string {
 	var sampleValue string
@@ -0,0 +1,78 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pattern_value
+
+import (
+	"strings"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/protocol/base"
+)
+
+const (
+	prefixPrefix = constant.Prefix + ":"
+	suffixPrefix = constant.Suffix + ":"
+)
+
+// PrefixValuePattern matches with patterns like 'key=prefix:hello'
+type PrefixValuePattern struct {
+}
+
+// SuffixValuePattern matches with patterns like 'key=suffix:hello'
+type SuffixValuePattern struct {
+}
+
+func init() {
+	SetValuePattern(constant.Prefix, NewPrefixValuePattern)
+	SetValuePattern(constant.Suffix, NewSuffixValuePattern)
+}
+
+func NewPrefixValuePattern() ValuePattern {
+	return &PrefixValuePattern{}
+}
+
+func (p *PrefixValuePattern) Priority() int64 {
+	return 20
+}
+
+func (p *PrefixValuePattern) ShouldMatch(pattern string) bool {
+	return strings.HasPrefix(pattern, prefixPrefix)
+}
+
+func (p *PrefixValuePattern) Match(pattern string, value string, url *common.URL, invocation base.Invocation, isWhenCondition bool) bool {
+	return strings.HasPrefix(value, strings.TrimPrefix(pattern, prefixPrefix))
+}
+
+func NewSuffixValuePattern() ValuePattern {
+	return &SuffixValuePattern{}
+}
+
+func (s *SuffixValuePattern) Priority() int64 {
+	return 20
+}
+
+func (s *SuffixValuePattern) ShouldMatch(pattern string) bool {
+	return strings.HasPrefix(pattern, suffixPrefix)
+}
+
+func (s *SuffixValuePattern) Match(pattern string, value string, url *common.URL, invocation base.Invocation, isWhenCondition bool) bool {
+	return strings.HasSuffix(value, strings.TrimPrefix(pattern, suffixPrefix))
+}
@@ -0,0 +1,34 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pattern_value
+
+import (
+	"github.com/hashicorp/golang-lru"
+)
+
+// maxCompiledPatterns bounds the compiled patterns cached by a value pattern, so that the patterns of
+// the rules removed or changed are evicted at last. An evicted pattern is compiled again when it is matched.
+const maxCompiledPatterns = 1024
+
+func newCompiledPatterns() *lru.Cache {
+	cache, err := lru.New(maxCompiledPatterns)
+	if err != nil {
+		panic(err)
+	}
+	return cache
+}
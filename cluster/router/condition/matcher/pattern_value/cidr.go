@@ -0,0 +1,101 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pattern_value
+
+import (
+	"net"
+	"strings"
+)
+
+import (
+	"github.com/dubbogo/gost/log/logger"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/protocol/base"
+)
+
+const cidrPrefix = constant.CIDR + ":"
+
+// networks caches the parsed networks, pattern -> []*net.IPNet
+var networks = newCompiledPatterns()
+
+// CIDRValuePattern matches the ip address, e.g. the host or the remote address, with patterns like
+// 'host=cidr:10.0.0.0/8' or 'host=cidr:10.0.0.0/8|192.168.0.0/16'. The port of the address is ignored.
+type CIDRValuePattern struct {
+}
+
+func init() {
+	SetValuePattern(constant.CIDR, NewCIDRValuePattern)
+}
+
+func NewCIDRValuePattern() ValuePattern {
+	return &CIDRValuePattern{}
+}
+
+func (c *CIDRValuePattern) Priority() int64 {
+	return 40
+}
+
+func (c *CIDRValuePattern) ShouldMatch(pattern string) bool {
+	return strings.HasPrefix(pattern, cidrPrefix)
+}
+
+func (c *CIDRValuePattern) Compile(pattern string) error {
+	_, err := parseNetworks(pattern)
+	return err
+}
+
+func (c *CIDRValuePattern) Match(pattern string, value string, url *common.URL, invocation base.Invocation, isWhenCondition bool) bool {
+	nets, err := parseNetworks(pattern)
+	if err != nil {
+		logger.Errorf("Invalid condition rule '%s', will ignore, err: %v", pattern, err)
+		return !isWhenCondition
+	}
+	if host, _, err := net.SplitHostPort(value); err == nil {
+		value = host
+	}
+	ip := net.ParseIP(value)
+	if ip == nil {
+		return false
+	}
+	for _, n := range nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseNetworks(pattern string) ([]*net.IPNet, error) {
+	if nets, ok := networks.Get(pattern); ok {
+		return nets.([]*net.IPNet), nil
+	}
+	var nets []*net.IPNet
+	for _, cidr := range strings.Split(strings.TrimPrefix(pattern, cidrPrefix), "|") {
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, err
+		}
+		nets = append(nets, n)
+	}
+	networks.Add(pattern, nets)
+	return nets, nil
+}
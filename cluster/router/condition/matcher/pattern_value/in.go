@@ -0,0 +1,87 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pattern_value
+
+import (
+	"strings"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/protocol/base"
+)
+
+const (
+	inPrefix    = constant.In + ":"
+	notInPrefix = constant.NotIn + ":"
+)
+
+// InValuePattern matches with patterns like 'key=in:a|b|c'
+type InValuePattern struct {
+}
+
+// NotInValuePattern matches with patterns like 'key=notin:a|b|c'
+type NotInValuePattern struct {
+}
+
+func init() {
+	SetValuePattern(constant.In, NewInValuePattern)
+	SetValuePattern(constant.NotIn, NewNotInValuePattern)
+}
+
+func NewInValuePattern() ValuePattern {
+	return &InValuePattern{}
+}
+
+func (i *InValuePattern) Priority() int64 {
+	return 50
+}
+
+func (i *InValuePattern) ShouldMatch(pattern string) bool {
+	return strings.HasPrefix(pattern, inPrefix)
+}
+
+func (i *InValuePattern) Match(pattern string, value string, url *common.URL, invocation base.Invocation, isWhenCondition bool) bool {
+	return contains(strings.TrimPrefix(pattern, inPrefix), value)
+}
+
+func NewNotInValuePattern() ValuePattern {
+	return &NotInValuePattern{}
+}
+
+func (n *NotInValuePattern) Priority() int64 {
+	return 50
+}
+
+func (n *NotInValuePattern) ShouldMatch(pattern string) bool {
+	return strings.HasPrefix(pattern, notInPrefix)
+}
+
+func (n *NotInValuePattern) Match(pattern string, value string, url *common.URL, invocation base.Invocation, isWhenCondition bool) bool {
+	return !contains(strings.TrimPrefix(pattern, notInPrefix), value)
+}
+
+func contains(set string, value string) bool {
+	for _, item := range strings.Split(set, "|") {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
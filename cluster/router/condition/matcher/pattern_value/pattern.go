@@ -31,3 +31,10 @@ type ValuePattern interface {
 	// 0 to ^int(0) is better, smaller value by better priority
 	Priority() int64
 }
+
+// Compilable is implemented by the value patterns which compile the pattern when the rule is parsed, so that
+// the pattern is not compiled for every request and an illegal pattern is reported with the rule.
+type Compilable interface {
+	// Compile compiles and caches the pattern, it returns an error if the pattern is illegal
+	Compile(pattern string) error
+}
@@ -0,0 +1,141 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pattern_value
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+import (
+	"github.com/dubbogo/gost/log/logger"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/protocol/base"
+)
+
+const rangePrefix = constant.NumericRange + ":"
+
+// ranges caches the parsed numeric ranges, pattern -> *numericRange
+var ranges = newCompiledPatterns()
+
+// NumericRangeValuePattern matches with patterns like 'key=range:[1.5..10)', 'key=range:(0..]' or 'key=range:[..100]'.
+// '[' and ']' include the bound, '(' and ')' exclude the bound, and an empty bound means unbounded.
+type NumericRangeValuePattern struct {
+}
+
+type numericRange struct {
+	min, max                   float64
+	hasMin, hasMax             bool
+	minInclusive, maxInclusive bool
+}
+
+func init() {
+	SetValuePattern(constant.NumericRange, NewNumericRangeValuePattern)
+}
+
+func NewNumericRangeValuePattern() ValuePattern {
+	return &NumericRangeValuePattern{}
+}
+
+func (n *NumericRangeValuePattern) Priority() int64 {
+	return 30
+}
+
+func (n *NumericRangeValuePattern) ShouldMatch(pattern string) bool {
+	return strings.HasPrefix(pattern, rangePrefix)
+}
+
+func (n *NumericRangeValuePattern) Compile(pattern string) error {
+	_, err := parseRange(pattern)
+	return err
+}
+
+func (n *NumericRangeValuePattern) Match(pattern string, value string, url *common.URL, invocation base.Invocation, isWhenCondition bool) bool {
+	r, err := parseRange(pattern)
+	if err != nil {
+		logger.Errorf("Invalid condition rule '%s', will ignore, err: %v", pattern, err)
+		return !isWhenCondition
+	}
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return false
+	}
+	return r.contains(v)
+}
+
+func (r *numericRange) contains(v float64) bool {
+	if r.hasMin && (v < r.min || (v == r.min && !r.minInclusive)) {
+		return false
+	}
+	if r.hasMax && (v > r.max || (v == r.max && !r.maxInclusive)) {
+		return false
+	}
+	return true
+}
+
+func parseRange(pattern string) (*numericRange, error) {
+	if r, ok := ranges.Get(pattern); ok {
+		return r.(*numericRange), nil
+	}
+	raw := strings.TrimPrefix(pattern, rangePrefix)
+	if len(raw) < 4 {
+		return nil, fmt.Errorf("illegal numeric range %s", raw)
+	}
+	r := &numericRange{}
+	switch raw[0] {
+	case '[':
+		r.minInclusive = true
+	case '(':
+	default:
+		return nil, fmt.Errorf("numeric range %s should start with '[' or '('", raw)
+	}
+	switch raw[len(raw)-1] {
+	case ']':
+		r.maxInclusive = true
+	case ')':
+	default:
+		return nil, fmt.Errorf("numeric range %s should end with ']' or ')'", raw)
+	}
+	bounds := strings.Split(raw[1:len(raw)-1], "..")
+	if len(bounds) != 2 || (bounds[0] == "" && bounds[1] == "") {
+		return nil, fmt.Errorf("illegal numeric range %s", raw)
+	}
+	var err error
+	if bounds[0] != "" {
+		if r.min, err = strconv.ParseFloat(bounds[0], 64); err != nil {
+			return nil, fmt.Errorf("illegal lower bound of numeric range %s", raw)
+		}
+		r.hasMin = true
+	}
+	if bounds[1] != "" {
+		if r.max, err = strconv.ParseFloat(bounds[1], 64); err != nil {
+			return nil, fmt.Errorf("illegal upper bound of numeric range %s", raw)
+		}
+		r.hasMax = true
+	}
+	if r.hasMin && r.hasMax && r.min > r.max {
+		return nil, fmt.Errorf("the lower bound is greater than the upper bound in numeric range %s", raw)
+	}
+	ranges.Add(pattern, r)
+	return r, nil
+}
@@ -0,0 +1,85 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pattern_value
+
+import (
+	"regexp"
+	"strings"
+)
+
+import (
+	"github.com/dubbogo/gost/log/logger"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/protocol/base"
+)
+
+const regexPrefix = constant.Regex + ":"
+
+// regexps caches the compiled regular expressions, pattern -> *regexp.Regexp
+var regexps = newCompiledPatterns()
+
+// RegexValuePattern matches with patterns like 'key=regex:^v2\.\d+$'. The expression should not contain
+// the separators of the condition rule, which are '&', '!', '=', ',' and whitespaces.
+type RegexValuePattern struct {
+}
+
+func init() {
+	SetValuePattern(constant.Regex, NewRegexValuePattern)
+}
+
+func NewRegexValuePattern() ValuePattern {
+	return &RegexValuePattern{}
+}
+
+func (r *RegexValuePattern) Priority() int64 {
+	return 10
+}
+
+func (r *RegexValuePattern) ShouldMatch(pattern string) bool {
+	return strings.HasPrefix(pattern, regexPrefix)
+}
+
+func (r *RegexValuePattern) Compile(pattern string) error {
+	_, err := compileRegex(pattern)
+	return err
+}
+
+func (r *RegexValuePattern) Match(pattern string, value string, url *common.URL, invocation base.Invocation, isWhenCondition bool) bool {
+	re, err := compileRegex(pattern)
+	if err != nil {
+		logger.Errorf("Invalid condition rule '%s', will ignore, err: %v", pattern, err)
+		return !isWhenCondition
+	}
+	return re.MatchString(value)
+}
+
+func compileRegex(pattern string) (*regexp.Regexp, error) {
+	if re, ok := regexps.Get(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(strings.TrimPrefix(pattern, regexPrefix))
+	if err != nil {
+		return nil, err
+	}
+	regexps.Add(pattern, re)
+	return re, nil
+}
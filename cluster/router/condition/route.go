@@ -237,6 +237,9 @@ func processEqualNotEqualSeparator(separator, content string, currentMatcher mat
 	if separator == "!=" {
 		values = currentMatcher.GetMismatches()
 	}
+	if err := matcher.CompilePattern(content); err != nil {
+		return nil, nil, errors.Wrapf(err, illegalMsg, rule, separator, content)
+	}
 	values[content] = struct{}{}
 	return values, currentMatcher, nil
 }
@@ -245,6 +248,9 @@ func processCommaSeparator(content string, values map[string]struct{}, rule stri
 	if len(values) == 0 {
 		return nil, errors.Errorf(illegalMsg, rule, ",", content)
 	}
+	if err := matcher.CompilePattern(content); err != nil {
+		return nil, errors.Wrapf(err, illegalMsg, rule, ",", content)
+	}
 	values[content] = struct{}{}
 	return values, nil
 }
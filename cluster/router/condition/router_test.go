@@ -658,6 +658,198 @@ func TestRouteRangePattern(t *testing.T) {
 	}
 }
 
+func TestRouteValuePatterns(t *testing.T) {
+	consumerURL, _ := common.NewURL(localConsumerAddr)
+
+	url1, _ := common.NewURL(remoteProviderAddr + region)
+	url2, _ := common.NewURL(localProviderAddr)
+	url3, _ := common.NewURL(localProviderAddr)
+
+	ink1 := base.NewBaseInvoker(url1)
+	ink2 := base.NewBaseInvoker(url2)
+	ink3 := base.NewBaseInvoker(url3)
+
+	invokerList := make([]base.Invoker, 0, 3)
+	invokerList = append(invokerList, ink1)
+	invokerList = append(invokerList, ink2)
+	invokerList = append(invokerList, ink3)
+
+	testData := []struct {
+		name            string
+		attachmentKey   string
+		attachmentValue string
+		rule            string
+
+		wantVal int
+	}{
+		{
+			name:            "Match the regex",
+			attachmentKey:   "user_id",
+			attachmentValue: "vip_123",
+			rule:            "attachments[user_id] = regex:^vip_[0-9]+$ => region=hangzhou",
+
+			wantVal: 1,
+		},
+		{
+			name:            "Mismatch the regex",
+			attachmentKey:   "user_id",
+			attachmentValue: "user_123",
+			rule:            "attachments[user_id] = regex:^vip_[0-9]+$ => region=hangzhou",
+
+			wantVal: 3,
+		},
+		{
+			name:            "Match the prefix",
+			attachmentKey:   "user_id",
+			attachmentValue: "vip_123",
+			rule:            "attachments[user_id] = prefix:vip_ => region=hangzhou",
+
+			wantVal: 1,
+		},
+		{
+			name:            "Match the suffix",
+			attachmentKey:   "user_id",
+			attachmentValue: "123_test",
+			rule:            "attachments[user_id] = suffix:_test => region=hangzhou",
+
+			wantVal: 1,
+		},
+		{
+			name:            "In the numeric range",
+			attachmentKey:   "score",
+			attachmentValue: "9.5",
+			rule:            "attachments[score] = range:[1.5..10) => region=hangzhou",
+
+			wantVal: 1,
+		},
+		{
+			name:            "Out of the exclusive bound of numeric range",
+			attachmentKey:   "score",
+			attachmentValue: "10",
+			rule:            "attachments[score] = range:[1.5..10) => region=hangzhou",
+
+			wantVal: 3,
+		},
+		{
+			name:            "In the unbounded numeric range",
+			attachmentKey:   "score",
+			attachmentValue: "-100",
+			rule:            "attachments[score] = range:[..0] => region=hangzhou",
+
+			wantVal: 1,
+		},
+		{
+			name:            "In the cidr",
+			attachmentKey:   "client_ip",
+			attachmentValue: "10.1.2.3:8080",
+			rule:            "attachments[client_ip] = cidr:192.168.0.0/16|10.0.0.0/8 => region=hangzhou",
+
+			wantVal: 1,
+		},
+		{
+			name:            "Out of the cidr",
+			attachmentKey:   "client_ip",
+			attachmentValue: "172.16.0.1",
+			rule:            "attachments[client_ip] = cidr:192.168.0.0/16|10.0.0.0/8 => region=hangzhou",
+
+			wantVal: 3,
+		},
+		{
+			name:            "In the values",
+			attachmentKey:   "tenant",
+			attachmentValue: "b",
+			rule:            "attachments[tenant] = in:a|b|c => region=hangzhou",
+
+			wantVal: 1,
+		},
+		{
+			name:            "Not in the excluded values",
+			attachmentKey:   "tenant",
+			attachmentValue: "b",
+			rule:            "attachments[tenant] = notin:a|b|c => region=hangzhou",
+
+			wantVal: 3,
+		},
+	}
+
+	for _, data := range testData {
+		t.Run(data.name, func(t *testing.T) {
+			rpcInvocation := invocation.NewRPCInvocation(method, nil, nil)
+			rpcInvocation.SetAttachment(data.attachmentKey, data.attachmentValue)
+
+			url, err := common.NewURL(conditionAddr)
+			assert.Nil(t, err)
+			url.AddParam(constant.RuleKey, data.rule)
+			url.AddParam(constant.ForceKey, "true")
+			router, err := NewConditionStateRouter(url)
+			assert.Nil(t, err)
+
+			filterInvokers := router.Route(invokerList, consumerURL, rpcInvocation)
+			assert.Equal(t, data.wantVal, len(filterInvokers))
+		})
+	}
+}
+
+func TestRouteIllegalValuePatterns(t *testing.T) {
+	rules := []string{
+		"attachments[user_id] = regex:^vip_[0-9+$ => region=hangzhou",
+		"attachments[score] = range:[10..1] => region=hangzhou",
+		"attachments[score] = range:1..10 => region=hangzhou",
+		"host = cidr:10.0.0.0/33 => region=hangzhou",
+		"host = 127.0.0.1 => region=regex:(hang",
+	}
+	for _, rule := range rules {
+		url, err := common.NewURL(conditionAddr)
+		assert.Nil(t, err)
+		url.AddParam(constant.RuleKey, rule)
+		_, err = NewConditionStateRouter(url)
+		assert.Error(t, err, rule)
+	}
+}
+
+func TestMultiConditionRouteValuePatterns(t *testing.T) {
+	d := DynamicRouter{}
+	d.Process(&config_center.ConfigChangeEvent{
+		Value: `configVersion: v3.1
+scope: service
+key: org.apache.dubbo.samples.CommentService
+force: false
+runtime: true
+enabled: true
+conditions:
+  - from:
+      match: region=regex:^bei
+    to:
+      - match: env=notin:gray|test
+        weight: 100
+`,
+		ConfigType: remoting.EventTypeUpdate,
+	})
+	assert.NotNil(t, d.conditionRouter)
+
+	res := d.Route(buildInvokers(), newUrl("consumer://127.0.0.1/com.foo.BarService?region=beijing"),
+		invocation.NewRPCInvocation("echo", nil, nil))
+	assert.Len(t, res, 14)
+	for _, ivk := range res {
+		assert.Equal(t, "normal", ivk.GetURL().GetParam("env", ""))
+	}
+
+	d.Process(&config_center.ConfigChangeEvent{
+		Value: `configVersion: v3.1
+scope: service
+key: org.apache.dubbo.samples.CommentService
+enabled: true
+conditions:
+  - from:
+      match: region=regex:[bei
+    to:
+      - match: env=gray
+`,
+		ConfigType: remoting.EventTypeUpdate,
+	})
+	assert.Nil(t, d.conditionRouter)
+}
+
 func TestRouteMultipleConditions(t *testing.T) {
 	url1, _ := common.NewURL(remoteProviderAddr + region)
 	url2, _ := common.NewURL(localProviderAddr)
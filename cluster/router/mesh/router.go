@@ -0,0 +1,257 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mesh
+
+import (
+	"math/rand"
+	"strings"
+	"sync"
+)
+
+import (
+	"github.com/dubbogo/gost/log/logger"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+	conf "dubbo.apache.org/dubbo-go/v3/common/config"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/config"
+	"dubbo.apache.org/dubbo-go/v3/config_center"
+	"dubbo.apache.org/dubbo-go/v3/protocol/base"
+	"dubbo.apache.org/dubbo-go/v3/remoting"
+)
+
+// PriorityRouter routes the requests by the mesh rules of the provider applications. The rule of an application
+// is set by the config center with the key "{application}.MESHAPPRULE", which consists of the DestinationRules
+// defining the subsets of the providers by the labels of their urls, and the VirtualServices routing the matched
+// requests to the weighted subsets, e.g.
+//
+//	apiVersion: service.dubbo.apache.org/v1alpha1
+//	kind: DestinationRule
+//	metadata:
+//	  name: demo-route
+//	spec:
+//	  host: demo
+//	  subsets:
+//	    - name: v1
+//	      labels:
+//	        version: v1
+//	    - name: v2
+//	      labels:
+//	        version: v2
+//	---
+//	apiVersion: service.dubbo.apache.org/v1alpha1
+//	kind: VirtualService
+//	metadata:
+//	  name: demo-route
+//	spec:
+//	  hosts:
+//	    - demo
+//	  dubbo:
+//	    - services:
+//	        - exact: org.apache.dubbo.samples.UserProvider
+//	      routedetail:
+//	        - name: gray
+//	          match:
+//	            - method:
+//	                name_match:
+//	                  exact: GetUser
+//	              sourceLabels:
+//	                env: gray
+//	          route:
+//	            - destination:
+//	                host: demo
+//	                subset: v2
+//	                fallback:
+//	                  destination:
+//	                    host: demo
+//	                    subset: v1
+//	        - name: default
+//	          route:
+//	            - destination:
+//	                host: demo
+//	                subset: v1
+//	              weight: 90
+//	            - destination:
+//	                host: demo
+//	                subset: v2
+//	              weight: 10
+//
+// The requests which match no route detail are not routed. If no provider is found in a destination and its
+// fallbacks, the other destinations are tried, and no provider is returned at last.
+type PriorityRouter struct {
+	mu sync.RWMutex
+	// rules is the mesh rules of the subscribed applications, the value is nil if there is no rule
+	rules map[string]*meshRule
+}
+
+func NewMeshPriorityRouter() *PriorityRouter {
+	return &PriorityRouter{rules: make(map[string]*meshRule)}
+}
+
+// Route Determine the target invokers list.
+func (p *PriorityRouter) Route(invokers []base.Invoker, url *common.URL, invocation base.Invocation) []base.Invoker {
+	if len(invokers) == 0 {
+		return invokers
+	}
+	for _, application := range applications(invokers) {
+		p.mu.RLock()
+		rule := p.rules[application]
+		p.mu.RUnlock()
+		if rule == nil {
+			continue
+		}
+		destinations := rule.getDestinations(url, invocation)
+		if len(destinations) == 0 {
+			continue
+		}
+		if res := selectInvokers(rule, invokers, destinations); len(res) > 0 {
+			return res
+		}
+		logger.Warnf("[mesh router] No provider of %s is found in the destinations of the mesh rule of %s.",
+			url.ServiceKey(), application)
+		return []base.Invoker{}
+	}
+	return invokers
+}
+
+func (p *PriorityRouter) URL() *common.URL {
+	return nil
+}
+
+func (p *PriorityRouter) Priority() int64 {
+	// the subsets are chosen before the tag and condition routers filter the providers
+	return -100
+}
+
+// Notify subscribes the mesh rules of the applications of the invokers.
+func (p *PriorityRouter) Notify(invokers []base.Invoker) {
+	if len(invokers) == 0 {
+		return
+	}
+	dynamicConfiguration := conf.GetEnvInstance().GetDynamicConfiguration()
+	if dynamicConfiguration == nil {
+		logger.Infof("Config center does not start, Mesh router will not be enabled")
+		return
+	}
+	for _, application := range applications(invokers) {
+		p.mu.Lock()
+		_, subscribed := p.rules[application]
+		if !subscribed {
+			p.rules[application] = nil
+		}
+		p.mu.Unlock()
+		if subscribed {
+			continue
+		}
+		key := strings.Join([]string{application, constant.MeshRouteSuffix}, "")
+		dynamicConfiguration.AddListener(key, p)
+		value, err := dynamicConfiguration.GetRule(key)
+		if err != nil {
+			logger.Errorf("Failed to query mesh rule, key=%s, err=%v", key, err)
+			continue
+		}
+		if value == "" {
+			logger.Infof("mesh rule is empty, key=%s", key)
+			continue
+		}
+		p.Process(&config_center.ConfigChangeEvent{Key: key, Value: value, ConfigType: remoting.EventTypeAdd})
+	}
+}
+
+func (p *PriorityRouter) Process(event *config_center.ConfigChangeEvent) {
+	application := strings.TrimSuffix(event.Key, constant.MeshRouteSuffix)
+	if event.ConfigType == remoting.EventTypeDel {
+		p.mu.Lock()
+		p.rules[application] = nil
+		p.mu.Unlock()
+		return
+	}
+	rule, err := parseRule(event.Value.(string))
+	if err != nil {
+		logger.Warnf("[mesh router] Failed to parse mesh rule, key=%s, err=%v, "+
+			"and we will use the original mesh rule.", event.Key, err)
+		return
+	}
+	p.mu.Lock()
+	p.rules[application] = rule
+	p.mu.Unlock()
+	logger.Infof("[mesh router] Parse mesh rule success, key=%s", event.Key)
+}
+
+// applications returns the distinct applications of the invokers in order.
+func applications(invokers []base.Invoker) []string {
+	var res []string
+	seen := make(map[string]struct{})
+	for _, invoker := range invokers {
+		application := invoker.GetURL().GetParam(constant.ApplicationKey, "")
+		if application == "" {
+			continue
+		}
+		if _, ok := seen[application]; !ok {
+			seen[application] = struct{}{}
+			res = append(res, application)
+		}
+	}
+	return res
+}
+
+// selectInvokers selects a destination by weight, and returns the invokers of it or its fallbacks. The other
+// destinations are selected if there is no provider in the selected one.
+func selectInvokers(rule *meshRule, invokers []base.Invoker, destinations []*config.DubboRouteDestination) []base.Invoker {
+	candidates := make([]*config.DubboRouteDestination, 0, len(destinations))
+	for _, d := range destinations {
+		if d != nil {
+			candidates = append(candidates, d)
+		}
+	}
+	for len(candidates) > 0 {
+		i := selectByWeight(candidates)
+		if res := rule.fallbackInvokers(invokers, candidates[i]); len(res) > 0 {
+			return res
+		}
+		candidates = append(candidates[:i], candidates[i+1:]...)
+	}
+	return nil
+}
+
+// selectByWeight returns the index of the destination selected by weight, the destinations are selected
+// evenly if none of them has a weight.
+func selectByWeight(destinations []*config.DubboRouteDestination) int {
+	totalWeight := 0
+	for _, d := range destinations {
+		if d.Weight > 0 {
+			totalWeight += d.Weight
+		}
+	}
+	if totalWeight == 0 {
+		return rand.Intn(len(destinations))
+	}
+	offset := rand.Intn(totalWeight)
+	for i, d := range destinations {
+		if d.Weight <= 0 {
+			continue
+		}
+		if offset < d.Weight {
+			return i
+		}
+		offset -= d.Weight
+	}
+	return len(destinations) - 1
+}
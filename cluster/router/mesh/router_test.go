@@ -0,0 +1,225 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mesh
+
+import (
+	"testing"
+)
+
+import (
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/config_center"
+	"dubbo.apache.org/dubbo-go/v3/protocol/base"
+	"dubbo.apache.org/dubbo-go/v3/protocol/invocation"
+	"dubbo.apache.org/dubbo-go/v3/remoting"
+)
+
+const meshRuleKey = "demo.MESHAPPRULE"
+
+const ruleContent = `apiVersion: service.dubbo.apache.org/v1alpha1
+kind: DestinationRule
+metadata:
+  name: demo-route
+spec:
+  host: demo
+  subsets:
+    - name: v1
+      labels:
+        version: v1
+    - name: v2
+      labels:
+        version: v2
+    - name: v3
+      labels:
+        version: v3
+---
+apiVersion: service.dubbo.apache.org/v1alpha1
+kind: VirtualService
+metadata:
+  name: demo-route
+spec:
+  hosts:
+    - demo
+  dubbo:
+    - services:
+        - exact: com.foo.BarService
+      routedetail:
+        - name: gray
+          match:
+            - method:
+                name_match:
+                  exact: GetUser
+              sourceLabels:
+                env: gray
+          route:
+            - destination:
+                host: demo
+                subset: v3
+                fallback:
+                  destination:
+                    host: demo
+                    subset: v2
+        - name: vip
+          match:
+            - attachments:
+                dubbocontext:
+                  user:
+                    prefix: vip
+          route:
+            - destination:
+                host: demo
+                subset: v2
+        - name: missing
+          match:
+            - method:
+                name_match:
+                  exact: GetMissing
+          route:
+            - destination:
+                host: demo
+                subset: v4
+        - name: default
+          route:
+            - destination:
+                host: demo
+                subset: v1
+              weight: 100
+            - destination:
+                host: demo
+                subset: v2
+              weight: 0
+`
+
+var providerUrls = []string{
+	"dubbo://127.0.0.1:20000/com.foo.BarService?application=demo&version=v1",
+	"dubbo://127.0.0.2:20000/com.foo.BarService?application=demo&version=v1",
+	"dubbo://127.0.0.3:20000/com.foo.BarService?application=demo&version=v2",
+}
+
+func buildInvokers() []base.Invoker {
+	res := make([]base.Invoker, 0, len(providerUrls))
+	for _, url := range providerUrls {
+		u, err := common.NewURL(url)
+		if err != nil {
+			panic(err)
+		}
+		res = append(res, base.NewBaseInvoker(u))
+	}
+	return res
+}
+
+func newRouter(rule string) *PriorityRouter {
+	r := NewMeshPriorityRouter()
+	r.Process(&config_center.ConfigChangeEvent{Key: meshRuleKey, Value: rule, ConfigType: remoting.EventTypeAdd})
+	return r
+}
+
+func TestParseRule(t *testing.T) {
+	rule, err := parseRule(ruleContent)
+	assert.Nil(t, err)
+	assert.Len(t, rule.routes, 1)
+	assert.Len(t, rule.routes[0].RouteDetail, 4)
+	assert.Equal(t, map[string]string{"version": "v2"}, rule.subsets["demo"]["v2"])
+
+	_, err = parseRule("apiVersion: service.dubbo.apache.org/v1alpha1\nkind: Gateway\n")
+	assert.Error(t, err)
+
+	_, err = parseRule("kind: DestinationRule\nspec:\n  host: demo\n  subsets:\n    - labels:\n        version: v1\n")
+	assert.Error(t, err)
+}
+
+func TestRoute(t *testing.T) {
+	r := newRouter(ruleContent)
+	invokers := buildInvokers()
+
+	testData := []struct {
+		name        string
+		consumerURL string
+		method      string
+		attachments map[string]any
+
+		want []base.Invoker
+	}{
+		{
+			name:        "fallback to v2 as there is no provider in v3",
+			consumerURL: "consumer://127.0.0.1/com.foo.BarService?env=gray",
+			method:      "GetUser",
+
+			want: invokers[2:],
+		},
+		{
+			name:        "match the attachments",
+			consumerURL: "consumer://127.0.0.1/com.foo.BarService",
+			method:      "GetUser",
+			attachments: map[string]any{"user": "vip_1"},
+
+			want: invokers[2:],
+		},
+		{
+			name:        "match the default route",
+			consumerURL: "consumer://127.0.0.1/com.foo.BarService?env=normal",
+			method:      "GetUser",
+
+			want: invokers[:2],
+		},
+		{
+			name:        "no provider in the destination",
+			consumerURL: "consumer://127.0.0.1/com.foo.BarService",
+			method:      "GetMissing",
+
+			want: []base.Invoker{},
+		},
+		{
+			name:        "mismatch the services",
+			consumerURL: "consumer://127.0.0.1/com.foo.QuxService",
+			method:      "GetUser",
+
+			want: invokers,
+		},
+	}
+	for _, data := range testData {
+		t.Run(data.name, func(t *testing.T) {
+			url, _ := common.NewURL(data.consumerURL)
+			inv := invocation.NewRPCInvocation(data.method, nil, data.attachments)
+			assert.Equal(t, data.want, r.Route(invokers, url, inv))
+		})
+	}
+}
+
+func TestRouteWithoutRule(t *testing.T) {
+	invokers := buildInvokers()
+	url, _ := common.NewURL("consumer://127.0.0.1/com.foo.BarService")
+	inv := invocation.NewRPCInvocation("GetUser", nil, nil)
+
+	r := NewMeshPriorityRouter()
+	assert.Equal(t, invokers, r.Route(invokers, url, inv))
+
+	r = newRouter(ruleContent)
+	assert.Equal(t, invokers[:2], r.Route(invokers, url, inv))
+
+	// the illegal rule is ignored, and the original one is kept
+	r.Process(&config_center.ConfigChangeEvent{Key: meshRuleKey, Value: "kind: Gateway", ConfigType: remoting.EventTypeUpdate})
+	assert.Equal(t, invokers[:2], r.Route(invokers, url, inv))
+
+	r.Process(&config_center.ConfigChangeEvent{Key: meshRuleKey, ConfigType: remoting.EventTypeDel})
+	assert.Equal(t, invokers, r.Route(invokers, url, inv))
+}
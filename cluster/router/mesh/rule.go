@@ -0,0 +1,211 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mesh
+
+import (
+	"io"
+	"strings"
+)
+
+import (
+	perrors "github.com/pkg/errors"
+
+	"gopkg.in/yaml.v2"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/config"
+	"dubbo.apache.org/dubbo-go/v3/protocol/base"
+)
+
+const (
+	kindVirtualService  = "VirtualService"
+	kindDestinationRule = "DestinationRule"
+
+	// maxFallbackDepth limits the length of the fallback chain, in case of the circular fallbacks
+	maxFallbackDepth = 8
+)
+
+// meshRule is the parsed mesh rule of an application
+type meshRule struct {
+	routes []*config.DubboRoute
+	// subsets is the labels of the subsets, host -> subset name -> labels
+	subsets map[string]map[string]map[string]string
+}
+
+// parseRule parses the multi-document mesh rule which consists of VirtualServices and DestinationRules.
+func parseRule(content string) (*meshRule, error) {
+	rule := &meshRule{subsets: make(map[string]map[string]map[string]string)}
+	decoder := yaml.NewDecoder(strings.NewReader(content))
+	for {
+		doc := map[string]any{}
+		if err := decoder.Decode(&doc); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+		if len(doc) == 0 {
+			continue
+		}
+		raw, err := yaml.Marshal(doc)
+		if err != nil {
+			return nil, err
+		}
+		switch kind, _ := doc["kind"].(string); kind {
+		case kindVirtualService:
+			vs := &config.VirtualServiceConfig{}
+			if err = yaml.Unmarshal(raw, vs); err != nil {
+				return nil, err
+			}
+			for _, route := range vs.Spec.Dubbo {
+				if route != nil {
+					rule.routes = append(rule.routes, route)
+				}
+			}
+		case kindDestinationRule:
+			dr := &config.DestinationRuleConfig{}
+			if err = yaml.Unmarshal(raw, dr); err != nil {
+				return nil, err
+			}
+			subsets := rule.subsets[dr.Spec.Host]
+			if subsets == nil {
+				subsets = make(map[string]map[string]string)
+				rule.subsets[dr.Spec.Host] = subsets
+			}
+			for _, s := range dr.Spec.Subsets {
+				if s == nil || s.Name == "" {
+					return nil, perrors.Errorf("the subset of DestinationRule %s has no name", dr.Metadata.Name)
+				}
+				subsets[s.Name] = s.Labels
+			}
+		default:
+			return nil, perrors.Errorf("unknown kind %q of mesh rule", kind)
+		}
+	}
+	return rule, nil
+}
+
+// getDestinations returns the destinations of the first route detail which matches the request, and nil if
+// there is none.
+func (r *meshRule) getDestinations(url *common.URL, invocation base.Invocation) []*config.DubboRouteDestination {
+	for _, route := range r.routes {
+		if !isServiceMatch(route, url) {
+			continue
+		}
+		for _, detail := range route.RouteDetail {
+			if detail != nil && isDetailMatch(detail, url, invocation) {
+				return detail.Route
+			}
+		}
+	}
+	return nil
+}
+
+// subsetInvokers returns the invokers of the application host with all the labels of the subset.
+func (r *meshRule) subsetInvokers(invokers []base.Invoker, destination config.DubboDestination) []base.Invoker {
+	labels, ok := r.subsets[destination.Host][destination.Subset]
+	if !ok {
+		return nil
+	}
+	var res []base.Invoker
+	for _, invoker := range invokers {
+		url := invoker.GetURL()
+		if destination.Host != "" && url.GetParam(constant.ApplicationKey, "") != destination.Host {
+			continue
+		}
+		matched := true
+		for k, v := range labels {
+			if url.GetParam(k, "") != v {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			res = append(res, invoker)
+		}
+	}
+	return res
+}
+
+// fallbackInvokers returns the invokers of the destination, or the ones of its fallbacks in order if there is
+// no provider in the destination.
+func (r *meshRule) fallbackInvokers(invokers []base.Invoker, destination *config.DubboRouteDestination) []base.Invoker {
+	for i := 0; destination != nil && i < maxFallbackDepth; i++ {
+		if res := r.subsetInvokers(invokers, destination.Destination); len(res) > 0 {
+			return res
+		}
+		if destination.Destination.Fallback != nil {
+			destination = destination.Destination.Fallback
+		} else {
+			destination = destination.Fallback
+		}
+	}
+	return nil
+}
+
+func isServiceMatch(route *config.DubboRoute, url *common.URL) bool {
+	if len(route.Services) == 0 && len(route.Service) == 0 {
+		return true
+	}
+	return isAnyMatch(route.Services, url) || isAnyMatch(route.Service, url)
+}
+
+func isAnyMatch(services []*common.StringMatch, url *common.URL) bool {
+	for _, s := range services {
+		if s != nil && (s.IsMatch(url.Interface()) || s.IsMatch(url.ServiceKey())) {
+			return true
+		}
+	}
+	return false
+}
+
+func isDetailMatch(detail *config.DubboRouteDetail, url *common.URL, invocation base.Invocation) bool {
+	if len(detail.Match) == 0 {
+		return true
+	}
+	for _, m := range detail.Match {
+		if m != nil && isRequestMatch(m, url, invocation) {
+			return true
+		}
+	}
+	return false
+}
+
+func isRequestMatch(m *config.DubboMatchRequest, url *common.URL, invocation base.Invocation) bool {
+	if m.Method != nil && m.Method.NameMatch != nil {
+		if invocation == nil || !m.Method.NameMatch.IsMatch(invocation.ActualMethodName()) {
+			return false
+		}
+	}
+	for k, v := range m.SourceLabels {
+		if url.GetParam(k, "") != v {
+			return false
+		}
+	}
+	if m.Attachments != nil {
+		for k, sm := range m.Attachments.DubboContext {
+			if invocation == nil || sm == nil || !sm.IsMatch(invocation.GetAttachmentWithDefaultValue(k, "")) {
+				return false
+			}
+		}
+	}
+	return true
+}
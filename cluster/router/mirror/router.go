@@ -0,0 +1,361 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mirror
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+import (
+	"github.com/dubbogo/gost/log/logger"
+
+	perrors "github.com/pkg/errors"
+
+	"gopkg.in/yaml.v2"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/cluster/router/condition"
+	"dubbo.apache.org/dubbo-go/v3/cluster/utils"
+	"dubbo.apache.org/dubbo-go/v3/common"
+	conf "dubbo.apache.org/dubbo-go/v3/common/config"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/config"
+	"dubbo.apache.org/dubbo-go/v3/config_center"
+	"dubbo.apache.org/dubbo-go/v3/metrics"
+	clusterMetrics "dubbo.apache.org/dubbo-go/v3/metrics/cluster"
+	"dubbo.apache.org/dubbo-go/v3/protocol/base"
+	"dubbo.apache.org/dubbo-go/v3/protocol/invocation"
+	"dubbo.apache.org/dubbo-go/v3/remoting"
+)
+
+// mirroredAttribute marks the invocation which has been mirrored, so the retries of it are not mirrored again.
+const mirroredAttribute = "mirror.mirrored"
+
+// PriorityRouter copies a percentage of the requests to the shadow providers selected by tag or condition rule,
+// the shadow providers are removed from the candidates of the live requests. The mirrored requests are sent
+// asynchronously with their own timeout and marked by the "dubbo.mirror" attachment, and the responses are
+// thrown away. At most max-concurrency mirrored requests are in flight, the requests are not mirrored once it is
+// reached. The rule is set by the config center with the key "{service}.mirror-router", e.g.
+//
+//	configVersion: v3.1
+//	scope: service
+//	key: org.apache.dubbo.samples.UserProvider
+//	enabled: true
+//	mirror:
+//	  percentage: 10
+//	  tag: shadow
+//	  condition: version=2.0.0
+//	  timeout: 500ms
+//	  max-concurrency: 50
+type PriorityRouter struct {
+	mu   sync.RWMutex
+	key  string
+	rule *mirrorRule
+}
+
+type mirrorRule struct {
+	ratio   float64
+	tag     string
+	matcher *condition.FieldMatcher
+	timeout time.Duration
+	// sem limits the in-flight mirrored requests
+	sem chan struct{}
+}
+
+func NewMirrorPriorityRouter() *PriorityRouter {
+	return &PriorityRouter{}
+}
+
+// Route Determine the target invokers list.
+func (p *PriorityRouter) Route(invokers []base.Invoker, url *common.URL, invocation base.Invocation) []base.Invoker {
+	if len(invokers) == 0 {
+		return invokers
+	}
+	p.mu.RLock()
+	rule := p.rule
+	p.mu.RUnlock()
+	if rule == nil {
+		return invokers
+	}
+
+	primary := make([]base.Invoker, 0, len(invokers))
+	var shadow []base.Invoker
+	for _, invoker := range invokers {
+		if rule.isShadow(url, invoker) {
+			shadow = append(shadow, invoker)
+		} else {
+			primary = append(primary, invoker)
+		}
+	}
+	if len(shadow) == 0 {
+		return invokers
+	}
+	if len(primary) == 0 {
+		logger.Warnf("[mirror router] all the providers of %s are shadow providers, the mirror rule is ignored.",
+			url.ServiceKey())
+		return invokers
+	}
+	if shouldMirror(invocation) && rand.Float64() < rule.ratio {
+		invocation.SetAttribute(mirroredAttribute, true)
+		rule.mirror(shadow[rand.Intn(len(shadow))], url, invocation)
+	}
+	return primary
+}
+
+func (p *PriorityRouter) URL() *common.URL {
+	return nil
+}
+
+func (p *PriorityRouter) Priority() int64 {
+	// the shadow providers are selected before the mesh (-100), tag and condition (0) routers filter the providers
+	return -200
+}
+
+func (p *PriorityRouter) Notify(invokers []base.Invoker) {
+	if len(invokers) == 0 {
+		return
+	}
+	url := invokers[0].GetURL()
+	if url == nil {
+		logger.Error("Failed to notify a mirror rule, because url is empty")
+		return
+	}
+	dynamicConfiguration := conf.GetEnvInstance().GetDynamicConfiguration()
+	if dynamicConfiguration == nil {
+		logger.Infof("Config center does not start, Mirror router will not be enabled")
+		return
+	}
+
+	key := strings.Join([]string{url.ColonSeparatedKey(), constant.MirrorRuleSuffix}, "")
+	p.mu.Lock()
+	subscribed := p.key == key
+	p.key = key
+	p.mu.Unlock()
+	if subscribed {
+		return
+	}
+	dynamicConfiguration.AddListener(key, p)
+	value, err := dynamicConfiguration.GetRule(key)
+	if err != nil {
+		logger.Errorf("Failed to query mirror rule, key=%s, err=%v", key, err)
+		return
+	}
+	if value == "" {
+		logger.Infof("mirror rule is empty, key=%s", key)
+		return
+	}
+	p.Process(&config_center.ConfigChangeEvent{Key: key, Value: value, ConfigType: remoting.EventTypeAdd})
+}
+
+func (p *PriorityRouter) Process(event *config_center.ConfigChangeEvent) {
+	if event.ConfigType == remoting.EventTypeDel {
+		p.mu.Lock()
+		p.rule = nil
+		p.mu.Unlock()
+		return
+	}
+	rule, err := parseRule(event.Value.(string))
+	if err != nil {
+		logger.Warnf("[mirror router] Failed to parse mirror rule, key=%s, err=%v, "+
+			"and we will use the original mirror rule.", event.Key, err)
+		return
+	}
+	p.mu.Lock()
+	p.rule = rule
+	p.mu.Unlock()
+	logger.Infof("[mirror router] Parse mirror rule success, key=%s, rule=%+v", event.Key, rule)
+}
+
+// parseRule parses the mirror rule, it returns nil if the rule is disabled.
+func parseRule(content string) (*mirrorRule, error) {
+	m := map[string]any{}
+	if err := yaml.Unmarshal([]byte(content), m); err != nil {
+		return nil, err
+	}
+	rawVersion, ok := m["configVersion"]
+	if !ok {
+		return nil, fmt.Errorf("miss `ConfigVersion` in %s", content)
+	}
+	version, ok := rawVersion.(string)
+	if !ok {
+		return nil, fmt.Errorf("`ConfigVersion` should be of type `string`, got %T", rawVersion)
+	}
+	v, err := utils.ParseVersion(version)
+	if err != nil {
+		return nil, fmt.Errorf("invalid version %s: %s", version, err.Error())
+	}
+	if v.Less(utils.V3_1) {
+		return nil, fmt.Errorf("mirror rule requires version v3.1 or above, got %s", version)
+	}
+
+	cfg := config.MirrorRouter{Enabled: true}
+	if err = yaml.Unmarshal([]byte(content), &cfg); err != nil {
+		return nil, err
+	}
+	if !cfg.Enabled || cfg.Mirror.Percentage <= 0 {
+		return nil, nil
+	}
+	if cfg.Mirror.Percentage > 100 {
+		return nil, perrors.Errorf("mirror.percentage=%v, expect 0-100", cfg.Mirror.Percentage)
+	}
+	rule := &mirrorRule{
+		ratio: cfg.Mirror.Percentage / 100,
+		tag:   strings.TrimSpace(cfg.Mirror.Tag),
+	}
+	if c := strings.TrimSpace(cfg.Mirror.Condition); c != "" {
+		m, err := condition.NewFieldMatcher(c)
+		if err != nil {
+			return nil, err
+		}
+		rule.matcher = &m
+	}
+	if rule.tag == "" && rule.matcher == nil {
+		return nil, perrors.New("neither mirror.tag nor mirror.condition is set")
+	}
+	timeout := cfg.Mirror.Timeout
+	if timeout == "" {
+		timeout = constant.DefaultMirrorTimeout
+	}
+	d, err := time.ParseDuration(timeout)
+	if err != nil || d <= 0 {
+		return nil, perrors.Errorf("mirror.timeout=%s, expect a positive duration", timeout)
+	}
+	rule.timeout = d
+	maxConcurrency := cfg.Mirror.MaxConcurrency
+	if maxConcurrency == 0 {
+		maxConcurrency = constant.DefaultMirrorMaxConcurrency
+	}
+	if maxConcurrency < 0 {
+		return nil, perrors.Errorf("mirror.max-concurrency=%d, expect a positive number", maxConcurrency)
+	}
+	rule.sem = make(chan struct{}, maxConcurrency)
+	return rule, nil
+}
+
+func (r *mirrorRule) isShadow(url *common.URL, invoker base.Invoker) bool {
+	if r.tag != "" && invoker.GetURL().GetParam(constant.Tagkey, "") != r.tag {
+		return false
+	}
+	return r.matcher == nil || r.matcher.MatchInvoker(url, invoker, nil)
+}
+
+// shouldMirror reports whether the invocation could be mirrored. The mirrored requests, the retries of
+// a mirrored invocation and the streaming calls are not mirrored.
+func shouldMirror(invocation base.Invocation) bool {
+	if _, ok := invocation.GetAttachment(constant.MirrorKey); ok {
+		return false
+	}
+	if _, ok := invocation.GetAttribute(mirroredAttribute); ok {
+		return false
+	}
+	callType, ok := invocation.GetAttribute(constant.CallTypeKey)
+	return !ok || callType == constant.CallUnary
+}
+
+// mirror sends a copy of the invocation to the shadow invoker asynchronously, and reports the outcome. The
+// invocation is not mirrored if there are too many mirrored requests in flight.
+func (r *mirrorRule) mirror(invoker base.Invoker, url *common.URL, inv base.Invocation) {
+	address := invoker.GetURL().Location
+	select {
+	case r.sem <- struct{}{}:
+	default:
+		logger.Debugf("[mirror router] Too many mirrored requests in flight, the request of %s.%s to %s is dropped",
+			url.Interface(), inv.MethodName(), address)
+		return
+	}
+	timeout := r.timeout
+	mirrorInv := newMirrorInvocation(inv, timeout)
+	go func() {
+		defer func() {
+			<-r.sem
+			if e := recover(); e != nil {
+				logger.Warnf("[mirror router] The mirrored request to %s panics: %v", address, e)
+			}
+		}()
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		start := time.Now()
+		res := invoker.Invoke(ctx, mirrorInv)
+		costMs := float64(time.Since(start)) / float64(time.Millisecond)
+		succ := res.Error() == nil
+		if !succ {
+			logger.Debugf("[mirror router] The mirrored request of %s.%s to %s failed: %v",
+				url.Interface(), inv.MethodName(), address, res.Error())
+		}
+		metrics.Publish(clusterMetrics.NewMirrorEvent(url.Interface(), inv.MethodName(), url.Group(), url.Version(),
+			address, succ, costMs))
+	}()
+}
+
+// newMirrorInvocation copies the invocation with a new reply, so the response of the mirrored request
+// doesn't overwrite the one of the live request.
+func newMirrorInvocation(inv base.Invocation, timeout time.Duration) *invocation.RPCInvocation {
+	reply := newReply(inv.Reply())
+	rawValues := append([]any(nil), inv.ParameterRawValues()...)
+	// the reply is the last one of the raw values in triple protocol
+	if n := len(rawValues); n > 0 && inv.Reply() != nil && isSame(rawValues[n-1], inv.Reply()) {
+		rawValues[n-1] = reply
+	}
+	attachments := make(map[string]any, len(inv.Attachments())+2)
+	for k, v := range inv.Attachments() {
+		attachments[k] = v
+	}
+	attachments[constant.MirrorKey] = "true"
+	attachments[constant.TimeoutKey] = timeout.String()
+
+	mirrorInv := invocation.NewRPCInvocationWithOptions(
+		invocation.WithMethodName(inv.MethodName()),
+		invocation.WithParameterTypes(inv.ParameterTypes()),
+		invocation.WithParameterTypeNames(inv.ParameterTypeNames()),
+		invocation.WithParameterValues(inv.ParameterValues()),
+		invocation.WithArguments(inv.Arguments()),
+		invocation.WithParameterRawValues(rawValues),
+		invocation.WithReply(reply),
+		invocation.WithAttachments(attachments),
+	)
+	for k, v := range inv.Attributes() {
+		if k != mirroredAttribute {
+			mirrorInv.SetAttribute(k, v)
+		}
+	}
+	return mirrorInv
+}
+
+// newReply returns a new zero value of the type which the reply points to.
+func newReply(reply any) any {
+	if reply == nil {
+		return nil
+	}
+	t := reflect.TypeOf(reply)
+	if t.Kind() != reflect.Ptr {
+		return reply
+	}
+	return reflect.New(t.Elem()).Interface()
+}
+
+func isSame(a, b any) bool {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	return va.Kind() == reflect.Ptr && vb.Kind() == reflect.Ptr && va.Pointer() == vb.Pointer()
+}
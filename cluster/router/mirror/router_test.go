@@ -0,0 +1,195 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mirror
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/config_center"
+	"dubbo.apache.org/dubbo-go/v3/protocol/base"
+	"dubbo.apache.org/dubbo-go/v3/protocol/invocation"
+	"dubbo.apache.org/dubbo-go/v3/protocol/result"
+	"dubbo.apache.org/dubbo-go/v3/remoting"
+)
+
+const ruleContent = `configVersion: v3.1
+scope: service
+key: com.foo.BarService
+enabled: true
+mirror:
+  percentage: 100
+  tag: shadow
+  condition: version=2.0.0
+  timeout: 200ms
+`
+
+type reply struct {
+	Value string
+}
+
+// recordInvoker records the invocations it receives.
+type recordInvoker struct {
+	*base.BaseInvoker
+	invocations chan base.Invocation
+}
+
+func (i *recordInvoker) Invoke(_ context.Context, inv base.Invocation) result.Result {
+	inv.Reply().(*reply).Value = "shadow"
+	i.invocations <- inv
+	return &result.RPCResult{}
+}
+
+func newInvoker(t *testing.T, rawURL string) *recordInvoker {
+	url, err := common.NewURL(rawURL)
+	assert.Nil(t, err)
+	return &recordInvoker{BaseInvoker: base.NewBaseInvoker(url), invocations: make(chan base.Invocation, 8)}
+}
+
+func TestParseRule(t *testing.T) {
+	rule, err := parseRule(ruleContent)
+	assert.Nil(t, err)
+	assert.Equal(t, 1.0, rule.ratio)
+	assert.Equal(t, "shadow", rule.tag)
+	assert.NotNil(t, rule.matcher)
+	assert.Equal(t, 200*time.Millisecond, rule.timeout)
+	assert.Equal(t, constant.DefaultMirrorMaxConcurrency, cap(rule.sem))
+
+	rule, err = parseRule("configVersion: v3.1\nenabled: false\nmirror:\n  percentage: 10\n  tag: shadow")
+	assert.Nil(t, err)
+	assert.Nil(t, rule)
+
+	rule, err = parseRule("configVersion: v3.1\nmirror:\n  percentage: 10\n  tag: shadow\n  max-concurrency: 5")
+	assert.Nil(t, err)
+	assert.Equal(t, 0.1, rule.ratio)
+	assert.Nil(t, rule.matcher)
+	assert.Equal(t, time.Second, rule.timeout)
+	assert.Equal(t, 5, cap(rule.sem))
+
+	illegalRules := []string{
+		"mirror:\n  percentage: 10\n  tag: shadow",
+		"configVersion: v3.0\nmirror:\n  percentage: 10\n  tag: shadow",
+		"configVersion: v3.1\nmirror:\n  percentage: 101\n  tag: shadow",
+		"configVersion: v3.1\nmirror:\n  percentage: 10",
+		"configVersion: v3.1\nmirror:\n  percentage: 10\n  tag: shadow\n  timeout: abc",
+		"configVersion: v3.1\nmirror:\n  percentage: 10\n  tag: shadow\n  max-concurrency: -1",
+	}
+	for _, content := range illegalRules {
+		_, err = parseRule(content)
+		assert.NotNil(t, err, content)
+	}
+}
+
+// blockingInvoker blocks the invocations until it is released.
+type blockingInvoker struct {
+	*base.BaseInvoker
+	invoked chan struct{}
+	release chan struct{}
+}
+
+func (i *blockingInvoker) Invoke(_ context.Context, _ base.Invocation) result.Result {
+	i.invoked <- struct{}{}
+	<-i.release
+	return &result.RPCResult{}
+}
+
+func TestMirrorMaxConcurrency(t *testing.T) {
+	url, _ := common.NewURL("dubbo://127.0.0.2:20000/com.foo.BarService?dubbo.tag=shadow")
+	shadow := &blockingInvoker{BaseInvoker: base.NewBaseInvoker(url), invoked: make(chan struct{}, 8),
+		release: make(chan struct{})}
+	rule, err := parseRule("configVersion: v3.1\nmirror:\n  percentage: 100\n  tag: shadow\n  max-concurrency: 1")
+	assert.Nil(t, err)
+
+	rule.mirror(shadow, url, invocation.NewRPCInvocation("echo", nil, nil))
+	<-shadow.invoked
+	// the mirrored request is dropped since the previous one is in flight
+	rule.mirror(shadow, url, invocation.NewRPCInvocation("echo", nil, nil))
+	close(shadow.release)
+	assert.Eventually(t, func() bool {
+		return len(rule.sem) == 0
+	}, time.Second, 10*time.Millisecond)
+	assert.Len(t, shadow.invoked, 0)
+
+	rule.mirror(shadow, url, invocation.NewRPCInvocation("echo", nil, nil))
+	select {
+	case <-shadow.invoked:
+	case <-time.After(time.Second):
+		assert.Fail(t, "the request is not mirrored")
+	}
+}
+
+func TestMirrorRoute(t *testing.T) {
+	primary := newInvoker(t, "dubbo://127.0.0.1:20000/com.foo.BarService?version=1.0.0")
+	shadow := newInvoker(t, "dubbo://127.0.0.2:20000/com.foo.BarService?version=2.0.0&dubbo.tag=shadow")
+	tagOnly := newInvoker(t, "dubbo://127.0.0.3:20000/com.foo.BarService?version=1.0.0&dubbo.tag=shadow")
+	invokers := []base.Invoker{primary, shadow, tagOnly}
+	url, _ := common.NewURL("consumer://127.0.0.1/com.foo.BarService")
+
+	r := NewMirrorPriorityRouter()
+	inv := invocation.NewRPCInvocation("echo", []any{"hello"}, map[string]any{"user": "dubbo"})
+	assert.Equal(t, invokers, r.Route(invokers, url, inv))
+
+	r.Process(&config_center.ConfigChangeEvent{Key: "com.foo.BarService.mirror-router", Value: ruleContent,
+		ConfigType: remoting.EventTypeAdd})
+	liveReply := &reply{}
+	inv = invocation.NewRPCInvocationWithOptions(
+		invocation.WithMethodName("echo"),
+		invocation.WithArguments([]any{"hello"}),
+		invocation.WithReply(liveReply),
+		invocation.WithParameterRawValues([]any{"hello", liveReply}),
+		invocation.WithAttachment("user", "dubbo"),
+	)
+	assert.Equal(t, []base.Invoker{primary, tagOnly}, r.Route(invokers, url, inv))
+
+	select {
+	case mirrored := <-shadow.invocations:
+		assert.Equal(t, "echo", mirrored.MethodName())
+		assert.Equal(t, []any{"hello"}, mirrored.Arguments())
+		assert.Equal(t, "dubbo", mirrored.GetAttachmentWithDefaultValue("user", ""))
+		assert.Equal(t, "true", mirrored.GetAttachmentWithDefaultValue(constant.MirrorKey, ""))
+		assert.Equal(t, "200ms", mirrored.GetAttachmentWithDefaultValue(constant.TimeoutKey, ""))
+		assert.Equal(t, mirrored.Reply(), mirrored.ParameterRawValues()[1])
+		// the response of the mirrored request is thrown away
+		assert.Equal(t, "", liveReply.Value)
+	case <-time.After(time.Second):
+		assert.Fail(t, "the request is not mirrored")
+	}
+
+	// the retry of the invocation is not mirrored again
+	assert.Equal(t, []base.Invoker{primary, tagOnly}, r.Route(invokers, url, inv))
+	// the mirrored request is not mirrored again
+	inv = invocation.NewRPCInvocation("echo", nil, map[string]any{constant.MirrorKey: "true"})
+	assert.Equal(t, []base.Invoker{primary, tagOnly}, r.Route(invokers, url, inv))
+	select {
+	case <-shadow.invocations:
+		assert.Fail(t, "the request is mirrored twice")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	r.Process(&config_center.ConfigChangeEvent{Key: "com.foo.BarService.mirror-router", ConfigType: remoting.EventTypeDel})
+	assert.Equal(t, invokers, r.Route(invokers, url, inv))
+}
@@ -0,0 +1,282 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package split
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"strings"
+	"sync"
+)
+
+import (
+	"github.com/dubbogo/gost/log/logger"
+
+	"gopkg.in/yaml.v2"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/cluster/utils"
+	"dubbo.apache.org/dubbo-go/v3/common"
+	conf "dubbo.apache.org/dubbo-go/v3/common/config"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/config"
+	"dubbo.apache.org/dubbo-go/v3/config_center"
+	"dubbo.apache.org/dubbo-go/v3/protocol/base"
+	"dubbo.apache.org/dubbo-go/v3/remoting"
+)
+
+// PriorityRouter splits the requests among the subsets of providers by weights. The subsets are chosen
+// by labels, version and tag of the providers. If the sticky key is set, the requests with the same
+// attachment of the sticky key, e.g. a user id, are always sent to the same subset. The rule is set by the
+// config center with the key "{service}.split-router", e.g.
+//
+//	configVersion: v3.1
+//	scope: service
+//	key: org.apache.dubbo.samples.UserProvider
+//	enabled: true
+//	stickyKey: userId
+//	subsets:
+//	  - name: v1
+//	    version: 1.0.0
+//	    weight: 95
+//	  - name: v2
+//	    labels:
+//	      env: canary
+//	    version: 2.0.0
+//	    weight: 5
+type PriorityRouter struct {
+	mu   sync.RWMutex
+	key  string
+	rule *splitRule
+}
+
+type splitRule struct {
+	force     bool
+	stickyKey string
+	subsets   []*subset
+}
+
+type subset struct {
+	name    string
+	labels  map[string]string
+	version string
+	tag     string
+	weight  int
+}
+
+func NewSplitPriorityRouter() *PriorityRouter {
+	return &PriorityRouter{}
+}
+
+// Route Determine the target invokers list.
+func (p *PriorityRouter) Route(invokers []base.Invoker, url *common.URL, invocation base.Invocation) []base.Invoker {
+	if len(invokers) == 0 {
+		return invokers
+	}
+	p.mu.RLock()
+	rule := p.rule
+	p.mu.RUnlock()
+	if rule == nil {
+		return invokers
+	}
+
+	// the subsets without any provider are skipped, and the traffic is split among the others
+	candidates := make([][]base.Invoker, len(rule.subsets))
+	totalWeight := 0
+	for i, s := range rule.subsets {
+		for _, invoker := range invokers {
+			if s.match(invoker.GetURL()) {
+				candidates[i] = append(candidates[i], invoker)
+			}
+		}
+		if len(candidates[i]) > 0 {
+			totalWeight += s.weight
+		}
+	}
+	if totalWeight == 0 {
+		if rule.force {
+			logger.Warnf("[split router] No provider of %s matches the subsets of the split rule.", url.ServiceKey())
+			return []base.Invoker{}
+		}
+		return invokers
+	}
+
+	offset := rule.offset(invocation, totalWeight)
+	for i, s := range rule.subsets {
+		if len(candidates[i]) == 0 {
+			continue
+		}
+		if offset < s.weight {
+			return candidates[i]
+		}
+		offset -= s.weight
+	}
+	// unreachable
+	return invokers
+}
+
+func (p *PriorityRouter) URL() *common.URL {
+	return nil
+}
+
+func (p *PriorityRouter) Priority() int64 {
+	// the traffic is split after the condition routers filter the providers
+	return 150
+}
+
+func (p *PriorityRouter) Notify(invokers []base.Invoker) {
+	if len(invokers) == 0 {
+		return
+	}
+	url := invokers[0].GetURL()
+	if url == nil {
+		logger.Error("Failed to notify a split rule, because url is empty")
+		return
+	}
+	dynamicConfiguration := conf.GetEnvInstance().GetDynamicConfiguration()
+	if dynamicConfiguration == nil {
+		logger.Infof("Config center does not start, Split router will not be enabled")
+		return
+	}
+
+	key := strings.Join([]string{url.ColonSeparatedKey(), constant.SplitRuleSuffix}, "")
+	p.mu.Lock()
+	subscribed := p.key == key
+	p.key = key
+	p.mu.Unlock()
+	if subscribed {
+		return
+	}
+	dynamicConfiguration.AddListener(key, p)
+	value, err := dynamicConfiguration.GetRule(key)
+	if err != nil {
+		logger.Errorf("Failed to query split rule, key=%s, err=%v", key, err)
+		return
+	}
+	if value == "" {
+		logger.Infof("Split rule is empty, key=%s", key)
+		return
+	}
+	p.Process(&config_center.ConfigChangeEvent{Key: key, Value: value, ConfigType: remoting.EventTypeAdd})
+}
+
+func (p *PriorityRouter) Process(event *config_center.ConfigChangeEvent) {
+	if event.ConfigType == remoting.EventTypeDel {
+		p.mu.Lock()
+		p.rule = nil
+		p.mu.Unlock()
+		return
+	}
+	rule, err := parseRule(event.Value.(string))
+	if err != nil {
+		logger.Warnf("[split router] Failed to parse split rule, key=%s, err=%v, "+
+			"and we will use the original split rule.", event.Key, err)
+		return
+	}
+	p.mu.Lock()
+	p.rule = rule
+	p.mu.Unlock()
+	logger.Infof("[split router] Parse split rule success, key=%s", event.Key)
+}
+
+// parseRule parses the split rule, it returns nil if the rule is disabled.
+func parseRule(rawConfig string) (*splitRule, error) {
+	m := map[string]any{}
+	if err := yaml.Unmarshal([]byte(rawConfig), m); err != nil {
+		return nil, err
+	}
+	rawVersion, ok := m["configVersion"]
+	if !ok {
+		return nil, fmt.Errorf("miss `ConfigVersion` in %s", rawConfig)
+	}
+	version, ok := rawVersion.(string)
+	if !ok {
+		return nil, fmt.Errorf("`ConfigVersion` should be of type `string`, got %T", rawVersion)
+	}
+	v, err := utils.ParseVersion(version)
+	if err != nil {
+		return nil, fmt.Errorf("invalid version %s: %s", version, err.Error())
+	}
+	if v.Less(utils.V3_1) {
+		return nil, fmt.Errorf("split rule requires version v3.1 or above, got %s", version)
+	}
+
+	cfg := config.SplitRouter{Enabled: true}
+	if err = yaml.Unmarshal([]byte(rawConfig), &cfg); err != nil {
+		return nil, err
+	}
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	rule := &splitRule{
+		force:     cfg.Force,
+		stickyKey: strings.TrimSpace(cfg.StickyKey),
+		subsets:   make([]*subset, 0, len(cfg.Subsets)),
+	}
+	for _, s := range cfg.Subsets {
+		if s == nil {
+			continue
+		}
+		if s.Weight < 0 {
+			return nil, fmt.Errorf("the weight of subset %s is %d, expect a non-negative number", s.Name, s.Weight)
+		}
+		if len(s.Labels) == 0 && s.Version == "" && s.Tag == "" {
+			return nil, fmt.Errorf("subset %s should be chosen by labels, version or tag", s.Name)
+		}
+		rule.subsets = append(rule.subsets, &subset{
+			name:    s.Name,
+			labels:  s.Labels,
+			version: s.Version,
+			tag:     s.Tag,
+			weight:  s.Weight,
+		})
+	}
+	if len(rule.subsets) == 0 {
+		return nil, fmt.Errorf("no subset in %s", rawConfig)
+	}
+	return rule, nil
+}
+
+// offset returns the offset of the request in the total weight, which decides the subset.
+func (r *splitRule) offset(invocation base.Invocation, totalWeight int) int {
+	if r.stickyKey != "" {
+		if value, ok := invocation.GetAttachment(r.stickyKey); ok && value != "" {
+			h := fnv.New32a()
+			_, _ = h.Write([]byte(value))
+			return int(h.Sum32() % uint32(totalWeight))
+		}
+	}
+	return rand.Intn(totalWeight)
+}
+
+func (s *subset) match(url *common.URL) bool {
+	if s.version != "" && url.GetParam(constant.VersionKey, "") != s.version {
+		return false
+	}
+	if s.tag != "" && url.GetParam(constant.Tagkey, "") != s.tag {
+		return false
+	}
+	for k, v := range s.labels {
+		if url.GetParam(k, "") != v {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,147 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package split
+
+import (
+	"fmt"
+	"testing"
+)
+
+import (
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/config_center"
+	"dubbo.apache.org/dubbo-go/v3/protocol/base"
+	"dubbo.apache.org/dubbo-go/v3/protocol/invocation"
+	"dubbo.apache.org/dubbo-go/v3/remoting"
+)
+
+const splitRuleKey = "com.foo.BarService.split-router"
+
+const ruleContent = `configVersion: v3.1
+scope: service
+key: com.foo.BarService
+enabled: true
+stickyKey: userId
+subsets:
+  - name: v1
+    version: 1.0.0
+    weight: 90
+  - name: v2
+    labels:
+      env: canary
+    version: 2.0.0
+    weight: 10
+`
+
+var providerUrls = []string{
+	"dubbo://127.0.0.1:20000/com.foo.BarService?version=1.0.0",
+	"dubbo://127.0.0.2:20000/com.foo.BarService?version=1.0.0",
+	"dubbo://127.0.0.3:20000/com.foo.BarService?version=2.0.0&env=canary",
+	"dubbo://127.0.0.4:20000/com.foo.BarService?version=2.0.0",
+}
+
+func buildInvokers() []base.Invoker {
+	res := make([]base.Invoker, 0, len(providerUrls))
+	for _, url := range providerUrls {
+		u, err := common.NewURL(url)
+		if err != nil {
+			panic(err)
+		}
+		res = append(res, base.NewBaseInvoker(u))
+	}
+	return res
+}
+
+func newRouter(rule string) *PriorityRouter {
+	r := NewSplitPriorityRouter()
+	r.Process(&config_center.ConfigChangeEvent{Key: splitRuleKey, Value: rule, ConfigType: remoting.EventTypeAdd})
+	return r
+}
+
+func TestParseRule(t *testing.T) {
+	rule, err := parseRule(ruleContent)
+	assert.Nil(t, err)
+	assert.Equal(t, "userId", rule.stickyKey)
+	assert.Len(t, rule.subsets, 2)
+	assert.Equal(t, 90, rule.subsets[0].weight)
+	assert.Equal(t, map[string]string{"env": "canary"}, rule.subsets[1].labels)
+
+	rule, err = parseRule("configVersion: v3.1\nenabled: false\nsubsets:\n  - version: 1.0.0")
+	assert.Nil(t, err)
+	assert.Nil(t, rule)
+
+	_, err = parseRule("subsets:\n  - version: 1.0.0")
+	assert.NotNil(t, err)
+	_, err = parseRule("configVersion: v3.0\nsubsets:\n  - version: 1.0.0")
+	assert.NotNil(t, err)
+	_, err = parseRule("configVersion: v3.1\nsubsets:\n  - name: empty\n    weight: 10")
+	assert.NotNil(t, err)
+	_, err = parseRule("configVersion: v3.1\nsubsets:\n  - version: 1.0.0\n    weight: -1")
+	assert.NotNil(t, err)
+}
+
+func TestSplitRoute(t *testing.T) {
+	invokers := buildInvokers()
+	url, _ := common.NewURL("consumer://127.0.0.1/com.foo.BarService")
+	inv := invocation.NewRPCInvocation("echo", nil, nil)
+
+	r := NewSplitPriorityRouter()
+	assert.Equal(t, invokers, r.Route(invokers, url, inv))
+
+	r = newRouter(ruleContent)
+	counts := make(map[int]int)
+	for i := 0; i < 10000; i++ {
+		res := r.Route(invokers, url, inv)
+		counts[len(res)]++
+		if len(res) == 1 {
+			assert.Equal(t, invokers[2], res[0])
+		} else {
+			assert.Equal(t, invokers[:2], res)
+		}
+	}
+	assert.InDelta(t, 0.1, float64(counts[1])/10000, 0.02)
+
+	// the subset without any provider is skipped
+	assert.Equal(t, invokers[:2], r.Route(append(invokers[:2:2], invokers[3]), url, inv))
+
+	// no provider matches the subsets
+	assert.Equal(t, invokers[3:], r.Route(invokers[3:], url, inv))
+	r = newRouter("configVersion: v3.1\nforce: true\nsubsets:\n  - version: 3.0.0\n    weight: 100")
+	assert.Empty(t, r.Route(invokers, url, inv))
+
+	r.Process(&config_center.ConfigChangeEvent{Key: splitRuleKey, ConfigType: remoting.EventTypeDel})
+	assert.Equal(t, invokers, r.Route(invokers, url, inv))
+}
+
+func TestSplitRouteSticky(t *testing.T) {
+	invokers := buildInvokers()
+	url, _ := common.NewURL("consumer://127.0.0.1/com.foo.BarService")
+	r := newRouter(ruleContent)
+
+	for i := 0; i < 100; i++ {
+		inv := invocation.NewRPCInvocation("echo", nil, map[string]any{"userId": fmt.Sprintf("user-%d", i)})
+		expected := r.Route(invokers, url, inv)
+		for j := 0; j < 10; j++ {
+			assert.Equal(t, expected, r.Route(invokers, url, inv))
+		}
+	}
+}
@@ -105,6 +105,12 @@ func (env *Environment) GetDynamicConfiguration() config_center.DynamicConfigura
 	return env.dynamicConfiguration
 }
 
+// GetConfigHistory gets the history of the dynamic configs applied, it returns nil if the history is not kept
+func (env *Environment) GetConfigHistory() *config_center.HistoryDynamicConfiguration {
+	history, _ := env.dynamicConfiguration.(*config_center.HistoryDynamicConfiguration)
+	return history
+}
+
 // InmemoryConfiguration stores config in memory
 type InmemoryConfiguration struct {
 	store *sync.Map
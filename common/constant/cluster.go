@@ -27,6 +27,12 @@ const (
 	ClusterKeyForking         = "forking"
 	ClusterKeyZoneAware       = "zoneAware"
 	ClusterKeyAdaptiveService = "adaptiveService"
+	ClusterKeyHedging         = "hedging"
+)
+
+// cluster interceptor keys
+const (
+	FaultInjectionInterceptorKey = "fault_injection"
 )
 
 const (
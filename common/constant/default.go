@@ -43,6 +43,8 @@ const (
 	DefaultProtocol         = "dubbo"
 	DefaultRegTimeout       = "5s"
 	DefaultRegTTL           = "15m"
+	DefaultSnapshotMaxAge   = "24h"
+	DefaultCompositeTimeout = "3s"
 	DefaultCluster          = "failover"
 	DefaultFailbackTimes    = "3"
 	DefaultFailbackTimesInt = 3
@@ -115,6 +117,9 @@ const (
 
 	// graceful shutdown
 	DefaultGracefulShutdownTimeout = 10 * time.Second
+
+	// the revisions of each dynamic config kept in the history
+	DefaultConfigHistorySize = 10
 )
 
 const (
@@ -34,3 +34,11 @@ const (
 	DubboPortToRegistryKey     = "DUBBO_PORT_TO_REGISTRY"
 	DubboDefaultPortToRegistry = "80"
 )
+
+// environment variables of the secret resolvers
+const (
+	SecretKeyFileEnvKey  = "DUBBO_GO_SECRET_KEY_FILE" // the file of the AES key, which is base64 encoded
+	VaultAddrEnvKey      = "VAULT_ADDR"
+	VaultTokenEnvKey     = "VAULT_TOKEN"
+	VaultNamespaceEnvKey = "VAULT_NAMESPACE"
+)
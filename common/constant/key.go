@@ -103,6 +103,7 @@ const (
 	AuthProviderFilterKey                = "auth"
 	EchoFilterKey                        = "echo"
 	ExecuteLimitFilterKey                = "execute"
+	FaultInjectionProviderFilterKey      = "fault_provider"
 	GenericFilterKey                     = "generic"
 	GenericServiceFilterKey              = "generic_service"
 	GracefulShutdownProviderFilterKey    = "pshutdown"
@@ -129,12 +130,55 @@ const (
 	LoadbalanceKey                     = "loadbalance"
 	WeightKey                          = "weight"
 	WarmupKey                          = "warmup"
+	WarmupCurveKey                     = "warmup.curve"
+	WarmupMinWeightPercentKey          = "warmup.min-weight-percent"
+	WarmupCurveLinear                  = "linear"
+	WarmupCurveAggressive              = "aggressive"
 	RetriesKey                         = "retries"
 	StickyKey                          = "sticky"
 	BeanName                           = "bean.name"
 	FailBackTasksKey                   = "failbacktasks"
 	ForksKey                           = "forks"
 	DefaultForks                       = 2
+	HedgingDelayKey                    = "hedging.delay"
+	HedgingMaxAttemptsKey              = "hedging.max-attempts"
+	DefaultHedgingDelay                = "100ms"
+	DefaultHedgingMaxAttempts          = 2
+	RetryBudgetKey                     = "retry.budget"
+	RetryBackoffKey                    = "retry.backoff"
+	RetryMaxBackoffKey                 = "retry.max-backoff"
+	RetryableCodesKey                  = "retryable.codes"
+	FaultInjectionEnabledKey           = "fault-injection.enabled"
+	OutlierEnabledKey                  = "outlier.enabled"
+	OutlierConsecutiveFailuresKey      = "outlier.consecutive-failures"
+	OutlierErrorRateKey                = "outlier.error-rate"
+	OutlierMinRequestsKey              = "outlier.min-requests"
+	OutlierBaseEjectionTimeKey         = "outlier.base-ejection-time"
+	OutlierMaxEjectionTimeKey          = "outlier.max-ejection-time"
+	OutlierMaxEjectionPercentKey       = "outlier.max-ejection-percent"
+	DefaultOutlierConsecutiveFailures  = 5
+	DefaultOutlierErrorRate            = 0.5
+	DefaultOutlierMinRequests          = 20
+	DefaultOutlierBaseEjectionTime     = "30s"
+	DefaultOutlierMaxEjectionTime      = "5m"
+	DefaultOutlierMaxEjectionPercent   = 50
+	HealthCheckEnabledKey              = "health-check.enabled"
+	HealthCheckProberKey               = "health-check.prober"
+	HealthCheckIntervalKey             = "health-check.interval"
+	HealthCheckTimeoutKey              = "health-check.timeout"
+	HealthCheckFailureThresholdKey     = "health-check.failure-threshold"
+	HealthCheckSuccessThresholdKey     = "health-check.success-threshold"
+	DefaultHealthCheckInterval         = "10s"
+	DefaultHealthCheckTimeout          = "3s"
+	DefaultHealthCheckFailureThreshold = 3
+	DefaultHealthCheckSuccessThreshold = 2
+	LocalityRegionKey                  = "region"
+	LocalityZoneKey                    = "zone"
+	LocalityLocalRegionKey             = "locality.region"
+	LocalityLocalZoneKey               = "locality.zone"
+	LocalityLoadBalanceKey             = "locality.loadbalance"
+	LocalitySpilloverThresholdKey      = "locality.spillover-threshold"
+	DefaultLocalitySpilloverThreshold  = 0.7
 	DefaultTimeout                     = 1000
 	TPSLimiterKey                      = "tps.limiter"
 	TPSRejectedExecutionHandlerKey     = "tps.limit.rejected.handler"
@@ -204,6 +248,13 @@ const (
 	RegistryTypeAll         = "all"
 )
 
+// registry snapshot keys
+const (
+	RegistrySnapshotKey       = "registry.snapshot"         // enables the local snapshots of the provider addresses
+	RegistrySnapshotDirKey    = "registry.snapshot.dir"     // the directory of the snapshot files
+	RegistrySnapshotMaxAgeKey = "registry.snapshot.max-age" // the snapshots older than it are not loaded, non-positive means no limit
+)
+
 const (
 	ApplicationKey         = "application"
 	ApplicationTagKey      = "application.tag"
@@ -243,6 +294,7 @@ const (
 	ConfigSecretKey           = "config-center.secret"
 	ConfigBackupConfigKey     = "config-center.isBackupConfig"
 	ConfigBackupConfigPathKey = "config-center.backupConfigPath"
+	ConfigHistorySizeKey      = "config-center.history-size"
 )
 
 const (
@@ -300,7 +352,39 @@ const (
 )
 
 const (
-	FileKey = "file"
+	FileKey             = "file"
+	FileRegistryPathKey = "file.path" // the path of the yaml or json file listing the providers and the instances
+)
+
+const (
+	MulticastKey                 = "multicast"
+	MulticastInterfaceKey        = "multicast.interface"         // the name of the network interface joining the multicast group
+	MulticastAnnounceIntervalKey = "multicast.announce-interval" // the interval of re-announcing the registered urls
+	MulticastExpireKey           = "multicast.expire"            // the announced urls expire if they are not re-announced in it
+)
+
+const (
+	MultipleKey                    = "multiple"
+	MultipleServiceRegistryKey     = "service-registry"               // the comma separated urls of the registries to register to
+	MultipleReferenceRegistryKey   = "reference-registry"             // the comma separated urls of the registries to subscribe, service-registry is used if absent
+	MultipleMergePolicyKey         = "multiple.merge-policy"          // union or preferred, see registry/multiple
+	MultipleHealthCheckIntervalKey = "multiple.health-check-interval" // the interval of checking the availability of the registries
+)
+
+const (
+	SecretEnvKey    = "env"       // ${secret:env:NAME} is resolved to the environment variable NAME
+	SecretAESKey    = "aes"       // ${secret:aes:ciphertext} is decrypted by the local AES key
+	SecretVaultKey  = "vault"     // ${secret:vault:path#field} is resolved to the field of the secret in vault
+	SecretPrefix    = "${secret:" // ${secret:name:ref} is resolved by the secret resolver of the name
+	SecretSuffix    = "}"
+	SecretEncPrefix = "ENC(" // ENC(ciphertext) is the short form of ${secret:aes:ciphertext}
+	SecretEncSuffix = ")"
+)
+
+const (
+	CompositeKey        = "composite"
+	CompositeSourcesKey = "composite.sources" // the comma separated urls of the config centers, in descending order of precedence
+	CompositeTimeoutKey = "composite.timeout" // the timeout of reading from each config center
 )
 
 const (
@@ -319,6 +403,32 @@ const (
 	EtcdV3Key = "etcdv3"
 )
 
+const (
+	RedisKey           = "redis"
+	RedisDBKey         = "redis.db"          // the index of the database
+	RedisMasterNameKey = "redis.master-name" // the master name of the sentinels, the addresses are the sentinels' if it is set
+)
+
+const (
+	KubernetesKey          = "kubernetes"
+	KubernetesNamespaceKey = "kubernetes.namespace"  // the namespace of the pods and config maps
+	KubernetesPodNameKey   = "kubernetes.pod-name"   // the name of the pod where the application runs
+	KubernetesConfigKey    = "kubernetes.kubeconfig" // the kubeconfig file used out of the cluster
+)
+
+const (
+	ConsulKey              = "consul"
+	ConsulTokenKey         = "consul.token"          // the ACL token of the requests
+	ConsulDatacenterKey    = "consul.datacenter"     // the datacenter of the requests, the one of the agent by default
+	ConsulWaitTimeKey      = "consul.wait-time"      // the max wait time of the blocking queries
+	ConsulCheckKey         = "consul.check"          // the type of the health check, ttl or http
+	ConsulCheckTTLKey      = "consul.check.ttl"      // the ttl of the ttl check, the heartbeat interval is a third of it
+	ConsulCheckHTTPKey     = "consul.check.http"     // the url requested by the http check
+	ConsulCheckIntervalKey = "consul.check.interval" // the interval of the http check
+	// the critical instances are deregistered by consul after the duration
+	ConsulDeregisterCriticalKey = "consul.check.deregister-critical-after"
+)
+
 const (
 	// PassThroughProxyFactoryKey is key of proxy factory with raw data input service
 	PassThroughProxyFactoryKey = "dubbo-raw"
@@ -335,15 +445,21 @@ const (
 	TagRouterRuleSuffix               = ".tag-router"
 	ConditionRouterRuleSuffix         = ".condition-router" // Specify condition router suffix
 	AffinityRuleSuffix                = ".affinity-router"  // Specify affinity router suffix
+	MirrorRuleSuffix                  = ".mirror-router"    // Specify mirror router suffix
+	SplitRuleSuffix                   = ".split-router"     // Specify traffic split router suffix
+	FaultInjectionRuleSuffix          = ".fault-injection"  // Specify fault injection rule suffix
 	MeshRouteSuffix                   = ".MESHAPPRULE"      // Specify mesh router suffix
 	ForceUseTag                       = "dubbo.force.tag"   // the tag in attachment
 	ForceUseCondition                 = "dubbo.force.condition"
 	Tagkey                            = "dubbo.tag" // key of tag
 	ConditionKey                      = "dubbo.condition"
+	MirrorKey                         = "dubbo.mirror"            // the attachment which marks the mirrored requests
 	AttachmentKey                     = DubboCtxKey("attachment") // key in context in invoker
 	TagRouterFactoryKey               = "tag"
 	AffinityAppRouterFactoryKey       = "application.affinity"
 	AffinityServiceRouterFactoryKey   = "service.affinity"
+	MirrorServiceRouterFactoryKey     = "service.mirror"
+	SplitServiceRouterFactoryKey      = "service.split"
 	ConditionAppRouterFactoryKey      = "provider.condition"
 	ConditionServiceRouterFactoryKey  = "service.condition"
 	ScriptRouterFactoryKey            = "consumer.script"
@@ -354,8 +470,17 @@ const (
 	Param                             = "param"
 	Scope                             = "scope"
 	Wildcard                          = "wildcard"
+	Regex                             = "regex"
+	Prefix                            = "prefix"
+	Suffix                            = "suffix"
+	NumericRange                      = "range"
+	CIDR                              = "cidr"
+	In                                = "in"
+	NotIn                             = "notin"
 	MeshRouterFactoryKey              = "mesh"
 	DefaultRouteConditionSubSetWeight = 100
+	DefaultMirrorTimeout              = "1s"
+	DefaultMirrorMaxConcurrency       = 100
 )
 
 // Auth filter
@@ -458,6 +583,7 @@ const (
 	RegistryEnabledKey                   = "metrics.registry.enabled"
 	ConfigCenterEnabledKey               = "metrics.config-center.enabled"
 	RpcEnabledKey                        = "metrics.rpc.enabled"
+	ClusterEnabledKey                    = "metrics.cluster.enabled"
 	AggregationEnabledKey                = "aggregation.enabled"
 	AggregationBucketNumKey              = "aggregation.bucket.num"
 	AggregationTimeWindowSecondsKey      = "aggregation.time.window.seconds"
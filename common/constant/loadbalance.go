@@ -26,4 +26,6 @@ const (
 	LoadXDSRingHash                             = "xdsringhash"
 	LoadBalanceKeyInterleavedWeightedRoundRobin = "interleavedweightedroundrobin"
 	LoadBalanceKeyAliasMethod                   = "aliasmethod"
+	LoadBalanceKeyPeakEWMA                      = "peakewma"
+	LoadBalanceKeyLocality                      = "locality"
 )
@@ -24,6 +24,7 @@ const (
 	MetricsApp          = "dubbo.metrics.app"
 	MetricsConfigCenter = "dubbo.metrics.configCenter"
 	MetricsRpc          = "dubbo.metrics.rpc"
+	MetricsCluster      = "dubbo.metrics.cluster"
 )
 
 const (
@@ -41,6 +42,8 @@ const (
 	TagGroup              = "group"
 	TagVersion            = "version"
 	TagErrorCode          = "error"
+	TagAddress            = "address"
+	TagRegistry           = "registry"
 )
 const (
 	MetricNamespace                     = "dubbo"
@@ -45,3 +45,8 @@ func GetCluster(name string) (cluster.Cluster, error) {
 	}
 	return clusters[name](), nil
 }
+
+// HasCluster checks whether the cluster fault-tolerant mode with @name exists, without creating it
+func HasCluster(name string) bool {
+	return clusters[name] != nil
+}
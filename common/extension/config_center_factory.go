@@ -39,3 +39,8 @@ func GetConfigCenterFactory(name string) (config_center.DynamicConfigurationFact
 	}
 	return configCenterFactories[name](), nil
 }
+
+// HasConfigCenterFactory checks whether the config center factory with @name exists, without creating it
+func HasConfigCenterFactory(name string) bool {
+	return configCenterFactories[name] != nil
+}
@@ -44,6 +44,11 @@ func GetFilter(name string) (filter.Filter, bool) {
 	return filters[name](), true
 }
 
+// HasFilter checks whether the filter extension with @name exists, without creating it
+func HasFilter(name string) bool {
+	return filters[name] != nil
+}
+
 // SetRejectedExecutionHandler sets the RejectedExecutionHandler with @name
 func SetRejectedExecutionHandler(name string, creator func() filter.RejectedExecutionHandler) {
 	rejectedExecutionHandler[name] = creator
@@ -37,3 +37,8 @@ func GetLoadbalance(name string) loadbalance.LoadBalance {
 
 	return loadbalances[name]()
 }
+
+// HasLoadbalance checks whether the loadbalance extension with @name exists, without creating it
+func HasLoadbalance(name string) bool {
+	return loadbalances[name] != nil
+}
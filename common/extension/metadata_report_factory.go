@@ -35,3 +35,8 @@ func GetMetadataReportFactory(name string) report.MetadataReportFactory {
 	}
 	return metaDataReportFactories[name]()
 }
+
+// HasMetadataReportFactory checks whether the metadata report factory with @name exists, without creating it
+func HasMetadataReportFactory(name string) bool {
+	return metaDataReportFactories[name] != nil
+}
@@ -35,3 +35,8 @@ func GetProtocol(name string) base.Protocol {
 	}
 	return protocols[name]()
 }
+
+// HasProtocol checks whether the protocol extension with @name exists, without creating it
+func HasProtocol(name string) bool {
+	return protocols[name] != nil
+}
@@ -43,3 +43,8 @@ func GetProxyFactory(name string) proxy.ProxyFactory {
 	}
 	return proxyFactories[name]()
 }
+
+// HasProxyFactory checks whether the proxy factory with @name exists, without creating it
+func HasProxyFactory(name string) bool {
+	return proxyFactories[name] != nil
+}
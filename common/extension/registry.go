@@ -36,3 +36,8 @@ func GetRegistry(name string, config *common.URL) (registry.Registry, error) {
 	}
 	return registries[name](config)
 }
+
+// HasRegistry checks whether the registry extension with @name exists, without creating it
+func HasRegistry(name string) bool {
+	return registries[name] != nil
+}
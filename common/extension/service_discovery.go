@@ -48,3 +48,8 @@ func GetServiceDiscovery(url *common.URL) (registry.ServiceDiscovery, error) {
 	}
 	return creator(url)
 }
+
+// HasServiceDiscovery checks whether the service discovery of the protocol with @name exists, without creating it
+func HasServiceDiscovery(name string) bool {
+	return discoveryCreatorMap[name] != nil
+}
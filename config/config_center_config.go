@@ -19,6 +19,7 @@ package config
 
 import (
 	"net/url"
+	"strconv"
 	"strings"
 )
 
@@ -186,10 +187,28 @@ func (c *CenterConfig) GetDynamicConfiguration() (config_center.DynamicConfigura
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
+	dynamicConfig = c.withHistory(dynamicConfig)
 	envInstance.SetDynamicConfiguration(dynamicConfig)
 	return dynamicConfig, nil
 }
 
+// withHistory keeps the history of the dynamic configs applied unless the history size is not positive
+func (c *CenterConfig) withHistory(dynamicConfig config_center.DynamicConfiguration) config_center.DynamicConfiguration {
+	size := constant.DefaultConfigHistorySize
+	if v, ok := c.Params[constant.ConfigHistorySizeKey]; ok {
+		var err error
+		if size, err = strconv.Atoi(v); err != nil {
+			logger.Warnf("[Config Center] The %s %s is not an integer, use %d instead",
+				constant.ConfigHistorySizeKey, v, constant.DefaultConfigHistorySize)
+			size = constant.DefaultConfigHistorySize
+		}
+	}
+	if size <= 0 {
+		return dynamicConfig
+	}
+	return config_center.NewHistoryDynamicConfiguration(dynamicConfig, c.Protocol, size)
+}
+
 func NewConfigCenterConfigBuilder() *ConfigCenterConfigBuilder {
 	return &ConfigCenterConfigBuilder{configCenterConfig: newEmptyConfigCenterConfig()}
 }
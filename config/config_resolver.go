@@ -35,6 +35,7 @@ import (
 
 import (
 	"dubbo.apache.org/dubbo-go/v3/common/constant/file"
+	"dubbo.apache.org/dubbo-go/v3/config/secret"
 )
 
 // GetConfigResolver get config resolver
@@ -67,6 +68,9 @@ func GetConfigResolver(conf *loaderConf) *koanf.Koanf {
 	if err != nil {
 		panic(err)
 	}
+	if k, err = secret.Resolve(k); err != nil {
+		panic(err)
+	}
 	return resolvePlaceholder(k)
 }
 
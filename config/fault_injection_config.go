@@ -0,0 +1,54 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+)
+
+// FaultInjectionConfig is the fault injection rule of a service which is set by the config center, the faults
+// are injected by the fault_injection cluster interceptor and the fault_provider filter for chaos testing.
+type FaultInjectionConfig struct {
+	Scope   string       `validate:"required" yaml:"scope" json:"scope,omitempty" property:"scope"` // only `service` is supported.
+	Key     string       `validate:"required" yaml:"key" json:"key,omitempty" property:"key"`       // specifies which service the rule body acts on.
+	Enabled bool         `default:"true" yaml:"enabled" json:"enabled,omitempty" property:"enabled"`
+	Side    string       `yaml:"side" json:"side,omitempty" property:"side"` // `consumer`, `provider` or empty for both sides.
+	Faults  []*FaultRule `yaml:"faults" json:"faults,omitempty" property:"faults"`
+}
+
+// FaultRule injects the delay or abort fault to the percentage of the requests matching the methods and attachments.
+type FaultRule struct {
+	Methods     []string             `yaml:"methods" json:"methods,omitempty" property:"methods"` // empty or `*` for all the methods.
+	Attachments []*common.ParamMatch `yaml:"attachments" json:"attachments,omitempty" property:"attachments"`
+	Percentage  float64              `default:"100" yaml:"percentage" json:"percentage,omitempty" property:"percentage"`
+	Delay       *FaultDelay          `yaml:"delay" json:"delay,omitempty" property:"delay"`
+	Abort       *FaultAbort          `yaml:"abort" json:"abort,omitempty" property:"abort"`
+}
+
+// FaultDelay delays the requests by the fixed duration, or by a random duration between fixed and max if max is set.
+type FaultDelay struct {
+	Fixed string `yaml:"fixed" json:"fixed,omitempty" property:"fixed"`
+	Max   string `yaml:"max" json:"max,omitempty" property:"max"`
+}
+
+// FaultAbort aborts the requests with the triple error code, e.g. `unavailable`, or the dubbo response status,
+// e.g. 31 for SERVER_TIMEOUT.
+type FaultAbort struct {
+	Code   string `yaml:"code" json:"code,omitempty" property:"code"`
+	Status int    `yaml:"status" json:"status,omitempty" property:"status"`
+}
@@ -19,6 +19,7 @@ package config
 
 import (
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -106,6 +107,30 @@ func WithRequestTimeout(timeout time.Duration) MethodOption {
 	}
 }
 
+// WithRetryBudget limits the retries to the ratio of recent successful calls.
+func WithRetryBudget(ratio float64) MethodOption {
+	return func(opts *MethodOptions) {
+		opts.Method.RetryBudget = strconv.FormatFloat(ratio, 'f', -1, 64)
+	}
+}
+
+// WithRetryBackoff sets the exponential backoff between retries.
+func WithRetryBackoff(backoff, maxBackoff time.Duration) MethodOption {
+	return func(opts *MethodOptions) {
+		opts.Method.RetryBackoff = backoff.String()
+		opts.Method.RetryMaxBackoff = maxBackoff.String()
+	}
+}
+
+// WithRetryableCodes sets the triple codes which could be retried, such as unavailable. The errors of the other
+// protocols are mapped to the codes, the timeouts are deadline_exceeded, the connection errors are unavailable and
+// the rest of them are unknown.
+func WithRetryableCodes(codes ...string) MethodOption {
+	return func(opts *MethodOptions) {
+		opts.Method.RetryableCodes = strings.Join(codes, ",")
+	}
+}
+
 type MethodOptions struct {
 	Method *global.MethodConfig
 }
@@ -36,6 +36,8 @@ import (
 
 import (
 	"dubbo.apache.org/dubbo-go/v3/cluster/directory/static"
+	"dubbo.apache.org/dubbo-go/v3/cluster/healthcheck"
+	"dubbo.apache.org/dubbo-go/v3/cluster/outlier"
 	"dubbo.apache.org/dubbo-go/v3/common"
 	"dubbo.apache.org/dubbo-go/v3/common/constant"
 	"dubbo.apache.org/dubbo-go/v3/common/extension"
@@ -272,7 +274,8 @@ func (rc *ReferenceConfig) Refer(srv any) {
 			if err != nil {
 				panic(err)
 			} else {
-				rc.invoker = cluster.Join(static.NewDirectory(invokers))
+				dir := healthcheck.NewDirectory(static.NewDirectory(invokers), rc.invoker.GetURL())
+				rc.invoker = cluster.Join(outlier.NewDirectory(dir, rc.invoker.GetURL()))
 			}
 		}
 	} else {
@@ -290,8 +293,14 @@ func (rc *ReferenceConfig) Refer(srv any) {
 		cluster, err := extension.GetCluster(hitClu)
 		if err != nil {
 			panic(err)
+		}
+		dir := static.NewDirectory(invokers)
+		if regURL == nil {
+			// the outlier detection and health checking of registry invokers are done by their own directories
+			ivkURL := invokers[0].GetURL()
+			rc.invoker = cluster.Join(outlier.NewDirectory(healthcheck.NewDirectory(dir, ivkURL), ivkURL))
 		} else {
-			rc.invoker = cluster.Join(static.NewDirectory(invokers))
+			rc.invoker = cluster.Join(dir)
 		}
 	}
 
@@ -95,6 +95,126 @@ type AffinityRouter struct {
 	AffinityAware AffinityAware `yaml:"affinityAware" json:"affinityAware,omitempty" property:"affinityAware"`
 }
 
+// MirrorRouter -- RouteConfigVersion == v3.1
+type MirrorRouter struct {
+	Scope   string     `validate:"required" yaml:"scope" json:"scope,omitempty" property:"scope"` // must be chosen from `service` and `application`.
+	Key     string     `validate:"required" yaml:"key" json:"key,omitempty" property:"key"`       // specifies which service or application the rule body acts on.
+	Runtime bool       `default:"false" yaml:"runtime" json:"runtime,omitempty" property:"runtime"`
+	Enabled bool       `default:"true" yaml:"enabled" json:"enabled,omitempty" property:"enabled"`
+	Mirror  MirrorRule `yaml:"mirror" json:"mirror,omitempty" property:"mirror"`
+}
+
+// MirrorRule selects the shadow providers by tag or condition, and copies the percentage of requests to them.
+type MirrorRule struct {
+	Percentage float64 `default:"0" yaml:"percentage" json:"percentage,omitempty" property:"percentage"`
+	Tag        string  `yaml:"tag" json:"tag,omitempty" property:"tag"`
+	Condition  string  `yaml:"condition" json:"condition,omitempty" property:"condition"`
+	Timeout    string  `default:"1s" yaml:"timeout" json:"timeout,omitempty" property:"timeout"`
+	// MaxConcurrency limits the in-flight mirrored requests, the requests are not mirrored once it is reached
+	MaxConcurrency int `default:"100" yaml:"max-concurrency" json:"max-concurrency,omitempty" property:"max-concurrency"`
+}
+
+// SplitRouter -- RouteConfigVersion == v3.1
+type SplitRouter struct {
+	Scope     string         `validate:"required" yaml:"scope" json:"scope,omitempty" property:"scope"` // must be chosen from `service` and `application`.
+	Key       string         `validate:"required" yaml:"key" json:"key,omitempty" property:"key"`       // specifies which service or application the rule body acts on.
+	Force     bool           `default:"false" yaml:"force" json:"force,omitempty" property:"force"`
+	Runtime   bool           `default:"false" yaml:"runtime" json:"runtime,omitempty" property:"runtime"`
+	Enabled   bool           `default:"true" yaml:"enabled" json:"enabled,omitempty" property:"enabled"`
+	StickyKey string         `yaml:"stickyKey" json:"stickyKey,omitempty" property:"stickyKey"` // the attachment hashed to assign the requests to subsets stickily
+	Subsets   []*SplitSubset `yaml:"subsets" json:"subsets,omitempty" property:"subsets"`
+}
+
+// SplitSubset is a destination subset of the providers chosen by labels, version and tag.
+type SplitSubset struct {
+	Name    string            `yaml:"name" json:"name,omitempty" property:"name"`
+	Labels  map[string]string `yaml:"labels" json:"labels,omitempty" property:"labels"`
+	Version string            `yaml:"version" json:"version,omitempty" property:"version"`
+	Tag     string            `yaml:"tag" json:"tag,omitempty" property:"tag"`
+	Weight  int               `yaml:"weight" json:"weight,omitempty" property:"weight"`
+}
+
+// MeshMetadata is the metadata of the mesh rules.
+type MeshMetadata struct {
+	Name string `yaml:"name" json:"name,omitempty" property:"name"`
+}
+
+// VirtualServiceConfig is the VirtualService document of the mesh rule "{application}.MESHAPPRULE", it routes
+// the matched requests to the destination subsets.
+type VirtualServiceConfig struct {
+	APIVersion string             `yaml:"apiVersion" json:"apiVersion,omitempty" property:"apiVersion"`
+	Kind       string             `yaml:"kind" json:"kind,omitempty" property:"kind"`
+	Metadata   MeshMetadata       `yaml:"metadata" json:"metadata,omitempty" property:"metadata"`
+	Spec       VirtualServiceSpec `yaml:"spec" json:"spec,omitempty" property:"spec"`
+}
+
+type VirtualServiceSpec struct {
+	Hosts []string      `yaml:"hosts" json:"hosts,omitempty" property:"hosts"`
+	Dubbo []*DubboRoute `yaml:"dubbo" json:"dubbo,omitempty" property:"dubbo"`
+}
+
+// DubboRoute is the routes of the services, the route details are matched in order.
+type DubboRoute struct {
+	Name        string                `yaml:"name" json:"name,omitempty" property:"name"`
+	Services    []*common.StringMatch `yaml:"services" json:"services,omitempty" property:"services"`
+	Service     []*common.StringMatch `yaml:"service" json:"service,omitempty" property:"service"` // Deprecated: use Services instead
+	RouteDetail []*DubboRouteDetail   `yaml:"routedetail" json:"routedetail,omitempty" property:"routedetail"`
+}
+
+type DubboRouteDetail struct {
+	Name  string                   `yaml:"name" json:"name,omitempty" property:"name"`
+	Match []*DubboMatchRequest     `yaml:"match" json:"match,omitempty" property:"match"`
+	Route []*DubboRouteDestination `yaml:"route" json:"route,omitempty" property:"route"`
+}
+
+// DubboMatchRequest matches the requests by method, source labels of the consumer and attachments.
+type DubboMatchRequest struct {
+	Name         string                `yaml:"name" json:"name,omitempty" property:"name"`
+	Method       *DubboMethodMatch     `yaml:"method" json:"method,omitempty" property:"method"`
+	SourceLabels map[string]string     `yaml:"sourceLabels" json:"sourceLabels,omitempty" property:"sourceLabels"`
+	Attachments  *DubboAttachmentMatch `yaml:"attachments" json:"attachments,omitempty" property:"attachments"`
+}
+
+type DubboMethodMatch struct {
+	NameMatch *common.StringMatch `yaml:"name_match" json:"name_match,omitempty" property:"name_match"`
+}
+
+type DubboAttachmentMatch struct {
+	DubboContext map[string]*common.StringMatch `yaml:"dubbocontext" json:"dubbocontext,omitempty" property:"dubbocontext"`
+}
+
+// DubboRouteDestination is a weighted destination, the fallback is used if there is no provider in the destination.
+type DubboRouteDestination struct {
+	Destination DubboDestination       `yaml:"destination" json:"destination,omitempty" property:"destination"`
+	Fallback    *DubboRouteDestination `yaml:"fallback" json:"fallback,omitempty" property:"fallback"`
+	Weight      int                    `yaml:"weight" json:"weight,omitempty" property:"weight"`
+}
+
+type DubboDestination struct {
+	Host     string                 `yaml:"host" json:"host,omitempty" property:"host"` // the application of the providers
+	Subset   string                 `yaml:"subset" json:"subset,omitempty" property:"subset"`
+	Fallback *DubboRouteDestination `yaml:"fallback" json:"fallback,omitempty" property:"fallback"`
+}
+
+// DestinationRuleConfig is the DestinationRule document of the mesh rule "{application}.MESHAPPRULE", it defines
+// the subsets of the providers by labels.
+type DestinationRuleConfig struct {
+	APIVersion string              `yaml:"apiVersion" json:"apiVersion,omitempty" property:"apiVersion"`
+	Kind       string              `yaml:"kind" json:"kind,omitempty" property:"kind"`
+	Metadata   MeshMetadata        `yaml:"metadata" json:"metadata,omitempty" property:"metadata"`
+	Spec       DestinationRuleSpec `yaml:"spec" json:"spec,omitempty" property:"spec"`
+}
+
+type DestinationRuleSpec struct {
+	Host    string        `yaml:"host" json:"host,omitempty" property:"host"`
+	Subsets []*MeshSubset `yaml:"subsets" json:"subsets,omitempty" property:"subsets"`
+}
+
+type MeshSubset struct {
+	Name   string            `yaml:"name" json:"name,omitempty" property:"name"`
+	Labels map[string]string `yaml:"labels" json:"labels,omitempty" property:"labels"`
+}
+
 // Prefix dubbo.router
 func (RouterConfig) Prefix() string {
 	return constant.RouterConfigPrefix
@@ -0,0 +1,126 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package secret
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"os"
+	"strings"
+	"sync"
+)
+
+import (
+	perrors "github.com/pkg/errors"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/common/extension"
+	"dubbo.apache.org/dubbo-go/v3/config/interfaces"
+)
+
+var defaultAESResolver = &aesResolver{}
+
+func init() {
+	extension.SetSecretResolver(constant.SecretAESKey, func() interfaces.SecretResolver {
+		return defaultAESResolver
+	})
+}
+
+// aesResolver decrypts the ciphertext by the AES key in the file of the DUBBO_GO_SECRET_KEY_FILE environment
+// variable. The ciphertext is the base64 encoded nonce and sealed secret of AES-GCM, see Encrypt. The key is
+// read once and cached until the environment variable points to another file.
+type aesResolver struct {
+	mu   sync.Mutex
+	path string
+	key  []byte
+}
+
+func (r *aesResolver) Resolve(ref string) (string, error) {
+	key, err := r.getKey()
+	if err != nil {
+		return "", err
+	}
+	return Decrypt(key, ref)
+}
+
+func (r *aesResolver) getKey() ([]byte, error) {
+	path := os.Getenv(constant.SecretKeyFileEnvKey)
+	if path == "" {
+		return nil, perrors.Errorf("the environment variable %s of the AES key file is not set", constant.SecretKeyFileEnvKey)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.key != nil && r.path == path {
+		return r.key, nil
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, perrors.WithMessage(err, "read the AES key file")
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(content)))
+	if err != nil {
+		return nil, perrors.New("the AES key file is not base64 encoded")
+	}
+	r.path, r.key = path, key
+	return key, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Encrypt encrypts the secret by the AES key, the result can be used as ENC(result) in the config
+func Encrypt(key []byte, secret string) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, []byte(secret), nil)), nil
+}
+
+// Decrypt decrypts the ciphertext returned by Encrypt
+func Decrypt(key []byte, ciphertext string) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	data, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return "", perrors.New("the ciphertext is not base64 encoded")
+	}
+	if len(data) < gcm.NonceSize() {
+		return "", perrors.New("the ciphertext is too short")
+	}
+	secret, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", perrors.New("failed to decrypt the ciphertext, the AES key may be wrong")
+	}
+	return string(secret), nil
+}
@@ -0,0 +1,23 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package secret resolves the secrets referenced by the config values when the config is loaded. A value is
+// a secret reference if it is ENC(ciphertext) or ${secret:name:ref}, where name is a registered secret resolver,
+// e.g. ${secret:env:DB_PASSWORD} or ${secret:vault:secret/data/db#password}, and the config fails to be loaded if
+// a secret cannot be resolved. The resolvers of the environment variables, the local
+// AES key and the HTTP API of vault are built in.
+package secret
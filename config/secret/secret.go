@@ -0,0 +1,123 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package secret
+
+import (
+	"strconv"
+	"strings"
+)
+
+import (
+	"github.com/knadh/koanf"
+	"github.com/knadh/koanf/providers/confmap"
+
+	perrors "github.com/pkg/errors"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/common/extension"
+)
+
+// Resolve replaces the secret references in the string values of the config with the secrets, it returns an
+// error if any secret cannot be resolved. The secrets are never logged or contained in the error.
+func Resolve(k *koanf.Koanf) (*koanf.Koanf, error) {
+	// the raw config keeps the keys containing the delimiter, e.g. the interface names as the service keys
+	raw := k.Raw()
+	resolved, err := resolveValue(raw, "", k.Delim())
+	if err != nil || !resolved {
+		return k, err
+	}
+	res := koanf.New(k.Delim())
+	if err = res.Load(confmap.Provider(raw, ""), nil); err != nil {
+		return k, perrors.WithMessage(err, "load the resolved secrets")
+	}
+	return res, nil
+}
+
+// resolveValue resolves the secrets in the map or the slice in place, it returns whether any secret is resolved
+func resolveValue(value any, path, delim string) (bool, error) {
+	resolved := false
+	resolveItem := func(item any, itemPath string, set func(string)) error {
+		if s, ok := item.(string); ok {
+			secret, ok, err := resolveString(s, itemPath)
+			if ok {
+				set(secret)
+				resolved = true
+			}
+			return err
+		}
+		ok, err := resolveValue(item, itemPath, delim)
+		resolved = resolved || ok
+		return err
+	}
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			itemPath := key
+			if path != "" {
+				itemPath = path + delim + key
+			}
+			if err := resolveItem(item, itemPath, func(s string) { v[key] = s }); err != nil {
+				return false, err
+			}
+		}
+	case []any:
+		for i, item := range v {
+			itemPath := path + "[" + strconv.Itoa(i) + "]"
+			if err := resolveItem(item, itemPath, func(s string) { v[i] = s }); err != nil {
+				return false, err
+			}
+		}
+	}
+	return resolved, nil
+}
+
+// resolveString returns the secret and true if the value is a secret reference, and an error if the secret
+// cannot be resolved
+func resolveString(value, path string) (string, bool, error) {
+	name, ref, ok := parseReference(value)
+	if !ok {
+		return "", false, nil
+	}
+	resolver, err := extension.GetSecretResolver(name)
+	if err != nil {
+		return "", false, perrors.Errorf("failed to resolve the secret of the config %s: %v", path, err)
+	}
+	secret, err := resolver.Resolve(ref)
+	if err != nil {
+		return "", false, perrors.Errorf("failed to resolve the secret of the config %s by the %s resolver: %v",
+			path, name, err)
+	}
+	return secret, true, nil
+}
+
+// parseReference returns the name of the resolver and the reference if the value is a secret reference
+func parseReference(s string) (name, ref string, ok bool) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, constant.SecretEncPrefix) && strings.HasSuffix(s, constant.SecretEncSuffix) {
+		return constant.SecretAESKey, s[len(constant.SecretEncPrefix) : len(s)-len(constant.SecretEncSuffix)], true
+	}
+	if !strings.HasPrefix(s, constant.SecretPrefix) || !strings.HasSuffix(s, constant.SecretSuffix) {
+		return "", "", false
+	}
+	s = s[len(constant.SecretPrefix) : len(s)-len(constant.SecretSuffix)]
+	// the name is empty if the reference is malformed, so that it fails to be resolved
+	name, ref, _ = strings.Cut(s, ":")
+	return strings.TrimSpace(name), strings.TrimSpace(ref), true
+}
@@ -0,0 +1,212 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package secret
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+import (
+	"github.com/knadh/koanf"
+	"github.com/knadh/koanf/parsers/yaml"
+	"github.com/knadh/koanf/providers/rawbytes"
+
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+)
+
+func writeKeyFile(t *testing.T) []byte {
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	assert.Nil(t, err)
+	path := filepath.Join(t.TempDir(), "secret.key")
+	assert.Nil(t, os.WriteFile(path, []byte(base64.StdEncoding.EncodeToString(key)+"\n"), 0600))
+	t.Setenv(constant.SecretKeyFileEnvKey, path)
+	return key
+}
+
+func TestParseReference(t *testing.T) {
+	name, ref, ok := parseReference(" ENC(abc) ")
+	assert.True(t, ok)
+	assert.Equal(t, constant.SecretAESKey, name)
+	assert.Equal(t, "abc", ref)
+
+	name, ref, ok = parseReference("${secret:vault:secret/data/db#password}")
+	assert.True(t, ok)
+	assert.Equal(t, constant.SecretVaultKey, name)
+	assert.Equal(t, "secret/data/db#password", ref)
+
+	_, _, ok = parseReference("${zookeeper.address}")
+	assert.False(t, ok)
+	// the placeholders whose keys are the names of the resolvers are not secret references
+	_, _, ok = parseReference("${env:dev}")
+	assert.False(t, ok)
+	_, _, ok = parseReference("${vault}")
+	assert.False(t, ok)
+	_, _, ok = parseReference("plain")
+	assert.False(t, ok)
+}
+
+func TestEnvResolver(t *testing.T) {
+	t.Setenv("DUBBO_GO_TEST_SECRET", "s3cret")
+	r := &envResolver{}
+	value, err := r.Resolve("DUBBO_GO_TEST_SECRET")
+	assert.Nil(t, err)
+	assert.Equal(t, "s3cret", value)
+
+	_, err = r.Resolve("DUBBO_GO_TEST_SECRET_UNSET")
+	assert.NotNil(t, err)
+}
+
+func TestAESResolver(t *testing.T) {
+	key := writeKeyFile(t)
+	ciphertext, err := Encrypt(key, "s3cret")
+	assert.Nil(t, err)
+
+	r := &aesResolver{}
+	value, err := r.Resolve(ciphertext)
+	assert.Nil(t, err)
+	assert.Equal(t, "s3cret", value)
+
+	_, err = r.Resolve(base64.StdEncoding.EncodeToString([]byte("malformed ciphertext")))
+	assert.NotNil(t, err)
+
+	// the key is cached until the key file is changed
+	path := os.Getenv(constant.SecretKeyFileEnvKey)
+	assert.Nil(t, os.Remove(path))
+	value, err = r.Resolve(ciphertext)
+	assert.Nil(t, err)
+	assert.Equal(t, "s3cret", value)
+
+	writeKeyFile(t)
+	_, err = r.Resolve(ciphertext)
+	assert.NotNil(t, err)
+	assert.NotContains(t, err.Error(), "s3cret")
+}
+
+func newVaultServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/db":
+			_, _ = w.Write([]byte(`{"data":{"data":{"password":"s3cret"},"metadata":{"version":1}}}`))
+		case "/v1/kv/db":
+			_, _ = w.Write([]byte(`{"data":{"value":"v1-s3cret"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv(constant.VaultAddrEnvKey, server.URL)
+	t.Setenv(constant.VaultTokenEnvKey, "token")
+	return server
+}
+
+func TestVaultResolver(t *testing.T) {
+	newVaultServer(t)
+	r := &vaultResolver{client: http.DefaultClient}
+
+	value, err := r.Resolve("secret/data/db#password")
+	assert.Nil(t, err)
+	assert.Equal(t, "s3cret", value)
+
+	value, err = r.Resolve("/kv/db")
+	assert.Nil(t, err)
+	assert.Equal(t, "v1-s3cret", value)
+
+	_, err = r.Resolve("secret/data/db#username")
+	assert.NotNil(t, err)
+	_, err = r.Resolve("secret/data/missing#password")
+	assert.NotNil(t, err)
+
+	t.Setenv(constant.VaultTokenEnvKey, "wrong")
+	_, err = r.Resolve("secret/data/db#password")
+	assert.NotNil(t, err)
+}
+
+func TestResolve(t *testing.T) {
+	key := writeKeyFile(t)
+	ciphertext, err := Encrypt(key, "aes-s3cret")
+	assert.Nil(t, err)
+	newVaultServer(t)
+	t.Setenv("DUBBO_GO_TEST_SECRET", "env-s3cret")
+
+	conf := `
+dubbo:
+  registries:
+    demo:
+      address: ${zookeeper.address}
+      username: ${secret:env:DUBBO_GO_TEST_SECRET}
+      password: ENC(` + ciphertext + `)
+  config-center:
+    password: ${secret:vault:secret/data/db#password}
+    username: ${env:dev}
+    timeout: 3s
+  provider:
+    services:
+      org.apache.dubbo.Greeter:
+        token: ${secret:env:DUBBO_GO_TEST_SECRET}
+        registry-ids:
+          - ${secret:env:DUBBO_GO_TEST_SECRET}
+`
+	k := koanf.New(".")
+	assert.Nil(t, k.Load(rawbytes.Provider([]byte(conf)), yaml.Parser()))
+
+	k, err = Resolve(k)
+	assert.Nil(t, err)
+	assert.Equal(t, "${zookeeper.address}", k.String("dubbo.registries.demo.address"))
+	assert.Equal(t, "env-s3cret", k.String("dubbo.registries.demo.username"))
+	assert.Equal(t, "aes-s3cret", k.String("dubbo.registries.demo.password"))
+	assert.Equal(t, "s3cret", k.String("dubbo.config-center.password"))
+	// the placeholder whose key is env is kept
+	assert.Equal(t, "${env:dev}", k.String("dubbo.config-center.username"))
+	assert.Equal(t, "3s", k.String("dubbo.config-center.timeout"))
+
+	services := k.Raw()["dubbo"].(map[string]any)["provider"].(map[string]any)["services"].(map[string]any)
+	service := services["org.apache.dubbo.Greeter"].(map[string]any)
+	assert.Equal(t, "env-s3cret", service["token"])
+	assert.Equal(t, []any{"env-s3cret"}, service["registry-ids"])
+}
+
+func TestResolveFailed(t *testing.T) {
+	for _, value := range []string{
+		"${secret:env:DUBBO_GO_TEST_SECRET_UNSET}",
+		"${secret:unknown:ref}",
+		"${secret:DUBBO_GO_TEST_SECRET}",
+	} {
+		k := koanf.New(".")
+		assert.Nil(t, k.Load(rawbytes.Provider([]byte("dubbo:\n  config-center:\n    password: "+value)), yaml.Parser()))
+		_, err := Resolve(k)
+		assert.NotNil(t, err, value)
+		if err != nil {
+			assert.Contains(t, err.Error(), "dubbo.config-center.password")
+		}
+	}
+}
@@ -0,0 +1,109 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package secret
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+import (
+	perrors "github.com/pkg/errors"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/common/extension"
+	"dubbo.apache.org/dubbo-go/v3/config/interfaces"
+)
+
+const (
+	vaultTimeout      = 5 * time.Second
+	vaultDefaultField = "value"
+)
+
+func init() {
+	extension.SetSecretResolver(constant.SecretVaultKey, func() interfaces.SecretResolver {
+		return &vaultResolver{client: &http.Client{Timeout: vaultTimeout}}
+	})
+}
+
+// vaultResolver resolves ${secret:vault:path#field} to the field of the secret read from the HTTP API of vault, the
+// field is "value" if it is absent. Both the kv v1 and v2 secret engines are supported, the path of a kv v2
+// secret contains "data", e.g. secret/data/db. The address, the token and the namespace of vault are got from
+// the VAULT_ADDR, VAULT_TOKEN and VAULT_NAMESPACE environment variables.
+type vaultResolver struct {
+	client *http.Client
+}
+
+func (r *vaultResolver) Resolve(ref string) (string, error) {
+	addr := os.Getenv(constant.VaultAddrEnvKey)
+	if addr == "" {
+		return "", perrors.Errorf("the environment variable %s of the vault address is not set", constant.VaultAddrEnvKey)
+	}
+	path, field, ok := strings.Cut(ref, "#")
+	if !ok || field == "" {
+		field = vaultDefaultField
+	}
+	path = strings.Trim(path, "/")
+
+	req, err := http.NewRequest(http.MethodGet, strings.TrimRight(addr, "/")+"/v1/"+path, nil)
+	if err != nil {
+		return "", err
+	}
+	if token := os.Getenv(constant.VaultTokenEnvKey); token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	if namespace := os.Getenv(constant.VaultNamespaceEnvKey); namespace != "" {
+		req.Header.Set("X-Vault-Namespace", namespace)
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return "", perrors.WithMessagef(err, "read the secret %s from vault", path)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", perrors.Errorf("read the secret %s from vault, the status is %d", path, resp.StatusCode)
+	}
+
+	var secret struct {
+		Data map[string]any `json:"data"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&secret); err != nil {
+		return "", perrors.Errorf("the secret %s from vault is malformed", path)
+	}
+	data := secret.Data
+	// the data of kv v2 secrets is wrapped with the metadata
+	if inner, ok := data["data"].(map[string]any); ok {
+		if _, ok = data["metadata"]; ok {
+			data = inner
+		}
+	}
+	value, ok := data[field]
+	if !ok || value == nil {
+		return "", perrors.Errorf("the field %s is not found in the secret %s", field, path)
+	}
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	return fmt.Sprint(value), nil
+}
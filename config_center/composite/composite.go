@@ -0,0 +1,228 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package composite
+
+import (
+	"sync"
+	"time"
+)
+
+import (
+	gxset "github.com/dubbogo/gost/container/set"
+
+	perrors "github.com/pkg/errors"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/config_center"
+	"dubbo.apache.org/dubbo-go/v3/config_center/parser"
+)
+
+// Source is a config center layered by the composite config center
+type Source struct {
+	// Name identifies the config center in the logs and the resolutions
+	Name          string
+	Configuration config_center.DynamicConfiguration
+}
+
+// Layer is the value of a key in a source
+type Layer struct {
+	Source string
+	Value  string
+	// Err is the error of reading the key from the source, the key is regarded as absent in the source if it is not nil
+	Err error
+}
+
+// Resolution tells where the effective value of a key comes from
+type Resolution struct {
+	Key   string
+	Group string
+	// Source is the name of the source providing the effective value, it is empty if no source has the key
+	Source string
+	Value  string
+	// Layers are the values of the key in all the sources, in descending order of precedence
+	Layers []Layer
+}
+
+// DynamicConfiguration layers the sources in descending order of precedence. A key is read from all the sources
+// at the same time, and the value of the first source which has the key is taken. A source failing to respond
+// in time is regarded as not having the key. The configs are published to and removed from the first source.
+type DynamicConfiguration struct {
+	config_center.BaseDynamicConfiguration
+	url     *common.URL
+	sources []Source
+	timeout time.Duration
+	parser  parser.ConfigurationParser
+
+	lock     sync.Mutex
+	watchers map[watchKey]*keyWatcher
+}
+
+// NewDynamicConfiguration returns a config center layering the sources, the timeout of reading from each source
+// is got from the composite.timeout param of the url
+func NewDynamicConfiguration(url *common.URL, sources ...Source) *DynamicConfiguration {
+	return &DynamicConfiguration{
+		url:      url,
+		sources:  sources,
+		timeout:  url.GetParamDuration(constant.CompositeTimeoutKey, constant.DefaultCompositeTimeout),
+		watchers: make(map[watchKey]*keyWatcher),
+	}
+}
+
+// Parser Get Parser
+func (c *DynamicConfiguration) Parser() parser.ConfigurationParser {
+	return c.parser
+}
+
+// SetParser Set Parser
+func (c *DynamicConfiguration) SetParser(p parser.ConfigurationParser) {
+	c.parser = p
+}
+
+// GetProperties get properties file
+func (c *DynamicConfiguration) GetProperties(key string, opts ...config_center.Option) (string, error) {
+	return c.get(key, opts, config_center.DynamicConfiguration.GetProperties)
+}
+
+// GetRule get Router rule properties file
+func (c *DynamicConfiguration) GetRule(key string, opts ...config_center.Option) (string, error) {
+	return c.get(key, opts, config_center.DynamicConfiguration.GetRule)
+}
+
+// GetInternalProperty get value by key in Default properties file(dubbo.properties)
+func (c *DynamicConfiguration) GetInternalProperty(key string, opts ...config_center.Option) (string, error) {
+	return c.get(key, opts, config_center.DynamicConfiguration.GetInternalProperty)
+}
+
+// Resolve reads the key from all the sources, and tells which source the effective value comes from
+func (c *DynamicConfiguration) Resolve(key string, opts ...config_center.Option) *Resolution {
+	return c.resolve(key, opts, config_center.DynamicConfiguration.GetProperties)
+}
+
+// PublishConfig will publish the config with the (key, group, value) pair to the first source
+func (c *DynamicConfiguration) PublishConfig(key string, group string, value string) error {
+	return c.sources[0].Configuration.PublishConfig(key, group, value)
+}
+
+// RemoveConfig will remove the config with the (key, group) pair from the first source
+func (c *DynamicConfiguration) RemoveConfig(key string, group string) error {
+	return c.sources[0].Configuration.RemoveConfig(key, group)
+}
+
+// GetConfigKeysByGroup returns the keys of the group in all the sources, an error is returned only if all the
+// sources fail
+func (c *DynamicConfiguration) GetConfigKeysByGroup(group string) (*gxset.HashSet, error) {
+	results := c.query(func(dc config_center.DynamicConfiguration) (any, error) {
+		return dc.GetConfigKeysByGroup(group)
+	})
+	res := gxset.NewSet()
+	var lastErr error
+	failures := 0
+	for i, r := range results {
+		if r.err != nil {
+			failures++
+			lastErr = perrors.WithMessagef(r.err, "config center %s", c.sources[i].Name)
+			continue
+		}
+		if keys, ok := r.value.(*gxset.HashSet); ok && keys != nil {
+			res.Add(keys.Values()...)
+		}
+	}
+	if failures == len(results) {
+		return nil, lastErr
+	}
+	return res, nil
+}
+
+type getter func(dc config_center.DynamicConfiguration, key string, opts ...config_center.Option) (string, error)
+
+func (c *DynamicConfiguration) get(key string, opts []config_center.Option, get getter) (string, error) {
+	r := c.resolve(key, opts, get)
+	if r.Source != "" {
+		return r.Value, nil
+	}
+	for _, l := range r.Layers {
+		if l.Err != nil {
+			return "", perrors.WithMessagef(l.Err, "the config %s is not found, config center %s", key, l.Source)
+		}
+	}
+	return "", perrors.Errorf("the config %s is not found", key)
+}
+
+// resolve reads the key from all the sources, an empty value is regarded as absent
+func (c *DynamicConfiguration) resolve(key string, opts []config_center.Option, get getter) *Resolution {
+	results := c.query(func(dc config_center.DynamicConfiguration) (any, error) {
+		return get(dc, key, opts...)
+	})
+	r := &Resolution{
+		Key:    key,
+		Group:  config_center.NewOptions(opts...).Center.Group,
+		Layers: make([]Layer, len(results)),
+	}
+	for i, res := range results {
+		value, _ := res.value.(string)
+		r.Layers[i] = Layer{Source: c.sources[i].Name, Value: value, Err: res.err}
+		if r.Source == "" && res.err == nil && value != "" {
+			r.Source, r.Value = c.sources[i].Name, value
+		}
+	}
+	return r
+}
+
+type result struct {
+	value any
+	err   error
+}
+
+// query calls f with all the sources at the same time, the results are in the order of the sources. The sources
+// not returning in time get a timeout error, and their results are dropped once they return.
+func (c *DynamicConfiguration) query(f func(dc config_center.DynamicConfiguration) (any, error)) []result {
+	type indexedResult struct {
+		index int
+		result
+	}
+	// it is buffered so that the sources returning after the timeout are not blocked
+	ch := make(chan indexedResult, len(c.sources))
+	for i, s := range c.sources {
+		go func(i int, dc config_center.DynamicConfiguration) {
+			value, err := f(dc)
+			ch <- indexedResult{index: i, result: result{value: value, err: err}}
+		}(i, s.Configuration)
+	}
+
+	results := make([]result, len(c.sources))
+	received := make([]bool, len(c.sources))
+	timer := time.NewTimer(c.timeout)
+	defer timer.Stop()
+	for n := 0; n < len(c.sources); n++ {
+		select {
+		case r := <-ch:
+			results[r.index], received[r.index] = r.result, true
+		case <-timer.C:
+			for i := range results {
+				if !received[i] {
+					results[i].err = perrors.Errorf("timeout after %s", c.timeout)
+				}
+			}
+			return results
+		}
+	}
+	return results
+}
@@ -0,0 +1,337 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package composite
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+import (
+	gxset "github.com/dubbogo/gost/container/set"
+
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/common/extension"
+	"dubbo.apache.org/dubbo-go/v3/config_center"
+	"dubbo.apache.org/dubbo-go/v3/config_center/parser"
+	"dubbo.apache.org/dubbo-go/v3/remoting"
+)
+
+var memoryConfigs sync.Map // location -> *memoryConfiguration
+
+func init() {
+	extension.SetConfigCenterFactory("memory", func() config_center.DynamicConfigurationFactory {
+		return &memoryConfigurationFactory{}
+	})
+}
+
+type memoryConfigurationFactory struct{}
+
+func (f *memoryConfigurationFactory) GetDynamicConfiguration(url *common.URL) (config_center.DynamicConfiguration, error) {
+	if url.GetParamBool("unreachable", false) {
+		return nil, errors.New("unreachable")
+	}
+	dc, _ := memoryConfigs.LoadOrStore(url.Location, newMemoryConfiguration())
+	return dc.(*memoryConfiguration), nil
+}
+
+// memoryConfiguration is a config center keeping the configs in memory
+type memoryConfiguration struct {
+	config_center.BaseDynamicConfiguration
+	lock      sync.Mutex
+	configs   map[string]string
+	listeners map[string]map[config_center.ConfigurationListener]struct{}
+	delay     time.Duration
+	err       error
+}
+
+func newMemoryConfiguration() *memoryConfiguration {
+	return &memoryConfiguration{
+		configs:   make(map[string]string),
+		listeners: make(map[string]map[config_center.ConfigurationListener]struct{}),
+	}
+}
+
+func (m *memoryConfiguration) Parser() parser.ConfigurationParser {
+	return nil
+}
+
+func (m *memoryConfiguration) SetParser(parser.ConfigurationParser) {}
+
+func (m *memoryConfiguration) AddListener(key string, listener config_center.ConfigurationListener, _ ...config_center.Option) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.listeners[key] == nil {
+		m.listeners[key] = make(map[config_center.ConfigurationListener]struct{})
+	}
+	m.listeners[key][listener] = struct{}{}
+}
+
+func (m *memoryConfiguration) RemoveListener(key string, listener config_center.ConfigurationListener, _ ...config_center.Option) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	delete(m.listeners[key], listener)
+}
+
+func (m *memoryConfiguration) GetProperties(key string, _ ...config_center.Option) (string, error) {
+	m.lock.Lock()
+	delay, err, value := m.delay, m.err, m.configs[key]
+	m.lock.Unlock()
+	time.Sleep(delay)
+	return value, err
+}
+
+func (m *memoryConfiguration) GetRule(key string, opts ...config_center.Option) (string, error) {
+	return m.GetProperties(key, opts...)
+}
+
+func (m *memoryConfiguration) GetInternalProperty(key string, opts ...config_center.Option) (string, error) {
+	return m.GetProperties(key, opts...)
+}
+
+func (m *memoryConfiguration) PublishConfig(key string, _ string, value string) error {
+	m.lock.Lock()
+	_, exists := m.configs[key]
+	m.configs[key] = value
+	listeners := m.copyListeners(key)
+	m.lock.Unlock()
+	changeType := remoting.EventTypeUpdate
+	if !exists {
+		changeType = remoting.EventTypeAdd
+	}
+	for _, l := range listeners {
+		l.Process(&config_center.ConfigChangeEvent{Key: key, Value: value, ConfigType: changeType})
+	}
+	return nil
+}
+
+func (m *memoryConfiguration) RemoveConfig(key string, _ string) error {
+	m.lock.Lock()
+	delete(m.configs, key)
+	listeners := m.copyListeners(key)
+	m.lock.Unlock()
+	for _, l := range listeners {
+		l.Process(&config_center.ConfigChangeEvent{Key: key, ConfigType: remoting.EventTypeDel})
+	}
+	return nil
+}
+
+func (m *memoryConfiguration) GetConfigKeysByGroup(string) (*gxset.HashSet, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.err != nil {
+		return nil, m.err
+	}
+	keys := gxset.NewSet()
+	for k := range m.configs {
+		keys.Add(k)
+	}
+	return keys, nil
+}
+
+func (m *memoryConfiguration) copyListeners(key string) []config_center.ConfigurationListener {
+	listeners := make([]config_center.ConfigurationListener, 0, len(m.listeners[key]))
+	for l := range m.listeners[key] {
+		listeners = append(listeners, l)
+	}
+	return listeners
+}
+
+func (m *memoryConfiguration) set(delay time.Duration, err error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.delay, m.err = delay, err
+}
+
+func newTestConfiguration(t *testing.T, count int) (*DynamicConfiguration, []*memoryConfiguration) {
+	url, err := common.NewURL("composite://127.0.0.1", common.WithParamsValue(constant.CompositeTimeoutKey, "100ms"))
+	assert.NoError(t, err)
+	var (
+		sources []Source
+		mcs     []*memoryConfiguration
+	)
+	for i := 0; i < count; i++ {
+		mc := newMemoryConfiguration()
+		sources = append(sources, Source{Name: string(rune('a' + i)), Configuration: mc})
+		mcs = append(mcs, mc)
+	}
+	return NewDynamicConfiguration(url, sources...), mcs
+}
+
+func TestFactory(t *testing.T) {
+	factory, err := extension.GetConfigCenterFactory(constant.CompositeKey)
+	assert.NoError(t, err)
+
+	url, _ := common.NewURL("composite://127.0.0.1")
+	_, err = factory.GetDynamicConfiguration(url)
+	assert.Error(t, err)
+
+	url.SetParam(constant.CompositeSourcesKey, "memory://factory-1,memory://factory-2?unreachable=true,unknown://1,memory://factory-3")
+	url.SetParam(constant.ConfigGroupKey, "group")
+	dc, err := factory.GetDynamicConfiguration(url)
+	assert.NoError(t, err)
+	c := dc.(*DynamicConfiguration)
+	assert.NotNil(t, c.Parser())
+	assert.Len(t, c.sources, 2)
+	assert.Equal(t, "memory://factory-1", c.sources[0].Name)
+	assert.Equal(t, "memory://factory-3", c.sources[1].Name)
+
+	url.SetParam(constant.CompositeSourcesKey, "memory://factory-2?unreachable=true")
+	_, err = factory.GetDynamicConfiguration(url)
+	assert.Error(t, err)
+}
+
+func TestParseSourceURLs(t *testing.T) {
+	url, _ := common.NewURL("composite://127.0.0.1",
+		common.WithParamsValue(constant.CompositeSourcesKey, "nacos://127.0.0.1:8848, apollo://127.0.0.1:8080?config-center.group=other"),
+		common.WithParamsValue(constant.ConfigGroupKey, "group"))
+	urls, err := parseSourceURLs(url)
+	assert.NoError(t, err)
+	assert.Len(t, urls, 2)
+	assert.Equal(t, "nacos", urls[0].Protocol)
+	assert.Equal(t, "group", urls[0].GetParam(constant.ConfigGroupKey, ""))
+	assert.Equal(t, "", urls[0].GetParam(constant.CompositeSourcesKey, ""))
+	assert.Equal(t, "other", urls[1].GetParam(constant.ConfigGroupKey, ""))
+
+	url.SetParam(constant.CompositeSourcesKey, "127.0.0.1:8848")
+	_, err = parseSourceURLs(url)
+	assert.Error(t, err)
+}
+
+func TestGetProperties(t *testing.T) {
+	c, mcs := newTestConfiguration(t, 3)
+	_, err := c.GetProperties("key")
+	assert.Error(t, err)
+
+	assert.NoError(t, mcs[2].PublishConfig("key", "", "c"))
+	value, err := c.GetProperties("key")
+	assert.NoError(t, err)
+	assert.Equal(t, "c", value)
+
+	assert.NoError(t, mcs[1].PublishConfig("key", "", "b"))
+	value, err = c.GetRule("key")
+	assert.NoError(t, err)
+	assert.Equal(t, "b", value)
+
+	// the first source has the highest precedence
+	assert.NoError(t, c.PublishConfig("key", "", "a"))
+	value, err = c.GetInternalProperty("key")
+	assert.NoError(t, err)
+	assert.Equal(t, "a", value)
+
+	assert.NoError(t, c.RemoveConfig("key", ""))
+	value, err = c.GetProperties("key")
+	assert.NoError(t, err)
+	assert.Equal(t, "b", value)
+}
+
+func TestFailureIsolation(t *testing.T) {
+	c, mcs := newTestConfiguration(t, 3)
+	for _, mc := range mcs {
+		assert.NoError(t, mc.PublishConfig("key", "", "value"))
+	}
+	assert.NoError(t, mcs[1].PublishConfig("key", "", "b"))
+	assert.NoError(t, mcs[2].PublishConfig("other", "", "c"))
+	mcs[0].set(time.Second, nil)
+	mcs[1].set(0, errors.New("unreachable"))
+
+	start := time.Now()
+	value, err := c.GetProperties("key")
+	assert.NoError(t, err)
+	assert.Equal(t, "value", value)
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+
+	r := c.Resolve("key", config_center.WithGroup("group"))
+	assert.Equal(t, "key", r.Key)
+	assert.Equal(t, "group", r.Group)
+	assert.Equal(t, "c", r.Source)
+	assert.Equal(t, "value", r.Value)
+	assert.Len(t, r.Layers, 3)
+	assert.Error(t, r.Layers[0].Err)
+	assert.Error(t, r.Layers[1].Err)
+	assert.NoError(t, r.Layers[2].Err)
+
+	keys, err := c.GetConfigKeysByGroup("group")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, keys.Size())
+
+	mcs[2].set(0, errors.New("unreachable"))
+	_, err = c.GetProperties("key")
+	assert.Error(t, err)
+	mcs[0].set(0, errors.New("unreachable"))
+	_, err = c.GetConfigKeysByGroup("group")
+	assert.Error(t, err)
+}
+
+func TestListener(t *testing.T) {
+	c, mcs := newTestConfiguration(t, 2)
+	assert.NoError(t, mcs[1].PublishConfig("key", "", "b"))
+	listener := &mockListener{}
+	c.AddListener("key", listener)
+	c.AddListener("key", listener)
+	assert.Len(t, c.watchers, 1)
+	assert.Empty(t, listener.all())
+
+	// the value of the first source overrides the one of the second source
+	assert.NoError(t, mcs[0].PublishConfig("key", "", "a"))
+	assert.Equal(t, []config_center.ConfigChangeEvent{{Key: "key", Value: "a", ConfigType: remoting.EventTypeUpdate}}, listener.all())
+
+	// the effective value is not changed
+	assert.NoError(t, mcs[1].PublishConfig("key", "", "b2"))
+	assert.NoError(t, mcs[0].PublishConfig("key", "", "a"))
+	assert.Len(t, listener.all(), 1)
+
+	assert.NoError(t, mcs[0].RemoveConfig("key", ""))
+	assert.Equal(t, config_center.ConfigChangeEvent{Key: "key", Value: "b2", ConfigType: remoting.EventTypeUpdate}, listener.all()[1])
+	assert.NoError(t, mcs[1].RemoveConfig("key", ""))
+	assert.Equal(t, config_center.ConfigChangeEvent{Key: "key", ConfigType: remoting.EventTypeDel}, listener.all()[2])
+	assert.NoError(t, mcs[1].PublishConfig("key", "", "b3"))
+	assert.Equal(t, config_center.ConfigChangeEvent{Key: "key", Value: "b3", ConfigType: remoting.EventTypeAdd}, listener.all()[3])
+
+	c.RemoveListener("key", listener)
+	assert.Empty(t, c.watchers)
+	for _, mc := range mcs {
+		assert.Empty(t, mc.listeners["key"])
+	}
+	assert.NoError(t, mcs[0].PublishConfig("key", "", "a"))
+	assert.Len(t, listener.all(), 4)
+}
+
+type mockListener struct {
+	lock   sync.Mutex
+	events []config_center.ConfigChangeEvent
+}
+
+func (l *mockListener) Process(event *config_center.ConfigChangeEvent) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.events = append(l.events, *event)
+}
+
+func (l *mockListener) all() []config_center.ConfigChangeEvent {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return append([]config_center.ConfigChangeEvent(nil), l.events...)
+}
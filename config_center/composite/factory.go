@@ -0,0 +1,107 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package composite
+
+import (
+	"strings"
+)
+
+import (
+	"github.com/dubbogo/gost/log/logger"
+
+	perrors "github.com/pkg/errors"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/common/extension"
+	"dubbo.apache.org/dubbo-go/v3/config_center"
+	"dubbo.apache.org/dubbo-go/v3/config_center/parser"
+)
+
+func init() {
+	extension.SetConfigCenterFactory(constant.CompositeKey, func() config_center.DynamicConfigurationFactory {
+		return &compositeDynamicConfigurationFactory{}
+	})
+}
+
+type compositeDynamicConfigurationFactory struct{}
+
+// GetDynamicConfiguration creates the config centers in the composite.sources param of the url. The config
+// centers failed to be created are skipped, an error is returned only if none of them is created.
+func (f *compositeDynamicConfigurationFactory) GetDynamicConfiguration(url *common.URL) (config_center.DynamicConfiguration, error) {
+	urls, err := parseSourceURLs(url)
+	if err != nil {
+		return nil, err
+	}
+	sources := make([]Source, 0, len(urls))
+	for _, u := range urls {
+		name := getName(u)
+		factory, err := extension.GetConfigCenterFactory(u.Protocol)
+		if err != nil {
+			logger.Warnf("[Composite ConfigCenter] Skip the config center %s: %v", name, err)
+			continue
+		}
+		dc, err := factory.GetDynamicConfiguration(u)
+		if err != nil {
+			logger.Warnf("[Composite ConfigCenter] Skip the config center %s: %v", name, err)
+			continue
+		}
+		sources = append(sources, Source{Name: name, Configuration: dc})
+	}
+	if len(sources) == 0 {
+		return nil, perrors.Errorf("none of the config centers %s is available", url.GetParam(constant.CompositeSourcesKey, ""))
+	}
+	dynamicConfiguration := NewDynamicConfiguration(url, sources...)
+	dynamicConfiguration.SetParser(&parser.DefaultConfigurationParser{})
+	return dynamicConfiguration, nil
+}
+
+// parseSourceURLs parses the urls of the config centers, the params of the composite url are inherited by them
+func parseSourceURLs(url *common.URL) ([]*common.URL, error) {
+	var urls []*common.URL
+	for _, address := range strings.Split(url.GetParam(constant.CompositeSourcesKey, ""), constant.CommaSeparator) {
+		if address = strings.TrimSpace(address); address == "" {
+			continue
+		}
+		sub, err := common.NewURL(address)
+		if err != nil {
+			return nil, perrors.WithMessagef(err, "parse the config center url %s", address)
+		}
+		if sub.Protocol == "" {
+			return nil, perrors.Errorf("the config center url %s has no protocol", address)
+		}
+		url.RangeParams(func(key, value string) bool {
+			if !strings.HasPrefix(key, constant.CompositeKey+".") && sub.GetParam(key, "") == "" {
+				sub.SetParam(key, value)
+			}
+			return true
+		})
+		urls = append(urls, sub)
+	}
+	if len(urls) == 0 {
+		return nil, perrors.Errorf("%s is not set in the composite config center url", constant.CompositeSourcesKey)
+	}
+	return urls, nil
+}
+
+// getName returns the name of the config center, which is used in the logs and the resolutions
+func getName(url *common.URL) string {
+	return url.Protocol + "://" + url.Location
+}
@@ -0,0 +1,186 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package composite
+
+import (
+	"fmt"
+	"sync"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/config_center"
+	"dubbo.apache.org/dubbo-go/v3/remoting"
+)
+
+type watchKey struct {
+	key   string
+	group string
+}
+
+// keyWatcher tracks the value of a key in each source, and notifies the listeners only when the effective value
+// of the key changes.
+type keyWatcher struct {
+	key string
+
+	lock sync.Mutex
+	// values are the values of the key in the sources, nil means the key is absent in the source
+	values []*string
+	// notified marks the sources whose values are notified, their values are not overwritten by the initial ones
+	notified  []bool
+	effective *string
+	listeners map[config_center.ConfigurationListener]struct{}
+	// sourceListeners are the listeners added to the sources
+	sourceListeners []*sourceListener
+}
+
+// sourceListener receives the changes of the key in a source
+type sourceListener struct {
+	watcher *keyWatcher
+	index   int
+}
+
+func (l *sourceListener) Process(event *config_center.ConfigChangeEvent) {
+	var value *string
+	if event.ConfigType != remoting.EventTypeDel {
+		value = toString(event.Value)
+	}
+	l.watcher.update(l.index, value, true)
+}
+
+// AddListener watches the key in all the sources, the listener is notified when the effective value of the key
+// is changed
+func (c *DynamicConfiguration) AddListener(key string, listener config_center.ConfigurationListener, opts ...config_center.Option) {
+	wk := watchKey{key: key, group: config_center.NewOptions(opts...).Center.Group}
+	c.lock.Lock()
+	if w, ok := c.watchers[wk]; ok {
+		w.lock.Lock()
+		w.listeners[listener] = struct{}{}
+		w.lock.Unlock()
+		c.lock.Unlock()
+		return
+	}
+	w := &keyWatcher{
+		key:       key,
+		values:    make([]*string, len(c.sources)),
+		notified:  make([]bool, len(c.sources)),
+		listeners: map[config_center.ConfigurationListener]struct{}{listener: {}},
+	}
+	for i := range c.sources {
+		w.sourceListeners = append(w.sourceListeners, &sourceListener{watcher: w, index: i})
+	}
+	c.watchers[wk] = w
+	c.lock.Unlock()
+
+	// the listeners are added before reading the initial values, so that no change is missed
+	for i, s := range c.sources {
+		s.Configuration.AddListener(key, w.sourceListeners[i], opts...)
+	}
+	r := c.resolve(key, opts, config_center.DynamicConfiguration.GetProperties)
+	for i, l := range r.Layers {
+		if l.Err == nil && l.Value != "" {
+			value := l.Value
+			w.update(i, &value, false)
+		}
+	}
+}
+
+// RemoveListener removes the listener, and stops watching the key in the sources if it has no listener
+func (c *DynamicConfiguration) RemoveListener(key string, listener config_center.ConfigurationListener, opts ...config_center.Option) {
+	wk := watchKey{key: key, group: config_center.NewOptions(opts...).Center.Group}
+	c.lock.Lock()
+	w, ok := c.watchers[wk]
+	if !ok {
+		c.lock.Unlock()
+		return
+	}
+	w.lock.Lock()
+	delete(w.listeners, listener)
+	empty := len(w.listeners) == 0
+	w.lock.Unlock()
+	if !empty {
+		c.lock.Unlock()
+		return
+	}
+	delete(c.watchers, wk)
+	c.lock.Unlock()
+
+	for i, s := range c.sources {
+		s.Configuration.RemoveListener(key, w.sourceListeners[i], opts...)
+	}
+}
+
+// update sets the value of the key in the source, and notifies the listeners if the effective value is changed.
+// The initial value of a source is ignored if a change of the source has been notified, and the initial values
+// are not notified to the listeners.
+func (w *keyWatcher) update(index int, value *string, notified bool) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if !notified && w.notified[index] {
+		return
+	}
+	w.notified[index] = w.notified[index] || notified
+	w.values[index] = value
+
+	var effective *string
+	for _, v := range w.values {
+		if v != nil {
+			effective = v
+			break
+		}
+	}
+	old := w.effective
+	w.effective = effective
+	if !notified {
+		return
+	}
+
+	var event *config_center.ConfigChangeEvent
+	switch {
+	case old == nil && effective == nil:
+		return
+	case old == nil:
+		event = &config_center.ConfigChangeEvent{Key: w.key, Value: *effective, ConfigType: remoting.EventTypeAdd}
+	case effective == nil:
+		event = &config_center.ConfigChangeEvent{Key: w.key, ConfigType: remoting.EventTypeDel}
+	case *old == *effective:
+		return
+	default:
+		event = &config_center.ConfigChangeEvent{Key: w.key, Value: *effective, ConfigType: remoting.EventTypeUpdate}
+	}
+	for listener := range w.listeners {
+		listener.Process(event)
+	}
+}
+
+// toString returns the value of the event as a string, nil means the value is empty
+func toString(value any) *string {
+	var s string
+	switch v := value.(type) {
+	case nil:
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		s = fmt.Sprint(v)
+	}
+	if s == "" {
+		return nil
+	}
+	return &s
+}
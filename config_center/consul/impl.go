@@ -0,0 +1,157 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package consul
+
+import (
+	"strings"
+)
+
+import (
+	gxset "github.com/dubbogo/gost/container/set"
+	"github.com/dubbogo/gost/log/logger"
+
+	"github.com/hashicorp/consul/api"
+
+	perrors "github.com/pkg/errors"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/config_center"
+	"dubbo.apache.org/dubbo-go/v3/config_center/parser"
+	"dubbo.apache.org/dubbo-go/v3/remoting/consul"
+)
+
+const defaultRootPath = "dubbo/config"
+
+// consulDynamicConfiguration stores the configs in the KV store of consul, the key of the config is
+// dubbo/config/{group}/{key}.
+type consulDynamicConfiguration struct {
+	config_center.BaseDynamicConfiguration
+	url           *common.URL
+	rootPath      string
+	client        *api.Client
+	cacheListener *CacheListener
+	parser        parser.ConfigurationParser
+}
+
+func newConsulDynamicConfiguration(url *common.URL) (*consulDynamicConfiguration, error) {
+	client, err := consul.NewClientByURL(url)
+	if err != nil {
+		return nil, perrors.WithMessage(err, "new consul client")
+	}
+	logger.Infof("[Consul ConfigCenter] New Consul ConfigCenter with url = %+v", url.ToMap())
+	return &consulDynamicConfiguration{
+		url:           url,
+		rootPath:      defaultRootPath,
+		client:        client,
+		cacheListener: NewCacheListener(client, defaultRootPath),
+	}, nil
+}
+
+// Parser Get Parser
+func (c *consulDynamicConfiguration) Parser() parser.ConfigurationParser {
+	return c.parser
+}
+
+// SetParser Set Parser
+func (c *consulDynamicConfiguration) SetParser(p parser.ConfigurationParser) {
+	c.parser = p
+}
+
+// AddListener watches the key of the group, the listener is notified when the config is changed
+func (c *consulDynamicConfiguration) AddListener(key string, listener config_center.ConfigurationListener, opts ...config_center.Option) {
+	tmpOpts := config_center.NewOptions(opts...)
+	c.cacheListener.AddListener(c.getPath(key, tmpOpts.Center.Group), listener)
+}
+
+// RemoveListener removes the listener, and stops watching the key if it has no listener
+func (c *consulDynamicConfiguration) RemoveListener(key string, listener config_center.ConfigurationListener, opts ...config_center.Option) {
+	tmpOpts := config_center.NewOptions(opts...)
+	c.cacheListener.RemoveListener(c.getPath(key, tmpOpts.Center.Group), listener)
+}
+
+// GetProperties get properties file
+func (c *consulDynamicConfiguration) GetProperties(key string, opts ...config_center.Option) (string, error) {
+	tmpOpts := config_center.NewOptions(opts...)
+	path := c.getPath(key, tmpOpts.Center.Group)
+	pair, _, err := c.client.KV().Get(path, nil)
+	if err != nil {
+		return "", perrors.WithStack(err)
+	}
+	if pair == nil {
+		return "", perrors.Errorf("the config %s is not found", path)
+	}
+	return string(pair.Value), nil
+}
+
+// GetRule get Router rule properties file
+func (c *consulDynamicConfiguration) GetRule(key string, opts ...config_center.Option) (string, error) {
+	return c.GetProperties(key, opts...)
+}
+
+// GetInternalProperty get value by key in Default properties file(dubbo.properties)
+func (c *consulDynamicConfiguration) GetInternalProperty(key string, opts ...config_center.Option) (string, error) {
+	return c.GetProperties(key, opts...)
+}
+
+// PublishConfig will publish the config with the (key, group, value) pair
+func (c *consulDynamicConfiguration) PublishConfig(key string, group string, value string) error {
+	_, err := c.client.KV().Put(&api.KVPair{Key: c.getPath(key, group), Value: []byte(value)}, nil)
+	return err
+}
+
+// RemoveConfig will remove the config with the (key, group) pair
+func (c *consulDynamicConfiguration) RemoveConfig(key string, group string) error {
+	_, err := c.client.KV().Delete(c.getPath(key, group), nil)
+	return err
+}
+
+// GetConfigKeysByGroup will return all keys with the group
+func (c *consulDynamicConfiguration) GetConfigKeysByGroup(group string) (*gxset.HashSet, error) {
+	prefix := c.getPath("", group) + constant.PathSeparator
+	keys, _, err := c.client.KV().Keys(prefix, constant.PathSeparator, nil)
+	if err != nil {
+		return nil, perrors.WithStack(err)
+	}
+	res := gxset.NewSet()
+	for _, k := range keys {
+		// the sub folders are not the keys of the group
+		if k = strings.TrimPrefix(k, prefix); k != "" && !strings.HasSuffix(k, constant.PathSeparator) {
+			res.Add(k)
+		}
+	}
+	return res, nil
+}
+
+// Close stops watching all the keys
+func (c *consulDynamicConfiguration) Close() error {
+	c.cacheListener.Close()
+	return nil
+}
+
+func (c *consulDynamicConfiguration) getPath(key string, group string) string {
+	if len(group) == 0 {
+		group = config_center.DefaultGroup
+	}
+	if len(key) == 0 {
+		return c.rootPath + constant.PathSeparator + group
+	}
+	return c.rootPath + constant.PathSeparator + group + constant.PathSeparator + key
+}
@@ -0,0 +1,138 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package consul
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/extension"
+	"dubbo.apache.org/dubbo-go/v3/config_center"
+	"dubbo.apache.org/dubbo-go/v3/remoting"
+	"dubbo.apache.org/dubbo-go/v3/remoting/consul/fake"
+)
+
+const key = "com.dubbo.go"
+
+func initConsulData(t *testing.T) (*fake.Server, *consulDynamicConfiguration) {
+	server := fake.NewServer()
+	url, err := common.NewURL("consul://" + server.Address() + "?consul.wait-time=1s")
+	assert.NoError(t, err)
+	factory, err := extension.GetConfigCenterFactory("consul")
+	assert.NoError(t, err)
+	dc, err := factory.GetDynamicConfiguration(url)
+	assert.NoError(t, err)
+	return server, dc.(*consulDynamicConfiguration)
+}
+
+func TestPublishAndGetConfig(t *testing.T) {
+	server, dc := initConsulData(t)
+	defer server.Close()
+
+	_, err := dc.GetProperties(key)
+	assert.Error(t, err)
+	assert.NoError(t, dc.PublishConfig(key, "", "A"))
+	prop, err := dc.GetProperties(key)
+	assert.NoError(t, err)
+	assert.Equal(t, "A", prop)
+	rule, err := dc.GetRule(key, config_center.WithGroup("dubbo"))
+	assert.NoError(t, err)
+	assert.Equal(t, "A", rule)
+
+	assert.NoError(t, dc.RemoveConfig(key, ""))
+	_, err = dc.GetInternalProperty(key)
+	assert.Error(t, err)
+}
+
+func TestGetConfigKeysByGroup(t *testing.T) {
+	server, dc := initConsulData(t)
+	defer server.Close()
+
+	assert.NoError(t, dc.PublishConfig("a", "group", "1"))
+	assert.NoError(t, dc.PublishConfig("b", "group", "2"))
+	assert.NoError(t, dc.PublishConfig("c/d", "group", "3"))
+	assert.NoError(t, dc.PublishConfig("e", "group2", "4"))
+	keys, err := dc.GetConfigKeysByGroup("group")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, keys.Size())
+	assert.True(t, keys.Contains("a"))
+	assert.True(t, keys.Contains("b"))
+
+	keys, err = dc.GetConfigKeysByGroup("none")
+	assert.NoError(t, err)
+	assert.True(t, keys.Empty())
+}
+
+func TestAddListener(t *testing.T) {
+	server, dc := initConsulData(t)
+	defer server.Close()
+	defer dc.Close()
+
+	group := "dubbogo"
+	assert.NoError(t, dc.PublishConfig(key, group, "Test Value"))
+	listener := &mockDataListener{}
+	dc.AddListener(key, listener, config_center.WithGroup(group))
+	// the initial value is not notified
+	time.Sleep(100 * time.Millisecond)
+	assert.Nil(t, listener.last())
+
+	assert.NoError(t, dc.PublishConfig(key, group, "Test Value 2"))
+	assert.Eventually(t, func() bool {
+		event := listener.last()
+		return event != nil && event.ConfigType == remoting.EventTypeUpdate && event.Value == "Test Value 2"
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, key, listener.last().Key)
+
+	assert.NoError(t, dc.RemoveConfig(key, group))
+	assert.Eventually(t, func() bool {
+		return listener.last().ConfigType == remoting.EventTypeDel
+	}, time.Second, 10*time.Millisecond)
+
+	assert.NoError(t, dc.PublishConfig(key, group, "Test Value 3"))
+	assert.Eventually(t, func() bool {
+		return listener.last().ConfigType == remoting.EventTypeAdd
+	}, time.Second, 10*time.Millisecond)
+
+	dc.RemoveListener(key, listener, config_center.WithGroup(group))
+	assert.Empty(t, dc.cacheListener.watchers)
+}
+
+type mockDataListener struct {
+	lock  sync.Mutex
+	event *config_center.ConfigChangeEvent
+}
+
+func (l *mockDataListener) Process(event *config_center.ConfigChangeEvent) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.event = event
+}
+
+func (l *mockDataListener) last() *config_center.ConfigChangeEvent {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.event
+}
@@ -0,0 +1,151 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package consul
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+import (
+	"github.com/hashicorp/consul/api"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/config_center"
+	"dubbo.apache.org/dubbo-go/v3/metrics"
+	metricsConfigCenter "dubbo.apache.org/dubbo-go/v3/metrics/config_center"
+	"dubbo.apache.org/dubbo-go/v3/remoting"
+	"dubbo.apache.org/dubbo-go/v3/remoting/consul"
+)
+
+// CacheListener watches the keys which have listeners by the blocking queries, and notifies the listeners of
+// the changes of the keys.
+type CacheListener struct {
+	client   *api.Client
+	rootPath string
+
+	lock     sync.Mutex
+	watchers map[string]*keyWatcher
+}
+
+type keyWatcher struct {
+	listeners map[config_center.ConfigurationListener]struct{}
+	cancel    context.CancelFunc
+	// modifyIndex is the modify index of the value notified last time, it is 0 if the key doesn't exist
+	modifyIndex uint64
+}
+
+// NewCacheListener creates a new CacheListener
+func NewCacheListener(client *api.Client, rootPath string) *CacheListener {
+	return &CacheListener{client: client, rootPath: rootPath, watchers: make(map[string]*keyWatcher)}
+}
+
+// AddListener adds the listener of the key, and starts watching the key if it is not watched yet
+func (l *CacheListener) AddListener(key string, listener config_center.ConfigurationListener) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if w, ok := l.watchers[key]; ok {
+		w.listeners[listener] = struct{}{}
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &keyWatcher{listeners: map[config_center.ConfigurationListener]struct{}{listener: {}}, cancel: cancel}
+	l.watchers[key] = w
+	go consul.Watch(ctx, func(ctx context.Context, index uint64) (uint64, error) {
+		pair, meta, err := l.client.KV().Get(key, consul.QueryOptions(ctx, index))
+		if err != nil {
+			return 0, err
+		}
+		if meta.LastIndex != index {
+			l.dataChange(key, w, pair, index == 0)
+		}
+		return meta.LastIndex, nil
+	})
+}
+
+// RemoveListener removes the listener of the key, and stops watching the key if it has no listener
+func (l *CacheListener) RemoveListener(key string, listener config_center.ConfigurationListener) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	w, ok := l.watchers[key]
+	if !ok {
+		return
+	}
+	delete(w.listeners, listener)
+	if len(w.listeners) == 0 {
+		w.cancel()
+		delete(l.watchers, key)
+	}
+}
+
+// Close stops watching all the keys
+func (l *CacheListener) Close() {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	for key, w := range l.watchers {
+		w.cancel()
+		delete(l.watchers, key)
+	}
+}
+
+// dataChange notifies the listeners if the value of the key is changed, the value got by the first query is
+// the initial one, which is not notified.
+func (l *CacheListener) dataChange(key string, w *keyWatcher, pair *api.KVPair, initial bool) {
+	var (
+		changeType remoting.EventType
+		value      string
+	)
+	l.lock.Lock()
+	switch {
+	case pair == nil && w.modifyIndex == 0:
+		l.lock.Unlock()
+		return
+	case pair == nil:
+		changeType, w.modifyIndex = remoting.EventTypeDel, 0
+	case pair.ModifyIndex == w.modifyIndex:
+		l.lock.Unlock()
+		return
+	case w.modifyIndex == 0:
+		changeType, value, w.modifyIndex = remoting.EventTypeAdd, string(pair.Value), pair.ModifyIndex
+	default:
+		changeType, value, w.modifyIndex = remoting.EventTypeUpdate, string(pair.Value), pair.ModifyIndex
+	}
+	listeners := make([]config_center.ConfigurationListener, 0, len(w.listeners))
+	for listener := range w.listeners {
+		listeners = append(listeners, listener)
+	}
+	l.lock.Unlock()
+	if initial {
+		return
+	}
+
+	k, group := l.pathToKeyGroup(key)
+	metrics.Publish(metricsConfigCenter.NewIncMetricEvent(k, group, changeType, metricsConfigCenter.Consul))
+	for _, listener := range listeners {
+		listener.Process(&config_center.ConfigChangeEvent{Key: k, Value: value, ConfigType: changeType})
+	}
+}
+
+func (l *CacheListener) pathToKeyGroup(path string) (string, string) {
+	groupKey := strings.TrimPrefix(path, l.rootPath+constant.PathSeparator)
+	group, key, _ := strings.Cut(groupKey, constant.PathSeparator)
+	return key, group
+}
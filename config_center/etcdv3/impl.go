@@ -0,0 +1,220 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package etcdv3
+
+import (
+	"strings"
+	"sync"
+)
+
+import (
+	gxset "github.com/dubbogo/gost/container/set"
+	gxetcd "github.com/dubbogo/gost/database/kv/etcd/v3"
+	"github.com/dubbogo/gost/log/logger"
+
+	perrors "github.com/pkg/errors"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/config_center"
+	"dubbo.apache.org/dubbo-go/v3/config_center/parser"
+	"dubbo.apache.org/dubbo-go/v3/remoting/etcdv3"
+)
+
+const (
+	defaultRootPath = "/dubbo/config"
+	clientName      = "etcd config center client"
+)
+
+// etcdV3DynamicConfiguration stores the configs in etcd, the key of the config is /dubbo/config/{group}/{key}.
+type etcdV3DynamicConfiguration struct {
+	config_center.BaseDynamicConfiguration
+	url      *common.URL
+	rootPath string
+	wg       sync.WaitGroup
+	cltLock  sync.Mutex
+	done     chan struct{}
+	once     sync.Once
+	client   *gxetcd.Client
+
+	listener      *etcdv3.EventListener
+	cacheListener *CacheListener
+	parser        parser.ConfigurationParser
+}
+
+func newEtcdV3DynamicConfiguration(url *common.URL) (*etcdV3DynamicConfiguration, error) {
+	c := &etcdV3DynamicConfiguration{
+		url:      url,
+		rootPath: defaultRootPath,
+		done:     make(chan struct{}),
+	}
+	timeout := url.GetParamDuration(constant.ConfigTimeoutKey, config_center.DefaultConfigTimeout)
+	logger.Infof("[Etcd ConfigCenter] New Etcd ConfigCenter with address: %s, timeout: %s", url.Location, timeout)
+	if err := etcdv3.ValidateClient(
+		c,
+		gxetcd.WithName(clientName),
+		gxetcd.WithTimeout(timeout),
+		gxetcd.WithEndpoints(strings.Split(url.Location, ",")...),
+	); err != nil {
+		return nil, err
+	}
+	c.listener = etcdv3.NewEventListener(c.client)
+	c.cacheListener = NewCacheListener(c.rootPath, c.listener)
+	return c, nil
+}
+
+// Parser Get Parser
+func (c *etcdV3DynamicConfiguration) Parser() parser.ConfigurationParser {
+	return c.parser
+}
+
+// SetParser Set Parser
+func (c *etcdV3DynamicConfiguration) SetParser(p parser.ConfigurationParser) {
+	c.parser = p
+}
+
+// AddListener watches the key of the group, the listener is notified when the config is changed
+func (c *etcdV3DynamicConfiguration) AddListener(key string, listener config_center.ConfigurationListener, opts ...config_center.Option) {
+	tmpOpts := config_center.NewOptions(opts...)
+	c.cacheListener.AddListener(c.getPath(key, tmpOpts.Center.Group), listener)
+}
+
+// RemoveListener removes the listener, and stops watching the key if it has no listener
+func (c *etcdV3DynamicConfiguration) RemoveListener(key string, listener config_center.ConfigurationListener, opts ...config_center.Option) {
+	tmpOpts := config_center.NewOptions(opts...)
+	c.cacheListener.RemoveListener(c.getPath(key, tmpOpts.Center.Group), listener)
+}
+
+// GetProperties get properties file
+func (c *etcdV3DynamicConfiguration) GetProperties(key string, opts ...config_center.Option) (string, error) {
+	tmpOpts := config_center.NewOptions(opts...)
+	value, err := c.client.Get(c.getPath(key, tmpOpts.Center.Group))
+	if err != nil {
+		return "", perrors.WithStack(err)
+	}
+	return value, nil
+}
+
+// GetRule get Router rule properties file
+func (c *etcdV3DynamicConfiguration) GetRule(key string, opts ...config_center.Option) (string, error) {
+	return c.GetProperties(key, opts...)
+}
+
+// GetInternalProperty get value by key in Default properties file(dubbo.properties)
+func (c *etcdV3DynamicConfiguration) GetInternalProperty(key string, opts ...config_center.Option) (string, error) {
+	return c.GetProperties(key, opts...)
+}
+
+// PublishConfig will publish the config with the (key, group, value) pair
+func (c *etcdV3DynamicConfiguration) PublishConfig(key string, group string, value string) error {
+	return perrors.WithStack(c.client.Put(c.getPath(key, group), value))
+}
+
+// RemoveConfig will remove the config with the (key, group) pair
+func (c *etcdV3DynamicConfiguration) RemoveConfig(key string, group string) error {
+	return perrors.WithStack(c.client.Delete(c.getPath(key, group)))
+}
+
+// GetConfigKeysByGroup will return all keys with the group
+func (c *etcdV3DynamicConfiguration) GetConfigKeysByGroup(group string) (*gxset.HashSet, error) {
+	prefix := c.getPath("", group) + constant.PathSeparator
+	keys, _, err := c.client.GetChildrenKVList(prefix)
+	if err != nil && perrors.Cause(err) != gxetcd.ErrKVPairNotFound {
+		return nil, perrors.WithStack(err)
+	}
+	return keysOfGroup(prefix, keys), nil
+}
+
+// keysOfGroup returns the keys directly under the prefix, the keys in the sub folders are not the keys of the group
+func keysOfGroup(prefix string, paths []string) *gxset.HashSet {
+	res := gxset.NewSet()
+	for _, p := range paths {
+		if k := strings.TrimPrefix(p, prefix); k != "" && !strings.Contains(k, constant.PathSeparator) {
+			res.Add(k)
+		}
+	}
+	return res
+}
+
+// Client gets the etcd client
+func (c *etcdV3DynamicConfiguration) Client() *gxetcd.Client {
+	return c.client
+}
+
+// SetClient sets the etcd client
+func (c *etcdV3DynamicConfiguration) SetClient(client *gxetcd.Client) {
+	c.client = client
+}
+
+// ClientLock returns lock for client
+func (c *etcdV3DynamicConfiguration) ClientLock() *sync.Mutex {
+	return &c.cltLock
+}
+
+func (c *etcdV3DynamicConfiguration) WaitGroup() *sync.WaitGroup {
+	return &c.wg
+}
+
+func (c *etcdV3DynamicConfiguration) Done() chan struct{} {
+	return c.done
+}
+
+// RestartCallBack does nothing, since there is nothing registered by the config center
+func (c *etcdV3DynamicConfiguration) RestartCallBack() bool {
+	return true
+}
+
+func (c *etcdV3DynamicConfiguration) GetURL() *common.URL {
+	return c.url
+}
+
+func (c *etcdV3DynamicConfiguration) IsAvailable() bool {
+	select {
+	case <-c.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// Destroy stops watching all the keys and closes the client
+func (c *etcdV3DynamicConfiguration) Destroy() {
+	c.once.Do(func() {
+		close(c.done)
+		c.cacheListener.Close()
+		c.listener.Close()
+		c.cltLock.Lock()
+		defer c.cltLock.Unlock()
+		if c.client != nil {
+			c.client.Close()
+			c.client = nil
+		}
+	})
+}
+
+func (c *etcdV3DynamicConfiguration) getPath(key string, group string) string {
+	if len(group) == 0 {
+		group = config_center.DefaultGroup
+	}
+	if len(key) == 0 {
+		return c.rootPath + constant.PathSeparator + group
+	}
+	return c.rootPath + constant.PathSeparator + group + constant.PathSeparator + key
+}
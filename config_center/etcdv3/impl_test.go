@@ -0,0 +1,131 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package etcdv3
+
+import (
+	"net"
+	"sync"
+	"testing"
+)
+
+import (
+	gxetcd "github.com/dubbogo/gost/database/kv/etcd/v3"
+
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/common/extension"
+	"dubbo.apache.org/dubbo-go/v3/config_center"
+	"dubbo.apache.org/dubbo-go/v3/remoting"
+	"dubbo.apache.org/dubbo-go/v3/remoting/etcdv3"
+)
+
+// newTestConfiguration returns a configuration whose client is not connected, so the watches stop at once
+func newTestConfiguration() *etcdV3DynamicConfiguration {
+	listener := etcdv3.NewEventListener(&gxetcd.Client{})
+	return &etcdV3DynamicConfiguration{
+		rootPath:      defaultRootPath,
+		done:          make(chan struct{}),
+		listener:      listener,
+		cacheListener: NewCacheListener(defaultRootPath, listener),
+	}
+}
+
+func TestGetDynamicConfigurationError(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	address := l.Addr().String()
+	assert.NoError(t, l.Close())
+
+	factory, err := extension.GetConfigCenterFactory(constant.EtcdV3Key)
+	assert.NoError(t, err)
+	url, err := common.NewURL("etcdv3://" + address + "?" + constant.ConfigTimeoutKey + "=100ms")
+	assert.NoError(t, err)
+	_, err = factory.GetDynamicConfiguration(url)
+	assert.Error(t, err)
+}
+
+func TestGetPath(t *testing.T) {
+	c := newTestConfiguration()
+	assert.Equal(t, "/dubbo/config/dubbo/key", c.getPath("key", ""))
+	assert.Equal(t, "/dubbo/config/group/key", c.getPath("key", "group"))
+	assert.Equal(t, "/dubbo/config/group", c.getPath("", "group"))
+}
+
+func TestKeysOfGroup(t *testing.T) {
+	prefix := "/dubbo/config/group/"
+	keys := keysOfGroup(prefix, []string{prefix + "a", prefix + "b", prefix + "c/d", prefix})
+	assert.Equal(t, 2, keys.Size())
+	assert.True(t, keys.Contains("a"))
+	assert.True(t, keys.Contains("b"))
+	assert.True(t, keysOfGroup(prefix, nil).Empty())
+}
+
+func TestCacheListener(t *testing.T) {
+	c := newTestConfiguration()
+	l1, l2 := &mockDataListener{}, &mockDataListener{}
+	c.AddListener("key", l1, config_center.WithGroup("group"))
+	c.AddListener("key", l2, config_center.WithGroup("group"))
+	assert.Len(t, c.cacheListener.watchers, 1)
+
+	path := c.getPath("key", "group")
+	assert.True(t, c.cacheListener.DataChange(remoting.Event{Path: path, Action: remoting.EventTypeUpdate, Content: "value"}))
+	assert.False(t, c.cacheListener.DataChange(remoting.Event{Path: c.getPath("other", "group"), Action: remoting.EventTypeAdd}))
+	for _, l := range []*mockDataListener{l1, l2} {
+		event := l.last()
+		assert.NotNil(t, event)
+		assert.Equal(t, "key", event.Key)
+		assert.Equal(t, "value", event.Value)
+		assert.Equal(t, remoting.EventTypeUpdate, event.ConfigType)
+	}
+
+	c.RemoveListener("key", l1, config_center.WithGroup("group"))
+	assert.True(t, c.cacheListener.DataChange(remoting.Event{Path: path, Action: remoting.EventTypeDel}))
+	assert.Equal(t, remoting.EventTypeUpdate, l1.last().ConfigType)
+	assert.Equal(t, remoting.EventTypeDel, l2.last().ConfigType)
+
+	c.RemoveListener("key", l2, config_center.WithGroup("group"))
+	assert.Empty(t, c.cacheListener.watchers)
+	assert.False(t, c.cacheListener.DataChange(remoting.Event{Path: path, Action: remoting.EventTypeDel}))
+
+	c.AddListener("key", l1)
+	assert.True(t, c.IsAvailable())
+	c.Destroy()
+	assert.False(t, c.IsAvailable())
+	assert.Empty(t, c.cacheListener.watchers)
+}
+
+type mockDataListener struct {
+	lock  sync.Mutex
+	event *config_center.ConfigChangeEvent
+}
+
+func (l *mockDataListener) Process(event *config_center.ConfigChangeEvent) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.event = event
+}
+
+func (l *mockDataListener) last() *config_center.ConfigChangeEvent {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.event
+}
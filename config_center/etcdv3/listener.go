@@ -0,0 +1,118 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package etcdv3
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/config_center"
+	"dubbo.apache.org/dubbo-go/v3/metrics"
+	metricsConfigCenter "dubbo.apache.org/dubbo-go/v3/metrics/config_center"
+	"dubbo.apache.org/dubbo-go/v3/remoting"
+	"dubbo.apache.org/dubbo-go/v3/remoting/etcdv3"
+)
+
+// CacheListener watches the keys which have listeners, and notifies the listeners of the changes of the keys.
+type CacheListener struct {
+	etcdEventListener *etcdv3.EventListener
+	rootPath          string
+
+	lock     sync.Mutex
+	watchers map[string]*keyWatcher
+}
+
+type keyWatcher struct {
+	listeners map[config_center.ConfigurationListener]struct{}
+	cancel    context.CancelFunc
+}
+
+// NewCacheListener creates a new CacheListener
+func NewCacheListener(rootPath string, listener *etcdv3.EventListener) *CacheListener {
+	return &CacheListener{etcdEventListener: listener, rootPath: rootPath, watchers: make(map[string]*keyWatcher)}
+}
+
+// AddListener adds the listener of the key, and starts watching the key if it is not watched yet
+func (l *CacheListener) AddListener(key string, listener config_center.ConfigurationListener) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if w, ok := l.watchers[key]; ok {
+		w.listeners[listener] = struct{}{}
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	l.watchers[key] = &keyWatcher{listeners: map[config_center.ConfigurationListener]struct{}{listener: {}}, cancel: cancel}
+	l.etcdEventListener.ListenConfigurationEvent(ctx, key, l)
+}
+
+// RemoveListener removes the listener of the key, and stops watching the key if it has no listener
+func (l *CacheListener) RemoveListener(key string, listener config_center.ConfigurationListener) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	w, ok := l.watchers[key]
+	if !ok {
+		return
+	}
+	delete(w.listeners, listener)
+	if len(w.listeners) == 0 {
+		w.cancel()
+		delete(l.watchers, key)
+	}
+}
+
+// Close stops watching all the keys
+func (l *CacheListener) Close() {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	for key, w := range l.watchers {
+		w.cancel()
+		delete(l.watchers, key)
+	}
+}
+
+// DataChange notifies the listeners of the key of the event
+func (l *CacheListener) DataChange(event remoting.Event) bool {
+	l.lock.Lock()
+	w, ok := l.watchers[event.Path]
+	if !ok {
+		l.lock.Unlock()
+		return false
+	}
+	listeners := make([]config_center.ConfigurationListener, 0, len(w.listeners))
+	for listener := range w.listeners {
+		listeners = append(listeners, listener)
+	}
+	l.lock.Unlock()
+
+	key, group := l.pathToKeyGroup(event.Path)
+	metrics.Publish(metricsConfigCenter.NewIncMetricEvent(key, group, event.Action, metricsConfigCenter.Etcd))
+	for _, listener := range listeners {
+		listener.Process(&config_center.ConfigChangeEvent{Key: key, Value: event.Content, ConfigType: event.Action})
+	}
+	return true
+}
+
+func (l *CacheListener) pathToKeyGroup(path string) (string, string) {
+	groupKey := strings.TrimPrefix(path, l.rootPath+constant.PathSeparator)
+	group, key, _ := strings.Cut(groupKey, constant.PathSeparator)
+	return key, group
+}
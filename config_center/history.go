@@ -0,0 +1,261 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config_center
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+import (
+	"github.com/dubbogo/gost/log/logger"
+
+	perrors "github.com/pkg/errors"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/remoting"
+)
+
+// RevisionSourceRollback is the source of the revisions applied by rolling back
+const RevisionSourceRollback = "rollback"
+
+// Revision is a version of the dynamic config of a key applied to the listeners
+type Revision struct {
+	Key   string
+	Group string
+	// Version increases from 1 for each key in a group
+	Version int64
+	Content string
+	// Source is the config center the revision comes from, or RevisionSourceRollback
+	Source     string
+	ConfigType remoting.EventType
+	Time       time.Time
+}
+
+// HistoryKey identifies a config by its group and key, the same key in different groups has its own history
+type HistoryKey struct {
+	Group string
+	Key   string
+}
+
+func newHistoryKey(key, group string) HistoryKey {
+	if group == "" {
+		group = DefaultGroup
+	}
+	return HistoryKey{Group: group, Key: key}
+}
+
+// HistoryDynamicConfiguration keeps the history of the dynamic configs applied to the listeners in process, so
+// that what changed can be inspected and a bad change can be rolled back to an earlier revision quickly.
+type HistoryDynamicConfiguration struct {
+	DynamicConfiguration
+	source string
+	size   int
+
+	mu        sync.RWMutex
+	histories map[HistoryKey][]*Revision
+	versions  map[HistoryKey]int64
+	// listeners are the wrapped listeners by config and the listener added
+	listeners map[HistoryKey]map[ConfigurationListener]*historyListener
+}
+
+// NewHistoryDynamicConfiguration wraps the dynamic configuration to keep at most size revisions for each key,
+// the source is the name of the config center recorded in the revisions.
+func NewHistoryDynamicConfiguration(dc DynamicConfiguration, source string, size int) *HistoryDynamicConfiguration {
+	return &HistoryDynamicConfiguration{
+		DynamicConfiguration: dc,
+		source:               source,
+		size:                 size,
+		histories:            make(map[HistoryKey][]*Revision),
+		versions:             make(map[HistoryKey]int64),
+		listeners:            make(map[HistoryKey]map[ConfigurationListener]*historyListener),
+	}
+}
+
+// AddListener adds the listener whose notifications are recorded
+func (h *HistoryDynamicConfiguration) AddListener(key string, listener ConfigurationListener, opts ...Option) {
+	hk := newHistoryKey(key, NewOptions(opts...).Center.Group)
+	wrapped := &historyListener{
+		key:      hk,
+		history:  h,
+		listener: listener,
+	}
+	h.mu.Lock()
+	if h.listeners[hk] == nil {
+		h.listeners[hk] = make(map[ConfigurationListener]*historyListener)
+	}
+	h.listeners[hk][listener] = wrapped
+	h.mu.Unlock()
+	h.DynamicConfiguration.AddListener(key, wrapped, opts...)
+}
+
+// RemoveListener removes the listener added by AddListener
+func (h *HistoryDynamicConfiguration) RemoveListener(key string, listener ConfigurationListener, opts ...Option) {
+	hk := newHistoryKey(key, NewOptions(opts...).Center.Group)
+	h.mu.Lock()
+	wrapped, ok := h.listeners[hk][listener]
+	if ok {
+		delete(h.listeners[hk], listener)
+		if len(h.listeners[hk]) == 0 {
+			delete(h.listeners, hk)
+		}
+	}
+	h.mu.Unlock()
+	if ok {
+		h.DynamicConfiguration.RemoveListener(key, wrapped, opts...)
+		return
+	}
+	h.DynamicConfiguration.RemoveListener(key, listener, opts...)
+}
+
+// GetProperties gets the properties and records them as the first revision of the key
+func (h *HistoryDynamicConfiguration) GetProperties(key string, opts ...Option) (string, error) {
+	content, err := h.DynamicConfiguration.GetProperties(key, opts...)
+	if err == nil && content != "" {
+		h.record(newHistoryKey(key, NewOptions(opts...).Center.Group), content, h.source, remoting.EventTypeAdd)
+	}
+	return content, err
+}
+
+// GetRule gets the rule and records it as the first revision of the key
+func (h *HistoryDynamicConfiguration) GetRule(key string, opts ...Option) (string, error) {
+	content, err := h.DynamicConfiguration.GetRule(key, opts...)
+	if err == nil && content != "" {
+		h.record(newHistoryKey(key, NewOptions(opts...).Center.Group), content, h.source, remoting.EventTypeAdd)
+	}
+	return content, err
+}
+
+// Keys returns the configs having revisions in the order of group and key
+func (h *HistoryDynamicConfiguration) Keys() []HistoryKey {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	keys := make([]HistoryKey, 0, len(h.histories))
+	for key := range h.histories {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Group != keys[j].Group {
+			return keys[i].Group < keys[j].Group
+		}
+		return keys[i].Key < keys[j].Key
+	})
+	return keys
+}
+
+// Revisions returns the revisions of the key in the group kept in the history, from the earliest to the latest.
+// The group is the default one if it is empty.
+func (h *HistoryDynamicConfiguration) Revisions(key, group string) []Revision {
+	hk := newHistoryKey(key, group)
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	revisions := make([]Revision, 0, len(h.histories[hk]))
+	for _, revision := range h.histories[hk] {
+		revisions = append(revisions, *revision)
+	}
+	return revisions
+}
+
+// Rollback applies the content of the revision of the key in the group to the listeners of it again as a new
+// revision. If publish is true, the content is published to the config center as well, so that the other
+// processes are rolled back too. The group is the default one if it is empty.
+func (h *HistoryDynamicConfiguration) Rollback(key, group string, version int64, publish bool) error {
+	hk := newHistoryKey(key, group)
+	h.mu.RLock()
+	var target *Revision
+	for _, revision := range h.histories[hk] {
+		if revision.Version == version {
+			target = revision
+			break
+		}
+	}
+	listeners := make([]*historyListener, 0, len(h.listeners[hk]))
+	for _, listener := range h.listeners[hk] {
+		listeners = append(listeners, listener)
+	}
+	h.mu.RUnlock()
+	if target == nil {
+		return perrors.Errorf("the revision %d of the config %s in the group %s is not found in the history",
+			version, key, hk.Group)
+	}
+
+	configType := remoting.EventTypeUpdate
+	if target.ConfigType == remoting.EventTypeDel {
+		configType = remoting.EventTypeDel
+	}
+	logger.Infof("[Config Center] Roll back the config %s in the group %s to the revision %d", key, hk.Group, version)
+	h.record(hk, target.Content, RevisionSourceRollback, configType)
+	event := &ConfigChangeEvent{Key: key, Value: target.Content, ConfigType: configType}
+	for _, listener := range listeners {
+		listener.listener.Process(event)
+	}
+
+	if !publish {
+		return nil
+	}
+	if configType == remoting.EventTypeDel {
+		return h.DynamicConfiguration.RemoveConfig(key, hk.Group)
+	}
+	return h.DynamicConfiguration.PublishConfig(key, hk.Group, target.Content)
+}
+
+// record appends a revision of the config unless it is the same as the latest one, e.g. the notification of the
+// content published by Rollback
+func (h *HistoryDynamicConfiguration) record(hk HistoryKey, content, source string, configType remoting.EventType) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	revisions := h.histories[hk]
+	if configType != remoting.EventTypeDel {
+		configType = remoting.EventTypeAdd
+		if n := len(revisions); n > 0 && revisions[n-1].ConfigType != remoting.EventTypeDel {
+			configType = remoting.EventTypeUpdate
+		}
+	}
+	if n := len(revisions); n > 0 && revisions[n-1].Content == content && revisions[n-1].ConfigType == configType {
+		return
+	}
+	h.versions[hk]++
+	revisions = append(revisions, &Revision{
+		Key:        hk.Key,
+		Group:      hk.Group,
+		Version:    h.versions[hk],
+		Content:    content,
+		Source:     source,
+		ConfigType: configType,
+		Time:       time.Now(),
+	})
+	if len(revisions) > h.size {
+		revisions = revisions[len(revisions)-h.size:]
+	}
+	h.histories[hk] = revisions
+}
+
+// historyListener records the notifications before passing them to the listener
+type historyListener struct {
+	key      HistoryKey
+	history  *HistoryDynamicConfiguration
+	listener ConfigurationListener
+}
+
+func (l *historyListener) Process(event *ConfigChangeEvent) {
+	content, _ := event.Value.(string)
+	l.history.record(l.key, content, l.history.source, event.ConfigType)
+	l.listener.Process(event)
+}
@@ -0,0 +1,204 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config_center
+
+import (
+	"sync"
+	"testing"
+)
+
+import (
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/remoting"
+)
+
+const historyTestKey = "demo.condition-router"
+
+// historyTestConfiguration notifies the listeners of the published configs like a config center, the configs
+// and the listeners are kept by the group and the key
+type historyTestConfiguration struct {
+	DynamicConfiguration
+	mu        sync.Mutex
+	rules     map[HistoryKey]string
+	listeners map[HistoryKey]ConfigurationListener
+}
+
+func newHistoryTestConfiguration() *historyTestConfiguration {
+	return &historyTestConfiguration{
+		rules:     make(map[HistoryKey]string),
+		listeners: make(map[HistoryKey]ConfigurationListener),
+	}
+}
+
+func historyTestKeyOf(key string, opts ...Option) HistoryKey {
+	return newHistoryKey(key, NewOptions(opts...).Center.Group)
+}
+
+func (c *historyTestConfiguration) AddListener(key string, listener ConfigurationListener, opts ...Option) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.listeners[historyTestKeyOf(key, opts...)] = listener
+}
+
+func (c *historyTestConfiguration) RemoveListener(key string, listener ConfigurationListener, opts ...Option) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	hk := historyTestKeyOf(key, opts...)
+	if c.listeners[hk] == listener {
+		delete(c.listeners, hk)
+	}
+}
+
+func (c *historyTestConfiguration) GetRule(key string, opts ...Option) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.rules[historyTestKeyOf(key, opts...)], nil
+}
+
+func (c *historyTestConfiguration) PublishConfig(key, group, value string) error {
+	c.notifyGroup(key, group, value, remoting.EventTypeUpdate)
+	return nil
+}
+
+func (c *historyTestConfiguration) RemoveConfig(key, group string) error {
+	c.notifyGroup(key, group, "", remoting.EventTypeDel)
+	return nil
+}
+
+// notify notifies the change of the config in the default group
+func (c *historyTestConfiguration) notify(key, value string, configType remoting.EventType) {
+	c.notifyGroup(key, DefaultGroup, value, configType)
+}
+
+func (c *historyTestConfiguration) notifyGroup(key, group, value string, configType remoting.EventType) {
+	hk := newHistoryKey(key, group)
+	c.mu.Lock()
+	c.rules[hk] = value
+	listener := c.listeners[hk]
+	c.mu.Unlock()
+	if listener != nil {
+		listener.Process(&ConfigChangeEvent{Key: key, Value: value, ConfigType: configType})
+	}
+}
+
+type historyTestListener struct {
+	events []*ConfigChangeEvent
+}
+
+func (l *historyTestListener) Process(event *ConfigChangeEvent) {
+	l.events = append(l.events, event)
+}
+
+func versionsOf(revisions []Revision) []int64 {
+	versions := make([]int64, 0, len(revisions))
+	for _, revision := range revisions {
+		versions = append(versions, revision.Version)
+	}
+	return versions
+}
+
+func TestHistoryDynamicConfiguration(t *testing.T) {
+	dc := newHistoryTestConfiguration()
+	dc.rules[newHistoryKey(historyTestKey, "")] = "v1"
+	history := NewHistoryDynamicConfiguration(dc, "zookeeper", 3)
+
+	listener := &historyTestListener{}
+	history.AddListener(historyTestKey, listener, WithGroup("dubbo"))
+	content, err := history.GetRule(historyTestKey, WithGroup("dubbo"))
+	assert.Nil(t, err)
+	assert.Equal(t, "v1", content)
+
+	dc.notify(historyTestKey, "v2", remoting.EventTypeUpdate)
+	// the duplicated notification is not recorded
+	dc.notify(historyTestKey, "v2", remoting.EventTypeUpdate)
+	assert.Len(t, listener.events, 2)
+
+	revisions := history.Revisions(historyTestKey, "")
+	assert.Equal(t, []int64{1, 2}, versionsOf(revisions))
+	assert.Equal(t, "v1", revisions[0].Content)
+	assert.Equal(t, remoting.EventTypeAdd, revisions[0].ConfigType)
+	assert.Equal(t, "dubbo", revisions[0].Group)
+	assert.Equal(t, "zookeeper", revisions[1].Source)
+	assert.Equal(t, remoting.EventTypeUpdate, revisions[1].ConfigType)
+	assert.Equal(t, []HistoryKey{{Group: "dubbo", Key: historyTestKey}}, history.Keys())
+
+	// roll back locally
+	assert.Nil(t, history.Rollback(historyTestKey, "", 1, false))
+	assert.Len(t, listener.events, 3)
+	assert.Equal(t, "v1", listener.events[2].Value)
+	assert.Equal(t, "v2", dc.rules[newHistoryKey(historyTestKey, "")])
+	revisions = history.Revisions(historyTestKey, "")
+	assert.Equal(t, []int64{1, 2, 3}, versionsOf(revisions))
+	assert.Equal(t, RevisionSourceRollback, revisions[2].Source)
+	assert.Equal(t, "v1", revisions[2].Content)
+
+	// roll back and publish, the notification of the published content is not recorded again
+	assert.Nil(t, history.Rollback(historyTestKey, "", 2, true))
+	assert.Equal(t, "v2", dc.rules[newHistoryKey(historyTestKey, "")])
+	assert.Equal(t, "v2", listener.events[len(listener.events)-1].Value)
+	revisions = history.Revisions(historyTestKey, "")
+	assert.Equal(t, []int64{2, 3, 4}, versionsOf(revisions))
+	assert.Equal(t, RevisionSourceRollback, revisions[2].Source)
+
+	dc.notify(historyTestKey, "", remoting.EventTypeDel)
+	revisions = history.Revisions(historyTestKey, "")
+	assert.Equal(t, []int64{3, 4, 5}, versionsOf(revisions))
+	assert.Equal(t, remoting.EventTypeDel, revisions[2].ConfigType)
+
+	// roll back the deletion
+	assert.Nil(t, history.Rollback(historyTestKey, "", 5, true))
+	assert.Equal(t, remoting.EventTypeDel, listener.events[len(listener.events)-1].ConfigType)
+	assert.Equal(t, []int64{3, 4, 5}, versionsOf(history.Revisions(historyTestKey, "")))
+
+	assert.NotNil(t, history.Rollback(historyTestKey, "", 1, false))
+	assert.NotNil(t, history.Rollback("unknown", "", 1, false))
+
+	history.RemoveListener(historyTestKey, listener, WithGroup("dubbo"))
+	assert.Empty(t, dc.listeners)
+}
+
+func TestHistoryDynamicConfigurationGroups(t *testing.T) {
+	dc := newHistoryTestConfiguration()
+	history := NewHistoryDynamicConfiguration(dc, "zookeeper", 3)
+
+	appListener, governanceListener := &historyTestListener{}, &historyTestListener{}
+	history.AddListener(historyTestKey, appListener, WithGroup("app"))
+	history.AddListener(historyTestKey, governanceListener, WithGroup("governance"))
+	dc.notifyGroup(historyTestKey, "app", "app-v1", remoting.EventTypeAdd)
+	dc.notifyGroup(historyTestKey, "governance", "governance-v1", remoting.EventTypeAdd)
+	dc.notifyGroup(historyTestKey, "app", "app-v2", remoting.EventTypeUpdate)
+
+	// the same key in different groups has its own revisions
+	assert.Equal(t, []HistoryKey{{Group: "app", Key: historyTestKey}, {Group: "governance", Key: historyTestKey}},
+		history.Keys())
+	assert.Equal(t, []int64{1, 2}, versionsOf(history.Revisions(historyTestKey, "app")))
+	revisions := history.Revisions(historyTestKey, "governance")
+	assert.Equal(t, []int64{1}, versionsOf(revisions))
+	assert.Equal(t, "governance-v1", revisions[0].Content)
+
+	// the rollback of a group is applied to the listeners of the group only
+	assert.Nil(t, history.Rollback(historyTestKey, "app", 1, true))
+	assert.Equal(t, "app-v1", appListener.events[len(appListener.events)-1].Value)
+	assert.Len(t, governanceListener.events, 1)
+	assert.Equal(t, "app-v1", dc.rules[newHistoryKey(historyTestKey, "app")])
+	assert.Equal(t, "governance-v1", dc.rules[newHistoryKey(historyTestKey, "governance")])
+	assert.NotNil(t, history.Rollback(historyTestKey, "governance", 2, false))
+}
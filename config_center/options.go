@@ -65,6 +65,12 @@ func WithApollo() Option {
 	}
 }
 
+func WithConsul() Option {
+	return func(opts *Options) {
+		opts.Center.Protocol = constant.ConsulKey
+	}
+}
+
 func WithConfigCenter(cc string) Option {
 	return func(opts *Options) {
 		opts.Center.Protocol = cc
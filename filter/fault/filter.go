@@ -0,0 +1,67 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fault
+
+import (
+	"context"
+	"sync"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/common/extension"
+	"dubbo.apache.org/dubbo-go/v3/filter"
+	"dubbo.apache.org/dubbo-go/v3/protocol/base"
+	"dubbo.apache.org/dubbo-go/v3/protocol/result"
+)
+
+var (
+	once     sync.Once
+	instance *faultFilter
+)
+
+func init() {
+	extension.SetFilter(constant.FaultInjectionProviderFilterKey, newFaultFilter)
+}
+
+// faultFilter injects the provider side faults of the rule "{service}.fault-injection" into the requests before
+// they reach the service. The consumer side faults are injected by faultInterceptor.
+type faultFilter struct{}
+
+func newFaultFilter() filter.Filter {
+	once.Do(func() {
+		instance = &faultFilter{}
+	})
+	return instance
+}
+
+// Invoke delays or aborts the invocation if it matches a fault of the rule, and invokes the next one otherwise.
+func (f *faultFilter) Invoke(ctx context.Context, invoker base.Invoker, invocation base.Invocation) result.Result {
+	url := invoker.GetURL()
+	if fault := defaultRuleManager.match(url, constant.SideProvider, invocation); fault != nil {
+		if res := fault.inject(ctx, url, invocation); res != nil {
+			return res
+		}
+	}
+	return invoker.Invoke(ctx, invocation)
+}
+
+// OnResponse dummy process, returns the result directly
+func (f *faultFilter) OnResponse(_ context.Context, result result.Result, _ base.Invoker, _ base.Invocation) result.Result {
+	return result
+}
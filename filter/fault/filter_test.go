@@ -0,0 +1,287 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fault
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/golang/mock/gomock"
+
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	clusterpkg "dubbo.apache.org/dubbo-go/v3/cluster/cluster"
+	_ "dubbo.apache.org/dubbo-go/v3/cluster/cluster/failover"
+	"dubbo.apache.org/dubbo-go/v3/cluster/directory/static"
+	_ "dubbo.apache.org/dubbo-go/v3/cluster/loadbalance/random"
+	"dubbo.apache.org/dubbo-go/v3/common"
+	conf "dubbo.apache.org/dubbo-go/v3/common/config"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/common/extension"
+	"dubbo.apache.org/dubbo-go/v3/config_center"
+	"dubbo.apache.org/dubbo-go/v3/metrics"
+	clusterMetrics "dubbo.apache.org/dubbo-go/v3/metrics/cluster"
+	"dubbo.apache.org/dubbo-go/v3/protocol/base"
+	"dubbo.apache.org/dubbo-go/v3/protocol/invocation"
+	"dubbo.apache.org/dubbo-go/v3/protocol/mock"
+	"dubbo.apache.org/dubbo-go/v3/protocol/result"
+	"dubbo.apache.org/dubbo-go/v3/protocol/triple/triple_protocol"
+	"dubbo.apache.org/dubbo-go/v3/remoting"
+)
+
+const serviceURL = "tri://127.0.0.1:20000/com.ikurento.user.UserProvider?interface=com.ikurento.user.UserProvider" +
+	"&fault-injection.enabled=true"
+
+func newTestInterceptor(url *common.URL) clusterpkg.Interceptor {
+	interceptor := newFaultInterceptor()
+	interceptor.(clusterpkg.InitializableInterceptor).Init(url)
+	return interceptor
+}
+
+func setRule(t *testing.T, url *common.URL, content string) {
+	key := url.ColonSeparatedKey() + constant.FaultInjectionRuleSuffix
+	listener := &ruleListener{key: key, manager: defaultRuleManager}
+	listener.Process(&config_center.ConfigChangeEvent{Key: key, Value: content, ConfigType: remoting.EventTypeUpdate})
+	t.Cleanup(func() {
+		listener.Process(&config_center.ConfigChangeEvent{Key: key, ConfigType: remoting.EventTypeDel})
+	})
+}
+
+func TestParseRule(t *testing.T) {
+	rule, err := parseRule(`
+scope: service
+key: com.ikurento.user.UserProvider
+side: provider
+faults:
+  - methods: [GetUser]
+    percentage: 50
+    delay:
+      fixed: 10ms
+      max: 20ms
+  - abort:
+      status: 31
+`)
+	assert.Nil(t, err)
+	assert.Equal(t, constant.SideProvider, rule.side)
+	assert.Len(t, rule.faults, 2)
+	assert.Equal(t, 0.5, rule.faults[0].ratio)
+	assert.Equal(t, 10*time.Millisecond, rule.faults[0].delay)
+	assert.Equal(t, 20*time.Millisecond, rule.faults[0].maxDelay)
+	assert.Equal(t, 1.0, rule.faults[1].ratio)
+	assert.Contains(t, rule.faults[1].abort.Error(), "SERVER_TIMEOUT")
+	var statusErr *StatusError
+	assert.True(t, errors.As(rule.faults[1].abort, &statusErr))
+	assert.Equal(t, byte(31), statusErr.Status)
+	assert.Equal(t, triple_protocol.CodeDeadlineExceeded, triple_protocol.CodeOf(rule.faults[1].abort))
+
+	rule, err = parseRule(`
+scope: service
+key: com.ikurento.user.UserProvider
+enabled: false
+faults:
+  - abort:
+      code: unavailable
+`)
+	assert.Nil(t, err)
+	assert.Nil(t, rule)
+
+	illegalFaults := []string{
+		"side: server\nfaults:\n  - abort:\n      code: unavailable",
+		"faults:\n  - percentage: 120\n    abort:\n      code: unavailable",
+		"faults:\n  - abort:\n      code: not_a_code",
+		"faults:\n  - abort:\n      code: 17",
+		"faults:\n  - abort:\n      status: 20",
+		"faults:\n  - abort: {}",
+		"faults:\n  - percentage: 10",
+		"faults:\n  - delay:\n      fixed: 1s\n      max: 10ms",
+		"faults:\n  - delay:\n      fixed: -1s",
+	}
+	for _, content := range illegalFaults {
+		_, err = parseRule(content)
+		assert.Error(t, err, content)
+	}
+}
+
+func TestInterceptorAbort(t *testing.T) {
+	url, _ := common.NewURL(serviceURL)
+	setRule(t, url, `
+faults:
+  - methods: [GetUser]
+    attachments:
+      - key: user
+        value:
+          exact: tester
+    abort:
+      code: unavailable
+`)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	invoker := mock.NewMockInvoker(ctrl)
+	invoker.EXPECT().GetURL().Return(url).AnyTimes()
+	invoker.EXPECT().Invoke(gomock.Any(), gomock.Any()).Return(&result.RPCResult{}).Times(2)
+
+	interceptor := newTestInterceptor(url)
+	inv := invocation.NewRPCInvocation("GetUser", nil, map[string]any{"user": "tester"})
+	res := interceptor.Invoke(context.Background(), invoker, inv)
+	assert.Equal(t, triple_protocol.CodeUnavailable, triple_protocol.CodeOf(res.Error()))
+
+	// the attachment mismatches
+	inv = invocation.NewRPCInvocation("GetUser", nil, map[string]any{"user": "someone"})
+	assert.Nil(t, interceptor.Invoke(context.Background(), invoker, inv).Error())
+
+	// the method mismatches
+	inv = invocation.NewRPCInvocation("GetUsers", nil, map[string]any{"user": "tester"})
+	assert.Nil(t, interceptor.Invoke(context.Background(), invoker, inv).Error())
+}
+
+func TestFilterDelay(t *testing.T) {
+	url, _ := common.NewURL(serviceURL)
+	setRule(t, url, `
+faults:
+  - delay:
+      fixed: 50ms
+`)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	invoker := mock.NewMockInvoker(ctrl)
+	invoker.EXPECT().GetURL().Return(url).AnyTimes()
+	invoker.EXPECT().Invoke(gomock.Any(), gomock.Any()).Return(&result.RPCResult{}).Times(1)
+
+	flt := newFaultFilter()
+	start := time.Now()
+	res := flt.Invoke(context.Background(), invoker, invocation.NewRPCInvocation("GetUser", nil, nil))
+	assert.Nil(t, res.Error())
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+
+	// the delay is interrupted by the deadline of the request
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	res = flt.Invoke(ctx, invoker, invocation.NewRPCInvocation("GetUser", nil, nil))
+	assert.ErrorIs(t, res.Error(), context.DeadlineExceeded)
+}
+
+func TestFilterSide(t *testing.T) {
+	url, _ := common.NewURL(serviceURL)
+	setRule(t, url, `
+side: provider
+faults:
+  - abort:
+      code: internal
+`)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	invoker := mock.NewMockInvoker(ctrl)
+	invoker.EXPECT().GetURL().Return(url).AnyTimes()
+	invoker.EXPECT().Invoke(gomock.Any(), gomock.Any()).Return(&result.RPCResult{}).Times(1)
+
+	inv := invocation.NewRPCInvocation("GetUser", nil, nil)
+	assert.Nil(t, newTestInterceptor(url).Invoke(context.Background(), invoker, inv).Error())
+	res := newFaultFilter().Invoke(context.Background(), invoker, inv)
+	assert.Equal(t, triple_protocol.CodeInternal, triple_protocol.CodeOf(res.Error()))
+}
+
+func TestInterceptorSubscribe(t *testing.T) {
+	url, _ := common.NewURL("tri://127.0.0.1:20000/com.ikurento.user.OrderProvider?" +
+		"interface=com.ikurento.user.OrderProvider&fault-injection.enabled=true")
+	defer conf.GetEnvInstance().SetDynamicConfiguration(nil)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	invoker := mock.NewMockInvoker(ctrl)
+	invoker.EXPECT().Invoke(gomock.Any(), gomock.Any()).Return(&result.RPCResult{}).Times(2)
+
+	// the rule is not subscribed before the config center is started
+	conf.GetEnvInstance().SetDynamicConfiguration(nil)
+	interceptor := newTestInterceptor(url)
+	assert.Nil(t, interceptor.Invoke(context.Background(), invoker, invocation.NewRPCInvocation("GetOrder", nil, nil)).Error())
+	defaultRuleManager.mu.RLock()
+	_, subscribed := defaultRuleManager.rules[ruleKey(url)]
+	defaultRuleManager.mu.RUnlock()
+	assert.False(t, subscribed)
+
+	factory := &config_center.MockDynamicConfigurationFactory{Content: "faults:\n  - abort:\n      code: internal"}
+	dc, err := factory.GetDynamicConfiguration(url)
+	assert.Nil(t, err)
+	conf.GetEnvInstance().SetDynamicConfiguration(dc)
+	res := interceptor.Invoke(context.Background(), invoker, invocation.NewRPCInvocation("GetOrder", nil, nil))
+	assert.Equal(t, triple_protocol.CodeInternal, triple_protocol.CodeOf(res.Error()))
+
+	// the fault injection is not enabled by the reference
+	disabledURL := url.Clone()
+	disabledURL.SetParam(constant.FaultInjectionEnabledKey, "false")
+	res = newTestInterceptor(disabledURL).Invoke(context.Background(), invoker,
+		invocation.NewRPCInvocation("GetOrder", nil, nil))
+	assert.Nil(t, res.Error())
+}
+
+func TestInterceptorFailoverCluster(t *testing.T) {
+	url, _ := common.NewURL(serviceURL + "&" + constant.RetriesKey + "=2")
+	setRule(t, url, `
+faults:
+  - abort:
+      code: unavailable
+`)
+	events := make(chan metrics.MetricsEvent, 10)
+	metrics.Subscribe(constant.MetricsCluster, events)
+	defer metrics.Unsubscribe(constant.MetricsCluster)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	var invokers []base.Invoker
+	for i := 0; i < 3; i++ {
+		invoker := mock.NewMockInvoker(ctrl)
+		invoker.EXPECT().GetURL().Return(url).AnyTimes()
+		invoker.EXPECT().IsAvailable().Return(true).AnyTimes()
+		// the aborted request never reaches the providers, so that it is not retried by the cluster
+		invoker.EXPECT().Invoke(gomock.Any(), gomock.Any()).Times(0)
+		invokers = append(invokers, invoker)
+	}
+	failover, err := extension.GetCluster(constant.ClusterKeyFailover)
+	assert.Nil(t, err)
+	clusterInvoker := failover.Join(static.NewDirectory(invokers))
+
+	res := clusterInvoker.Invoke(context.Background(), invocation.NewRPCInvocation("GetUser", nil, nil))
+	assert.Equal(t, triple_protocol.CodeUnavailable, triple_protocol.CodeOf(res.Error()))
+	assert.Len(t, events, 1)
+	event := <-events
+	assert.Equal(t, clusterMetrics.FaultAbort, event.(*clusterMetrics.ClusterMetricsEvent).Name)
+}
+
+func TestRuleListener(t *testing.T) {
+	url, _ := common.NewURL(serviceURL)
+	key := url.ColonSeparatedKey() + constant.FaultInjectionRuleSuffix
+	inv := invocation.NewRPCInvocation("GetUser", nil, nil)
+	listener := &ruleListener{key: key, manager: defaultRuleManager}
+
+	listener.Process(&config_center.ConfigChangeEvent{Key: key, ConfigType: remoting.EventTypeAdd,
+		Value: "faults:\n  - abort:\n      code: internal"})
+	assert.NotNil(t, defaultRuleManager.match(url, constant.SideConsumer, inv))
+
+	// the illegal rule is ignored, and the original one is kept
+	listener.Process(&config_center.ConfigChangeEvent{Key: key, ConfigType: remoting.EventTypeUpdate,
+		Value: "faults:\n  - percentage: 10"})
+	assert.NotNil(t, defaultRuleManager.match(url, constant.SideConsumer, inv))
+
+	listener.Process(&config_center.ConfigChangeEvent{Key: key, ConfigType: remoting.EventTypeDel})
+	assert.Nil(t, defaultRuleManager.match(url, constant.SideConsumer, inv))
+}
@@ -0,0 +1,82 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fault
+
+import (
+	"context"
+)
+
+import (
+	clusterpkg "dubbo.apache.org/dubbo-go/v3/cluster/cluster"
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/protocol/base"
+	"dubbo.apache.org/dubbo-go/v3/protocol/result"
+)
+
+// injectedAttributeKey marks the invocation whose consumer side faults have been checked, so that the faults are
+// not injected again by the nested cluster invokers, e.g. the ones of the zone-aware cluster for multiple registries.
+const injectedAttributeKey = "dubbo-go.fault.injected"
+
+func init() {
+	clusterpkg.SetClusterInterceptor(constant.FaultInjectionInterceptorKey, newFaultInterceptor)
+}
+
+// faultInterceptor injects the consumer side faults of the rule "{service}.fault-injection" in front of the cluster
+// invoker, so that an aborted request is returned to the caller once instead of being retried by the cluster.
+// It is enabled by the "fault-injection.enabled" parameter of the reference.
+type faultInterceptor struct {
+	// url is the reference url, it is nil if the fault injection is disabled
+	url *common.URL
+}
+
+func newFaultInterceptor() clusterpkg.Interceptor {
+	return &faultInterceptor{}
+}
+
+// Init subscribes the rule of the reference if the fault injection is enabled.
+func (i *faultInterceptor) Init(url *common.URL) {
+	// the cluster invoker of the registry directory has the registry url, the reference url is the sub url of it
+	if url.SubURL != nil {
+		url = url.SubURL
+	}
+	if !url.GetParamBool(constant.FaultInjectionEnabledKey, false) {
+		return
+	}
+	i.url = url
+	defaultRuleManager.subscribe(url)
+}
+
+// Invoke delays or aborts the invocation if it matches a fault of the rule, and invokes the cluster invoker otherwise.
+func (i *faultInterceptor) Invoke(ctx context.Context, invoker base.Invoker, invocation base.Invocation) result.Result {
+	if i.url == nil {
+		return invoker.Invoke(ctx, invocation)
+	}
+	if _, ok := invocation.GetAttribute(injectedAttributeKey); ok {
+		return invoker.Invoke(ctx, invocation)
+	}
+	invocation.SetAttribute(injectedAttributeKey, true)
+
+	url := i.url
+	if fault := defaultRuleManager.match(url, constant.SideConsumer, invocation); fault != nil {
+		if res := fault.inject(ctx, url, invocation); res != nil {
+			return res
+		}
+	}
+	return invoker.Invoke(ctx, invocation)
+}
@@ -0,0 +1,387 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fault
+
+import (
+	"context"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+import (
+	"github.com/dubbogo/gost/log/logger"
+
+	perrors "github.com/pkg/errors"
+
+	"gopkg.in/yaml.v2"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+	conf "dubbo.apache.org/dubbo-go/v3/common/config"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/config"
+	"dubbo.apache.org/dubbo-go/v3/config_center"
+	"dubbo.apache.org/dubbo-go/v3/metrics"
+	clusterMetrics "dubbo.apache.org/dubbo-go/v3/metrics/cluster"
+	"dubbo.apache.org/dubbo-go/v3/protocol/base"
+	"dubbo.apache.org/dubbo-go/v3/protocol/dubbo/hessian2"
+	"dubbo.apache.org/dubbo-go/v3/protocol/result"
+	"dubbo.apache.org/dubbo-go/v3/protocol/triple/triple_protocol"
+	"dubbo.apache.org/dubbo-go/v3/remoting"
+)
+
+type dubboStatus struct {
+	name string
+	// code is the triple error code of the same meaning, which is used by the retry policies
+	code triple_protocol.Code
+}
+
+// dubboStatuses are the dubbo response statuses which could be used to abort the requests
+var dubboStatuses = map[byte]dubboStatus{
+	hessian2.Response_CLIENT_TIMEOUT:    {"CLIENT_TIMEOUT", triple_protocol.CodeDeadlineExceeded},
+	hessian2.Response_SERVER_TIMEOUT:    {"SERVER_TIMEOUT", triple_protocol.CodeDeadlineExceeded},
+	hessian2.Response_BAD_REQUEST:       {"BAD_REQUEST", triple_protocol.CodeInvalidArgument},
+	hessian2.Response_BAD_RESPONSE:      {"BAD_RESPONSE", triple_protocol.CodeInternal},
+	hessian2.Response_SERVICE_NOT_FOUND: {"SERVICE_NOT_FOUND", triple_protocol.CodeUnimplemented},
+	hessian2.Response_SERVICE_ERROR:     {"SERVICE_ERROR", triple_protocol.CodeInternal},
+	hessian2.Response_SERVER_ERROR:      {"SERVER_ERROR", triple_protocol.CodeInternal},
+	hessian2.Response_CLIENT_ERROR:      {"CLIENT_ERROR", triple_protocol.CodeUnavailable},
+}
+
+// StatusError is the error of the requests aborted with a dubbo response status. It wraps the triple error of the
+// same meaning, so that triple_protocol.CodeOf returns the code of it.
+type StatusError struct {
+	Status byte
+	err    *triple_protocol.Error
+}
+
+func newStatusError(status byte) (*StatusError, bool) {
+	s, ok := dubboStatuses[status]
+	if !ok {
+		return nil, false
+	}
+	return &StatusError{
+		Status: status,
+		err:    triple_protocol.NewError(s.code, perrors.Errorf("fault injected, dubbo status %d(%s)", status, s.name)),
+	}, true
+}
+
+func (e *StatusError) Error() string {
+	return e.err.Message()
+}
+
+func (e *StatusError) Unwrap() error {
+	return e.err
+}
+
+var defaultRuleManager = newRuleManager()
+
+// ruleManager subscribes the fault injection rules of the services from the config center, e.g.
+//
+//	scope: service
+//	key: org.apache.dubbo.samples.UserProvider
+//	enabled: true
+//	side: consumer
+//	faults:
+//	  - methods: [GetUser]
+//	    attachments:
+//	      - key: user
+//	        value:
+//	          exact: tester
+//	    percentage: 50
+//	    delay:
+//	      fixed: 100ms
+//	      max: 300ms
+//	    abort:
+//	      code: unavailable
+type ruleManager struct {
+	mu sync.RWMutex
+	// rules is the rule of the subscribed keys, the value is nil if there is no rule of the key. The key is not
+	// subscribed until the config center is started.
+	rules map[string]*faultRule
+}
+
+type faultRule struct {
+	side   string
+	faults []*fault
+}
+
+type fault struct {
+	methods     []string
+	attachments []*common.ParamMatch
+	ratio       float64
+	delay       time.Duration
+	maxDelay    time.Duration
+	abort       error
+}
+
+func newRuleManager() *ruleManager {
+	return &ruleManager{rules: make(map[string]*faultRule)}
+}
+
+// match returns the first fault of the rule which matches the invocation of the side, and nil if there is none.
+func (m *ruleManager) match(url *common.URL, side string, invocation base.Invocation) *fault {
+	rule := m.getRule(url)
+	if rule == nil || (rule.side != "" && rule.side != side) {
+		return nil
+	}
+	for _, f := range rule.faults {
+		if f.isMatch(invocation) {
+			return f
+		}
+	}
+	return nil
+}
+
+// getRule returns the rule of the url, the rule is subscribed first if it is not, e.g. the config center was not
+// started when the reference was set up.
+func (m *ruleManager) getRule(url *common.URL) *faultRule {
+	key := ruleKey(url)
+	m.mu.RLock()
+	rule, subscribed := m.rules[key]
+	m.mu.RUnlock()
+	if !subscribed {
+		m.subscribe(url)
+		m.mu.RLock()
+		rule = m.rules[key]
+		m.mu.RUnlock()
+	}
+	return rule
+}
+
+// subscribe subscribes the rule of the url from the config center, it does nothing if the config center is not
+// started, so that the rule is subscribed again later.
+func (m *ruleManager) subscribe(url *common.URL) {
+	dynamicConfiguration := conf.GetEnvInstance().GetDynamicConfiguration()
+	if dynamicConfiguration == nil {
+		return
+	}
+	key := ruleKey(url)
+	m.mu.Lock()
+	if _, ok := m.rules[key]; ok {
+		m.mu.Unlock()
+		return
+	}
+	m.rules[key] = nil
+	m.mu.Unlock()
+
+	listener := &ruleListener{key: key, manager: m}
+	dynamicConfiguration.AddListener(key, listener)
+	value, err := dynamicConfiguration.GetRule(key)
+	if err != nil {
+		logger.Errorf("Failed to query fault injection rule, key=%s, err=%v", key, err)
+		return
+	}
+	if value == "" {
+		return
+	}
+	listener.Process(&config_center.ConfigChangeEvent{Key: key, Value: value, ConfigType: remoting.EventTypeAdd})
+}
+
+func ruleKey(url *common.URL) string {
+	return url.ColonSeparatedKey() + constant.FaultInjectionRuleSuffix
+}
+
+func (m *ruleManager) setRule(key string, rule *faultRule) {
+	m.mu.Lock()
+	m.rules[key] = rule
+	m.mu.Unlock()
+}
+
+// ruleListener reloads the fault injection rule of the key when it is changed in the config center
+type ruleListener struct {
+	key     string
+	manager *ruleManager
+}
+
+func (l *ruleListener) Process(event *config_center.ConfigChangeEvent) {
+	if event.ConfigType == remoting.EventTypeDel {
+		l.manager.setRule(l.key, nil)
+		logger.Infof("[fault injection] The fault injection rule is removed, key=%s", l.key)
+		return
+	}
+	rule, err := parseRule(event.Value.(string))
+	if err != nil {
+		logger.Warnf("[fault injection] Failed to parse fault injection rule, key=%s, err=%v, "+
+			"and we will use the original rule.", l.key, err)
+		return
+	}
+	l.manager.setRule(l.key, rule)
+	logger.Infof("[fault injection] Parse fault injection rule success, key=%s", l.key)
+}
+
+// parseRule parses the fault injection rule, it returns nil if the rule is disabled.
+func parseRule(content string) (*faultRule, error) {
+	cfg := config.FaultInjectionConfig{Enabled: true}
+	if err := yaml.Unmarshal([]byte(content), &cfg); err != nil {
+		return nil, err
+	}
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	side := strings.TrimSpace(cfg.Side)
+	if side != "" && side != constant.SideConsumer && side != constant.SideProvider {
+		return nil, perrors.Errorf("side=%s, expect %s or %s", side, constant.SideConsumer, constant.SideProvider)
+	}
+	rule := &faultRule{side: side, faults: make([]*fault, 0, len(cfg.Faults))}
+	for i, fc := range cfg.Faults {
+		if fc == nil {
+			continue
+		}
+		f, err := newFault(fc)
+		if err != nil {
+			return nil, perrors.WithMessagef(err, "faults[%d]", i)
+		}
+		rule.faults = append(rule.faults, f)
+	}
+	return rule, nil
+}
+
+func newFault(fc *config.FaultRule) (*fault, error) {
+	percentage := fc.Percentage
+	if percentage == 0 {
+		percentage = 100
+	}
+	if percentage < 0 || percentage > 100 {
+		return nil, perrors.Errorf("percentage=%v, expect 0-100", fc.Percentage)
+	}
+	f := &fault{
+		methods:     fc.Methods,
+		attachments: fc.Attachments,
+		ratio:       percentage / 100,
+	}
+	if fc.Delay != nil {
+		var err error
+		if f.delay, err = parseDuration(fc.Delay.Fixed); err != nil {
+			return nil, perrors.WithMessage(err, "delay.fixed")
+		}
+		if f.maxDelay, err = parseDuration(fc.Delay.Max); err != nil {
+			return nil, perrors.WithMessage(err, "delay.max")
+		}
+		if f.maxDelay != 0 && f.maxDelay < f.delay {
+			return nil, perrors.Errorf("delay.max=%s is less than delay.fixed=%s", fc.Delay.Max, fc.Delay.Fixed)
+		}
+	}
+	if fc.Abort != nil {
+		abort, err := newAbortError(fc.Abort)
+		if err != nil {
+			return nil, err
+		}
+		f.abort = abort
+	}
+	if f.delay == 0 && f.maxDelay == 0 && f.abort == nil {
+		return nil, perrors.New("neither delay nor abort is set")
+	}
+	return f, nil
+}
+
+func parseDuration(s string) (time.Duration, error) {
+	if s = strings.TrimSpace(s); s == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if d < 0 {
+		return 0, perrors.Errorf("%s is negative", s)
+	}
+	return d, nil
+}
+
+// newAbortError returns the triple error of the code, or the StatusError of the dubbo status.
+func newAbortError(ac *config.FaultAbort) (error, error) {
+	if code := strings.TrimSpace(ac.Code); code != "" {
+		var c triple_protocol.Code
+		if n, err := strconv.Atoi(code); err == nil {
+			c = triple_protocol.Code(n)
+		} else if err = c.UnmarshalText([]byte(code)); err != nil {
+			return nil, perrors.Errorf("abort.code=%s is not a triple error code", code)
+		}
+		if c < triple_protocol.CodeCanceled || c > triple_protocol.CodeUnauthenticated {
+			return nil, perrors.Errorf("abort.code=%s is not a triple error code", code)
+		}
+		return triple_protocol.NewError(c, perrors.New("fault injected")), nil
+	}
+	if ac.Status != 0 {
+		if ac.Status > 0 && ac.Status <= 0xff {
+			if err, ok := newStatusError(byte(ac.Status)); ok {
+				return err, nil
+			}
+		}
+		return nil, perrors.Errorf("abort.status=%d is not a dubbo response status", ac.Status)
+	}
+	return nil, perrors.New("neither abort.code nor abort.status is set")
+}
+
+func (f *fault) isMatch(invocation base.Invocation) bool {
+	if len(f.methods) > 0 {
+		method := invocation.ActualMethodName()
+		matched := false
+		for _, m := range f.methods {
+			if m == constant.AnyValue || m == method {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	for _, am := range f.attachments {
+		if am == nil {
+			continue
+		}
+		if !am.Value.IsMatch(invocation.GetAttachmentWithDefaultValue(am.Key, "")) {
+			return false
+		}
+	}
+	return f.ratio >= 1 || rand.Float64() < f.ratio
+}
+
+// inject delays the invocation and returns the aborted result, it returns nil if the invocation is not aborted.
+func (f *fault) inject(ctx context.Context, url *common.URL, invocation base.Invocation) result.Result {
+	method := invocation.ActualMethodName()
+	if delay := f.nextDelay(); delay > 0 {
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return &result.RPCResult{Err: ctx.Err()}
+		}
+		metrics.Publish(clusterMetrics.NewFaultDelayEvent(url.Interface(), method, url.Group(), url.Version(),
+			float64(delay)/float64(time.Millisecond)))
+	}
+	if f.abort == nil {
+		return nil
+	}
+	metrics.Publish(clusterMetrics.NewFaultAbortEvent(url.Interface(), method, url.Group(), url.Version()))
+	return &result.RPCResult{Err: f.abort}
+}
+
+func (f *fault) nextDelay() time.Duration {
+	if f.maxDelay <= f.delay {
+		return f.delay
+	}
+	return f.delay + time.Duration(rand.Int63n(int64(f.maxDelay-f.delay)+1))
+}
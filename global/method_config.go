@@ -32,6 +32,10 @@ type MethodConfig struct {
 	ExecuteLimitRejectedHandler string `yaml:"execute.limit.rejected.handler" json:"execute.limit.rejected.handler,omitempty" property:"execute.limit.rejected.handler"`
 	Sticky                      bool   `yaml:"sticky"   json:"sticky,omitempty" property:"sticky"`
 	RequestTimeout              string `yaml:"timeout"  json:"timeout,omitempty" property:"timeout"`
+	RetryBudget                 string `yaml:"retry-budget" json:"retry-budget,omitempty" property:"retry-budget"`
+	RetryBackoff                string `yaml:"retry-backoff" json:"retry-backoff,omitempty" property:"retry-backoff"`
+	RetryMaxBackoff             string `yaml:"retry-max-backoff" json:"retry-max-backoff,omitempty" property:"retry-max-backoff"`
+	RetryableCodes              string `yaml:"retryable-codes" json:"retryable-codes,omitempty" property:"retryable-codes"`
 }
 
 // Clone a new MethodConfig
@@ -54,5 +58,9 @@ func (c *MethodConfig) Clone() *MethodConfig {
 		ExecuteLimitRejectedHandler: c.ExecuteLimitRejectedHandler,
 		Sticky:                      c.Sticky,
 		RequestTimeout:              c.RequestTimeout,
+		RetryBudget:                 c.RetryBudget,
+		RetryBackoff:                c.RetryBackoff,
+		RetryMaxBackoff:             c.RetryMaxBackoff,
+		RetryableCodes:              c.RetryableCodes,
 	}
 }
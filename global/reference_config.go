@@ -44,6 +44,10 @@ type ReferenceConfig struct {
 	ForceTag         bool              `yaml:"force.tag"  json:"force.tag,omitempty" property:"force.tag"`
 	TracingKey       string            `yaml:"tracing-key" json:"tracing-key,omitempty" property:"tracing-key"`
 	MeshProviderPort int               `yaml:"mesh-provider-port" json:"mesh-provider-port,omitempty" property:"mesh-provider-port"`
+	RetryBudget      string            `yaml:"retry-budget" json:"retry-budget,omitempty" property:"retry-budget"`
+	RetryBackoff     string            `yaml:"retry-backoff" json:"retry-backoff,omitempty" property:"retry-backoff"`
+	RetryMaxBackoff  string            `yaml:"retry-max-backoff" json:"retry-max-backoff,omitempty" property:"retry-max-backoff"`
+	RetryableCodes   string            `yaml:"retryable-codes" json:"retryable-codes,omitempty" property:"retryable-codes"`
 
 	// config
 	MethodsConfig []*MethodConfig `yaml:"methods"  json:"methods,omitempty" property:"methods"`
@@ -136,6 +140,15 @@ func (c *ReferenceConfig) GetOptions() []ReferenceOption {
 	if c.MeshProviderPort != 0 {
 		refOpts = append(refOpts, WithReference_MeshProviderPort(c.MeshProviderPort))
 	}
+	if c.RetryBudget != "" {
+		refOpts = append(refOpts, WithReference_RetryBudget(c.RetryBudget))
+	}
+	if c.RetryBackoff != "" || c.RetryMaxBackoff != "" {
+		refOpts = append(refOpts, WithReference_RetryBackoff(c.RetryBackoff, c.RetryMaxBackoff))
+	}
+	if c.RetryableCodes != "" {
+		refOpts = append(refOpts, WithReference_RetryableCodes(c.RetryableCodes))
+	}
 	if c.KeepAliveInterval != "" {
 		refOpts = append(refOpts, WithReference_KeepAliveInterval(c.KeepAliveInterval))
 	}
@@ -200,6 +213,10 @@ func (c *ReferenceConfig) Clone() *ReferenceConfig {
 		ForceTag:             c.ForceTag,
 		TracingKey:           c.TracingKey,
 		MeshProviderPort:     c.MeshProviderPort,
+		RetryBudget:          c.RetryBudget,
+		RetryBackoff:         c.RetryBackoff,
+		RetryMaxBackoff:      c.RetryMaxBackoff,
+		RetryableCodes:       c.RetryableCodes,
 		KeepAliveInterval:    c.KeepAliveInterval,
 		KeepAliveTimeout:     c.KeepAliveTimeout,
 		IDLMode:              c.IDLMode,
@@ -336,6 +353,25 @@ func WithReference_MeshProviderPort(port int) ReferenceOption {
 	}
 }
 
+func WithReference_RetryBudget(budget string) ReferenceOption {
+	return func(cfg *ReferenceConfig) {
+		cfg.RetryBudget = budget
+	}
+}
+
+func WithReference_RetryBackoff(backoff, maxBackoff string) ReferenceOption {
+	return func(cfg *ReferenceConfig) {
+		cfg.RetryBackoff = backoff
+		cfg.RetryMaxBackoff = maxBackoff
+	}
+}
+
+func WithReference_RetryableCodes(codes string) ReferenceOption {
+	return func(cfg *ReferenceConfig) {
+		cfg.RetryableCodes = codes
+	}
+}
+
 func WithReference_KeepAliveInterval(interval string) ReferenceOption {
 	return func(cfg *ReferenceConfig) {
 		cfg.KeepAliveInterval = interval
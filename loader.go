@@ -39,6 +39,7 @@ import (
 import (
 	"dubbo.apache.org/dubbo-go/v3/common/constant"
 	"dubbo.apache.org/dubbo-go/v3/common/constant/file"
+	"dubbo.apache.org/dubbo-go/v3/config/secret"
 )
 
 var (
@@ -293,6 +294,9 @@ func GetConfigResolver(conf *loaderConf) *koanf.Koanf {
 	if err != nil {
 		panic(err)
 	}
+	if k, err = secret.Resolve(k); err != nil {
+		panic(err)
+	}
 	return resolvePlaceholder(k)
 }
 
@@ -54,6 +54,8 @@ var IncludeKeys = gxset.NewSet(
 	constant.TokenKey,
 	constant.VersionKey,
 	constant.WarmupKey,
+	constant.WarmupCurveKey,
+	constant.WarmupMinWeightPercentKey,
 	constant.WeightKey,
 	constant.ReleaseKey)
 
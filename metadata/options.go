@@ -213,6 +213,12 @@ func WithEtcdV3() ReportOption {
 	}
 }
 
+func WithConsul() ReportOption {
+	return func(opts *ReportOptions) {
+		opts.Protocol = constant.ConsulKey
+	}
+}
+
 func WithProtocol(meta string) ReportOption {
 	return func(opts *ReportOptions) {
 		opts.Protocol = meta
@@ -0,0 +1,225 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package consul
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+)
+
+import (
+	gxset "github.com/dubbogo/gost/container/set"
+	"github.com/dubbogo/gost/log/logger"
+
+	"github.com/hashicorp/consul/api"
+
+	perrors "github.com/pkg/errors"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/common/extension"
+	"dubbo.apache.org/dubbo-go/v3/metadata/info"
+	"dubbo.apache.org/dubbo-go/v3/metadata/mapping"
+	"dubbo.apache.org/dubbo-go/v3/metadata/report"
+	"dubbo.apache.org/dubbo-go/v3/registry"
+	"dubbo.apache.org/dubbo-go/v3/remoting/consul"
+)
+
+const (
+	DEFAULT_ROOT = "dubbo"
+	// maxCASRetries is the max times of retrying to update the mapping when it is updated concurrently
+	maxCASRetries = 5
+)
+
+func init() {
+	extension.SetMetadataReportFactory(constant.ConsulKey, func() report.MetadataReportFactory {
+		return &consulMetadataReportFactory{}
+	})
+}
+
+// consulMetadataReport is the implementation of MetadataReport based on the KV store of consul
+type consulMetadataReport struct {
+	client  *api.Client
+	rootDir string
+
+	lock sync.Mutex
+	// watchers is keyed by the path of the mapping, the value stops the watcher
+	watchers map[string]context.CancelFunc
+}
+
+// GetAppMetadata get metadata info from consul
+func (c *consulMetadataReport) GetAppMetadata(application, revision string) (*info.MetadataInfo, error) {
+	key := c.rootDir + application + constant.PathSeparator + revision
+	pair, _, err := c.client.KV().Get(key, nil)
+	if err != nil {
+		return nil, err
+	}
+	if pair == nil {
+		return nil, perrors.Errorf("the metadata %s is not found", key)
+	}
+	meta := &info.MetadataInfo{}
+	return meta, json.Unmarshal(pair.Value, meta)
+}
+
+// PublishAppMetadata publish metadata info to consul
+func (c *consulMetadataReport) PublishAppMetadata(application, revision string, info *info.MetadataInfo) error {
+	key := c.rootDir + application + constant.PathSeparator + revision
+	value, err := json.Marshal(info)
+	if err == nil {
+		_, err = c.client.KV().Put(&api.KVPair{Key: key, Value: value}, nil)
+	}
+	return err
+}
+
+// RegisterServiceAppMapping map the specified Dubbo service interface to current Dubbo app name, the mapping
+// is updated by check-and-set, so the apps registered concurrently are not lost
+func (c *consulMetadataReport) RegisterServiceAppMapping(key string, group string, value string) error {
+	path := c.mappingPath(key, group)
+	for i := 0; i < maxCASRetries; i++ {
+		pair, _, err := c.client.KV().Get(path, nil)
+		if err != nil {
+			return err
+		}
+		var (
+			newVal      = value
+			modifyIndex uint64
+		)
+		if pair != nil {
+			apps := toSet(string(pair.Value))
+			if apps.Contains(value) {
+				return nil
+			}
+			newVal = string(pair.Value) + constant.CommaSeparator + value
+			modifyIndex = pair.ModifyIndex
+		}
+		ok, _, err := c.client.KV().CAS(&api.KVPair{Key: path, Value: []byte(newVal), ModifyIndex: modifyIndex}, nil)
+		if err != nil || ok {
+			return err
+		}
+	}
+	return perrors.Errorf("failed to register the mapping %s, it is modified concurrently", path)
+}
+
+// GetServiceAppMapping get the app names from the specified Dubbo service interface, and watches the mapping
+// if the listener is not nil
+func (c *consulMetadataReport) GetServiceAppMapping(key string, group string, listener mapping.MappingListener) (*gxset.HashSet, error) {
+	path := c.mappingPath(key, group)
+	pair, meta, err := c.client.KV().Get(path, nil)
+	if err != nil {
+		return nil, err
+	}
+	if pair == nil {
+		return nil, perrors.Errorf("the mapping %s is not found", path)
+	}
+	apps := toSet(string(pair.Value))
+	if listener != nil {
+		c.watch(key, path, meta.LastIndex, apps, listener)
+	}
+	return apps, nil
+}
+
+// RemoveServiceAppMappingListener stops watching the mapping
+func (c *consulMetadataReport) RemoveServiceAppMappingListener(key string, group string) error {
+	path := c.mappingPath(key, group)
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if cancel, ok := c.watchers[path]; ok {
+		cancel()
+		delete(c.watchers, path)
+	}
+	return nil
+}
+
+// watch notifies the listener of the apps once the mapping is changed since the index
+func (c *consulMetadataReport) watch(key, path string, index uint64, apps *gxset.HashSet, listener mapping.MappingListener) {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.lock.Lock()
+	if old, ok := c.watchers[path]; ok {
+		old()
+	}
+	c.watchers[path] = cancel
+	c.lock.Unlock()
+
+	go consul.Watch(ctx, func(ctx context.Context, lastIndex uint64) (uint64, error) {
+		if lastIndex == 0 {
+			// the watch starts with the index of the result returned by GetServiceAppMapping
+			lastIndex = index
+		}
+		pair, meta, err := c.client.KV().Get(path, consul.QueryOptions(ctx, lastIndex))
+		if err != nil {
+			return 0, err
+		}
+		newIndex := meta.LastIndex
+		if newIndex == lastIndex || pair == nil {
+			return newIndex, nil
+		}
+		newApps := toSet(string(pair.Value))
+		if equals(apps, newApps) {
+			return newIndex, nil
+		}
+		apps = newApps
+		if err = listener.OnEvent(registry.NewServiceMappingChangedEvent(key, newApps)); err != nil {
+			logger.Errorf("[consul] Failed to notify the mapping of %s, err: %v", key, err)
+		}
+		return newIndex, nil
+	})
+}
+
+func (c *consulMetadataReport) mappingPath(key, group string) string {
+	return c.rootDir + group + constant.PathSeparator + key
+}
+
+func toSet(value string) *gxset.HashSet {
+	set := gxset.NewSet()
+	for _, app := range strings.Split(value, constant.CommaSeparator) {
+		if app = strings.TrimSpace(app); app != "" {
+			set.Add(app)
+		}
+	}
+	return set
+}
+
+func equals(a, b *gxset.HashSet) bool {
+	if a.Size() != b.Size() {
+		return false
+	}
+	for _, v := range a.Values() {
+		if !b.Contains(v) {
+			return false
+		}
+	}
+	return true
+}
+
+type consulMetadataReportFactory struct{}
+
+// CreateMetadataReport get the MetadataReport instance of consul
+func (c *consulMetadataReportFactory) CreateMetadataReport(url *common.URL) report.MetadataReport {
+	client, err := consul.NewClientByURL(url)
+	if err != nil {
+		logger.Errorf("Could not create consul metadata report. URL: %s,error:{%v}", url.String(), err)
+		return nil
+	}
+	group := url.GetParam(constant.MetadataReportGroupKey, DEFAULT_ROOT)
+	group = strings.Trim(group, constant.PathSeparator) + constant.PathSeparator
+	return &consulMetadataReport{client: client, rootDir: group, watchers: make(map[string]context.CancelFunc)}
+}
@@ -0,0 +1,112 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package consul
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+import (
+	gxset "github.com/dubbogo/gost/container/set"
+	"github.com/dubbogo/gost/gof/observer"
+
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/metadata/info"
+	"dubbo.apache.org/dubbo-go/v3/registry"
+	"dubbo.apache.org/dubbo-go/v3/remoting/consul/fake"
+)
+
+func newTestReport(t *testing.T, server *fake.Server) *consulMetadataReport {
+	url, err := common.NewURL("consul://" + server.Address() + "?consul.wait-time=1s")
+	assert.Nil(t, err)
+	report := (&consulMetadataReportFactory{}).CreateMetadataReport(url)
+	assert.NotNil(t, report)
+	return report.(*consulMetadataReport)
+}
+
+func TestAppMetadata(t *testing.T) {
+	server := fake.NewServer()
+	defer server.Close()
+	report := newTestReport(t, server)
+
+	_, err := report.GetAppMetadata("app", "1")
+	assert.NotNil(t, err)
+	assert.Nil(t, report.PublishAppMetadata("app", "1", info.NewAppMetadataInfo("app")))
+	meta, err := report.GetAppMetadata("app", "1")
+	assert.Nil(t, err)
+	assert.Equal(t, "app", meta.App)
+}
+
+func TestServiceAppMapping(t *testing.T) {
+	server := fake.NewServer()
+	defer server.Close()
+	report := newTestReport(t, server)
+
+	_, err := report.GetServiceAppMapping("org.apache.dubbo.Greeter", "mapping", nil)
+	assert.NotNil(t, err)
+
+	var wg sync.WaitGroup
+	for _, app := range []string{"app1", "app2", "app1"} {
+		wg.Add(1)
+		go func(app string) {
+			defer wg.Done()
+			assert.Nil(t, report.RegisterServiceAppMapping("org.apache.dubbo.Greeter", "mapping", app))
+		}(app)
+	}
+	wg.Wait()
+
+	listener := &mockMappingListener{}
+	apps, err := report.GetServiceAppMapping("org.apache.dubbo.Greeter", "mapping", listener)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, apps.Size())
+
+	assert.Nil(t, report.RegisterServiceAppMapping("org.apache.dubbo.Greeter", "mapping", "app3"))
+	assert.Eventually(t, func() bool {
+		apps := listener.last()
+		return apps != nil && apps.Size() == 3 && apps.Contains("app3")
+	}, time.Second, 10*time.Millisecond)
+
+	assert.Nil(t, report.RemoveServiceAppMappingListener("org.apache.dubbo.Greeter", "mapping"))
+	assert.Empty(t, report.watchers)
+}
+
+type mockMappingListener struct {
+	lock sync.Mutex
+	apps *gxset.HashSet
+}
+
+func (m *mockMappingListener) OnEvent(e observer.Event) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.apps = e.(*registry.ServiceMappingChangeEvent).GetServiceNames()
+	return nil
+}
+
+func (m *mockMappingListener) Stop() {}
+
+func (m *mockMappingListener) last() *gxset.HashSet {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.apps
+}
@@ -0,0 +1,365 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+import (
+	gxset "github.com/dubbogo/gost/container/set"
+	"github.com/dubbogo/gost/log/logger"
+
+	perrors "github.com/pkg/errors"
+
+	corev1 "k8s.io/api/core/v1"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/common/extension"
+	"dubbo.apache.org/dubbo-go/v3/metadata/info"
+	"dubbo.apache.org/dubbo-go/v3/metadata/mapping"
+	"dubbo.apache.org/dubbo-go/v3/metadata/report"
+	"dubbo.apache.org/dubbo-go/v3/registry"
+	"dubbo.apache.org/dubbo-go/v3/remoting/kubernetes"
+)
+
+const (
+	metadataPrefix = "dubbo-metadata-"
+	mappingPrefix  = "dubbo-mapping-"
+	defaultGroup   = "mapping"
+	// metadataKey is the key of the metadata in the ConfigMap of a revision
+	metadataKey = "metadata"
+	// maxConflictRetries is the max times of retrying to update the mapping when it is updated concurrently
+	maxConflictRetries = 5
+	syncTimeout        = 3 * time.Second
+)
+
+// illegalNameChars matches the chars which are not allowed in the names of Kubernetes resources
+var illegalNameChars = regexp.MustCompile(`[^a-z0-9.-]+`)
+
+func init() {
+	extension.SetMetadataReportFactory(constant.KubernetesKey, func() report.MetadataReportFactory {
+		return &kubernetesMetadataReportFactory{}
+	})
+}
+
+// kubernetesMetadataReport is the implementation of MetadataReport based on the ConfigMaps of Kubernetes.
+// The metadata of a revision of an application is stored in the ConfigMap dubbo-metadata-{application}.{revision},
+// so that the size of a ConfigMap doesn't grow with the revisions, and the mapping of a group is stored in the
+// ConfigMap dubbo-mapping-{group} keyed by the encoded interface name.
+type kubernetesMetadataReport struct {
+	client *kubernetes.Client
+
+	lock      sync.Mutex
+	informers map[string]*kubernetes.Informer
+	// listeners is keyed by the ConfigMap name and then the interface name
+	listeners map[string]map[string]*mappingListeners
+}
+
+type mappingListeners struct {
+	apps      *gxset.HashSet
+	listeners map[mapping.MappingListener]struct{}
+}
+
+func newKubernetesMetadataReport(client *kubernetes.Client) *kubernetesMetadataReport {
+	return &kubernetesMetadataReport{
+		client:    client,
+		informers: make(map[string]*kubernetes.Informer),
+		listeners: make(map[string]map[string]*mappingListeners),
+	}
+}
+
+// GetAppMetadata get metadata info from the ConfigMap of the application
+func (k *kubernetesMetadataReport) GetAppMetadata(application, revision string) (*info.MetadataInfo, error) {
+	cm, err := k.client.ConfigMaps().Get(context.Background(), metadataName(application, revision), metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	data, ok := cm.Data[metadataKey]
+	if !ok {
+		return nil, perrors.Errorf("the metadata of %s with revision %s is not found", application, revision)
+	}
+	meta := &info.MetadataInfo{}
+	return meta, json.Unmarshal([]byte(data), meta)
+}
+
+// PublishAppMetadata publish metadata info to the ConfigMap of the revision
+func (k *kubernetesMetadataReport) PublishAppMetadata(application, revision string, info *info.MetadataInfo) error {
+	value, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+	return k.update(metadataName(application, revision), func(data map[string]string) bool {
+		if data[metadataKey] == string(value) {
+			return false
+		}
+		data[metadataKey] = string(value)
+		return true
+	})
+}
+
+// RegisterServiceAppMapping map the specified Dubbo service interface to current Dubbo app name
+func (k *kubernetesMetadataReport) RegisterServiceAppMapping(interfaceName, group string, application string) error {
+	key, err := mappingKey(interfaceName)
+	if err != nil {
+		return err
+	}
+	return k.update(mappingName(group), func(data map[string]string) bool {
+		old := data[key]
+		if old == "" {
+			data[key] = application
+			return true
+		}
+		for _, app := range strings.Split(old, constant.CommaSeparator) {
+			if app == application {
+				return false
+			}
+		}
+		data[key] = old + constant.CommaSeparator + application
+		return true
+	})
+}
+
+// GetServiceAppMapping get the app names from the specified Dubbo service interface, and watches the mapping
+// if the listener is not nil
+func (k *kubernetesMetadataReport) GetServiceAppMapping(interfaceName, group string, l mapping.MappingListener) (*gxset.HashSet, error) {
+	key, err := mappingKey(interfaceName)
+	if err != nil {
+		return nil, err
+	}
+	name := mappingName(group)
+	if l != nil {
+		k.addListener(name, interfaceName, l)
+	}
+	cm, err := k.client.ConfigMaps().Get(context.Background(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	value, ok := cm.Data[key]
+	if !ok {
+		return nil, perrors.Errorf("the mapping of %s in group %s is not found", interfaceName, group)
+	}
+	return toSet(value), nil
+}
+
+// RemoveServiceAppMappingListener removes the listeners of the mapping, and stops watching the ConfigMap if
+// there is no listener any more
+func (k *kubernetesMetadataReport) RemoveServiceAppMappingListener(interfaceName, group string) error {
+	name := mappingName(group)
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	delete(k.listeners[name], interfaceName)
+	if len(k.listeners[name]) == 0 {
+		delete(k.listeners, name)
+		if informer, ok := k.informers[name]; ok {
+			informer.Stop()
+			delete(k.informers, name)
+		}
+	}
+	return nil
+}
+
+// update updates the data of the ConfigMap with the function, and retries if there is a conflict. The
+// function returns false if there is nothing to update.
+func (k *kubernetesMetadataReport) update(name string, f func(data map[string]string) bool) error {
+	ctx := context.Background()
+	for i := 0; ; i++ {
+		cm, err := k.client.ConfigMaps().Get(ctx, name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			cm = &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name}, Data: make(map[string]string)}
+			f(cm.Data)
+			if _, err = k.client.ConfigMaps().Create(ctx, cm, metav1.CreateOptions{}); !apierrors.IsAlreadyExists(err) {
+				return err
+			}
+		} else if err != nil {
+			return err
+		} else {
+			if cm.Data == nil {
+				cm.Data = make(map[string]string)
+			}
+			if !f(cm.Data) {
+				return nil
+			}
+			if _, err = k.client.ConfigMaps().Update(ctx, cm, metav1.UpdateOptions{}); !apierrors.IsConflict(err) {
+				return err
+			}
+		}
+		if i >= maxConflictRetries {
+			return perrors.WithMessagef(err, "update the ConfigMap %s", name)
+		}
+	}
+}
+
+func (k *kubernetesMetadataReport) addListener(name, interfaceName string, l mapping.MappingListener) {
+	k.lock.Lock()
+	listeners, ok := k.listeners[name]
+	if !ok {
+		listeners = make(map[string]*mappingListeners)
+		k.listeners[name] = listeners
+	}
+	ls, ok := listeners[interfaceName]
+	if !ok {
+		ls = &mappingListeners{listeners: make(map[mapping.MappingListener]struct{})}
+		listeners[interfaceName] = ls
+	}
+	ls.listeners[l] = struct{}{}
+	informer, ok := k.informers[name]
+	if !ok {
+		informer = kubernetes.NewConfigMapInformer(k.client, name, func(objects []metav1.Object) {
+			k.notify(name, objects)
+		})
+		k.informers[name] = informer
+	}
+	k.lock.Unlock()
+	if !ok {
+		informer.Run()
+		if !informer.WaitForSync(syncTimeout) {
+			logger.Warnf("[kubernetes] The ConfigMap %s is not synced in %s", name, syncTimeout)
+		}
+	}
+}
+
+// notify notifies the listeners of the interfaces whose mapping is changed
+func (k *kubernetesMetadataReport) notify(name string, objects []metav1.Object) {
+	data := make(map[string]string)
+	for _, obj := range objects {
+		if cm, ok := obj.(*corev1.ConfigMap); ok && cm.Name == name {
+			data = cm.Data
+		}
+	}
+	type notification struct {
+		event     *registry.ServiceMappingChangeEvent
+		listeners []mapping.MappingListener
+	}
+	var notifications []notification
+	k.lock.Lock()
+	for interfaceName, ls := range k.listeners[name] {
+		key, _ := mappingKey(interfaceName)
+		value, ok := data[key]
+		if !ok {
+			continue
+		}
+		apps := toSet(value)
+		if ls.apps != nil && equals(ls.apps, apps) {
+			continue
+		}
+		first := ls.apps == nil
+		ls.apps = apps
+		// the listeners get the mapping by GetServiceAppMapping at first, so the initial list is not notified
+		if first {
+			continue
+		}
+		n := notification{event: registry.NewServiceMappingChangedEvent(interfaceName, apps)}
+		for l := range ls.listeners {
+			n.listeners = append(n.listeners, l)
+		}
+		notifications = append(notifications, n)
+	}
+	k.lock.Unlock()
+	for _, n := range notifications {
+		for _, l := range n.listeners {
+			if err := l.OnEvent(n.event); err != nil {
+				logger.Errorf("[kubernetes] Failed to notify the mapping of %s, err: %v", n.event.GetServiceKey(), err)
+			}
+		}
+	}
+}
+
+func mappingName(group string) string {
+	if group == "" {
+		group = defaultGroup
+	}
+	return resourceName(mappingPrefix, group)
+}
+
+func metadataName(application, revision string) string {
+	return resourceName(metadataPrefix, application+"."+revision)
+}
+
+// mappingKey encodes the interface name to a legal key of ConfigMap data, the chars other than alphanumeric
+// characters, '-' and '.' are encoded as '_' followed by the hex code, e.g. "a_b/c" is encoded as "a_5Fb_2Fc".
+func mappingKey(interfaceName string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(interfaceName); i++ {
+		c := interfaceName[i]
+		if c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '.' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "_%02X", c)
+		}
+	}
+	key := b.String()
+	if errs := validation.IsConfigMapKey(key); len(errs) > 0 {
+		return "", perrors.Errorf("illegal interface name %q: %s", interfaceName, strings.Join(errs, "; "))
+	}
+	return key, nil
+}
+
+// resourceName returns a legal name of Kubernetes resources, which consists of lower case alphanumeric
+// characters, '-' or '.'
+func resourceName(prefix, name string) string {
+	name = illegalNameChars.ReplaceAllString(strings.ToLower(name), "-")
+	return strings.Trim(prefix+name, "-.")
+}
+
+func toSet(value string) *gxset.HashSet {
+	set := gxset.NewSet()
+	for _, app := range strings.Split(value, constant.CommaSeparator) {
+		if app = strings.TrimSpace(app); app != "" {
+			set.Add(app)
+		}
+	}
+	return set
+}
+
+func equals(a, b *gxset.HashSet) bool {
+	if a.Size() != b.Size() {
+		return false
+	}
+	for _, v := range a.Values() {
+		if !b.Contains(v) {
+			return false
+		}
+	}
+	return true
+}
+
+type kubernetesMetadataReportFactory struct{}
+
+// CreateMetadataReport get the MetadataReport instance of kubernetes
+func (k *kubernetesMetadataReportFactory) CreateMetadataReport(url *common.URL) report.MetadataReport {
+	client, err := kubernetes.NewClientByURL(url)
+	if err != nil {
+		logger.Errorf("Could not create kubernetes metadata report. URL: %s,error:{%v}", url.String(), err)
+		return nil
+	}
+	return newKubernetesMetadataReport(client)
+}
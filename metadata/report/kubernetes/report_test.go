@@ -0,0 +1,203 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+import (
+	gxset "github.com/dubbogo/gost/container/set"
+	"github.com/dubbogo/gost/gof/observer"
+
+	"github.com/stretchr/testify/assert"
+
+	corev1 "k8s.io/api/core/v1"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/metadata/info"
+	"dubbo.apache.org/dubbo-go/v3/registry"
+	"dubbo.apache.org/dubbo-go/v3/remoting/kubernetes"
+)
+
+// newTestClientset returns the fake clientset which checks the resource versions of the updated config maps
+// like the API server, so that the concurrent updates conflict.
+func newTestClientset() *fake.Clientset {
+	clientset := fake.NewSimpleClientset()
+	version := 0
+	// the reactors are called with the lock of the clientset held
+	clientset.PrependReactor("*", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		var cm *corev1.ConfigMap
+		switch a := action.(type) {
+		case k8stesting.CreateAction:
+			cm = a.GetObject().(*corev1.ConfigMap)
+		case k8stesting.UpdateAction:
+			cm = a.GetObject().(*corev1.ConfigMap)
+			old, err := clientset.Tracker().Get(a.GetResource(), a.GetNamespace(), cm.Name)
+			if err == nil && old.(*corev1.ConfigMap).ResourceVersion != cm.ResourceVersion {
+				return true, nil, apierrors.NewConflict(a.GetResource().GroupResource(), cm.Name,
+					errors.New("the object has been modified"))
+			}
+		default:
+			return false, nil, nil
+		}
+		version++
+		cm.ResourceVersion = strconv.Itoa(version)
+		return false, nil, nil
+	})
+	return clientset
+}
+
+// waitForWatch waits until the config maps are watched, since the fake clientset doesn't replay the events
+// happened before the watch.
+func waitForWatch(t *testing.T, clientset *fake.Clientset) {
+	assert.Eventually(t, func() bool {
+		for _, action := range clientset.Actions() {
+			if action.GetVerb() == "watch" && action.GetResource().Resource == "configmaps" {
+				return true
+			}
+		}
+		return false
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestAppMetadata(t *testing.T) {
+	report := newKubernetesMetadataReport(kubernetes.NewClient(newTestClientset(), "dubbo"))
+	meta := info.NewAppMetadataInfo("Demo_App")
+
+	_, err := report.GetAppMetadata("Demo_App", "1")
+	assert.True(t, apierrors.IsNotFound(err))
+
+	assert.Nil(t, report.PublishAppMetadata("Demo_App", "1", meta))
+	assert.Nil(t, report.PublishAppMetadata("Demo_App", "2", meta))
+	res, err := report.GetAppMetadata("Demo_App", "1")
+	assert.Nil(t, err)
+	assert.Equal(t, "Demo_App", res.App)
+	_, err = report.GetAppMetadata("Demo_App", "3")
+	assert.NotNil(t, err)
+
+	// every revision is stored in its own ConfigMap
+	for _, name := range []string{"dubbo-metadata-demo-app.1", "dubbo-metadata-demo-app.2"} {
+		cm, err := report.client.ConfigMaps().Get(context.Background(), name, metav1.GetOptions{})
+		assert.Nil(t, err)
+		assert.Len(t, cm.Data, 1)
+	}
+}
+
+func TestServiceAppMapping(t *testing.T) {
+	clientset := newTestClientset()
+	report := newKubernetesMetadataReport(kubernetes.NewClient(clientset, "dubbo"))
+
+	_, err := report.GetServiceAppMapping("org.apache.dubbo.Greeter", "", nil)
+	assert.NotNil(t, err)
+
+	assert.Nil(t, report.RegisterServiceAppMapping("org.apache.dubbo.Greeter", "", "app1"))
+	assert.Nil(t, report.RegisterServiceAppMapping("org.apache.dubbo.Greeter", "", "app1"))
+	listener := &mockMappingListener{}
+	apps, err := report.GetServiceAppMapping("org.apache.dubbo.Greeter", "", listener)
+	assert.Nil(t, err)
+	assert.True(t, apps.Contains("app1"))
+	assert.Equal(t, 1, apps.Size())
+	waitForWatch(t, clientset)
+
+	assert.Nil(t, report.RegisterServiceAppMapping("org.apache.dubbo.Greeter", "", "app2"))
+	assert.Eventually(t, func() bool {
+		apps := listener.last()
+		return apps != nil && apps.Contains("app1") && apps.Contains("app2")
+	}, time.Second, 10*time.Millisecond)
+
+	assert.Nil(t, report.RemoveServiceAppMappingListener("org.apache.dubbo.Greeter", ""))
+	assert.Empty(t, report.informers)
+}
+
+func TestConcurrentServiceAppMapping(t *testing.T) {
+	report := newKubernetesMetadataReport(kubernetes.NewClient(newTestClientset(), "dubbo"))
+	var wg sync.WaitGroup
+	for _, app := range []string{"app1", "app2", "app3"} {
+		wg.Add(1)
+		go func(app string) {
+			defer wg.Done()
+			assert.Nil(t, report.RegisterServiceAppMapping("org.apache.dubbo.Greeter", "group", app))
+		}(app)
+	}
+	wg.Wait()
+	apps, err := report.GetServiceAppMapping("org.apache.dubbo.Greeter", "group", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, apps.Size())
+}
+
+func TestResourceName(t *testing.T) {
+	assert.Equal(t, "dubbo-metadata-demo-app", resourceName(metadataPrefix, "Demo_App"))
+	assert.Equal(t, "dubbo-mapping-mapping", mappingName(""))
+	assert.Equal(t, "dubbo-mapping-a.b", mappingName("a.b"))
+	assert.Equal(t, "dubbo-metadata-demo-app.1", metadataName("Demo_App", "1"))
+}
+
+func TestMappingKey(t *testing.T) {
+	key, err := mappingKey("org.apache.dubbo-go.Greeter")
+	assert.Nil(t, err)
+	assert.Equal(t, "org.apache.dubbo-go.Greeter", key)
+	key, err = mappingKey("a_b/c")
+	assert.Nil(t, err)
+	assert.Equal(t, "a_5Fb_2Fc", key)
+	_, err = mappingKey(strings.Repeat("a", 254))
+	assert.NotNil(t, err)
+
+	report := newKubernetesMetadataReport(kubernetes.NewClient(newTestClientset(), "dubbo"))
+	assert.Nil(t, report.RegisterServiceAppMapping("com.demo.Greeter$Inner", "", "app1"))
+	apps, err := report.GetServiceAppMapping("com.demo.Greeter$Inner", "", nil)
+	assert.Nil(t, err)
+	assert.True(t, apps.Contains("app1"))
+	cm, err := report.client.ConfigMaps().Get(context.Background(), mappingName(""), metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, "app1", cm.Data["com.demo.Greeter_24Inner"])
+}
+
+type mockMappingListener struct {
+	lock sync.Mutex
+	apps *gxset.HashSet
+}
+
+func (m *mockMappingListener) OnEvent(e observer.Event) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.apps = e.(*registry.ServiceMappingChangeEvent).GetServiceNames()
+	return nil
+}
+
+func (m *mockMappingListener) Stop() {}
+
+func (m *mockMappingListener) last() *gxset.HashSet {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.apps
+}
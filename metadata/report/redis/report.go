@@ -0,0 +1,287 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+)
+
+import (
+	gxset "github.com/dubbogo/gost/container/set"
+	"github.com/dubbogo/gost/log/logger"
+
+	perrors "github.com/pkg/errors"
+
+	"github.com/redis/go-redis/v9"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/common/extension"
+	"dubbo.apache.org/dubbo-go/v3/metadata/info"
+	"dubbo.apache.org/dubbo-go/v3/metadata/mapping"
+	"dubbo.apache.org/dubbo-go/v3/metadata/report"
+	"dubbo.apache.org/dubbo-go/v3/registry"
+)
+
+const (
+	DEFAULT_ROOT = "dubbo"
+	// maxCASRetries is the max times of retrying to update the mapping when it is updated concurrently
+	maxCASRetries = 5
+)
+
+func init() {
+	extension.SetMetadataReportFactory(constant.RedisKey, func() report.MetadataReportFactory {
+		return &redisMetadataReportFactory{}
+	})
+}
+
+// redisMetadataReport is the implementation of MetadataReport based on redis. The metadata is stored by the key
+// {root}/{application}/{revision}, and the mappings of a group are stored in the hash {root}/{group} whose fields
+// are the service interfaces. Each change of a mapping is published to the channel {root}/{group}/{interface}.
+type redisMetadataReport struct {
+	client  redis.UniversalClient
+	rootDir string
+
+	lock sync.Mutex
+	// subscriptions is keyed by the channel of the mapping
+	subscriptions map[string]*subscription
+}
+
+// subscription is the subscription of a mapping channel shared by the listeners of the mapping
+type subscription struct {
+	pubsub    *redis.PubSub
+	apps      *gxset.HashSet
+	listeners map[mapping.MappingListener]struct{}
+}
+
+// GetAppMetadata get metadata info from redis
+func (r *redisMetadataReport) GetAppMetadata(application, revision string) (*info.MetadataInfo, error) {
+	key := r.rootDir + application + constant.PathSeparator + revision
+	data, err := r.client.Get(context.Background(), key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, perrors.Errorf("the metadata %s is not found", key)
+	}
+	if err != nil {
+		return nil, err
+	}
+	meta := &info.MetadataInfo{}
+	return meta, json.Unmarshal(data, meta)
+}
+
+// PublishAppMetadata publish metadata info to redis
+func (r *redisMetadataReport) PublishAppMetadata(application, revision string, info *info.MetadataInfo) error {
+	key := r.rootDir + application + constant.PathSeparator + revision
+	value, err := json.Marshal(info)
+	if err == nil {
+		err = r.client.Set(context.Background(), key, value, 0).Err()
+	}
+	return err
+}
+
+// RegisterServiceAppMapping map the specified Dubbo service interface to current Dubbo app name, the mapping
+// is updated in a transaction watching it, so the apps registered concurrently are not lost
+func (r *redisMetadataReport) RegisterServiceAppMapping(key string, group string, value string) error {
+	hash, channel := r.rootDir+group, r.mappingChannel(key, group)
+	ctx := context.Background()
+	for i := 0; i < maxCASRetries; i++ {
+		err := r.client.Watch(ctx, func(tx *redis.Tx) error {
+			oldVal, err := tx.HGet(ctx, hash, key).Result()
+			if err != nil && !errors.Is(err, redis.Nil) {
+				return err
+			}
+			newVal := value
+			if err == nil {
+				if toSet(oldVal).Contains(value) {
+					return nil
+				}
+				newVal = oldVal + constant.CommaSeparator + value
+			}
+			_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+				pipe.HSet(ctx, hash, key, newVal)
+				pipe.Publish(ctx, channel, newVal)
+				return nil
+			})
+			return err
+		}, hash)
+		if !errors.Is(err, redis.TxFailedErr) {
+			return err
+		}
+	}
+	return perrors.Errorf("failed to register the mapping %s of %s, it is modified concurrently", key, hash)
+}
+
+// GetServiceAppMapping get the app names from the specified Dubbo service interface, and subscribes the changes
+// of the mapping if the listener is not nil
+func (r *redisMetadataReport) GetServiceAppMapping(key string, group string, listener mapping.MappingListener) (*gxset.HashSet, error) {
+	hash, channel := r.rootDir+group, r.mappingChannel(key, group)
+	ctx := context.Background()
+	if listener != nil {
+		// subscribe before getting the mapping, so that no change is missed
+		if err := r.addListener(ctx, key, channel, listener); err != nil {
+			return nil, err
+		}
+	}
+	value, err := r.client.HGet(ctx, hash, key).Result()
+	if err != nil {
+		if listener != nil {
+			r.removeListener(channel, listener)
+		}
+		if errors.Is(err, redis.Nil) {
+			return nil, perrors.Errorf("the mapping %s of %s is not found", key, hash)
+		}
+		return nil, err
+	}
+	apps := toSet(value)
+	if listener != nil {
+		r.lock.Lock()
+		if sub, ok := r.subscriptions[channel]; ok && sub.apps == nil {
+			sub.apps = apps
+		}
+		r.lock.Unlock()
+	}
+	return apps, nil
+}
+
+// RemoveServiceAppMappingListener removes the listeners of the mapping and stops subscribing the changes of it
+func (r *redisMetadataReport) RemoveServiceAppMappingListener(key string, group string) error {
+	channel := r.mappingChannel(key, group)
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if sub, ok := r.subscriptions[channel]; ok {
+		delete(r.subscriptions, channel)
+		return sub.pubsub.Close()
+	}
+	return nil
+}
+
+// addListener adds the listener to the subscription of the channel, the channel is subscribed if it is not
+func (r *redisMetadataReport) addListener(ctx context.Context, key, channel string, listener mapping.MappingListener) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if sub, ok := r.subscriptions[channel]; ok {
+		sub.listeners[listener] = struct{}{}
+		return nil
+	}
+	pubsub := r.client.Subscribe(ctx, channel)
+	if _, err := pubsub.Receive(ctx); err != nil {
+		_ = pubsub.Close()
+		return err
+	}
+	sub := &subscription{pubsub: pubsub, listeners: map[mapping.MappingListener]struct{}{listener: {}}}
+	r.subscriptions[channel] = sub
+	go r.watch(key, sub)
+	return nil
+}
+
+// removeListener removes the listener from the subscription of the channel, and stops subscribing the channel if
+// there is no listener any more
+func (r *redisMetadataReport) removeListener(channel string, listener mapping.MappingListener) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	sub, ok := r.subscriptions[channel]
+	if !ok {
+		return
+	}
+	delete(sub.listeners, listener)
+	if len(sub.listeners) == 0 {
+		delete(r.subscriptions, channel)
+		_ = sub.pubsub.Close()
+	}
+}
+
+// watch notifies the listeners of the apps published to the channel once they are changed, it returns when the
+// subscription is closed
+func (r *redisMetadataReport) watch(key string, sub *subscription) {
+	for msg := range sub.pubsub.Channel() {
+		apps := toSet(msg.Payload)
+		r.lock.Lock()
+		if sub.apps != nil && equals(sub.apps, apps) {
+			r.lock.Unlock()
+			continue
+		}
+		sub.apps = apps
+		listeners := make([]mapping.MappingListener, 0, len(sub.listeners))
+		for l := range sub.listeners {
+			listeners = append(listeners, l)
+		}
+		r.lock.Unlock()
+		event := registry.NewServiceMappingChangedEvent(key, apps)
+		for _, l := range listeners {
+			if err := l.OnEvent(event); err != nil {
+				logger.Errorf("[redis] Failed to notify the mapping of %s, err: %v", key, err)
+			}
+		}
+	}
+}
+
+func (r *redisMetadataReport) mappingChannel(key, group string) string {
+	return r.rootDir + group + constant.PathSeparator + key
+}
+
+func toSet(value string) *gxset.HashSet {
+	set := gxset.NewSet()
+	for _, app := range strings.Split(value, constant.CommaSeparator) {
+		if app = strings.TrimSpace(app); app != "" {
+			set.Add(app)
+		}
+	}
+	return set
+}
+
+func equals(a, b *gxset.HashSet) bool {
+	if a.Size() != b.Size() {
+		return false
+	}
+	for _, v := range a.Values() {
+		if !b.Contains(v) {
+			return false
+		}
+	}
+	return true
+}
+
+type redisMetadataReportFactory struct{}
+
+// CreateMetadataReport get the MetadataReport instance of redis
+func (r *redisMetadataReportFactory) CreateMetadataReport(url *common.URL) report.MetadataReport {
+	timeout := url.GetParamDuration(constant.TimeoutKey, constant.DefaultRegTimeout)
+	client := redis.NewUniversalClient(&redis.UniversalOptions{
+		Addrs:       strings.Split(url.Location, constant.CommaSeparator),
+		Username:    url.Username,
+		Password:    url.Password,
+		DB:          url.GetParamByIntValue(constant.RedisDBKey, 0),
+		MasterName:  url.GetParam(constant.RedisMasterNameKey, ""),
+		DialTimeout: timeout,
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		_ = client.Close()
+		logger.Errorf("Could not create redis metadata report. URL: %s,error:{%v}", url.String(), err)
+		return nil
+	}
+	group := url.GetParam(constant.MetadataReportGroupKey, DEFAULT_ROOT)
+	group = strings.Trim(group, constant.PathSeparator) + constant.PathSeparator
+	return &redisMetadataReport{client: client, rootDir: group, subscriptions: make(map[string]*subscription)}
+}
@@ -0,0 +1,177 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package redis
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/alicebob/miniredis/v2"
+
+	gxset "github.com/dubbogo/gost/container/set"
+	"github.com/dubbogo/gost/gof/observer"
+
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/metadata/info"
+	"dubbo.apache.org/dubbo-go/v3/registry"
+)
+
+func newTestReport(t *testing.T, server *miniredis.Miniredis) *redisMetadataReport {
+	url, err := common.NewURL("redis://" + server.Addr())
+	assert.Nil(t, err)
+	report := (&redisMetadataReportFactory{}).CreateMetadataReport(url)
+	assert.NotNil(t, report)
+	return report.(*redisMetadataReport)
+}
+
+func TestCreateMetadataReport(t *testing.T) {
+	server := miniredis.RunT(t)
+	server.RequireUserAuth("user", "pwd")
+
+	url, err := common.NewURL("redis://user:pwd@" + server.Addr())
+	assert.Nil(t, err)
+	assert.NotNil(t, (&redisMetadataReportFactory{}).CreateMetadataReport(url))
+
+	url, err = common.NewURL("redis://user:wrong@" + server.Addr())
+	assert.Nil(t, err)
+	assert.Nil(t, (&redisMetadataReportFactory{}).CreateMetadataReport(url))
+}
+
+func TestAppMetadata(t *testing.T) {
+	server := miniredis.RunT(t)
+	report := newTestReport(t, server)
+
+	_, err := report.GetAppMetadata("app", "1")
+	assert.NotNil(t, err)
+	assert.Nil(t, report.PublishAppMetadata("app", "1", info.NewAppMetadataInfo("app")))
+	meta, err := report.GetAppMetadata("app", "1")
+	assert.Nil(t, err)
+	assert.Equal(t, "app", meta.App)
+	assert.True(t, server.Exists("dubbo/app/1"))
+}
+
+func TestAppMetadataWithGroup(t *testing.T) {
+	server := miniredis.RunT(t)
+	url, err := common.NewURL("redis://"+server.Addr(), common.WithParamsValue(constant.MetadataReportGroupKey, "/test/"))
+	assert.Nil(t, err)
+	report := (&redisMetadataReportFactory{}).CreateMetadataReport(url)
+	assert.NotNil(t, report)
+
+	assert.Nil(t, report.PublishAppMetadata("app", "1", info.NewAppMetadataInfo("app")))
+	assert.True(t, server.Exists("test/app/1"))
+}
+
+func TestServiceAppMapping(t *testing.T) {
+	server := miniredis.RunT(t)
+	report := newTestReport(t, server)
+
+	_, err := report.GetServiceAppMapping("org.apache.dubbo.Greeter", "mapping", nil)
+	assert.NotNil(t, err)
+
+	var wg sync.WaitGroup
+	for _, app := range []string{"app1", "app2", "app1"} {
+		wg.Add(1)
+		go func(app string) {
+			defer wg.Done()
+			assert.Nil(t, report.RegisterServiceAppMapping("org.apache.dubbo.Greeter", "mapping", app))
+		}(app)
+	}
+	wg.Wait()
+	apps, err := report.GetServiceAppMapping("org.apache.dubbo.Greeter", "mapping", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, apps.Size())
+	assert.True(t, apps.Contains("app1"))
+	assert.True(t, apps.Contains("app2"))
+	fields, err := server.HKeys("dubbo/mapping")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"org.apache.dubbo.Greeter"}, fields)
+}
+
+func TestServiceAppMappingListener(t *testing.T) {
+	server := miniredis.RunT(t)
+	report := newTestReport(t, server)
+
+	listener := &mockMappingListener{}
+	_, err := report.GetServiceAppMapping("org.apache.dubbo.Greeter", "mapping", listener)
+	assert.NotNil(t, err)
+	assert.Empty(t, report.subscriptions)
+
+	assert.Nil(t, report.RegisterServiceAppMapping("org.apache.dubbo.Greeter", "mapping", "app1"))
+	apps, err := report.GetServiceAppMapping("org.apache.dubbo.Greeter", "mapping", listener)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, apps.Size())
+
+	assert.Nil(t, report.RegisterServiceAppMapping("org.apache.dubbo.Greeter", "mapping", "app2"))
+	assert.Eventually(t, func() bool {
+		apps := listener.last()
+		return apps != nil && apps.Size() == 2 && apps.Contains("app2")
+	}, time.Second, 10*time.Millisecond)
+
+	// the listeners of the same mapping share the subscription, and both of them are notified
+	another := &mockMappingListener{}
+	_, err = report.GetServiceAppMapping("org.apache.dubbo.Greeter", "mapping", another)
+	assert.Nil(t, err)
+	assert.Len(t, report.subscriptions, 1)
+	assert.Nil(t, report.RegisterServiceAppMapping("org.apache.dubbo.Greeter", "mapping", "app3"))
+	for _, l := range []*mockMappingListener{listener, another} {
+		l := l
+		assert.Eventually(t, func() bool {
+			apps := l.last()
+			return apps != nil && apps.Size() == 3 && apps.Contains("app3")
+		}, time.Second, 10*time.Millisecond)
+	}
+
+	// the mapping of another service is not notified
+	assert.Nil(t, report.RegisterServiceAppMapping("org.apache.dubbo.Other", "mapping", "app3"))
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, 3, listener.last().Size())
+
+	assert.Nil(t, report.RemoveServiceAppMappingListener("org.apache.dubbo.Greeter", "mapping"))
+	assert.Empty(t, report.subscriptions)
+	assert.Nil(t, report.RegisterServiceAppMapping("org.apache.dubbo.Greeter", "mapping", "app4"))
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, 3, listener.last().Size())
+}
+
+type mockMappingListener struct {
+	lock sync.Mutex
+	apps *gxset.HashSet
+}
+
+func (m *mockMappingListener) OnEvent(e observer.Event) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.apps = e.(*registry.ServiceMappingChangeEvent).GetServiceNames()
+	return nil
+}
+
+func (m *mockMappingListener) Stop() {}
+
+func (m *mockMappingListener) last() *gxset.HashSet {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.apps
+}
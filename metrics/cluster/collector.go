@@ -0,0 +1,107 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package cluster collects the metrics of cluster strategies, such as retry budget, outlier detection,
+// traffic mirroring and fault injection.
+package cluster
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/metrics"
+)
+
+var (
+	clusterChan = make(chan metrics.MetricsEvent, 128)
+)
+
+func init() {
+	metrics.AddCollector("cluster", func(m metrics.MetricRegistry, url *common.URL) {
+		if url.GetParamBool(constant.ClusterEnabledKey, true) {
+			cc := &clusterCollector{metrics.BaseCollector{R: m}}
+			go cc.start()
+		}
+	})
+}
+
+// clusterCollector is the cluster's metrics collector
+type clusterCollector struct {
+	metrics.BaseCollector
+}
+
+func (cc *clusterCollector) start() {
+	metrics.Subscribe(constant.MetricsCluster, clusterChan)
+	for event := range clusterChan {
+		if clusterEvent, ok := event.(*ClusterMetricsEvent); ok {
+			switch clusterEvent.Name {
+			case RetryBudget:
+				cc.retryBudgetHandler(clusterEvent)
+			case OutlierEjection, OutlierRecovery:
+				cc.outlierHandler(clusterEvent)
+			case Mirror:
+				cc.mirrorHandler(clusterEvent)
+			case FaultDelay, FaultAbort:
+				cc.faultHandler(clusterEvent)
+			default:
+			}
+		}
+	}
+}
+
+// retryBudgetHandler handles retry budget metrics
+func (cc *clusterCollector) retryBudgetHandler(event *ClusterMetricsEvent) {
+	level := event.level()
+	if event.Succ {
+		cc.R.Counter(metrics.NewMetricId(RetryBudgetRetries, level)).Inc()
+	} else {
+		cc.R.Counter(metrics.NewMetricId(RetryBudgetExhausted, level)).Inc()
+	}
+	cc.R.Gauge(metrics.NewMetricId(RetryBudgetUsage, level)).Set(event.Value)
+}
+
+// outlierHandler handles outlier detection metrics
+func (cc *clusterCollector) outlierHandler(event *ClusterMetricsEvent) {
+	if event.Name == OutlierEjection {
+		cc.R.Counter(metrics.NewMetricId(OutlierEjections, event.addressLevel())).Inc()
+	} else {
+		cc.R.Counter(metrics.NewMetricId(OutlierRecoveries, event.addressLevel())).Inc()
+	}
+	cc.R.Gauge(metrics.NewMetricId(OutlierEjected, metrics.NewServiceMetric(event.Interface))).Set(event.Value)
+}
+
+// mirrorHandler handles traffic mirroring metrics
+func (cc *clusterCollector) mirrorHandler(event *ClusterMetricsEvent) {
+	level := event.level()
+	cc.R.Counter(metrics.NewMetricId(MirrorRequests, level)).Inc()
+	if event.Succ {
+		cc.R.Counter(metrics.NewMetricId(MirrorSucceed, level)).Inc()
+	} else {
+		cc.R.Counter(metrics.NewMetricId(MirrorFailed, level)).Inc()
+	}
+	cc.R.Rt(metrics.NewMetricId(MirrorRt, level), &metrics.RtOpts{}).Observe(event.Value)
+}
+
+// faultHandler handles fault injection metrics
+func (cc *clusterCollector) faultHandler(event *ClusterMetricsEvent) {
+	level := event.level()
+	if event.Name == FaultAbort {
+		cc.R.Counter(metrics.NewMetricId(FaultAborts, level)).Inc()
+		return
+	}
+	cc.R.Counter(metrics.NewMetricId(FaultDelays, level)).Inc()
+	cc.R.Rt(metrics.NewMetricId(FaultDelayTime, level), &metrics.RtOpts{}).Observe(event.Value)
+}
@@ -0,0 +1,138 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cluster
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/metrics"
+)
+
+// ClusterMetricsEvent contains info about cluster metrics
+type ClusterMetricsEvent struct {
+	Name      MetricName
+	Interface string
+	Method    string
+	Group     string
+	Version   string
+	Address   string
+	Succ      bool
+	Value     float64
+}
+
+func (e ClusterMetricsEvent) Type() string {
+	return constant.MetricsCluster
+}
+
+func (e *ClusterMetricsEvent) level() *metrics.MethodMetricLevel {
+	return &metrics.MethodMetricLevel{
+		ServiceMetricLevel: metrics.NewServiceMetric(e.Interface),
+		Method:             e.Method,
+		Group:              e.Group,
+		Version:            e.Version,
+	}
+}
+
+func (e *ClusterMetricsEvent) addressLevel() metrics.MetricLevel {
+	return &addressMetricLevel{ServiceMetricLevel: metrics.NewServiceMetric(e.Interface), Address: e.Address}
+}
+
+// addressMetricLevel is the level of a provider instance of the service
+type addressMetricLevel struct {
+	*metrics.ServiceMetricLevel
+	Address string
+}
+
+func (l addressMetricLevel) Tags() map[string]string {
+	tags := l.ServiceMetricLevel.Tags()
+	tags[constant.TagAddress] = l.Address
+	return tags
+}
+
+// NewRetryBudgetEvent for retry budget metrics, allowed reports whether the retry is allowed by the budget
+// and usage is the ratio of the used budget.
+func NewRetryBudgetEvent(interfaceName, method, group, version string, allowed bool, usage float64) metrics.MetricsEvent {
+	return &ClusterMetricsEvent{
+		Name:      RetryBudget,
+		Interface: interfaceName,
+		Method:    method,
+		Group:     group,
+		Version:   version,
+		Succ:      allowed,
+		Value:     usage,
+	}
+}
+
+// NewOutlierEjectionEvent for outlier detection metrics, ejected is the number of ejected invokers of the service
+// after the invoker of the address is ejected.
+func NewOutlierEjectionEvent(interfaceName, address string, ejected int) metrics.MetricsEvent {
+	return &ClusterMetricsEvent{
+		Name:      OutlierEjection,
+		Interface: interfaceName,
+		Address:   address,
+		Value:     float64(ejected),
+	}
+}
+
+// NewMirrorEvent for traffic mirroring metrics, succ reports whether the mirrored request to the shadow
+// provider of the address succeeded and costMs is the response time in milliseconds.
+func NewMirrorEvent(interfaceName, method, group, version, address string, succ bool, costMs float64) metrics.MetricsEvent {
+	return &ClusterMetricsEvent{
+		Name:      Mirror,
+		Interface: interfaceName,
+		Method:    method,
+		Group:     group,
+		Version:   version,
+		Address:   address,
+		Succ:      succ,
+		Value:     costMs,
+	}
+}
+
+// NewFaultDelayEvent for fault injection metrics, delayMs is the injected delay in milliseconds.
+func NewFaultDelayEvent(interfaceName, method, group, version string, delayMs float64) metrics.MetricsEvent {
+	return &ClusterMetricsEvent{
+		Name:      FaultDelay,
+		Interface: interfaceName,
+		Method:    method,
+		Group:     group,
+		Version:   version,
+		Value:     delayMs,
+	}
+}
+
+// NewFaultAbortEvent for fault injection metrics, it is published when a request is aborted by fault injection.
+func NewFaultAbortEvent(interfaceName, method, group, version string) metrics.MetricsEvent {
+	return &ClusterMetricsEvent{
+		Name:      FaultAbort,
+		Interface: interfaceName,
+		Method:    method,
+		Group:     group,
+		Version:   version,
+	}
+}
+
+// NewOutlierRecoveryEvent for outlier detection metrics, ejected is the number of ejected invokers of the service
+// after the invoker of the address is recovered.
+func NewOutlierRecoveryEvent(interfaceName, address string, ejected int) metrics.MetricsEvent {
+	return &ClusterMetricsEvent{
+		Name:      OutlierRecovery,
+		Interface: interfaceName,
+		Address:   address,
+		Value:     float64(ejected),
+	}
+}
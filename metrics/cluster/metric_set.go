@@ -0,0 +1,56 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cluster
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/metrics"
+)
+
+type MetricName int8
+
+const (
+	RetryBudget MetricName = iota
+	OutlierEjection
+	OutlierRecovery
+	Mirror
+	FaultDelay
+	FaultAbort
+)
+
+var (
+	// retry budget metrics key
+	RetryBudgetRetries   = metrics.NewMetricKey("dubbo_cluster_retry_budget_retries_total", "Total Retries Allowed By Retry Budget")
+	RetryBudgetExhausted = metrics.NewMetricKey("dubbo_cluster_retry_budget_exhausted_total", "Total Retries Rejected By Retry Budget")
+	RetryBudgetUsage     = metrics.NewMetricKey("dubbo_cluster_retry_budget_usage_ratio", "Retry Budget Usage Ratio")
+
+	// outlier detection metrics key
+	OutlierEjections  = metrics.NewMetricKey("dubbo_cluster_outlier_ejections_total", "Total Outlier Ejections")
+	OutlierRecoveries = metrics.NewMetricKey("dubbo_cluster_outlier_recoveries_total", "Total Outlier Recoveries")
+	OutlierEjected    = metrics.NewMetricKey("dubbo_cluster_outlier_ejected", "Current Ejected Invokers")
+
+	// traffic mirroring metrics key
+	MirrorRequests = metrics.NewMetricKey("dubbo_cluster_mirror_requests_total", "Total Mirrored Requests")
+	MirrorSucceed  = metrics.NewMetricKey("dubbo_cluster_mirror_requests_succeed_total", "Succeed Mirrored Requests")
+	MirrorFailed   = metrics.NewMetricKey("dubbo_cluster_mirror_requests_failed_total", "Failed Mirrored Requests")
+	MirrorRt       = metrics.NewMetricKey("dubbo_cluster_mirror_rt_milliseconds", "Response Time Of Mirrored Requests")
+
+	// fault injection metrics key
+	FaultDelays    = metrics.NewMetricKey("dubbo_cluster_fault_delays_total", "Total Requests Delayed By Fault Injection")
+	FaultDelayTime = metrics.NewMetricKey("dubbo_cluster_fault_delay_milliseconds", "Delay Time Injected By Fault Injection")
+	FaultAborts    = metrics.NewMetricKey("dubbo_cluster_fault_aborts_total", "Total Requests Aborted By Fault Injection")
+)
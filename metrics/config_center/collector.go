@@ -62,6 +62,8 @@ const (
 	Nacos     = "nacos"
 	Apollo    = "apollo"
 	Zookeeper = "zookeeper"
+	Consul    = "consul"
+	Etcd      = "etcd"
 )
 
 type ConfigCenterMetricEvent struct {
@@ -17,6 +17,10 @@
 
 package registry
 
+import (
+	"strconv"
+)
+
 import (
 	"dubbo.apache.org/dubbo-go/v3/common"
 	"dubbo.apache.org/dubbo-go/v3/common/constant"
@@ -56,6 +60,10 @@ func (rc *registryCollector) start() {
 				rc.serverRegHandler(registryEvent)
 			case ServerSub:
 				rc.serverSubHandler(registryEvent)
+			case SourceAvailable:
+				rc.sourceAvailableHandler(registryEvent)
+			case SourceAddress:
+				rc.sourceAddressHandler(registryEvent)
 			default:
 			}
 		}
@@ -127,3 +135,26 @@ func (rc *registryCollector) serverSubHandler(event *RegistryMetricsEvent) {
 	level := metrics.GetApplicationLevel()
 	rc.StateCount(ServiceSubscribeMetricNum, ServiceSubscribeMetricNumSucceed, ServiceSubscribeMetricNumFailed, level, event.Succ)
 }
+
+// sourceAvailableHandler handles the available state metrics of the source registry
+func (rc *registryCollector) sourceAvailableHandler(event *RegistryMetricsEvent) {
+	tags := metrics.GetApplicationLevel().Tags()
+	tags[constant.TagRegistry] = event.Attachment["Registry"]
+	available := 0.0
+	if event.Succ {
+		available = 1
+	}
+	rc.R.Gauge(metrics.NewMetricIdByLabels(SourceMetricAvailable, tags)).Set(available)
+}
+
+// sourceAddressHandler handles the address number metrics of the source registry
+func (rc *registryCollector) sourceAddressHandler(event *RegistryMetricsEvent) {
+	num, err := strconv.ParseFloat(event.Attachment["Num"], 64)
+	if err != nil {
+		return
+	}
+	tags := metrics.GetApplicationLevel().Tags()
+	tags[constant.TagRegistry] = event.Attachment["Registry"]
+	tags[constant.TagInterface] = event.Attachment["Interface"]
+	rc.R.Gauge(metrics.NewMetricIdByLabels(SourceMetricAddressNum, tags)).Set(num)
+}
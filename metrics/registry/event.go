@@ -18,6 +18,7 @@
 package registry
 
 import (
+	"strconv"
 	"time"
 )
 
@@ -95,3 +96,20 @@ func NewServerSubscribeEvent(succ bool) metrics.MetricsEvent {
 		Succ: succ,
 	}
 }
+
+// NewSourceAvailableEvent for the available state of the source registry
+func NewSourceAvailableEvent(source string, available bool) metrics.MetricsEvent {
+	return &RegistryMetricsEvent{
+		Name:       SourceAvailable,
+		Succ:       available,
+		Attachment: map[string]string{"Registry": source},
+	}
+}
+
+// NewSourceAddressEvent for the address number of the service from the source registry
+func NewSourceAddressEvent(source string, service string, num int) metrics.MetricsEvent {
+	return &RegistryMetricsEvent{
+		Name:       SourceAddress,
+		Attachment: map[string]string{"Registry": source, "Interface": service, "Num": strconv.Itoa(num)},
+	}
+}
@@ -30,6 +30,8 @@ const (
 	Directory
 	ServerReg
 	ServerSub
+	SourceAvailable
+	SourceAddress
 )
 
 const (
@@ -70,6 +72,10 @@ var (
 	ServiceSubscribeMetricNumSucceed = metrics.NewMetricKey("dubbo_registry_subscribe_service_num_succeed_total", "Succeed Service-Level Num")
 	ServiceSubscribeMetricNumFailed  = metrics.NewMetricKey("dubbo_registry_subscribe_service_num_failed_total", "Failed Service-Level Num")
 
+	// source registry metrics key, the source registries are aggregated by the multiple registry
+	SourceMetricAvailable  = metrics.NewMetricKey("dubbo_registry_source_available", "Available State Of The Source Registry")
+	SourceMetricAddressNum = metrics.NewMetricKey("dubbo_registry_source_address_num", "Addresses From The Source Registry")
+
 	// register metrics server rt key
 	RegisterServiceRt = metrics.NewMetricKey("dubbo_register_service_rt_milliseconds", "Service Register Time")
 
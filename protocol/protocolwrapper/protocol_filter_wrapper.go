@@ -130,6 +130,11 @@ func (fi *FilterInvoker) Invoke(ctx context.Context, invocation base.Invocation)
 	return fi.filter.OnResponse(ctx, result, fi.invoker, invocation)
 }
 
+// Unwrap returns the invoker below the filter chain, invoking it skips all the filters.
+func (fi *FilterInvoker) Unwrap() base.Invoker {
+	return fi.invoker
+}
+
 // Destroy will destroy invoker
 func (fi *FilterInvoker) Destroy() {
 	fi.invoker.Destroy()
@@ -0,0 +1,126 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package consul
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+import (
+	"github.com/dubbogo/gost/log/logger"
+
+	"github.com/hashicorp/consul/api"
+
+	perrors "github.com/pkg/errors"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/remoting/consul"
+)
+
+const (
+	checkTypeTTL  = "ttl"
+	checkTypeHTTP = "http"
+
+	defaultCheckTTL      = "15s"
+	defaultCheckInterval = "10s"
+	// tagSeparator separates the key and the value of the tags converted from params or metadata
+	tagSeparator = "="
+)
+
+// buildCheck builds the health check of the service instance by the params of the registry url, and returns
+// the interval of the heartbeat if it is a ttl check.
+func buildCheck(url *common.URL, serviceID string) (*api.AgentServiceCheck, time.Duration, error) {
+	check := &api.AgentServiceCheck{
+		CheckID:                        "service:" + serviceID,
+		DeregisterCriticalServiceAfter: url.GetParam(constant.ConsulDeregisterCriticalKey, ""),
+	}
+	switch checkType := url.GetParam(constant.ConsulCheckKey, checkTypeTTL); checkType {
+	case checkTypeTTL:
+		ttl, err := time.ParseDuration(url.GetParam(constant.ConsulCheckTTLKey, defaultCheckTTL))
+		if err != nil || ttl < time.Second {
+			return nil, 0, perrors.Errorf("illegal %s %s, it should be at least 1s",
+				constant.ConsulCheckTTLKey, url.GetParam(constant.ConsulCheckTTLKey, ""))
+		}
+		check.TTL = ttl.String()
+		// the check is critical until the first heartbeat by default
+		check.Status = api.HealthPassing
+		return check, ttl / 3, nil
+	case checkTypeHTTP:
+		check.HTTP = url.GetParam(constant.ConsulCheckHTTPKey, "")
+		if check.HTTP == "" {
+			return nil, 0, perrors.Errorf("%s is required by the http check", constant.ConsulCheckHTTPKey)
+		}
+		check.Interval = url.GetParam(constant.ConsulCheckIntervalKey, defaultCheckInterval)
+		return check, 0, nil
+	default:
+		return nil, 0, perrors.Errorf("unknown consul check type %s", checkType)
+	}
+}
+
+// heartbeat passes the ttl check periodically until the context is done. The service is registered again by
+// reregister if the check is lost, e.g. the agent is restarted.
+func heartbeat(ctx context.Context, client *api.Client, checkID string, interval time.Duration, reregister func() error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		err := client.Agent().UpdateTTLOpts(checkID, "", api.HealthPassing, (&api.QueryOptions{}).WithContext(ctx))
+		if consul.IsNotFound(err) {
+			logger.Warnf("[consul] The check %s is lost, register the service again", checkID)
+			err = reregister()
+		}
+		if err != nil && ctx.Err() == nil {
+			logger.Warnf("[consul] Failed to pass the check %s, err: %v", checkID, err)
+		}
+	}
+}
+
+// entryAddress returns the address of the service instance, which is the address of the node if it is not set
+func entryAddress(entry *api.ServiceEntry) string {
+	if entry.Service.Address == "" && entry.Node != nil {
+		return entry.Node.Address
+	}
+	return entry.Service.Address
+}
+
+func toTags(m map[string]string, tags ...string) []string {
+	for k, v := range m {
+		tags = append(tags, k+tagSeparator+v)
+	}
+	return tags
+}
+
+// fromTags converts the tags with the separator to a map, the other tags are ignored
+func fromTags(tags []string) map[string]string {
+	m := make(map[string]string, len(tags))
+	for _, tag := range tags {
+		if k, v, ok := strings.Cut(tag, tagSeparator); ok {
+			m[k] = v
+		}
+	}
+	return m
+}
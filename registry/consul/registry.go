@@ -0,0 +1,367 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package consul
+
+import (
+	"bytes"
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+import (
+	"github.com/dubbogo/gost/log/logger"
+
+	"github.com/hashicorp/consul/api"
+
+	perrors "github.com/pkg/errors"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/common/extension"
+	"dubbo.apache.org/dubbo-go/v3/metrics"
+	metricsRegistry "dubbo.apache.org/dubbo-go/v3/metrics/registry"
+	"dubbo.apache.org/dubbo-go/v3/registry"
+	"dubbo.apache.org/dubbo-go/v3/remoting"
+	"dubbo.apache.org/dubbo-go/v3/remoting/consul"
+)
+
+const (
+	// registryTag is the tag of the services registered by the interface-level registry
+	registryTag = "dubbo"
+	// serviceNameSeparator separates the category, the interface, the version and the group of the service name
+	serviceNameSeparator = ":"
+)
+
+func init() {
+	extension.SetRegistry(constant.ConsulKey, newConsulRegistry)
+}
+
+// consulRegistry registers the interface-level services to the local consul agent. The service name is
+// {category}:{interface}:{version}:{group}, the params of the url are converted to the tags, and the
+// subscribers watch the passing instances by the blocking queries.
+type consulRegistry struct {
+	*common.URL
+	client *api.Client
+	done   chan struct{}
+	wg     sync.WaitGroup
+
+	lock sync.Mutex
+	// registered is keyed by the service id, the value stops the heartbeat of the service
+	registered map[string]*registeredURL
+	// watchers is keyed by the service name and the listener, the value stops the watcher
+	watchers map[watcherKey]context.CancelFunc
+}
+
+type watcherKey struct {
+	serviceName string
+	listener    registry.NotifyListener
+}
+
+type registeredURL struct {
+	url    *common.URL
+	cancel context.CancelFunc
+}
+
+func newConsulRegistry(url *common.URL) (registry.Registry, error) {
+	logger.Infof("[Consul Registry] New consul registry with url = %+v", url.ToMap())
+	client, err := consul.NewClientByURL(url)
+	if err != nil {
+		return nil, err
+	}
+	return &consulRegistry{
+		URL:        url,
+		client:     client,
+		done:       make(chan struct{}),
+		registered: make(map[string]*registeredURL),
+		watchers:   make(map[watcherKey]context.CancelFunc),
+	}, nil
+}
+
+func getCategory(url *common.URL) string {
+	role, _ := strconv.Atoi(url.GetParam(constant.RegistryRoleKey, strconv.Itoa(common.PROVIDER)))
+	return common.DubboNodes[role]
+}
+
+func getServiceName(url *common.URL) string {
+	return buildServiceName(getCategory(url), url)
+}
+
+func getSubscribeName(url *common.URL) string {
+	return buildServiceName(common.DubboNodes[common.PROVIDER], url)
+}
+
+func buildServiceName(category string, url *common.URL) string {
+	var buffer bytes.Buffer
+	buffer.WriteString(category)
+	for _, key := range []string{constant.InterfaceKey, constant.VersionKey, constant.GroupKey} {
+		buffer.WriteString(serviceNameSeparator)
+		buffer.WriteString(strings.TrimSpace(url.GetParam(key, "")))
+	}
+	return buffer.String()
+}
+
+func getServiceID(serviceName string, url *common.URL) string {
+	return serviceName + serviceNameSeparator + url.Ip + serviceNameSeparator + url.Port
+}
+
+func createRegistration(url *common.URL, serviceName string) *api.AgentServiceRegistration {
+	params := make(map[string]string)
+	url.RangeParams(func(key, value string) bool {
+		params[key] = value
+		return true
+	})
+	params[constant.CategoryKey] = getCategory(url)
+	params[constant.ProtocolKey] = url.Protocol
+	params[constant.PathKey] = url.Path
+	if len(url.Methods) > 0 {
+		params[constant.MethodsKey] = strings.Join(url.Methods, constant.CommaSeparator)
+	}
+	port, _ := strconv.Atoi(url.Port)
+	return &api.AgentServiceRegistration{
+		ID:      getServiceID(serviceName, url),
+		Name:    serviceName,
+		Tags:    toTags(params, registryTag),
+		Address: url.Ip,
+		Port:    port,
+	}
+}
+
+// generateURL restores the url from the tags of the service instance
+func generateURL(entry *api.ServiceEntry) *common.URL {
+	params := fromTags(entry.Service.Tags)
+	path, protocol := params[constant.PathKey], params[constant.ProtocolKey]
+	if path == "" && params[constant.InterfaceKey] != "" {
+		path = "/" + params[constant.InterfaceKey]
+	}
+	if path == "" || protocol == "" {
+		logger.Errorf("[Consul Registry] The tags of the instance %s don't have the path or the protocol", entry.Service.ID)
+		return nil
+	}
+	values := url.Values{}
+	for k, v := range params {
+		values.Set(k, v)
+	}
+	return common.NewURLWithOptions(
+		common.WithIp(entryAddress(entry)),
+		common.WithPort(strconv.Itoa(entry.Service.Port)),
+		common.WithProtocol(protocol),
+		common.WithParams(values),
+		common.WithPath(path),
+	)
+}
+
+// Register registers the url to the consul agent with the health check.
+func (r *consulRegistry) Register(url *common.URL) error {
+	start := time.Now()
+	common.HandleRegisterIPAndPort(url)
+	registration := createRegistration(url, getServiceName(url))
+	check, interval, err := buildCheck(r.URL, registration.ID)
+	if err != nil {
+		return err
+	}
+	registration.Check = check
+	logger.Infof("[Consul Registry] Registry instance with id = %s", registration.ID)
+	err = r.client.Agent().ServiceRegister(registration)
+	metrics.Publish(metricsRegistry.NewRegisterEvent(err == nil, start))
+	if err != nil {
+		return perrors.WithMessagef(err, "register %s to consul", registration.Name)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r.lock.Lock()
+	if old, ok := r.registered[registration.ID]; ok {
+		old.cancel()
+	}
+	r.registered[registration.ID] = &registeredURL{url: url, cancel: cancel}
+	r.lock.Unlock()
+	if interval > 0 {
+		r.wg.Add(1)
+		go func() {
+			defer r.wg.Done()
+			heartbeat(ctx, r.client, check.CheckID, interval, func() error {
+				return r.client.Agent().ServiceRegisterOpts(registration, api.ServiceRegisterOpts{}.WithContext(ctx))
+			})
+		}()
+	}
+	return nil
+}
+
+// UnRegister deregisters the url from the consul agent.
+func (r *consulRegistry) UnRegister(url *common.URL) error {
+	common.HandleRegisterIPAndPort(url)
+	id := getServiceID(getServiceName(url), url)
+	r.lock.Lock()
+	if old, ok := r.registered[id]; ok {
+		old.cancel()
+		delete(r.registered, id)
+	}
+	r.lock.Unlock()
+	if err := r.client.Agent().ServiceDeregister(id); err != nil {
+		return perrors.WithMessagef(err, "deregister %s from consul", id)
+	}
+	return nil
+}
+
+// Subscribe watches the passing providers of the url, and notifies the listener of the changes.
+func (r *consulRegistry) Subscribe(url *common.URL, notifyListener registry.NotifyListener) error {
+	role, _ := strconv.Atoi(url.GetParam(constant.RegistryRoleKey, ""))
+	if role != common.CONSUMER {
+		return nil
+	}
+	if !r.IsAvailable() {
+		return perrors.New("consulRegistry is not available.")
+	}
+	serviceName := getSubscribeName(url)
+	key := watcherKey{serviceName: serviceName, listener: notifyListener}
+	ctx, cancel := context.WithCancel(context.Background())
+	r.lock.Lock()
+	if _, ok := r.watchers[key]; ok {
+		r.lock.Unlock()
+		cancel()
+		return nil
+	}
+	r.watchers[key] = cancel
+	r.lock.Unlock()
+	metrics.Publish(metricsRegistry.NewSubscribeEvent(true))
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		w := &serviceWatcher{client: r.client, serviceName: serviceName, listener: notifyListener}
+		consul.Watch(ctx, w.query)
+	}()
+	return nil
+}
+
+// UnSubscribe stops watching the providers of the url.
+func (r *consulRegistry) UnSubscribe(url *common.URL, notifyListener registry.NotifyListener) error {
+	key := watcherKey{serviceName: getSubscribeName(url), listener: notifyListener}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if cancel, ok := r.watchers[key]; ok {
+		cancel()
+		delete(r.watchers, key)
+	}
+	return nil
+}
+
+// LoadSubscribeInstances load subscribe instance
+func (r *consulRegistry) LoadSubscribeInstances(url *common.URL, notify registry.NotifyListener) error {
+	serviceName := getSubscribeName(url)
+	entries, _, err := r.client.Health().Service(serviceName, registryTag, true, nil)
+	if err != nil {
+		return perrors.WithMessagef(err, "could not query the instances for serviceName=%s", serviceName)
+	}
+	for _, entry := range entries {
+		if newURL := generateURL(entry); newURL != nil {
+			notify.Notify(&registry.ServiceEvent{Action: remoting.EventTypeAdd, Service: newURL})
+		}
+	}
+	return nil
+}
+
+// GetURL gets its registration URL
+func (r *consulRegistry) GetURL() *common.URL {
+	return r.URL
+}
+
+// IsAvailable returns false if the registry is destroyed
+func (r *consulRegistry) IsAvailable() bool {
+	select {
+	case <-r.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// Destroy stops the watchers and the heartbeats, and deregisters the registered urls.
+func (r *consulRegistry) Destroy() {
+	select {
+	case <-r.done:
+		return
+	default:
+		close(r.done)
+	}
+
+	r.lock.Lock()
+	for key, cancel := range r.watchers {
+		cancel()
+		delete(r.watchers, key)
+	}
+	urls := make([]*common.URL, 0, len(r.registered))
+	for _, registered := range r.registered {
+		registered.cancel()
+		urls = append(urls, registered.url)
+	}
+	r.lock.Unlock()
+	r.wg.Wait()
+
+	for _, url := range urls {
+		if err := r.UnRegister(url); err != nil {
+			logger.Errorf("Deregister URL:%+v err:%v", url, err.Error())
+		}
+	}
+}
+
+// serviceWatcher turns the changes of the instances into the service events.
+type serviceWatcher struct {
+	client      *api.Client
+	serviceName string
+	listener    registry.NotifyListener
+	// urls is keyed by the service id
+	urls map[string]*common.URL
+}
+
+func (w *serviceWatcher) query(ctx context.Context, index uint64) (uint64, error) {
+	entries, meta, err := w.client.Health().Service(w.serviceName, registryTag, true, consul.QueryOptions(ctx, index))
+	if err != nil {
+		return 0, err
+	}
+	if meta.LastIndex == index {
+		return index, nil
+	}
+	urls := make(map[string]*common.URL, len(entries))
+	for _, entry := range entries {
+		if u := generateURL(entry); u != nil {
+			urls[entry.Service.ID] = u
+		}
+	}
+	for id, u := range urls {
+		old, ok := w.urls[id]
+		switch {
+		case !ok:
+			w.listener.Notify(&registry.ServiceEvent{Action: remoting.EventTypeAdd, Service: u})
+		case old.String() != u.String():
+			w.listener.Notify(&registry.ServiceEvent{Action: remoting.EventTypeUpdate, Service: u})
+		}
+	}
+	for id, u := range w.urls {
+		if _, ok := urls[id]; !ok {
+			w.listener.Notify(&registry.ServiceEvent{Action: remoting.EventTypeDel, Service: u})
+		}
+	}
+	w.urls = urls
+	return meta.LastIndex, nil
+}
@@ -0,0 +1,174 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package consul
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/hashicorp/consul/api"
+
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/registry"
+	"dubbo.apache.org/dubbo-go/v3/remoting"
+	"dubbo.apache.org/dubbo-go/v3/remoting/consul/fake"
+)
+
+func newTestRegistry(t *testing.T, server *fake.Server) *consulRegistry {
+	url, _ := common.NewURL("consul://" + server.Address() + "?consul.wait-time=1s")
+	r, err := newConsulRegistry(url)
+	assert.Nil(t, err)
+	return r.(*consulRegistry)
+}
+
+func newProviderURL(port int) *common.URL {
+	url, _ := common.NewURL("dubbo://127.0.0.1:" + strconv.Itoa(port) + "/org.apache.dubbo.Greeter?" +
+		"interface=org.apache.dubbo.Greeter&version=1.0.0&group=g&methods=Greet,SayHello&" +
+		constant.RegistryRoleKey + "=" + strconv.Itoa(common.PROVIDER))
+	return url
+}
+
+func newConsumerURL() *common.URL {
+	url, _ := common.NewURL("consumer://127.0.0.1/org.apache.dubbo.Greeter?" +
+		"interface=org.apache.dubbo.Greeter&version=1.0.0&group=g&" +
+		constant.RegistryRoleKey + "=" + strconv.Itoa(common.CONSUMER))
+	return url
+}
+
+func TestServiceName(t *testing.T) {
+	assert.Equal(t, "providers:org.apache.dubbo.Greeter:1.0.0:g", getServiceName(newProviderURL(20000)))
+	assert.Equal(t, "consumers:org.apache.dubbo.Greeter:1.0.0:g", getServiceName(newConsumerURL()))
+	assert.Equal(t, "providers:org.apache.dubbo.Greeter:1.0.0:g", getSubscribeName(newConsumerURL()))
+}
+
+func TestRegister(t *testing.T) {
+	server := fake.NewServer()
+	defer server.Close()
+	r := newTestRegistry(t, server)
+
+	assert.Nil(t, r.Register(newProviderURL(20000)))
+	entries, _, err := r.client.Health().Service("providers:org.apache.dubbo.Greeter:1.0.0:g", registryTag, true, nil)
+	assert.Nil(t, err)
+	assert.Len(t, entries, 1)
+	url := generateURL(entries[0])
+	assert.Equal(t, "dubbo", url.Protocol)
+	assert.Equal(t, "20000", url.Port)
+	assert.Equal(t, "/org.apache.dubbo.Greeter", url.Path)
+	assert.Equal(t, "1.0.0", url.GetParam(constant.VersionKey, ""))
+	assert.Equal(t, "Greet,SayHello", url.GetParam(constant.MethodsKey, ""))
+
+	listener := &mockNotifyListener{}
+	assert.Nil(t, r.LoadSubscribeInstances(newConsumerURL(), listener))
+	assert.Len(t, listener.all(), 1)
+
+	assert.Nil(t, r.UnRegister(newProviderURL(20000)))
+	entries, _, err = r.client.Health().Service("providers:org.apache.dubbo.Greeter:1.0.0:g", registryTag, false, nil)
+	assert.Nil(t, err)
+	assert.Empty(t, entries)
+
+	url, _ = common.NewURL("consul://" + server.Address() + "?consul.check=http")
+	r2, _ := newConsulRegistry(url)
+	assert.NotNil(t, r2.Register(newProviderURL(20000)))
+}
+
+func TestSubscribe(t *testing.T) {
+	server := fake.NewServer()
+	defer server.Close()
+	r := newTestRegistry(t, server)
+	defer r.Destroy()
+
+	assert.Nil(t, r.Register(newProviderURL(20000)))
+	listener := &mockNotifyListener{}
+	assert.Nil(t, r.Subscribe(newConsumerURL(), listener))
+	assert.Eventually(t, func() bool {
+		return listener.count(remoting.EventTypeAdd) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	assert.Nil(t, r.Register(newProviderURL(20001)))
+	assert.Eventually(t, func() bool {
+		return listener.count(remoting.EventTypeAdd) == 2
+	}, time.Second, 10*time.Millisecond)
+
+	// the critical instance is removed
+	server.SetCheckStatus("providers:org.apache.dubbo.Greeter:1.0.0:g:127.0.0.1:20000", api.HealthCritical)
+	assert.Eventually(t, func() bool {
+		return listener.count(remoting.EventTypeDel) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	assert.Nil(t, r.UnSubscribe(newConsumerURL(), listener))
+	assert.Empty(t, r.watchers)
+}
+
+func TestDestroy(t *testing.T) {
+	server := fake.NewServer()
+	defer server.Close()
+	r := newTestRegistry(t, server)
+
+	assert.Nil(t, r.Register(newProviderURL(20000)))
+	assert.Nil(t, r.Subscribe(newConsumerURL(), &mockNotifyListener{}))
+	assert.True(t, r.IsAvailable())
+	r.Destroy()
+	assert.False(t, r.IsAvailable())
+	entries, _, err := r.client.Health().Service("providers:org.apache.dubbo.Greeter:1.0.0:g", "", false, nil)
+	assert.Nil(t, err)
+	assert.Empty(t, entries)
+	assert.NotNil(t, r.Subscribe(newConsumerURL(), &mockNotifyListener{}))
+}
+
+type mockNotifyListener struct {
+	lock   sync.Mutex
+	events []*registry.ServiceEvent
+}
+
+func (m *mockNotifyListener) Notify(event *registry.ServiceEvent) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.events = append(m.events, event)
+}
+
+func (m *mockNotifyListener) NotifyAll(events []*registry.ServiceEvent, f func()) {
+	for _, event := range events {
+		m.Notify(event)
+	}
+	f()
+}
+
+func (m *mockNotifyListener) all() []*registry.ServiceEvent {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.events
+}
+
+func (m *mockNotifyListener) count(action remoting.EventType) int {
+	n := 0
+	for _, event := range m.all() {
+		if event.Action == action {
+			n++
+		}
+	}
+	return n
+}
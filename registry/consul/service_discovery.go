@@ -0,0 +1,322 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package consul
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+import (
+	gxset "github.com/dubbogo/gost/container/set"
+	gxpage "github.com/dubbogo/gost/hash/page"
+	"github.com/dubbogo/gost/log/logger"
+
+	"github.com/hashicorp/consul/api"
+
+	perrors "github.com/pkg/errors"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/common/extension"
+	"dubbo.apache.org/dubbo-go/v3/registry"
+	"dubbo.apache.org/dubbo-go/v3/remoting/consul"
+)
+
+// instanceTag is the tag of the instances registered by the service discovery
+const instanceTag = "dubbo-instance"
+
+func init() {
+	extension.SetServiceDiscovery(constant.ConsulKey, newConsulServiceDiscovery)
+}
+
+// consulServiceDiscovery registers the application-level instances to the local consul agent, the metadata
+// of the instances is converted to the tags.
+type consulServiceDiscovery struct {
+	descriptor string
+	url        *common.URL
+	client     *api.Client
+
+	lock sync.Mutex
+	// registered is keyed by the instance id, the value stops the heartbeat of the instance
+	registered map[string]context.CancelFunc
+	// watchers is keyed by the service name, the value stops the watcher
+	watchers  map[string]context.CancelFunc
+	listeners map[string]*gxset.HashSet
+	wg        sync.WaitGroup
+}
+
+func newConsulServiceDiscovery(url *common.URL) (registry.ServiceDiscovery, error) {
+	client, err := consul.NewClientByURL(url)
+	if err != nil {
+		return nil, perrors.WithMessage(err, "new consul client")
+	}
+	return &consulServiceDiscovery{
+		descriptor: fmt.Sprintf("consul-service-discovery[%s]", url.Location),
+		url:        url,
+		client:     client,
+		registered: make(map[string]context.CancelFunc),
+		watchers:   make(map[string]context.CancelFunc),
+		listeners:  make(map[string]*gxset.HashSet),
+	}, nil
+}
+
+func (c *consulServiceDiscovery) String() string {
+	return c.descriptor
+}
+
+// Destroy stops the watchers and the heartbeats, and deregisters the registered instances
+func (c *consulServiceDiscovery) Destroy() error {
+	c.lock.Lock()
+	for name, cancel := range c.watchers {
+		cancel()
+		delete(c.watchers, name)
+	}
+	ids := make([]string, 0, len(c.registered))
+	for id, cancel := range c.registered {
+		cancel()
+		ids = append(ids, id)
+		delete(c.registered, id)
+	}
+	c.lock.Unlock()
+	c.wg.Wait()
+
+	for _, id := range ids {
+		if err := c.client.Agent().ServiceDeregister(id); err != nil {
+			logger.Errorf("[consul] Failed to deregister the instance %s, err: %v", id, err)
+		}
+	}
+	return nil
+}
+
+// Register registers the instance to the consul agent with the health check
+func (c *consulServiceDiscovery) Register(instance registry.ServiceInstance) error {
+	registration := toRegistration(instance)
+	check, interval, err := buildCheck(c.url, registration.ID)
+	if err != nil {
+		return err
+	}
+	registration.Check = check
+	if err = c.client.Agent().ServiceRegister(registration); err != nil {
+		return perrors.WithMessagef(err, "register the instance %s to consul", registration.ID)
+	}
+
+	c.lock.Lock()
+	if _, ok := c.registered[registration.ID]; ok {
+		// the heartbeat is started already
+		c.lock.Unlock()
+		return nil
+	}
+	if interval == 0 {
+		// there is no heartbeat for the http check
+		c.registered[registration.ID] = func() {}
+		c.lock.Unlock()
+		return nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	c.registered[registration.ID] = cancel
+	c.lock.Unlock()
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		heartbeat(ctx, c.client, check.CheckID, interval, func() error {
+			// the instance may be updated since it is registered, so the current one is registered again
+			return c.Register(instance)
+		})
+	}()
+	return nil
+}
+
+// Update registers the instance again, which replaces the registered one
+func (c *consulServiceDiscovery) Update(instance registry.ServiceInstance) error {
+	return c.Register(instance)
+}
+
+// Unregister stops the heartbeat and deregisters the instance
+func (c *consulServiceDiscovery) Unregister(instance registry.ServiceInstance) error {
+	id := getInstanceID(instance)
+	c.lock.Lock()
+	if cancel, ok := c.registered[id]; ok {
+		cancel()
+		delete(c.registered, id)
+	}
+	c.lock.Unlock()
+	return c.client.Agent().ServiceDeregister(id)
+}
+
+// GetDefaultPageSize will return the default page size
+func (c *consulServiceDiscovery) GetDefaultPageSize() int {
+	return registry.DefaultPageSize
+}
+
+// GetServices returns the names of the services with the instance tag
+func (c *consulServiceDiscovery) GetServices() *gxset.HashSet {
+	res := gxset.NewSet()
+	services, _, err := c.client.Catalog().Services(nil)
+	if err != nil {
+		logger.Errorf("[consul] Failed to get the services, err: %v", err)
+		return res
+	}
+	for name, tags := range services {
+		for _, tag := range tags {
+			if tag == instanceTag {
+				res.Add(name)
+				break
+			}
+		}
+	}
+	return res
+}
+
+// GetInstances returns all the instances of the service, the instance is healthy if all its checks are passing
+func (c *consulServiceDiscovery) GetInstances(serviceName string) []registry.ServiceInstance {
+	entries, _, err := c.client.Health().Service(serviceName, instanceTag, false, nil)
+	if err != nil {
+		logger.Errorf("[consul] Failed to get the instances of %s, err: %v", serviceName, err)
+		return make([]registry.ServiceInstance, 0)
+	}
+	return toInstances(entries)
+}
+
+// GetInstancesByPage will return a page containing instances of ServiceInstance with the serviceName
+// the page will start at offset
+func (c *consulServiceDiscovery) GetInstancesByPage(serviceName string, offset int, pageSize int) gxpage.Pager {
+	all := c.GetInstances(serviceName)
+	res := make([]any, 0, pageSize)
+	for i := offset; i < len(all) && i < offset+pageSize; i++ {
+		res = append(res, all[i])
+	}
+	return gxpage.NewPage(offset, pageSize, res, len(all))
+}
+
+// GetHealthyInstancesByPage will return a page containing instances of ServiceInstance.
+// The param healthy indices that the instance should be healthy or not.
+// The page will start at offset
+func (c *consulServiceDiscovery) GetHealthyInstancesByPage(serviceName string, offset int, pageSize int, healthy bool) gxpage.Pager {
+	all := c.GetInstances(serviceName)
+	res := make([]any, 0, pageSize)
+	var (
+		i     = offset
+		count = 0
+	)
+	for i < len(all) && count < pageSize {
+		if all[i].IsHealthy() == healthy {
+			res = append(res, all[i])
+			count++
+		}
+		i++
+	}
+	return gxpage.NewPage(offset, pageSize, res, len(all))
+}
+
+// GetRequestInstances Batch get all instances by the specified service names
+func (c *consulServiceDiscovery) GetRequestInstances(serviceNames []string, offset int, requestedSize int) map[string]gxpage.Pager {
+	res := make(map[string]gxpage.Pager, len(serviceNames))
+	for _, name := range serviceNames {
+		res[name] = c.GetInstancesByPage(name, offset, requestedSize)
+	}
+	return res
+}
+
+// AddListener watches the passing instances of the service names of the listener, and notifies the listener
+// of the instances when they are changed
+func (c *consulServiceDiscovery) AddListener(listener registry.ServiceInstancesChangedListener) error {
+	for _, name := range listener.GetServiceNames().Values() {
+		serviceName := name.(string)
+		c.lock.Lock()
+		listeners, ok := c.listeners[serviceName]
+		if !ok {
+			listeners = gxset.NewSet()
+			c.listeners[serviceName] = listeners
+		}
+		listeners.Add(listener)
+		_, ok = c.watchers[serviceName]
+		if ok {
+			c.lock.Unlock()
+			continue
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		c.watchers[serviceName] = cancel
+		c.lock.Unlock()
+
+		c.wg.Add(1)
+		go func() {
+			defer c.wg.Done()
+			consul.Watch(ctx, func(ctx context.Context, index uint64) (uint64, error) {
+				entries, meta, err := c.client.Health().Service(serviceName, instanceTag, true, consul.QueryOptions(ctx, index))
+				if err != nil {
+					return 0, err
+				}
+				if meta.LastIndex != index {
+					c.notify(serviceName, toInstances(entries))
+				}
+				return meta.LastIndex, nil
+			})
+		}()
+	}
+	return nil
+}
+
+func (c *consulServiceDiscovery) notify(serviceName string, instances []registry.ServiceInstance) {
+	c.lock.Lock()
+	listeners := c.listeners[serviceName].Values()
+	c.lock.Unlock()
+	event := registry.NewServiceInstancesChangedEvent(serviceName, instances)
+	for _, l := range listeners {
+		if err := l.(registry.ServiceInstancesChangedListener).OnEvent(event); err != nil {
+			logger.Warnf("[consul] Failed to notify the instances of %s, err: %v", serviceName, err)
+		}
+	}
+}
+
+func getInstanceID(instance registry.ServiceInstance) string {
+	if id := instance.GetID(); id != "" {
+		return id
+	}
+	return instance.GetHost() + ":" + strconv.Itoa(instance.GetPort())
+}
+
+func toRegistration(instance registry.ServiceInstance) *api.AgentServiceRegistration {
+	return &api.AgentServiceRegistration{
+		ID:      getInstanceID(instance),
+		Name:    instance.GetServiceName(),
+		Tags:    toTags(instance.GetMetadata(), instanceTag),
+		Address: instance.GetHost(),
+		Port:    instance.GetPort(),
+	}
+}
+
+func toInstances(entries []*api.ServiceEntry) []registry.ServiceInstance {
+	instances := make([]registry.ServiceInstance, 0, len(entries))
+	for _, entry := range entries {
+		instances = append(instances, &registry.DefaultServiceInstance{
+			ID:          entry.Service.ID,
+			ServiceName: entry.Service.Service,
+			Host:        entryAddress(entry),
+			Port:        entry.Service.Port,
+			Enable:      true,
+			Healthy:     entry.Checks.AggregatedStatus() == api.HealthPassing,
+			Metadata:    fromTags(entry.Service.Tags),
+		})
+	}
+	return instances
+}
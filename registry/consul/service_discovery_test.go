@@ -0,0 +1,158 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package consul
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+import (
+	gxset "github.com/dubbogo/gost/container/set"
+	"github.com/dubbogo/gost/gof/observer"
+
+	"github.com/hashicorp/consul/api"
+
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/registry"
+	"dubbo.apache.org/dubbo-go/v3/remoting/consul/fake"
+)
+
+const testName = "provider"
+
+func newTestServiceDiscovery(t *testing.T, server *fake.Server) *consulServiceDiscovery {
+	url, _ := common.NewURL("consul://" + server.Address() + "?consul.wait-time=1s")
+	sd, err := newConsulServiceDiscovery(url)
+	assert.Nil(t, err)
+	return sd.(*consulServiceDiscovery)
+}
+
+func newTestInstance(host string) *registry.DefaultServiceInstance {
+	return &registry.DefaultServiceInstance{
+		ID:          host + ":20000",
+		ServiceName: testName,
+		Host:        host,
+		Port:        20000,
+		Enable:      true,
+		Healthy:     true,
+		Metadata:    map[string]string{constant.ExportedServicesRevisionPropertyName: "1"},
+	}
+}
+
+func TestServiceDiscoveryRegister(t *testing.T) {
+	server := fake.NewServer()
+	defer server.Close()
+	sd := newTestServiceDiscovery(t, server)
+	defer func() {
+		_ = sd.Destroy()
+	}()
+
+	assert.Nil(t, sd.Register(newTestInstance("10.0.0.1")))
+	assert.Nil(t, sd.Register(newTestInstance("10.0.0.2")))
+	assert.True(t, sd.GetServices().Contains(testName))
+
+	instances := sd.GetInstances(testName)
+	assert.Len(t, instances, 2)
+	assert.Equal(t, "10.0.0.1", instances[0].GetHost())
+	assert.Equal(t, "1", instances[0].GetMetadata()[constant.ExportedServicesRevisionPropertyName])
+	assert.True(t, instances[0].IsHealthy())
+
+	ins := newTestInstance("10.0.0.1")
+	ins.Metadata[constant.ExportedServicesRevisionPropertyName] = "2"
+	assert.Nil(t, sd.Update(ins))
+	assert.Equal(t, "2", sd.GetInstances(testName)[0].GetMetadata()[constant.ExportedServicesRevisionPropertyName])
+
+	server.SetCheckStatus("10.0.0.2:20000", api.HealthCritical)
+	assert.Equal(t, 1, sd.GetHealthyInstancesByPage(testName, 0, 10, true).GetDataSize())
+	assert.Equal(t, 1, sd.GetHealthyInstancesByPage(testName, 0, 10, false).GetDataSize())
+	assert.Equal(t, 2, sd.GetInstancesByPage(testName, 0, 10).GetDataSize())
+	assert.Equal(t, 2, sd.GetRequestInstances([]string{testName}, 0, 10)[testName].GetDataSize())
+
+	assert.Nil(t, sd.Unregister(ins))
+	assert.Len(t, sd.GetInstances(testName), 1)
+	assert.Equal(t, registry.DefaultPageSize, sd.GetDefaultPageSize())
+}
+
+func TestServiceDiscoveryAddListener(t *testing.T) {
+	server := fake.NewServer()
+	defer server.Close()
+	sd := newTestServiceDiscovery(t, server)
+	defer func() {
+		_ = sd.Destroy()
+	}()
+
+	assert.Nil(t, sd.Register(newTestInstance("10.0.0.1")))
+	listener := &mockListener{services: gxset.NewSet(testName)}
+	assert.Nil(t, sd.AddListener(listener))
+	assert.Eventually(t, func() bool {
+		return len(listener.last()) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	assert.Nil(t, sd.Register(newTestInstance("10.0.0.2")))
+	assert.Eventually(t, func() bool {
+		return len(listener.last()) == 2
+	}, time.Second, 10*time.Millisecond)
+
+	server.SetCheckStatus("10.0.0.1:20000", api.HealthCritical)
+	assert.Eventually(t, func() bool {
+		instances := listener.last()
+		return len(instances) == 1 && instances[0].GetHost() == "10.0.0.2"
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestServiceDiscoveryDestroy(t *testing.T) {
+	server := fake.NewServer()
+	defer server.Close()
+	sd := newTestServiceDiscovery(t, server)
+
+	assert.Nil(t, sd.Register(newTestInstance("10.0.0.1")))
+	assert.Nil(t, sd.AddListener(&mockListener{services: gxset.NewSet(testName)}))
+	assert.Nil(t, sd.Destroy())
+	assert.Empty(t, sd.GetInstances(testName))
+}
+
+type mockListener struct {
+	registry.ServiceInstancesChangedListener
+	services *gxset.HashSet
+
+	lock      sync.Mutex
+	instances []registry.ServiceInstance
+}
+
+func (m *mockListener) GetServiceNames() *gxset.HashSet {
+	return m.services
+}
+
+func (m *mockListener) OnEvent(e observer.Event) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.instances = e.(*registry.ServiceInstancesChangedEvent).Instances
+	return nil
+}
+
+func (m *mockListener) last() []registry.ServiceInstance {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.instances
+}
@@ -47,6 +47,7 @@ import (
 	protocolbase "dubbo.apache.org/dubbo-go/v3/protocol/base"
 	"dubbo.apache.org/dubbo-go/v3/protocol/protocolwrapper"
 	"dubbo.apache.org/dubbo-go/v3/registry"
+	"dubbo.apache.org/dubbo-go/v3/registry/snapshot"
 	"dubbo.apache.org/dubbo-go/v3/remoting"
 )
 
@@ -73,6 +74,13 @@ type RegistryDirectory struct {
 	registerLock                   sync.Mutex // this lock if for register
 	SubscribedUrl                  *common.URL
 	RegisteredUrl                  *common.URL
+
+	// snapshots is nil if the snapshots of the providers are not enabled
+	snapshots         *snapshot.Store
+	snapshotLock      sync.Mutex
+	snapshotProviders map[string]*common.URL // the provider urls notified by the registry, keyed by url.Key()
+	lastSnapshot      *snapshot.Snapshot
+	fromSnapshot      bool // the providers are loaded from the snapshot and not replaced by the registry yet
 }
 
 // NewRegistryDirectory will create a new RegistryDirectory
@@ -101,8 +109,22 @@ func NewRegistryDirectory(url *common.URL, registry registry.Registry) (director
 	dir.consumerConfigurationListener.addNotifyListener(dir)
 	dir.referenceConfigurationListener = newReferenceConfigurationListener(dir, url)
 
-	if err := dir.registry.LoadSubscribeInstances(url.SubURL, dir); err != nil {
-		return nil, err
+	snapshots, err := snapshot.NewStoreByURL(url)
+	if err != nil {
+		logger.Warnf("The snapshots of the providers are disabled, err: %v", err)
+	}
+	if snapshots != nil {
+		dir.snapshots = snapshots
+		dir.snapshotProviders = make(map[string]*common.URL)
+	}
+
+	if err = dir.registry.LoadSubscribeInstances(url.SubURL, dir); err != nil {
+		if !dir.loadSnapshot() {
+			return nil, err
+		}
+		logger.Warnf("Failed to load the providers of %s from the registry, err: %v", url.SubURL.ServiceKey(), err)
+	} else if !dir.registry.IsAvailable() && len(dir.cacheInvokers) == 0 {
+		dir.loadSnapshot()
 	}
 	metrics.Publish(metricsRegistry.NewDirectoryEvent(metricsRegistry.NumAllInc))
 	return dir, nil
@@ -166,14 +188,21 @@ func (dir *RegistryDirectory) Notify(event *registry.ServiceEvent) {
 		return
 	}
 	start := time.Now()
+	dropped := dir.recordEvents([]*registry.ServiceEvent{event}, false)
 	dir.refreshInvokers(event)
+	for _, invoker := range dropped {
+		invoker.Destroy()
+	}
+	dir.saveSnapshot()
 	metrics.Publish(metricsRegistry.NewNotifyEvent(start))
 }
 
 // NotifyAll notify the events that are complete Service Event List.
 // After notify the address, the callback func will be invoked.
 func (dir *RegistryDirectory) NotifyAll(events []*registry.ServiceEvent, callback func()) {
+	dir.recordEvents(events, true)
 	dir.refreshAllInvokers(events, callback)
+	dir.saveSnapshot()
 }
 
 // refreshInvokers refreshes service's events.
@@ -0,0 +1,140 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package directory
+
+import (
+	"github.com/dubbogo/gost/log/logger"
+
+	perrors "github.com/pkg/errors"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	protocolbase "dubbo.apache.org/dubbo-go/v3/protocol/base"
+	"dubbo.apache.org/dubbo-go/v3/registry"
+	"dubbo.apache.org/dubbo-go/v3/registry/snapshot"
+	"dubbo.apache.org/dubbo-go/v3/remoting"
+)
+
+// snapshotKey distinguishes the snapshots of the same service from the different registries
+func (dir *RegistryDirectory) snapshotKey() string {
+	url := dir.GetURL()
+	return url.Protocol + "://" + url.Location + "/" + url.SubURL.ServiceKey()
+}
+
+// loadSnapshot loads the providers from the snapshot if the registry is unreachable, it returns false if there
+// is no valid snapshot.
+func (dir *RegistryDirectory) loadSnapshot() bool {
+	if dir.snapshots == nil {
+		return false
+	}
+	urls, s, err := dir.snapshots.Load(dir.snapshotKey())
+	if err != nil {
+		if !perrors.Is(err, snapshot.ErrNotFound) {
+			logger.Warnf("[Registry Directory] Failed to load the snapshot of %s, err: %v", dir.snapshotKey(), err)
+		}
+		return false
+	}
+	events := make([]*registry.ServiceEvent, 0, len(urls))
+	providers := make(map[string]*common.URL, len(urls))
+	for _, url := range urls {
+		events = append(events, &registry.ServiceEvent{Action: remoting.EventTypeUpdate, Service: url})
+		providers[url.Key()] = url
+	}
+	dir.snapshotLock.Lock()
+	dir.fromSnapshot = true
+	dir.snapshotProviders = providers
+	dir.lastSnapshot = s
+	dir.snapshotLock.Unlock()
+	dir.refreshAllInvokers(events, func() {})
+	logger.Warnf("[Registry Directory] The registry is unreachable, %d providers of %s are loaded from the snapshot saved at %s",
+		len(urls), dir.snapshotKey(), s.Timestamp)
+	return true
+}
+
+// recordEvents records the provider urls of the events notified by the registry. The providers loaded from the
+// snapshot are replaced by the first notification, the invokers of them are returned if the notification is
+// incremental, and the caller should destroy them once the invokers are refreshed.
+func (dir *RegistryDirectory) recordEvents(events []*registry.ServiceEvent, complete bool) []protocolbase.Invoker {
+	if dir.snapshots == nil {
+		return nil
+	}
+	dir.snapshotLock.Lock()
+	defer dir.snapshotLock.Unlock()
+
+	var dropped []protocolbase.Invoker
+	if dir.fromSnapshot {
+		dir.fromSnapshot = false
+		logger.Infof("[Registry Directory] The providers of %s loaded from the snapshot are replaced by the registry",
+			dir.snapshotKey())
+		if !complete {
+			dir.registerLock.Lock()
+			dir.cacheInvokersMap.Range(func(key, _ any) bool {
+				if invoker := dir.uncacheInvokerWithKey(key.(string)); invoker != nil {
+					dropped = append(dropped, invoker)
+				}
+				return true
+			})
+			dir.registerLock.Unlock()
+		}
+		dir.snapshotProviders = make(map[string]*common.URL)
+	}
+	if complete {
+		dir.snapshotProviders = make(map[string]*common.URL, len(events))
+	}
+	for _, event := range events {
+		url := event.Service
+		if url == nil || url.Protocol == constant.OverrideProtocol || url.Protocol == constant.RouterProtocol ||
+			url.GetParam(constant.CategoryKey, constant.DefaultCategory) != constant.DefaultCategory {
+			continue
+		}
+		if event.Action == remoting.EventTypeDel {
+			delete(dir.snapshotProviders, url.Key())
+		} else {
+			dir.snapshotProviders[url.Key()] = url
+		}
+	}
+	return dropped
+}
+
+// saveSnapshot saves the providers notified by the registry if they are changed. The empty providers are not
+// saved, so the last known providers are kept.
+func (dir *RegistryDirectory) saveSnapshot() {
+	if dir.snapshots == nil {
+		return
+	}
+	dir.snapshotLock.Lock()
+	defer dir.snapshotLock.Unlock()
+	if dir.fromSnapshot || len(dir.snapshotProviders) == 0 {
+		return
+	}
+	urls := make([]*common.URL, 0, len(dir.snapshotProviders))
+	for _, url := range dir.snapshotProviders {
+		urls = append(urls, url)
+	}
+	if dir.lastSnapshot.Equal(urls) {
+		return
+	}
+	s, err := dir.snapshots.Save(dir.snapshotKey(), urls)
+	if err != nil {
+		logger.Warnf("[Registry Directory] Failed to save the snapshot of %s, err: %v", dir.snapshotKey(), err)
+		return
+	}
+	dir.lastSnapshot = s
+}
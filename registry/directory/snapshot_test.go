@@ -0,0 +1,152 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package directory
+
+import (
+	"testing"
+)
+
+import (
+	perrors "github.com/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/common/extension"
+	"dubbo.apache.org/dubbo-go/v3/protocol/protocolwrapper"
+	"dubbo.apache.org/dubbo-go/v3/registry"
+	"dubbo.apache.org/dubbo-go/v3/registry/snapshot"
+	"dubbo.apache.org/dubbo-go/v3/remoting"
+)
+
+func newSnapshotURL(dir string) *common.URL {
+	url, _ := common.NewURL("mock://127.0.0.1:1111",
+		common.WithParamsValue(constant.RegistrySnapshotKey, "true"),
+		common.WithParamsValue(constant.RegistrySnapshotDirKey, dir))
+	url.SubURL, _ = common.NewURL("dubbo://127.0.0.1:20000/org.apache.dubbo-go.mockService",
+		common.WithParamsValue(constant.ClusterKey, "mock"),
+		common.WithParamsValue(constant.GroupKey, "group"),
+		common.WithParamsValue(constant.VersionKey, "1.0.0"))
+	return url
+}
+
+func newSnapshotProvider(port string) *common.URL {
+	url, _ := common.NewURL("dubbo://127.0.0.1:"+port+"/org.apache.dubbo-go.mockService",
+		common.WithParamsValue(constant.GroupKey, "group"),
+		common.WithParamsValue(constant.VersionKey, "1.0.0"))
+	return url
+}
+
+func TestSnapshot(t *testing.T) {
+	extension.SetProtocol(protocolwrapper.FILTER, protocolwrapper.NewMockProtocolFilter)
+	snapshotDir := t.TempDir()
+
+	// the providers notified by the registry are saved
+	reachable := &snapshotRegistry{available: true, providers: []*common.URL{newSnapshotProvider("20001"), newSnapshotProvider("20002")}}
+	dir, err := NewRegistryDirectory(newSnapshotURL(snapshotDir), reachable)
+	assert.Nil(t, err)
+	registryDir := dir.(*RegistryDirectory)
+	assert.Len(t, registryDir.cacheInvokers, 2)
+	urls, _, err := registryDir.snapshots.Load(registryDir.snapshotKey())
+	assert.Nil(t, err)
+	assert.Len(t, urls, 2)
+	// the empty providers are not saved
+	registryDir.NotifyAll([]*registry.ServiceEvent{}, func() {})
+	assert.Len(t, registryDir.cacheInvokers, 0)
+	urls, _, _ = registryDir.snapshots.Load(registryDir.snapshotKey())
+	assert.Len(t, urls, 2)
+	registryDir.Destroy()
+
+	// the providers are loaded from the snapshot if the registry is unreachable
+	unreachable := &snapshotRegistry{}
+	dir, err = NewRegistryDirectory(newSnapshotURL(snapshotDir), unreachable)
+	assert.Nil(t, err)
+	registryDir = dir.(*RegistryDirectory)
+	assert.True(t, registryDir.fromSnapshot)
+	assert.Len(t, registryDir.cacheInvokers, 2)
+
+	// the snapshot is replaced by the first notification
+	registryDir.Notify(&registry.ServiceEvent{Action: remoting.EventTypeAdd, Service: newSnapshotProvider("20003")})
+	assert.False(t, registryDir.fromSnapshot)
+	assert.Len(t, registryDir.cacheInvokers, 1)
+	assert.Equal(t, "20003", registryDir.cacheInvokers[0].GetURL().Port)
+	urls, _, _ = registryDir.snapshots.Load(registryDir.snapshotKey())
+	assert.Len(t, urls, 1)
+	assert.Equal(t, "20003", urls[0].Port)
+	registryDir.Notify(&registry.ServiceEvent{Action: remoting.EventTypeAdd, Service: newSnapshotProvider("20004")})
+	assert.Len(t, registryDir.cacheInvokers, 2)
+	registryDir.Destroy()
+
+	// no snapshot of the service
+	assert.Nil(t, snapshot.NewStore(snapshotDir, 0).Remove(registryDir.snapshotKey()))
+	_, err = NewRegistryDirectory(newSnapshotURL(snapshotDir), unreachable)
+	assert.NotNil(t, err)
+
+	// the snapshots are not enabled
+	url := newSnapshotURL(snapshotDir)
+	url.SetParam(constant.RegistrySnapshotKey, "false")
+	dir, err = NewRegistryDirectory(url, reachable)
+	assert.Nil(t, err)
+	assert.Nil(t, dir.(*RegistryDirectory).snapshots)
+}
+
+// snapshotRegistry notifies the providers on loading if it is available, or fails
+type snapshotRegistry struct {
+	available bool
+	providers []*common.URL
+}
+
+func (r *snapshotRegistry) GetURL() *common.URL {
+	return nil
+}
+
+func (r *snapshotRegistry) IsAvailable() bool {
+	return r.available
+}
+
+func (r *snapshotRegistry) Destroy() {
+}
+
+func (r *snapshotRegistry) Register(*common.URL) error {
+	return nil
+}
+
+func (r *snapshotRegistry) UnRegister(*common.URL) error {
+	return nil
+}
+
+func (r *snapshotRegistry) Subscribe(*common.URL, registry.NotifyListener) error {
+	return nil
+}
+
+func (r *snapshotRegistry) UnSubscribe(*common.URL, registry.NotifyListener) error {
+	return nil
+}
+
+func (r *snapshotRegistry) LoadSubscribeInstances(_ *common.URL, listener registry.NotifyListener) error {
+	if !r.available {
+		return perrors.New("the registry is unreachable")
+	}
+	for _, provider := range r.providers {
+		listener.Notify(&registry.ServiceEvent{Action: remoting.EventTypeAdd, Service: provider})
+	}
+	return nil
+}
@@ -0,0 +1,249 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package file
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+import (
+	"github.com/dubbogo/gost/log/logger"
+
+	"github.com/fsnotify/fsnotify"
+
+	perrors "github.com/pkg/errors"
+
+	"gopkg.in/yaml.v2"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/registry"
+)
+
+// registryFile is the content of the file, e.g.
+//
+//	services:
+//	  org.apache.dubbo.Greeter:
+//	    - tri://127.0.0.1:20000/org.apache.dubbo.Greeter?version=1.0.0
+//	applications:
+//	  greeter:
+//	    - host: 127.0.0.1
+//	      port: 20000
+//	      metadata:
+//	        dubbo.metadata.storage-type: local
+type registryFile struct {
+	// Services is keyed by the interface, the value is the urls of the providers
+	Services map[string][]string `yaml:"services" json:"services"`
+	// Applications is keyed by the application, the value is the instances of the application
+	Applications map[string][]*instanceConfig `yaml:"applications" json:"applications"`
+}
+
+type instanceConfig struct {
+	ID       string            `yaml:"id" json:"id"`
+	Host     string            `yaml:"host" json:"host"`
+	Port     int               `yaml:"port" json:"port"`
+	Weight   int64             `yaml:"weight" json:"weight"`
+	Enable   *bool             `yaml:"enable" json:"enable"`
+	Healthy  *bool             `yaml:"healthy" json:"healthy"`
+	Tag      string            `yaml:"tag" json:"tag"`
+	Metadata map[string]string `yaml:"metadata" json:"metadata"`
+}
+
+// snapshot is the parsed content of the file
+type snapshot struct {
+	// urls is keyed by the interface
+	urls map[string][]*common.URL
+	// instances is keyed by the application
+	instances map[string][]registry.ServiceInstance
+}
+
+func parse(path string, data []byte) (*snapshot, error) {
+	rf := &registryFile{}
+	var err error
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = json.Unmarshal(data, rf)
+	} else {
+		err = yaml.Unmarshal(data, rf)
+	}
+	if err != nil {
+		return nil, perrors.WithMessagef(err, "parse %s", path)
+	}
+
+	s := &snapshot{
+		urls:      make(map[string][]*common.URL, len(rf.Services)),
+		instances: make(map[string][]registry.ServiceInstance, len(rf.Applications)),
+	}
+	for intf, rawURLs := range rf.Services {
+		for _, rawURL := range rawURLs {
+			u, err := common.NewURL(rawURL)
+			if err != nil {
+				return nil, perrors.WithMessagef(err, "illegal url %s of %s", rawURL, intf)
+			}
+			if u.GetParam(constant.InterfaceKey, "") == "" {
+				u.SetParam(constant.InterfaceKey, intf)
+			}
+			s.urls[intf] = append(s.urls[intf], u)
+		}
+	}
+	for app, configs := range rf.Applications {
+		for i, c := range configs {
+			if c == nil || c.Host == "" || c.Port <= 0 {
+				return nil, perrors.Errorf("the host and the port of the instance %d of %s are required", i, app)
+			}
+			s.instances[app] = append(s.instances[app], c.toInstance(app))
+		}
+	}
+	return s, nil
+}
+
+func (c *instanceConfig) toInstance(app string) registry.ServiceInstance {
+	id := c.ID
+	if id == "" {
+		id = c.Host + ":" + strconv.Itoa(c.Port)
+	}
+	metadata := c.Metadata
+	if metadata == nil {
+		metadata = make(map[string]string)
+	}
+	return &registry.DefaultServiceInstance{
+		ID:          id,
+		ServiceName: app,
+		Host:        c.Host,
+		Port:        c.Port,
+		Weight:      c.Weight,
+		Enable:      c.Enable == nil || *c.Enable,
+		Healthy:     c.Healthy == nil || *c.Healthy,
+		Tag:         c.Tag,
+		Metadata:    metadata,
+	}
+}
+
+// watchedFile keeps the latest snapshot of the file, and calls the handlers with the old and the new snapshots
+// once the file is changed. The illegal content is ignored, so the last legal snapshot is kept.
+type watchedFile struct {
+	path    string
+	watcher *fsnotify.Watcher
+
+	lock     sync.RWMutex
+	snapshot *snapshot
+	handlers []func(old, cur *snapshot)
+}
+
+func newWatchedFile(path string) (*watchedFile, error) {
+	if path == "" {
+		return nil, perrors.Errorf("the path of the registry file is required, which is set by %s", constant.FileRegistryPathKey)
+	}
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, perrors.WithStack(err)
+	}
+	s, err := parse(path, data)
+	if err != nil {
+		return nil, err
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, perrors.WithStack(err)
+	}
+	// the directory is watched, since the editors usually replace the file by renaming another one
+	if err = watcher.Add(filepath.Dir(path)); err != nil {
+		_ = watcher.Close()
+		return nil, perrors.WithStack(err)
+	}
+	f := &watchedFile{path: path, watcher: watcher, snapshot: s}
+	go f.watch()
+	return f, nil
+}
+
+func (f *watchedFile) current() *snapshot {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	return f.snapshot
+}
+
+func (f *watchedFile) addHandler(handler func(old, cur *snapshot)) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.handlers = append(f.handlers, handler)
+}
+
+func (f *watchedFile) close() error {
+	return f.watcher.Close()
+}
+
+func (f *watchedFile) watch() {
+	for {
+		select {
+		case event, ok := <-f.watcher.Events:
+			if !ok {
+				return
+			}
+			if filepath.Clean(event.Name) != f.path || event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) == 0 {
+				continue
+			}
+			f.reload()
+		case err, ok := <-f.watcher.Errors:
+			if !ok {
+				return
+			}
+			logger.Warnf("[File Registry] Failed to watch %s, err: %v", f.path, err)
+		}
+	}
+}
+
+func (f *watchedFile) reload() {
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		// the file is being replaced, it will be reloaded by the create event
+		logger.Debugf("[File Registry] Failed to read %s, err: %v", f.path, err)
+		return
+	}
+	s, err := parse(f.path, data)
+	if err != nil {
+		logger.Warnf("[File Registry] Ignore the illegal content of %s, err: %v", f.path, err)
+		return
+	}
+	f.lock.Lock()
+	old := f.snapshot
+	f.snapshot = s
+	handlers := make([]func(old, cur *snapshot), len(f.handlers))
+	copy(handlers, f.handlers)
+	f.lock.Unlock()
+	for _, handler := range handlers {
+		handler(old, s)
+	}
+}
+
+// getPath returns the path of the file, which is set by the param, or the address of the registry url.
+func getPath(url *common.URL) string {
+	if path := url.GetParam(constant.FileRegistryPathKey, ""); path != "" {
+		return path
+	}
+	return url.Location + url.Path
+}
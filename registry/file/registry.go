@@ -0,0 +1,188 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package file
+
+import (
+	"strconv"
+	"sync"
+)
+
+import (
+	"github.com/dubbogo/gost/log/logger"
+
+	perrors "github.com/pkg/errors"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/common/extension"
+	"dubbo.apache.org/dubbo-go/v3/registry"
+	"dubbo.apache.org/dubbo-go/v3/remoting"
+)
+
+func init() {
+	extension.SetRegistry(constant.FileKey, newFileRegistry)
+}
+
+// fileRegistry is the interface-level registry whose providers are listed in the file. The providers can't
+// be registered, they are added or removed by editing the file.
+type fileRegistry struct {
+	*common.URL
+	file *watchedFile
+	done chan struct{}
+
+	lock        sync.Mutex
+	subscribers map[subscriber]*common.URL
+}
+
+type subscriber struct {
+	serviceKey string
+	listener   registry.NotifyListener
+}
+
+func newFileRegistry(url *common.URL) (registry.Registry, error) {
+	file, err := newWatchedFile(getPath(url))
+	if err != nil {
+		return nil, err
+	}
+	r := &fileRegistry{
+		URL:         url,
+		file:        file,
+		done:        make(chan struct{}),
+		subscribers: make(map[subscriber]*common.URL),
+	}
+	file.addHandler(r.onChange)
+	return r, nil
+}
+
+// Register does nothing, since the providers are listed in the file.
+func (r *fileRegistry) Register(url *common.URL) error {
+	logger.Debugf("[File Registry] Ignore registering %s, the providers are listed in %s", url.Key(), r.file.path)
+	return nil
+}
+
+// UnRegister does nothing, since the providers are listed in the file.
+func (r *fileRegistry) UnRegister(*common.URL) error {
+	return nil
+}
+
+// Subscribe notifies the listener of the providers of the url in the file, and the changes of them once the
+// file is changed.
+func (r *fileRegistry) Subscribe(url *common.URL, notifyListener registry.NotifyListener) error {
+	role, _ := strconv.Atoi(url.GetParam(constant.RegistryRoleKey, ""))
+	if role != common.CONSUMER {
+		return nil
+	}
+	if !r.IsAvailable() {
+		return perrors.New("fileRegistry is not available.")
+	}
+	r.lock.Lock()
+	r.subscribers[subscriber{serviceKey: url.ServiceKey(), listener: notifyListener}] = url
+	r.lock.Unlock()
+	return r.LoadSubscribeInstances(url, notifyListener)
+}
+
+// UnSubscribe stops notifying the listener of the changes of the providers.
+func (r *fileRegistry) UnSubscribe(url *common.URL, notifyListener registry.NotifyListener) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	delete(r.subscribers, subscriber{serviceKey: url.ServiceKey(), listener: notifyListener})
+	return nil
+}
+
+// LoadSubscribeInstances load subscribe instance
+func (r *fileRegistry) LoadSubscribeInstances(url *common.URL, notify registry.NotifyListener) error {
+	for _, provider := range providers(r.file.current(), url) {
+		notify.Notify(&registry.ServiceEvent{Action: remoting.EventTypeAdd, Service: provider.Clone()})
+	}
+	return nil
+}
+
+// GetURL gets its registration URL
+func (r *fileRegistry) GetURL() *common.URL {
+	return r.URL
+}
+
+// IsAvailable returns false if the registry is destroyed
+func (r *fileRegistry) IsAvailable() bool {
+	select {
+	case <-r.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// Destroy stops watching the file
+func (r *fileRegistry) Destroy() {
+	select {
+	case <-r.done:
+		return
+	default:
+		close(r.done)
+	}
+	if err := r.file.close(); err != nil {
+		logger.Warnf("[File Registry] Failed to close the watcher of %s, err: %v", r.file.path, err)
+	}
+}
+
+// onChange notifies the subscribers of the providers which are added or removed
+func (r *fileRegistry) onChange(old, cur *snapshot) {
+	r.lock.Lock()
+	subscribers := make(map[subscriber]*common.URL, len(r.subscribers))
+	for s, url := range r.subscribers {
+		subscribers[s] = url
+	}
+	r.lock.Unlock()
+
+	for s, url := range subscribers {
+		oldURLs, curURLs := toMap(providers(old, url)), toMap(providers(cur, url))
+		for k, u := range oldURLs {
+			if _, ok := curURLs[k]; !ok {
+				s.listener.Notify(&registry.ServiceEvent{Action: remoting.EventTypeDel, Service: u.Clone()})
+			}
+		}
+		for k, u := range curURLs {
+			if _, ok := oldURLs[k]; !ok {
+				s.listener.Notify(&registry.ServiceEvent{Action: remoting.EventTypeAdd, Service: u.Clone()})
+			}
+		}
+	}
+}
+
+// providers returns the providers in the snapshot matching the service key of the url
+func providers(s *snapshot, url *common.URL) []*common.URL {
+	var res []*common.URL
+	for _, urls := range s.urls {
+		for _, u := range urls {
+			if u.ServiceKey() == url.ServiceKey() || common.IsAnyCondition(url.Service(), url.Group(), url.Version(), u) {
+				res = append(res, u)
+			}
+		}
+	}
+	return res
+}
+
+func toMap(urls []*common.URL) map[string]*common.URL {
+	m := make(map[string]*common.URL, len(urls))
+	for _, u := range urls {
+		m[u.String()] = u
+	}
+	return m
+}
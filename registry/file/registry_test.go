@@ -0,0 +1,252 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+import (
+	gxset "github.com/dubbogo/gost/container/set"
+	"github.com/dubbogo/gost/gof/observer"
+
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/registry"
+	"dubbo.apache.org/dubbo-go/v3/remoting"
+)
+
+const (
+	testYAML = `
+services:
+  org.apache.dubbo.Greeter:
+    - tri://127.0.0.1:20000/org.apache.dubbo.Greeter?version=1.0.0
+    - tri://127.0.0.1:20001/org.apache.dubbo.Greeter?version=2.0.0
+  org.apache.dubbo.Echo:
+    - tri://127.0.0.1:20000/org.apache.dubbo.Echo
+applications:
+  greeter:
+    - host: 127.0.0.1
+      port: 20000
+      metadata:
+        dubbo.metadata.revision: "1"
+    - host: 127.0.0.2
+      port: 20000
+      healthy: false
+`
+	testJSON = `{"services": {"org.apache.dubbo.Greeter": ["tri://127.0.0.1:20000/org.apache.dubbo.Greeter"]}}`
+)
+
+func writeFile(t *testing.T, path, content string) {
+	// the file is replaced like the editors do
+	tmp := path + ".tmp"
+	assert.Nil(t, os.WriteFile(tmp, []byte(content), 0o644))
+	assert.Nil(t, os.Rename(tmp, path))
+}
+
+func newTestRegistry(t *testing.T, content string) (string, *fileRegistry) {
+	path := filepath.Join(t.TempDir(), "registry.yaml")
+	writeFile(t, path, content)
+	url, _ := common.NewURL("file://" + path)
+	r, err := newFileRegistry(url)
+	assert.Nil(t, err)
+	return path, r.(*fileRegistry)
+}
+
+func newConsumerURL(version string) *common.URL {
+	url, _ := common.NewURL("consumer://127.0.0.1/org.apache.dubbo.Greeter?interface=org.apache.dubbo.Greeter&version=" +
+		version + "&" + constant.RegistryRoleKey + "=" + strconv.Itoa(common.CONSUMER))
+	return url
+}
+
+func TestParse(t *testing.T) {
+	s, err := parse("registry.yaml", []byte(testYAML))
+	assert.Nil(t, err)
+	assert.Len(t, s.urls["org.apache.dubbo.Greeter"], 2)
+	assert.Equal(t, "org.apache.dubbo.Echo", s.urls["org.apache.dubbo.Echo"][0].GetParam(constant.InterfaceKey, ""))
+	assert.Len(t, s.instances["greeter"], 2)
+	assert.Equal(t, "127.0.0.1:20000", s.instances["greeter"][0].GetID())
+	assert.True(t, s.instances["greeter"][0].IsHealthy())
+	assert.False(t, s.instances["greeter"][1].IsHealthy())
+
+	s, err = parse("registry.json", []byte(testJSON))
+	assert.Nil(t, err)
+	assert.Len(t, s.urls["org.apache.dubbo.Greeter"], 1)
+
+	_, err = parse("registry.yaml", []byte("applications:\n  greeter:\n    - port: 20000"))
+	assert.NotNil(t, err)
+	_, err = parse("registry.json", []byte(testYAML))
+	assert.NotNil(t, err)
+}
+
+func TestNewFileRegistry(t *testing.T) {
+	url, _ := common.NewURL("file://" + filepath.Join(t.TempDir(), "none.yaml"))
+	_, err := newFileRegistry(url)
+	assert.NotNil(t, err)
+
+	_, r := newTestRegistry(t, testYAML)
+	assert.True(t, r.IsAvailable())
+	r.Destroy()
+	assert.False(t, r.IsAvailable())
+	assert.NotNil(t, r.Subscribe(newConsumerURL("1.0.0"), &mockNotifyListener{}))
+}
+
+func TestSubscribe(t *testing.T) {
+	path, r := newTestRegistry(t, testYAML)
+	defer r.Destroy()
+
+	listener := &mockNotifyListener{}
+	assert.Nil(t, r.Subscribe(newConsumerURL("1.0.0"), listener))
+	events := listener.all()
+	assert.Len(t, events, 1)
+	assert.Equal(t, "20000", events[0].Service.Port)
+
+	// the provider of 1.0.0 is moved to another port
+	writeFile(t, path, `
+services:
+  org.apache.dubbo.Greeter:
+    - tri://127.0.0.1:20002/org.apache.dubbo.Greeter?version=1.0.0
+`)
+	assert.Eventually(t, func() bool {
+		return len(listener.all()) == 3
+	}, 3*time.Second, 10*time.Millisecond)
+	events = listener.all()
+	assert.Equal(t, remoting.EventTypeDel, events[1].Action)
+	assert.Equal(t, "20000", events[1].Service.Port)
+	assert.Equal(t, remoting.EventTypeAdd, events[2].Action)
+	assert.Equal(t, "20002", events[2].Service.Port)
+
+	// the illegal content is ignored
+	writeFile(t, path, "services: [")
+	time.Sleep(100 * time.Millisecond)
+	assert.Len(t, listener.all(), 3)
+	assert.Len(t, r.file.current().urls["org.apache.dubbo.Greeter"], 1)
+
+	assert.Nil(t, r.UnSubscribe(newConsumerURL("1.0.0"), listener))
+	writeFile(t, path, testYAML)
+	time.Sleep(100 * time.Millisecond)
+	assert.Len(t, listener.all(), 3)
+}
+
+func TestLoadSubscribeInstances(t *testing.T) {
+	_, r := newTestRegistry(t, testYAML)
+	defer r.Destroy()
+
+	listener := &mockNotifyListener{}
+	assert.Nil(t, r.LoadSubscribeInstances(newConsumerURL("2.0.0"), listener))
+	assert.Len(t, listener.all(), 1)
+	assert.Equal(t, "20001", listener.all()[0].Service.Port)
+
+	// the providers don't subscribe the providers
+	provider, _ := common.NewURL("tri://127.0.0.1:20000/org.apache.dubbo.Greeter?interface=org.apache.dubbo.Greeter")
+	assert.Nil(t, r.Subscribe(provider, listener))
+	assert.Len(t, listener.all(), 1)
+	assert.Nil(t, r.Register(provider))
+	assert.Nil(t, r.UnRegister(provider))
+}
+
+func TestServiceDiscovery(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "registry.yaml")
+	writeFile(t, path, testYAML)
+	url, _ := common.NewURL("file://127.0.0.1?" + constant.FileRegistryPathKey + "=" + path)
+	sd, err := newFileServiceDiscovery(url)
+	assert.Nil(t, err)
+	defer func() {
+		_ = sd.Destroy()
+	}()
+
+	assert.True(t, sd.GetServices().Contains("greeter"))
+	assert.Len(t, sd.GetInstances("greeter"), 2)
+	assert.Equal(t, "1", sd.GetInstances("greeter")[0].GetMetadata()["dubbo.metadata.revision"])
+	assert.Equal(t, 1, sd.GetHealthyInstancesByPage("greeter", 0, 10, true).GetDataSize())
+	assert.Equal(t, 1, sd.GetInstancesByPage("greeter", 1, 10).GetDataSize())
+	assert.Equal(t, 2, sd.GetRequestInstances([]string{"greeter"}, 0, 10)["greeter"].GetDataSize())
+	assert.Nil(t, sd.Register(sd.GetInstances("greeter")[0]))
+	assert.Len(t, sd.GetInstances("greeter"), 2)
+
+	listener := &mockListener{services: gxset.NewSet("greeter")}
+	assert.Nil(t, sd.AddListener(listener))
+	writeFile(t, path, `
+applications:
+  greeter:
+    - host: 127.0.0.3
+      port: 20000
+`)
+	assert.Eventually(t, func() bool {
+		instances := listener.last()
+		return len(instances) == 1 && instances[0].GetHost() == "127.0.0.3"
+	}, 3*time.Second, 10*time.Millisecond)
+}
+
+type mockNotifyListener struct {
+	lock   sync.Mutex
+	events []*registry.ServiceEvent
+}
+
+func (m *mockNotifyListener) Notify(event *registry.ServiceEvent) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.events = append(m.events, event)
+}
+
+func (m *mockNotifyListener) NotifyAll(events []*registry.ServiceEvent, f func()) {
+	for _, event := range events {
+		m.Notify(event)
+	}
+	f()
+}
+
+func (m *mockNotifyListener) all() []*registry.ServiceEvent {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return append([]*registry.ServiceEvent(nil), m.events...)
+}
+
+type mockListener struct {
+	registry.ServiceInstancesChangedListener
+	services *gxset.HashSet
+
+	lock      sync.Mutex
+	instances []registry.ServiceInstance
+}
+
+func (m *mockListener) GetServiceNames() *gxset.HashSet {
+	return m.services
+}
+
+func (m *mockListener) OnEvent(e observer.Event) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.instances = e.(*registry.ServiceInstancesChangedEvent).Instances
+	return nil
+}
+
+func (m *mockListener) last() []registry.ServiceInstance {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.instances
+}
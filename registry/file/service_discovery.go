@@ -0,0 +1,189 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package file
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+import (
+	gxset "github.com/dubbogo/gost/container/set"
+	gxpage "github.com/dubbogo/gost/hash/page"
+	"github.com/dubbogo/gost/log/logger"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/common/extension"
+	"dubbo.apache.org/dubbo-go/v3/registry"
+)
+
+func init() {
+	extension.SetServiceDiscovery(constant.FileKey, newFileServiceDiscovery)
+}
+
+// fileServiceDiscovery is the application-level service discovery whose instances are listed in the file.
+// The instances can't be registered, they are added or removed by editing the file.
+type fileServiceDiscovery struct {
+	descriptor string
+	file       *watchedFile
+
+	lock sync.Mutex
+	// listeners is keyed by the service name
+	listeners map[string]*gxset.HashSet
+}
+
+func newFileServiceDiscovery(url *common.URL) (registry.ServiceDiscovery, error) {
+	file, err := newWatchedFile(getPath(url))
+	if err != nil {
+		return nil, err
+	}
+	sd := &fileServiceDiscovery{
+		descriptor: fmt.Sprintf("file-service-discovery[%s]", file.path),
+		file:       file,
+		listeners:  make(map[string]*gxset.HashSet),
+	}
+	file.addHandler(sd.onChange)
+	return sd, nil
+}
+
+func (f *fileServiceDiscovery) String() string {
+	return f.descriptor
+}
+
+// Destroy stops watching the file
+func (f *fileServiceDiscovery) Destroy() error {
+	return f.file.close()
+}
+
+// Register does nothing, since the instances are listed in the file.
+func (f *fileServiceDiscovery) Register(instance registry.ServiceInstance) error {
+	logger.Debugf("[File Registry] Ignore registering %s, the instances are listed in %s", instance.GetID(), f.file.path)
+	return nil
+}
+
+// Update does nothing, since the instances are listed in the file.
+func (f *fileServiceDiscovery) Update(registry.ServiceInstance) error {
+	return nil
+}
+
+// Unregister does nothing, since the instances are listed in the file.
+func (f *fileServiceDiscovery) Unregister(registry.ServiceInstance) error {
+	return nil
+}
+
+// GetDefaultPageSize will return the default page size
+func (f *fileServiceDiscovery) GetDefaultPageSize() int {
+	return registry.DefaultPageSize
+}
+
+// GetServices returns the applications in the file
+func (f *fileServiceDiscovery) GetServices() *gxset.HashSet {
+	res := gxset.NewSet()
+	for app := range f.file.current().instances {
+		res.Add(app)
+	}
+	return res
+}
+
+// GetInstances returns the instances of the application in the file
+func (f *fileServiceDiscovery) GetInstances(serviceName string) []registry.ServiceInstance {
+	instances := f.file.current().instances[serviceName]
+	res := make([]registry.ServiceInstance, 0, len(instances))
+	return append(res, instances...)
+}
+
+// GetInstancesByPage will return a page containing instances of ServiceInstance with the serviceName
+// the page will start at offset
+func (f *fileServiceDiscovery) GetInstancesByPage(serviceName string, offset int, pageSize int) gxpage.Pager {
+	all := f.GetInstances(serviceName)
+	res := make([]any, 0, pageSize)
+	for i := offset; i < len(all) && i < offset+pageSize; i++ {
+		res = append(res, all[i])
+	}
+	return gxpage.NewPage(offset, pageSize, res, len(all))
+}
+
+// GetHealthyInstancesByPage will return a page containing instances of ServiceInstance.
+// The param healthy indices that the instance should be healthy or not.
+// The page will start at offset
+func (f *fileServiceDiscovery) GetHealthyInstancesByPage(serviceName string, offset int, pageSize int, healthy bool) gxpage.Pager {
+	all := f.GetInstances(serviceName)
+	res := make([]any, 0, pageSize)
+	var (
+		i     = offset
+		count = 0
+	)
+	for i < len(all) && count < pageSize {
+		if all[i].IsHealthy() == healthy {
+			res = append(res, all[i])
+			count++
+		}
+		i++
+	}
+	return gxpage.NewPage(offset, pageSize, res, len(all))
+}
+
+// GetRequestInstances Batch get all instances by the specified service names
+func (f *fileServiceDiscovery) GetRequestInstances(serviceNames []string, offset int, requestedSize int) map[string]gxpage.Pager {
+	res := make(map[string]gxpage.Pager, len(serviceNames))
+	for _, name := range serviceNames {
+		res[name] = f.GetInstancesByPage(name, offset, requestedSize)
+	}
+	return res
+}
+
+// AddListener adds the listener which is notified once the instances of its service names are changed
+func (f *fileServiceDiscovery) AddListener(listener registry.ServiceInstancesChangedListener) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	for _, name := range listener.GetServiceNames().Values() {
+		listeners, ok := f.listeners[name.(string)]
+		if !ok {
+			listeners = gxset.NewSet()
+			f.listeners[name.(string)] = listeners
+		}
+		listeners.Add(listener)
+	}
+	return nil
+}
+
+// onChange notifies the listeners of the applications whose instances are changed
+func (f *fileServiceDiscovery) onChange(old, cur *snapshot) {
+	f.lock.Lock()
+	changed := make(map[string][]any)
+	for name, listeners := range f.listeners {
+		if !reflect.DeepEqual(old.instances[name], cur.instances[name]) {
+			changed[name] = listeners.Values()
+		}
+	}
+	f.lock.Unlock()
+
+	for name, listeners := range changed {
+		instances := append(make([]registry.ServiceInstance, 0, len(cur.instances[name])), cur.instances[name]...)
+		event := registry.NewServiceInstancesChangedEvent(name, instances)
+		for _, l := range listeners {
+			if err := l.(registry.ServiceInstancesChangedListener).OnEvent(event); err != nil {
+				logger.Warnf("[File Registry] Failed to notify the instances of %s, err: %v", name, err)
+			}
+		}
+	}
+}
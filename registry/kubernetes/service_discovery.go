@@ -0,0 +1,339 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sync"
+	"time"
+)
+
+import (
+	gxset "github.com/dubbogo/gost/container/set"
+	gxpage "github.com/dubbogo/gost/hash/page"
+	"github.com/dubbogo/gost/log/logger"
+
+	perrors "github.com/pkg/errors"
+
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/common/extension"
+	"dubbo.apache.org/dubbo-go/v3/registry"
+	"dubbo.apache.org/dubbo-go/v3/remoting/kubernetes"
+)
+
+const (
+	// ServiceNameLabel is the label of the pods, whose value is the service name of the instance
+	ServiceNameLabel = "dubbo.apache.org/service-name"
+	// InstanceAnnotation is the annotation of the pods, whose value is the instance in json
+	InstanceAnnotation = "dubbo.apache.org/instance"
+	// RevisionAnnotation is the annotation of the pods, whose value is the revision of the metadata
+	RevisionAnnotation = "dubbo.apache.org/revision"
+
+	syncTimeout = 3 * time.Second
+)
+
+// labelValueRegexp is the format of the label values of Kubernetes
+var labelValueRegexp = regexp.MustCompile(`^(([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9])?$`)
+
+func init() {
+	extension.SetServiceDiscovery(constant.KubernetesKey, newKubernetesServiceDiscovery)
+}
+
+// kubernetesServiceDiscovery registers the instance by patching the labels and annotations of the pod where
+// the application runs, and discovers the instances by watching the pods with the service name label.
+type kubernetesServiceDiscovery struct {
+	descriptor string
+	client     *kubernetes.Client
+	// podName is the name of the current pod
+	podName string
+
+	lock      sync.Mutex
+	informers map[string]*kubernetes.Informer
+	listeners map[string]*gxset.HashSet
+}
+
+func newKubernetesServiceDiscovery(url *common.URL) (registry.ServiceDiscovery, error) {
+	client, err := kubernetes.NewClientByURL(url)
+	if err != nil {
+		return nil, perrors.WithMessage(err, "new kubernetes client")
+	}
+	return newServiceDiscoveryWithClient(client, podName(url)), nil
+}
+
+func newServiceDiscoveryWithClient(client *kubernetes.Client, podName string) *kubernetesServiceDiscovery {
+	return &kubernetesServiceDiscovery{
+		descriptor: fmt.Sprintf("kubernetes-service-discovery[%s/%s]", client.Namespace(), podName),
+		client:     client,
+		podName:    podName,
+		informers:  make(map[string]*kubernetes.Informer),
+		listeners:  make(map[string]*gxset.HashSet),
+	}
+}
+
+// podName returns the name of the current pod, which is the hostname of the pod by default.
+func podName(url *common.URL) string {
+	if name := url.GetParam(constant.KubernetesPodNameKey, ""); name != "" {
+		return name
+	}
+	if name := os.Getenv("POD_NAME"); name != "" {
+		return name
+	}
+	name, _ := os.Hostname()
+	return name
+}
+
+func (k *kubernetesServiceDiscovery) String() string {
+	return k.descriptor
+}
+
+// Destroy stops watching the pods
+func (k *kubernetesServiceDiscovery) Destroy() error {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	for name, informer := range k.informers {
+		informer.Stop()
+		delete(k.informers, name)
+	}
+	return nil
+}
+
+// Register patches the labels and annotations of the current pod with the instance
+func (k *kubernetesServiceDiscovery) Register(instance registry.ServiceInstance) error {
+	serviceName := instance.GetServiceName()
+	if len(serviceName) > 63 || !labelValueRegexp.MatchString(serviceName) {
+		return perrors.Errorf("the service name %s is not a valid label value of kubernetes", serviceName)
+	}
+	data, err := json.Marshal(toDefaultServiceInstance(instance))
+	if err != nil {
+		return err
+	}
+	value, revision := string(data), instance.GetMetadata()[constant.ExportedServicesRevisionPropertyName]
+	labels := map[string]*string{ServiceNameLabel: &serviceName}
+	annotations := map[string]*string{InstanceAnnotation: &value, RevisionAnnotation: &revision}
+	if _, err = k.client.PatchPodMetadata(context.Background(), k.podName, labels, annotations); err != nil {
+		return perrors.WithMessagef(err, "register the instance to pod %s", k.podName)
+	}
+	return nil
+}
+
+// Update updates the annotations of the current pod with the instance
+func (k *kubernetesServiceDiscovery) Update(instance registry.ServiceInstance) error {
+	return k.Register(instance)
+}
+
+// Unregister removes the labels and annotations of the instance from the current pod
+func (k *kubernetesServiceDiscovery) Unregister(registry.ServiceInstance) error {
+	labels := map[string]*string{ServiceNameLabel: nil}
+	annotations := map[string]*string{InstanceAnnotation: nil, RevisionAnnotation: nil}
+	if _, err := k.client.PatchPodMetadata(context.Background(), k.podName, labels, annotations); err != nil {
+		return perrors.WithMessagef(err, "unregister the instance from pod %s", k.podName)
+	}
+	return nil
+}
+
+// GetDefaultPageSize will return the default page size
+func (k *kubernetesServiceDiscovery) GetDefaultPageSize() int {
+	return registry.DefaultPageSize
+}
+
+// GetServices returns the service names of all the pods with the service name label
+func (k *kubernetesServiceDiscovery) GetServices() *gxset.HashSet {
+	services := gxset.NewSet()
+	list, err := k.client.Pods().List(context.Background(), metav1.ListOptions{LabelSelector: ServiceNameLabel})
+	if err != nil {
+		logger.Errorf("[kubernetes] Failed to list the pods, err: %v", err)
+		return services
+	}
+	for _, pod := range list.Items {
+		services.Add(pod.Labels[ServiceNameLabel])
+	}
+	return services
+}
+
+// GetInstances returns the instances of the running pods with the service name
+func (k *kubernetesServiceDiscovery) GetInstances(serviceName string) []registry.ServiceInstance {
+	k.lock.Lock()
+	informer, ok := k.informers[serviceName]
+	k.lock.Unlock()
+	if ok {
+		return toInstances(informer.List())
+	}
+	list, err := k.client.Pods().List(context.Background(), metav1.ListOptions{LabelSelector: selector(serviceName)})
+	if err != nil {
+		logger.Errorf("[kubernetes] Failed to list the pods of %s, err: %v", serviceName, err)
+		return make([]registry.ServiceInstance, 0)
+	}
+	objects := make([]metav1.Object, 0, len(list.Items))
+	for i := range list.Items {
+		objects = append(objects, &list.Items[i])
+	}
+	return toInstances(objects)
+}
+
+// GetInstancesByPage will return a page containing instances of ServiceInstance with the serviceName
+// the page will start at offset
+func (k *kubernetesServiceDiscovery) GetInstancesByPage(serviceName string, offset int, pageSize int) gxpage.Pager {
+	all := k.GetInstances(serviceName)
+	res := make([]any, 0, pageSize)
+	for i := offset; i < len(all) && i < offset+pageSize; i++ {
+		res = append(res, all[i])
+	}
+	return gxpage.NewPage(offset, pageSize, res, len(all))
+}
+
+// GetHealthyInstancesByPage will return a page containing instances of ServiceInstance.
+// The param healthy indices that the instance should be healthy or not.
+// The page will start at offset
+func (k *kubernetesServiceDiscovery) GetHealthyInstancesByPage(serviceName string, offset int, pageSize int, healthy bool) gxpage.Pager {
+	all := k.GetInstances(serviceName)
+	res := make([]any, 0, pageSize)
+	var (
+		i     = offset
+		count = 0
+	)
+	for i < len(all) && count < pageSize {
+		if all[i].IsHealthy() == healthy {
+			res = append(res, all[i])
+			count++
+		}
+		i++
+	}
+	return gxpage.NewPage(offset, pageSize, res, len(all))
+}
+
+// GetRequestInstances Batch get all instances by the specified service names
+func (k *kubernetesServiceDiscovery) GetRequestInstances(serviceNames []string, offset int, requestedSize int) map[string]gxpage.Pager {
+	res := make(map[string]gxpage.Pager, len(serviceNames))
+	for _, name := range serviceNames {
+		res[name] = k.GetInstancesByPage(name, offset, requestedSize)
+	}
+	return res
+}
+
+// AddListener watches the pods of the service names of the listener, and notifies the listener of the
+// instances when the pods are changed
+func (k *kubernetesServiceDiscovery) AddListener(listener registry.ServiceInstancesChangedListener) error {
+	for _, name := range listener.GetServiceNames().Values() {
+		serviceName := name.(string)
+		k.lock.Lock()
+		listeners, ok := k.listeners[serviceName]
+		if !ok {
+			listeners = gxset.NewSet()
+			k.listeners[serviceName] = listeners
+		}
+		listeners.Add(listener)
+		informer, ok := k.informers[serviceName]
+		if !ok {
+			informer = kubernetes.NewPodInformer(k.client, selector(serviceName), func(pods []metav1.Object) {
+				k.notify(serviceName, pods)
+			})
+			k.informers[serviceName] = informer
+		}
+		k.lock.Unlock()
+		if !ok {
+			informer.Run()
+			if !informer.WaitForSync(syncTimeout) {
+				logger.Warnf("[kubernetes] The pods of %s are not synced in %s", serviceName, syncTimeout)
+			}
+		}
+	}
+	return nil
+}
+
+func (k *kubernetesServiceDiscovery) notify(serviceName string, pods []metav1.Object) {
+	k.lock.Lock()
+	listeners, ok := k.listeners[serviceName]
+	k.lock.Unlock()
+	if !ok {
+		return
+	}
+	event := registry.NewServiceInstancesChangedEvent(serviceName, toInstances(pods))
+	for _, l := range listeners.Values() {
+		if err := l.(registry.ServiceInstancesChangedListener).OnEvent(event); err != nil {
+			logger.Warnf("[kubernetes] Failed to notify the instances of %s, err: %v", serviceName, err)
+		}
+	}
+}
+
+func selector(serviceName string) string {
+	return ServiceNameLabel + "=" + serviceName
+}
+
+// toInstances returns the instances of the running pods, the host is the ip of the pod and the instance is
+// healthy if the pod is ready.
+func toInstances(pods []metav1.Object) []registry.ServiceInstance {
+	instances := make([]registry.ServiceInstance, 0, len(pods))
+	for _, obj := range pods {
+		pod := obj.(*corev1.Pod)
+		value, ok := pod.Annotations[InstanceAnnotation]
+		if !ok || pod.Status.PodIP == "" || pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+		instance := &registry.DefaultServiceInstance{}
+		if err := json.Unmarshal([]byte(value), instance); err != nil {
+			logger.Warnf("[kubernetes] Illegal instance annotation of pod %s, err: %v", pod.Name, err)
+			continue
+		}
+		instance.Host = pod.Status.PodIP
+		instance.Healthy = isReady(pod)
+		if revision := pod.Annotations[RevisionAnnotation]; revision != "" {
+			if instance.Metadata == nil {
+				instance.Metadata = make(map[string]string)
+			}
+			instance.Metadata[constant.ExportedServicesRevisionPropertyName] = revision
+		}
+		instances = append(instances, instance)
+	}
+	return instances
+}
+
+// isReady reports whether the pod is ready to serve
+func isReady(pod *corev1.Pod) bool {
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// toDefaultServiceInstance copies the instance without the service metadata, which is published by revision.
+func toDefaultServiceInstance(instance registry.ServiceInstance) *registry.DefaultServiceInstance {
+	return &registry.DefaultServiceInstance{
+		ID:          instance.GetID(),
+		ServiceName: instance.GetServiceName(),
+		Host:        instance.GetHost(),
+		Port:        instance.GetPort(),
+		Weight:      instance.GetWeight(),
+		Enable:      instance.IsEnable(),
+		Healthy:     instance.IsHealthy(),
+		Metadata:    instance.GetMetadata(),
+		Tag:         instance.GetTag(),
+	}
+}
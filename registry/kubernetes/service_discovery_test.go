@@ -0,0 +1,195 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+import (
+	gxset "github.com/dubbogo/gost/container/set"
+	"github.com/dubbogo/gost/gof/observer"
+
+	"github.com/stretchr/testify/assert"
+
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/registry"
+	"dubbo.apache.org/dubbo-go/v3/remoting/kubernetes"
+)
+
+const (
+	testNamespace = "dubbo"
+	testService   = "provider"
+)
+
+func newTestPod(name, ip string) *corev1.Pod {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace}}
+	pod.Status.Phase = corev1.PodRunning
+	pod.Status.PodIP = ip
+	pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+	return pod
+}
+
+func addPod(t *testing.T, client *kubernetes.Client, pod *corev1.Pod) {
+	_, err := client.Pods().Create(context.Background(), pod, metav1.CreateOptions{})
+	assert.Nil(t, err)
+}
+
+func newTestInstance() *registry.DefaultServiceInstance {
+	return &registry.DefaultServiceInstance{
+		ID:          "127.0.0.1:20000",
+		ServiceName: testService,
+		Host:        "127.0.0.1",
+		Port:        20000,
+		Enable:      true,
+		Healthy:     true,
+		Metadata:    map[string]string{constant.ExportedServicesRevisionPropertyName: "1"},
+	}
+}
+
+func TestRegister(t *testing.T) {
+	client := kubernetes.NewClient(fake.NewSimpleClientset(newTestPod("pod-1", "10.0.0.1")), testNamespace)
+	sd := newServiceDiscoveryWithClient(client, "pod-1")
+
+	err := sd.Register(newTestInstance())
+	assert.Nil(t, err)
+	assert.Equal(t, testService, sd.GetServices().Values()[0])
+
+	instances := sd.GetInstances(testService)
+	assert.Len(t, instances, 1)
+	assert.Equal(t, "10.0.0.1", instances[0].GetHost())
+	assert.Equal(t, 20000, instances[0].GetPort())
+	assert.True(t, instances[0].IsHealthy())
+	assert.Equal(t, "1", instances[0].GetMetadata()[constant.ExportedServicesRevisionPropertyName])
+
+	ins := newTestInstance()
+	ins.Metadata[constant.ExportedServicesRevisionPropertyName] = "2"
+	err = sd.Update(ins)
+	assert.Nil(t, err)
+	pod, _ := client.Pods().Get(context.Background(), "pod-1", metav1.GetOptions{})
+	assert.Equal(t, "2", pod.Annotations[RevisionAnnotation])
+
+	err = sd.Unregister(ins)
+	assert.Nil(t, err)
+	assert.Empty(t, sd.GetInstances(testService))
+	assert.True(t, sd.GetServices().Empty())
+
+	ins.ServiceName = "illegal/name"
+	assert.NotNil(t, sd.Register(ins))
+	assert.NotNil(t, newServiceDiscoveryWithClient(client, "pod-2").Register(newTestInstance()))
+}
+
+func TestGetInstances(t *testing.T) {
+	client := kubernetes.NewClient(fake.NewSimpleClientset(), testNamespace)
+	notReady := newTestPod("pod-2", "10.0.0.2")
+	notReady.Status.Conditions = nil
+	pending := newTestPod("pod-3", "")
+	pending.Status.Phase = "Pending"
+	for _, pod := range []*corev1.Pod{newTestPod("pod-1", "10.0.0.1"), notReady, pending} {
+		addPod(t, client, pod)
+		assert.Nil(t, newServiceDiscoveryWithClient(client, pod.Name).Register(newTestInstance()))
+	}
+	sd := newServiceDiscoveryWithClient(client, "pod-1")
+
+	assert.Len(t, sd.GetInstances(testService), 2)
+	assert.Equal(t, 2, sd.GetInstancesByPage(testService, 0, 10).GetDataSize())
+	assert.Equal(t, 1, sd.GetInstancesByPage(testService, 1, 10).GetDataSize())
+	assert.Equal(t, 1, sd.GetHealthyInstancesByPage(testService, 0, 10, true).GetDataSize())
+	assert.Equal(t, 1, sd.GetHealthyInstancesByPage(testService, 0, 10, false).GetDataSize())
+	assert.Equal(t, 2, sd.GetRequestInstances([]string{testService}, 0, 10)[testService].GetDataSize())
+	assert.Equal(t, registry.DefaultPageSize, sd.GetDefaultPageSize())
+}
+
+func TestAddListener(t *testing.T) {
+	clientset := fake.NewSimpleClientset(newTestPod("pod-1", "10.0.0.1"))
+	client := kubernetes.NewClient(clientset, testNamespace)
+	sd := newServiceDiscoveryWithClient(client, "pod-1")
+	defer func() {
+		_ = sd.Destroy()
+	}()
+	assert.Nil(t, sd.Register(newTestInstance()))
+
+	listener := &mockListener{services: gxset.NewSet(testService)}
+	assert.Nil(t, sd.AddListener(listener))
+	assert.Eventually(t, func() bool {
+		return len(listener.last()) == 1
+	}, time.Second, 10*time.Millisecond)
+	waitForWatch(t, clientset)
+
+	addPod(t, client, newTestPod("pod-2", "10.0.0.2"))
+	assert.Nil(t, newServiceDiscoveryWithClient(client, "pod-2").Register(newTestInstance()))
+	assert.Eventually(t, func() bool {
+		return len(listener.last()) == 2
+	}, time.Second, 10*time.Millisecond)
+	assert.Len(t, sd.GetInstances(testService), 2)
+
+	assert.Nil(t, client.Pods().Delete(context.Background(), "pod-1", metav1.DeleteOptions{}))
+	assert.Eventually(t, func() bool {
+		instances := listener.last()
+		return len(instances) == 1 && instances[0].GetHost() == "10.0.0.2"
+	}, time.Second, 10*time.Millisecond)
+}
+
+// waitForWatch waits until the pods are watched, since the fake clientset doesn't replay the events happened
+// before the watch.
+func waitForWatch(t *testing.T, clientset *fake.Clientset) {
+	assert.Eventually(t, func() bool {
+		for _, action := range clientset.Actions() {
+			if action.GetVerb() == "watch" && action.GetResource().Resource == "pods" {
+				return true
+			}
+		}
+		return false
+	}, time.Second, 10*time.Millisecond)
+}
+
+type mockListener struct {
+	registry.ServiceInstancesChangedListener
+	services *gxset.HashSet
+
+	lock      sync.Mutex
+	instances []registry.ServiceInstance
+}
+
+func (m *mockListener) GetServiceNames() *gxset.HashSet {
+	return m.services
+}
+
+func (m *mockListener) OnEvent(e observer.Event) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.instances = e.(*registry.ServiceInstancesChangedEvent).Instances
+	return nil
+}
+
+func (m *mockListener) last() []registry.ServiceInstance {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.instances
+}
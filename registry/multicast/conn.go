@@ -0,0 +1,107 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package multicast
+
+import (
+	"errors"
+	"net"
+	"strings"
+)
+
+import (
+	"github.com/dubbogo/gost/log/logger"
+
+	perrors "github.com/pkg/errors"
+
+	"golang.org/x/net/ipv4"
+)
+
+const (
+	// maxMessageSize is the max payload of an UDP datagram
+	maxMessageSize = 65507
+)
+
+// multicastConn sends and receives the messages of a multicast group
+type multicastConn struct {
+	group    *net.UDPAddr
+	sender   *net.UDPConn
+	receiver *net.UDPConn
+}
+
+// newMulticastConn joins the multicast group on the interface, the system default interface is used if it is nil.
+// The messages sent are looped back, so the registries in the same host, even in the same process, receive them.
+func newMulticastConn(address string, ifi *net.Interface) (*multicastConn, error) {
+	group, err := net.ResolveUDPAddr("udp4", address)
+	if err != nil {
+		return nil, perrors.WithMessagef(err, "resolve the multicast address %s", address)
+	}
+	if !group.IP.IsMulticast() {
+		return nil, perrors.Errorf("%s is not a multicast address, it should be in 224.0.0.0 ~ 239.255.255.255", address)
+	}
+	receiver, err := net.ListenMulticastUDP("udp4", ifi, group)
+	if err != nil {
+		return nil, perrors.WithMessagef(err, "join the multicast group %s", address)
+	}
+	sender, err := net.ListenUDP("udp4", &net.UDPAddr{})
+	if err != nil {
+		_ = receiver.Close()
+		return nil, perrors.WithMessage(err, "listen the udp sender")
+	}
+	conn := ipv4.NewPacketConn(sender)
+	if ifi != nil {
+		err = conn.SetMulticastInterface(ifi)
+	}
+	if err == nil {
+		err = conn.SetMulticastLoopback(true)
+	}
+	if err != nil {
+		_ = receiver.Close()
+		_ = sender.Close()
+		return nil, perrors.WithMessage(err, "set the multicast options")
+	}
+	return &multicastConn{group: group, sender: sender, receiver: receiver}, nil
+}
+
+func (c *multicastConn) send(msg string) error {
+	if len(msg) > maxMessageSize {
+		return perrors.Errorf("the message size %d exceeds the limit %d", len(msg), maxMessageSize)
+	}
+	_, err := c.sender.WriteToUDP([]byte(msg), c.group)
+	return err
+}
+
+// receive calls the handler with the messages received until the connection is closed
+func (c *multicastConn) receive(handler func(msg string)) {
+	buf := make([]byte, maxMessageSize)
+	for {
+		n, from, err := c.receiver.ReadFromUDP(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			logger.Warnf("[Multicast Registry] Failed to receive the message from %s, err: %v", c.group, err)
+			continue
+		}
+		logger.Debugf("[Multicast Registry] Receive the message from %s", from)
+		handler(strings.TrimSpace(string(buf[:n])))
+	}
+}
+
+func (c *multicastConn) close() error {
+	return errors.Join(c.receiver.Close(), c.sender.Close())
+}
@@ -0,0 +1,361 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package multicast
+
+import (
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+import (
+	"github.com/dubbogo/gost/log/logger"
+
+	perrors "github.com/pkg/errors"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/common/extension"
+	"dubbo.apache.org/dubbo-go/v3/metrics"
+	metricsRegistry "dubbo.apache.org/dubbo-go/v3/metrics/registry"
+	"dubbo.apache.org/dubbo-go/v3/registry"
+	"dubbo.apache.org/dubbo-go/v3/remoting"
+)
+
+const (
+	defaultGroupIP          = "224.5.6.7"
+	defaultGroupPort        = "1234"
+	defaultAnnounceInterval = "10s"
+	// the announced urls expire in defaultExpireFactor announce intervals by default
+	defaultExpireFactor = 3
+
+	registerCommand   = "register"
+	unregisterCommand = "unregister"
+	subscribeCommand  = "subscribe"
+)
+
+func init() {
+	extension.SetRegistry(constant.MulticastKey, newMulticastRegistry)
+}
+
+// multicastRegistry announces the registered providers to the multicast group, and collects the providers
+// announced by the others. The messages are "register {url}", "unregister {url}" and "subscribe {url}", the
+// same as the multicast registry of dubbo java. The providers are re-announced periodically, and the providers
+// which are not re-announced in the expire time are removed, so the crashed providers drop out.
+type multicastRegistry struct {
+	*common.URL
+	conn     *multicastConn
+	interval time.Duration
+	expire   time.Duration
+	done     chan struct{}
+	wg       sync.WaitGroup
+
+	// lock also serializes the notifications, so the events of an url are delivered in order
+	lock sync.Mutex
+	// registered is keyed by the message announcing the url
+	registered map[string]*common.URL
+	// announced is keyed by the url announced by the others
+	announced   map[string]*announcedURL
+	subscribers map[subscriber]*common.URL
+}
+
+type announcedURL struct {
+	url      *common.URL
+	expireAt time.Time
+}
+
+type subscriber struct {
+	serviceKey string
+	listener   registry.NotifyListener
+}
+
+func newMulticastRegistry(url *common.URL) (registry.Registry, error) {
+	logger.Infof("[Multicast Registry] New multicast registry with url = %+v", url.ToMap())
+	interval := url.GetParamDuration(constant.MulticastAnnounceIntervalKey, defaultAnnounceInterval)
+	if interval <= 0 {
+		return nil, perrors.Errorf("illegal %s %s", constant.MulticastAnnounceIntervalKey,
+			url.GetParam(constant.MulticastAnnounceIntervalKey, ""))
+	}
+	expire := url.GetParamDuration(constant.MulticastExpireKey, (defaultExpireFactor * interval).String())
+	if expire <= interval {
+		return nil, perrors.Errorf("%s %s should be longer than %s %s", constant.MulticastExpireKey, expire,
+			constant.MulticastAnnounceIntervalKey, interval)
+	}
+	var ifi *net.Interface
+	if name := url.GetParam(constant.MulticastInterfaceKey, ""); name != "" {
+		var err error
+		if ifi, err = net.InterfaceByName(name); err != nil {
+			return nil, perrors.WithMessagef(err, "find the multicast interface %s", name)
+		}
+	}
+	ip, port := url.Ip, url.Port
+	if ip == "" {
+		ip = defaultGroupIP
+	}
+	if port == "" {
+		port = defaultGroupPort
+	}
+	conn, err := newMulticastConn(net.JoinHostPort(ip, port), ifi)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &multicastRegistry{
+		URL:         url,
+		conn:        conn,
+		interval:    interval,
+		expire:      expire,
+		done:        make(chan struct{}),
+		registered:  make(map[string]*common.URL),
+		announced:   make(map[string]*announcedURL),
+		subscribers: make(map[subscriber]*common.URL),
+	}
+	r.wg.Add(2)
+	go func() {
+		defer r.wg.Done()
+		conn.receive(r.handle)
+	}()
+	go func() {
+		defer r.wg.Done()
+		r.announce()
+	}()
+	return r, nil
+}
+
+func isProvider(url *common.URL) bool {
+	role, _ := strconv.Atoi(url.GetParam(constant.RegistryRoleKey, strconv.Itoa(common.PROVIDER)))
+	return role == common.PROVIDER
+}
+
+func isMatch(consumer, provider *common.URL) bool {
+	return provider.ServiceKey() == consumer.ServiceKey() ||
+		common.IsAnyCondition(consumer.Service(), consumer.Group(), consumer.Version(), provider)
+}
+
+// Register announces the provider url to the multicast group, the consumer urls are not announced.
+func (r *multicastRegistry) Register(url *common.URL) error {
+	if !isProvider(url) {
+		return nil
+	}
+	if !r.IsAvailable() {
+		return perrors.New("multicastRegistry is not available.")
+	}
+	start := time.Now()
+	common.HandleRegisterIPAndPort(url)
+	msg := registerCommand + " " + url.String()
+	err := r.conn.send(msg)
+	metrics.Publish(metricsRegistry.NewRegisterEvent(err == nil, start))
+	if err != nil {
+		return perrors.WithMessagef(err, "announce %s", url.Key())
+	}
+	logger.Infof("[Multicast Registry] Register %s to %s", url.Key(), r.conn.group)
+	r.lock.Lock()
+	r.registered[msg] = url
+	r.lock.Unlock()
+	return nil
+}
+
+// UnRegister announces the provider url is offline.
+func (r *multicastRegistry) UnRegister(url *common.URL) error {
+	if !isProvider(url) {
+		return nil
+	}
+	msg := registerCommand + " " + url.String()
+	r.lock.Lock()
+	_, ok := r.registered[msg]
+	delete(r.registered, msg)
+	r.lock.Unlock()
+	if !ok {
+		return nil
+	}
+	logger.Infof("[Multicast Registry] UnRegister %s from %s", url.Key(), r.conn.group)
+	return r.conn.send(unregisterCommand + " " + url.String())
+}
+
+// Subscribe notifies the listener of the providers announced, and queries the providers of the url by the
+// subscribe message, the providers answer it by announcing themselves again.
+func (r *multicastRegistry) Subscribe(url *common.URL, notifyListener registry.NotifyListener) error {
+	if isProvider(url) {
+		return nil
+	}
+	if !r.IsAvailable() {
+		return perrors.New("multicastRegistry is not available.")
+	}
+	r.lock.Lock()
+	r.subscribers[subscriber{serviceKey: url.ServiceKey(), listener: notifyListener}] = url
+	r.notify(url, notifyListener)
+	r.lock.Unlock()
+	metrics.Publish(metricsRegistry.NewSubscribeEvent(true))
+	return r.conn.send(subscribeCommand + " " + url.String())
+}
+
+// UnSubscribe stops notifying the listener of the providers of the url.
+func (r *multicastRegistry) UnSubscribe(url *common.URL, notifyListener registry.NotifyListener) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	delete(r.subscribers, subscriber{serviceKey: url.ServiceKey(), listener: notifyListener})
+	return nil
+}
+
+// LoadSubscribeInstances notifies the listener of the providers of the url announced.
+func (r *multicastRegistry) LoadSubscribeInstances(url *common.URL, notify registry.NotifyListener) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.notify(url, notify)
+	return nil
+}
+
+func (r *multicastRegistry) notify(url *common.URL, listener registry.NotifyListener) {
+	for _, announced := range r.announced {
+		if isMatch(url, announced.url) {
+			listener.Notify(&registry.ServiceEvent{Action: remoting.EventTypeAdd, Service: announced.url.Clone()})
+		}
+	}
+}
+
+// GetURL gets its registration URL
+func (r *multicastRegistry) GetURL() *common.URL {
+	return r.URL
+}
+
+// IsAvailable returns false if the registry is destroyed
+func (r *multicastRegistry) IsAvailable() bool {
+	select {
+	case <-r.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// Destroy announces the registered providers are offline, and leaves the multicast group.
+func (r *multicastRegistry) Destroy() {
+	if !r.IsAvailable() {
+		return
+	}
+	close(r.done)
+	r.lock.Lock()
+	for msg, url := range r.registered {
+		if err := r.conn.send(unregisterCommand + " " + url.String()); err != nil {
+			logger.Warnf("[Multicast Registry] Failed to unregister %s, err: %v", url.Key(), err)
+		}
+		delete(r.registered, msg)
+	}
+	r.lock.Unlock()
+	if err := r.conn.close(); err != nil {
+		logger.Warnf("[Multicast Registry] Failed to leave the multicast group %s, err: %v", r.conn.group, err)
+	}
+	r.wg.Wait()
+}
+
+// handle handles the message received from the multicast group
+func (r *multicastRegistry) handle(msg string) {
+	command, rawURL, found := strings.Cut(msg, " ")
+	if !found {
+		logger.Warnf("[Multicast Registry] Ignore the illegal message %s", msg)
+		return
+	}
+	url, err := common.NewURL(rawURL)
+	if err != nil {
+		logger.Warnf("[Multicast Registry] Ignore the message %s with illegal url, err: %v", msg, err)
+		return
+	}
+
+	switch command {
+	case registerCommand:
+		r.lock.Lock()
+		if announced, ok := r.announced[rawURL]; ok {
+			announced.expireAt = time.Now().Add(r.expire)
+		} else {
+			r.announced[rawURL] = &announcedURL{url: url, expireAt: time.Now().Add(r.expire)}
+			r.dispatch(remoting.EventTypeAdd, url)
+		}
+		r.lock.Unlock()
+	case unregisterCommand:
+		r.lock.Lock()
+		if announced, ok := r.announced[rawURL]; ok {
+			delete(r.announced, rawURL)
+			r.dispatch(remoting.EventTypeDel, announced.url)
+		}
+		r.lock.Unlock()
+	case subscribeCommand:
+		// answers the query by announcing the matched providers again
+		r.lock.Lock()
+		var msgs []string
+		for m, provider := range r.registered {
+			if isMatch(url, provider) {
+				msgs = append(msgs, m)
+			}
+		}
+		r.lock.Unlock()
+		for _, m := range msgs {
+			if err = r.conn.send(m); err != nil {
+				logger.Warnf("[Multicast Registry] Failed to answer the subscription of %s, err: %v", url.Key(), err)
+			}
+		}
+	default:
+		logger.Warnf("[Multicast Registry] Ignore the message %s with unknown command", msg)
+	}
+}
+
+// dispatch notifies the subscribers matching the provider, the caller should hold the lock
+func (r *multicastRegistry) dispatch(action remoting.EventType, provider *common.URL) {
+	for s, url := range r.subscribers {
+		if isMatch(url, provider) {
+			s.listener.Notify(&registry.ServiceEvent{Action: action, Service: provider.Clone()})
+		}
+	}
+}
+
+// announce re-announces the registered providers and removes the expired providers periodically
+func (r *multicastRegistry) announce() {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-ticker.C:
+		}
+
+		r.lock.Lock()
+		msgs := make([]string, 0, len(r.registered))
+		for msg := range r.registered {
+			msgs = append(msgs, msg)
+		}
+		now := time.Now()
+		for rawURL, announced := range r.announced {
+			if now.After(announced.expireAt) {
+				logger.Warnf("[Multicast Registry] The provider %s expires", announced.url.Key())
+				delete(r.announced, rawURL)
+				r.dispatch(remoting.EventTypeDel, announced.url)
+			}
+		}
+		r.lock.Unlock()
+
+		for _, msg := range msgs {
+			if err := r.conn.send(msg); err != nil {
+				logger.Warnf("[Multicast Registry] Failed to re-announce %s, err: %v", msg, err)
+			}
+		}
+	}
+}
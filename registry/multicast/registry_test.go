@@ -0,0 +1,202 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package multicast
+
+import (
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/registry"
+	"dubbo.apache.org/dubbo-go/v3/remoting"
+)
+
+// newTestURL returns the registry url of a free port on the loopback interface
+func newTestURL(t *testing.T, params string) *common.URL {
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{})
+	assert.Nil(t, err)
+	port := conn.LocalAddr().(*net.UDPAddr).Port
+	assert.Nil(t, conn.Close())
+	url, err := common.NewURL("multicast://224.5.6.7:" + strconv.Itoa(port) + "?" +
+		constant.MulticastInterfaceKey + "=lo&" + params)
+	assert.Nil(t, err)
+	return url
+}
+
+func newTestRegistry(t *testing.T, url *common.URL) *multicastRegistry {
+	r, err := newMulticastRegistry(url)
+	assert.Nil(t, err)
+	t.Cleanup(r.Destroy)
+	return r.(*multicastRegistry)
+}
+
+func newProviderURL(port string) *common.URL {
+	url, _ := common.NewURL("tri://127.0.0.1:" + port + "/org.apache.dubbo.Greeter?interface=org.apache.dubbo.Greeter&version=1.0.0")
+	return url
+}
+
+func newConsumerURL() *common.URL {
+	url, _ := common.NewURL("consumer://127.0.0.1/org.apache.dubbo.Greeter?interface=org.apache.dubbo.Greeter&version=1.0.0&" +
+		constant.RegistryRoleKey + "=" + strconv.Itoa(common.CONSUMER))
+	return url
+}
+
+func TestNewMulticastRegistry(t *testing.T) {
+	url, _ := common.NewURL("multicast://127.0.0.1:1234")
+	_, err := newMulticastRegistry(url)
+	assert.NotNil(t, err)
+
+	_, err = newMulticastRegistry(newTestURL(t, constant.MulticastAnnounceIntervalKey+"=10s&"+constant.MulticastExpireKey+"=5s"))
+	assert.NotNil(t, err)
+
+	url = newTestURL(t, "")
+	url.SetParam(constant.MulticastInterfaceKey, "none")
+	_, err = newMulticastRegistry(url)
+	assert.NotNil(t, err)
+
+	r := newTestRegistry(t, newTestURL(t, ""))
+	assert.Equal(t, 10*time.Second, r.interval)
+	assert.Equal(t, 30*time.Second, r.expire)
+	assert.True(t, r.IsAvailable())
+	r.Destroy()
+	assert.False(t, r.IsAvailable())
+	assert.NotNil(t, r.Register(newProviderURL("20000")))
+	assert.NotNil(t, r.Subscribe(newConsumerURL(), &mockNotifyListener{}))
+}
+
+func TestRegisterAndSubscribe(t *testing.T) {
+	url := newTestURL(t, "")
+	provider, consumer := newTestRegistry(t, url), newTestRegistry(t, url)
+	// the consumer urls are not announced
+	assert.Nil(t, provider.Register(newConsumerURL()))
+	assert.Empty(t, provider.registered)
+
+	assert.Nil(t, provider.Register(newProviderURL("20000")))
+	// the consumer receives the announcement before subscribing
+	assert.Eventually(t, func() bool {
+		consumer.lock.Lock()
+		defer consumer.lock.Unlock()
+		return len(consumer.announced) == 1
+	}, 3*time.Second, 10*time.Millisecond)
+
+	listener := &mockNotifyListener{}
+	assert.Nil(t, consumer.Subscribe(newConsumerURL(), listener))
+	assert.Len(t, listener.all(), 1)
+	assert.Equal(t, remoting.EventTypeAdd, listener.all()[0].Action)
+
+	assert.Nil(t, provider.Register(newProviderURL("20001")))
+	assert.Eventually(t, func() bool {
+		return len(listener.all()) == 2
+	}, 3*time.Second, 10*time.Millisecond)
+	assert.Equal(t, "20001", listener.all()[1].Service.Port)
+
+	assert.Nil(t, provider.UnRegister(newProviderURL("20000")))
+	assert.Eventually(t, func() bool {
+		return len(listener.all()) == 3
+	}, 3*time.Second, 10*time.Millisecond)
+	assert.Equal(t, remoting.EventTypeDel, listener.all()[2].Action)
+	assert.Equal(t, "20000", listener.all()[2].Service.Port)
+
+	// the providers are offline once the registry is destroyed
+	provider.Destroy()
+	assert.Eventually(t, func() bool {
+		return len(listener.all()) == 4
+	}, 3*time.Second, 10*time.Millisecond)
+	assert.Equal(t, "20001", listener.all()[3].Service.Port)
+}
+
+func TestSubscribeQuery(t *testing.T) {
+	url := newTestURL(t, "")
+	provider := newTestRegistry(t, url)
+	assert.Nil(t, provider.Register(newProviderURL("20000")))
+
+	// the consumer joining later queries the providers by subscribing
+	consumer := newTestRegistry(t, url)
+	listener := &mockNotifyListener{}
+	assert.Nil(t, consumer.Subscribe(newConsumerURL(), listener))
+	assert.Eventually(t, func() bool {
+		return len(listener.all()) == 1
+	}, 3*time.Second, 10*time.Millisecond)
+	assert.Equal(t, "20000", listener.all()[0].Service.Port)
+
+	assert.Nil(t, consumer.UnSubscribe(newConsumerURL(), listener))
+	assert.Nil(t, provider.Register(newProviderURL("20001")))
+	time.Sleep(100 * time.Millisecond)
+	assert.Len(t, listener.all(), 1)
+
+	loaded := &mockNotifyListener{}
+	assert.Nil(t, consumer.LoadSubscribeInstances(newConsumerURL(), loaded))
+	assert.Len(t, loaded.all(), 2)
+}
+
+func TestExpire(t *testing.T) {
+	url := newTestURL(t, constant.MulticastAnnounceIntervalKey+"=50ms&"+constant.MulticastExpireKey+"=200ms")
+	provider, consumer := newTestRegistry(t, url), newTestRegistry(t, url)
+	listener := &mockNotifyListener{}
+	assert.Nil(t, consumer.Subscribe(newConsumerURL(), listener))
+	assert.Nil(t, provider.Register(newProviderURL("20000")))
+
+	// the crashed provider is announced once and never re-announced
+	crashed := newProviderURL("20001")
+	assert.Nil(t, consumer.conn.send(registerCommand+" "+crashed.String()))
+	// the illegal messages are ignored
+	assert.Nil(t, consumer.conn.send("illegal"))
+	assert.Nil(t, consumer.conn.send("unknown "+crashed.String()))
+
+	assert.Eventually(t, func() bool {
+		events := listener.all()
+		return len(events) == 3 && events[2].Action == remoting.EventTypeDel && events[2].Service.Port == "20001"
+	}, 3*time.Second, 10*time.Millisecond)
+	// the provider re-announced is alive
+	time.Sleep(300 * time.Millisecond)
+	assert.Len(t, listener.all(), 3)
+}
+
+type mockNotifyListener struct {
+	lock   sync.Mutex
+	events []*registry.ServiceEvent
+}
+
+func (m *mockNotifyListener) Notify(event *registry.ServiceEvent) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.events = append(m.events, event)
+}
+
+func (m *mockNotifyListener) NotifyAll(events []*registry.ServiceEvent, f func()) {
+	for _, event := range events {
+		m.Notify(event)
+	}
+	f()
+}
+
+func (m *mockNotifyListener) all() []*registry.ServiceEvent {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return append([]*registry.ServiceEvent(nil), m.events...)
+}
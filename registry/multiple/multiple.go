@@ -0,0 +1,135 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package multiple
+
+import (
+	"strings"
+)
+
+import (
+	perrors "github.com/pkg/errors"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+)
+
+const (
+	// UnionPolicy merges the addresses of all the registries
+	UnionPolicy = "union"
+	// PreferredPolicy uses the addresses of the first available registry having addresses, in the order of
+	// the reference registries, the other registries are the fallbacks
+	PreferredPolicy = "preferred"
+
+	defaultHealthCheckInterval = "10s"
+)
+
+// source is the addresses of a service from a registry
+type source struct {
+	available bool
+	size      int
+}
+
+// selectSources returns the indexes of the sources whose addresses are merged by the policy, the indexes are
+// in the order of the priorities.
+func selectSources(policy string, sources []source) []int {
+	if policy == PreferredPolicy {
+		for i, s := range sources {
+			if s.available && s.size > 0 {
+				return []int{i}
+			}
+		}
+		// the cached addresses of the unavailable registries are better than nothing
+		for i, s := range sources {
+			if s.size > 0 {
+				return []int{i}
+			}
+		}
+		return nil
+	}
+	indexes := make([]int, 0, len(sources))
+	for i := range sources {
+		indexes = append(indexes, i)
+	}
+	return indexes
+}
+
+func getPolicy(url *common.URL) (string, error) {
+	switch policy := url.GetParam(constant.MultipleMergePolicyKey, UnionPolicy); policy {
+	case UnionPolicy, PreferredPolicy:
+		return policy, nil
+	default:
+		return "", perrors.Errorf("unknown %s %s, it should be %s or %s", constant.MultipleMergePolicyKey, policy,
+			UnionPolicy, PreferredPolicy)
+	}
+}
+
+// getSubURLs parses the urls of the service registries and the reference registries. The params of the
+// multiple registry url are inherited by the sub urls unless they are set explicitly.
+func getSubURLs(url *common.URL) (services, references []*common.URL, err error) {
+	if services, err = parseSubURLs(url, url.GetParam(constant.MultipleServiceRegistryKey, "")); err != nil {
+		return nil, nil, err
+	}
+	if references, err = parseSubURLs(url, url.GetParam(constant.MultipleReferenceRegistryKey, "")); err != nil {
+		return nil, nil, err
+	}
+	if len(references) == 0 {
+		references = services
+	}
+	if len(references) == 0 {
+		return nil, nil, perrors.Errorf("neither %s nor %s is set in the multiple registry url",
+			constant.MultipleServiceRegistryKey, constant.MultipleReferenceRegistryKey)
+	}
+	return services, references, nil
+}
+
+func parseSubURLs(url *common.URL, addresses string) ([]*common.URL, error) {
+	var urls []*common.URL
+	for _, address := range strings.Split(addresses, constant.CommaSeparator) {
+		if address = strings.TrimSpace(address); address == "" {
+			continue
+		}
+		sub, err := common.NewURL(address)
+		if err != nil {
+			return nil, perrors.WithMessagef(err, "parse the registry url %s", address)
+		}
+		if sub.Protocol == "" {
+			return nil, perrors.Errorf("the registry url %s has no protocol", address)
+		}
+		url.RangeParams(func(key, value string) bool {
+			switch key {
+			case constant.MultipleServiceRegistryKey, constant.MultipleReferenceRegistryKey, constant.RegistryKey:
+			default:
+				if sub.GetParam(key, "") == "" {
+					sub.SetParam(key, value)
+				}
+			}
+			return true
+		})
+		// the service discovery extension is found by the registry param
+		sub.SetParam(constant.RegistryKey, sub.Protocol)
+		urls = append(urls, sub)
+	}
+	return urls, nil
+}
+
+// getName returns the name of the sub registry, which is used in the logs and the metrics
+func getName(url *common.URL) string {
+	return url.Protocol + "://" + url.Location
+}
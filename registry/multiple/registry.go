@@ -0,0 +1,426 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package multiple
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+import (
+	"github.com/dubbogo/gost/log/logger"
+
+	perrors "github.com/pkg/errors"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/common/extension"
+	"dubbo.apache.org/dubbo-go/v3/metrics"
+	metricsRegistry "dubbo.apache.org/dubbo-go/v3/metrics/registry"
+	"dubbo.apache.org/dubbo-go/v3/registry"
+	"dubbo.apache.org/dubbo-go/v3/remoting"
+)
+
+func init() {
+	extension.SetRegistry(constant.MultipleKey, newMultipleRegistry)
+}
+
+// multipleRegistry registers the urls to all the service registries, and subscribes all the reference
+// registries. The addresses from the reference registries are deduplicated and merged by the merge policy,
+// and the listeners are always notified of the complete merged list.
+type multipleRegistry struct {
+	*common.URL
+	policy     string
+	services   []*subRegistry
+	references []*subRegistry
+	done       chan struct{}
+	wg         sync.WaitGroup
+
+	lock          sync.Mutex
+	subscriptions map[subscriptionKey]*subscription
+}
+
+type subRegistry struct {
+	registry.Registry
+	name string
+	// available is the availability found by the last health check
+	available bool
+}
+
+type subscriptionKey struct {
+	serviceKey string
+	listener   registry.NotifyListener
+}
+
+func newMultipleRegistry(url *common.URL) (registry.Registry, error) {
+	logger.Infof("[Multiple Registry] New multiple registry with url = %+v", url.ToMap())
+	policy, err := getPolicy(url)
+	if err != nil {
+		return nil, err
+	}
+	serviceURLs, referenceURLs, err := getSubURLs(url)
+	if err != nil {
+		return nil, err
+	}
+	r := &multipleRegistry{
+		URL:           url,
+		policy:        policy,
+		done:          make(chan struct{}),
+		subscriptions: make(map[subscriptionKey]*subscription),
+	}
+	// the registry in both of the lists is created once
+	created := make(map[string]*subRegistry)
+	getOrCreate := func(sub *common.URL) (*subRegistry, error) {
+		if reg, ok := created[sub.String()]; ok {
+			return reg, nil
+		}
+		reg, err := extension.GetRegistry(sub.Protocol, sub)
+		if err != nil {
+			return nil, perrors.WithMessagef(err, "create the registry %s", getName(sub))
+		}
+		created[sub.String()] = &subRegistry{Registry: reg, name: getName(sub), available: reg.IsAvailable()}
+		return created[sub.String()], nil
+	}
+	for _, sub := range serviceURLs {
+		reg, err := getOrCreate(sub)
+		if err != nil {
+			destroy(created)
+			return nil, err
+		}
+		r.services = append(r.services, reg)
+	}
+	for _, sub := range referenceURLs {
+		reg, err := getOrCreate(sub)
+		if err != nil {
+			destroy(created)
+			return nil, err
+		}
+		r.references = append(r.references, reg)
+		metrics.Publish(metricsRegistry.NewSourceAvailableEvent(reg.name, reg.available))
+	}
+
+	interval := url.GetParamDuration(constant.MultipleHealthCheckIntervalKey, defaultHealthCheckInterval)
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		r.checkHealth(interval)
+	}()
+	return r, nil
+}
+
+func destroy(regs map[string]*subRegistry) {
+	for _, reg := range regs {
+		reg.Destroy()
+	}
+}
+
+// Register registers the url to all the service registries. It fails only if all the registries fail, the
+// urls are still served by the others if some registries are unreachable.
+func (r *multipleRegistry) Register(url *common.URL) error {
+	return r.forEach(r.services, "register "+url.Key(), func(_ int, reg registry.Registry) error {
+		return reg.Register(url)
+	})
+}
+
+// UnRegister unregisters the url from all the service registries.
+func (r *multipleRegistry) UnRegister(url *common.URL) error {
+	return r.forEach(r.services, "unregister "+url.Key(), func(_ int, reg registry.Registry) error {
+		return reg.UnRegister(url)
+	})
+}
+
+// Subscribe subscribes the url from all the reference registries, the listener is notified of the merged
+// addresses once any of the registries notifies.
+func (r *multipleRegistry) Subscribe(url *common.URL, notifyListener registry.NotifyListener) error {
+	if !r.IsAvailable() {
+		return perrors.New("multipleRegistry is not available.")
+	}
+	sub := r.getOrCreateSubscription(url, notifyListener)
+	return r.forEach(r.references, "subscribe "+url.Key(), func(i int, reg registry.Registry) error {
+		return reg.Subscribe(url, sub.listeners[i])
+	})
+}
+
+// UnSubscribe unsubscribes the url from all the reference registries.
+func (r *multipleRegistry) UnSubscribe(url *common.URL, notifyListener registry.NotifyListener) error {
+	key := subscriptionKey{serviceKey: url.ServiceKey(), listener: notifyListener}
+	r.lock.Lock()
+	sub, ok := r.subscriptions[key]
+	delete(r.subscriptions, key)
+	r.lock.Unlock()
+	if !ok {
+		return nil
+	}
+	return r.forEach(r.references, "unsubscribe "+url.Key(), func(i int, reg registry.Registry) error {
+		return reg.UnSubscribe(url, sub.listeners[i])
+	})
+}
+
+// LoadSubscribeInstances loads the addresses from all the reference registries, and notifies the listener
+// of the merged addresses once.
+func (r *multipleRegistry) LoadSubscribeInstances(url *common.URL, notify registry.NotifyListener) error {
+	sub := r.getOrCreateSubscription(url, notify)
+	sub.batch(func() {
+		_ = r.forEach(r.references, "load the instances of "+url.Key(), func(i int, reg registry.Registry) error {
+			return reg.LoadSubscribeInstances(url, sub.listeners[i])
+		})
+	})
+	return nil
+}
+
+func (r *multipleRegistry) getOrCreateSubscription(url *common.URL, listener registry.NotifyListener) *subscription {
+	key := subscriptionKey{serviceKey: url.ServiceKey(), listener: listener}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if sub, ok := r.subscriptions[key]; ok {
+		return sub
+	}
+	sub := newSubscription(r, url, listener)
+	r.subscriptions[key] = sub
+	return sub
+}
+
+// forEach calls the action with the registries, and returns the error only if all of them fail
+func (r *multipleRegistry) forEach(regs []*subRegistry, action string, f func(int, registry.Registry) error) error {
+	var errs []string
+	for i, reg := range regs {
+		if err := f(i, reg.Registry); err != nil {
+			logger.Warnf("[Multiple Registry] Failed to %s with the registry %s, err: %v", action, reg.name, err)
+			errs = append(errs, reg.name+": "+err.Error())
+		}
+	}
+	if len(regs) > 0 && len(errs) == len(regs) {
+		return perrors.Errorf("failed to %s with all the registries, errors: %s", action, strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// checkHealth checks the availability of the reference registries periodically, and notifies the
+// subscriptions again if any of them changes, so the preferred policy falls back to the next registry.
+func (r *multipleRegistry) checkHealth(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-ticker.C:
+		}
+		if r.updateAvailability() {
+			r.lock.Lock()
+			subs := make([]*subscription, 0, len(r.subscriptions))
+			for _, sub := range r.subscriptions {
+				subs = append(subs, sub)
+			}
+			r.lock.Unlock()
+			for _, sub := range subs {
+				sub.notify(nil)
+			}
+		}
+	}
+}
+
+// updateAvailability returns true if the availability of any reference registry changes
+func (r *multipleRegistry) updateAvailability() bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	changed := false
+	for _, reg := range r.references {
+		if available := reg.IsAvailable(); available != reg.available {
+			logger.Warnf("[Multiple Registry] The availability of the registry %s changes to %t", reg.name, available)
+			reg.available = available
+			changed = true
+			metrics.Publish(metricsRegistry.NewSourceAvailableEvent(reg.name, available))
+		}
+	}
+	return changed
+}
+
+// availability returns the availability of the reference registries found by the last health check
+func (r *multipleRegistry) availability() []bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	res := make([]bool, len(r.references))
+	for i, reg := range r.references {
+		res[i] = reg.available
+	}
+	return res
+}
+
+// GetURL gets its registration URL
+func (r *multipleRegistry) GetURL() *common.URL {
+	return r.URL
+}
+
+// IsAvailable returns true if any of the registries is available
+func (r *multipleRegistry) IsAvailable() bool {
+	select {
+	case <-r.done:
+		return false
+	default:
+	}
+	for _, reg := range r.all() {
+		if reg.IsAvailable() {
+			return true
+		}
+	}
+	return false
+}
+
+// Destroy destroys all the registries
+func (r *multipleRegistry) Destroy() {
+	select {
+	case <-r.done:
+		return
+	default:
+	}
+	close(r.done)
+	r.wg.Wait()
+	for _, reg := range r.all() {
+		reg.Destroy()
+	}
+}
+
+// all returns the distinct registries of the service registries and the reference registries
+func (r *multipleRegistry) all() []*subRegistry {
+	var regs []*subRegistry
+	seen := make(map[*subRegistry]struct{})
+	for _, list := range [][]*subRegistry{r.services, r.references} {
+		for _, reg := range list {
+			if _, ok := seen[reg]; !ok {
+				seen[reg] = struct{}{}
+				regs = append(regs, reg)
+			}
+		}
+	}
+	return regs
+}
+
+// subscription merges the addresses of a subscribed url from the reference registries
+type subscription struct {
+	registry  *multipleRegistry
+	url       *common.URL
+	listener  registry.NotifyListener
+	listeners []*sourceListener
+
+	lock sync.Mutex
+	// sources are the addresses from the reference registries, keyed by url.Key()
+	sources []map[string]*common.URL
+	// batching suppresses the notifications until the batch is done
+	batching bool
+}
+
+func newSubscription(r *multipleRegistry, url *common.URL, listener registry.NotifyListener) *subscription {
+	sub := &subscription{
+		registry: r,
+		url:      url,
+		listener: listener,
+		sources:  make([]map[string]*common.URL, len(r.references)),
+	}
+	for i := range r.references {
+		sub.sources[i] = make(map[string]*common.URL)
+		sub.listeners = append(sub.listeners, &sourceListener{subscription: sub, index: i})
+	}
+	return sub
+}
+
+// update updates the addresses of the source, and notifies the listener of the merged addresses
+func (s *subscription) update(index int, f func(urls map[string]*common.URL), callback func()) {
+	s.lock.Lock()
+	f(s.sources[index])
+	batching := s.batching
+	s.lock.Unlock()
+	if batching {
+		if callback != nil {
+			callback()
+		}
+		return
+	}
+	s.notify(callback)
+}
+
+func (s *subscription) batch(f func()) {
+	s.lock.Lock()
+	s.batching = true
+	s.lock.Unlock()
+	f()
+	s.lock.Lock()
+	s.batching = false
+	s.lock.Unlock()
+	s.notify(nil)
+}
+
+// notify notifies the listener of the merged addresses, the lock serializes the notifications
+func (s *subscription) notify(callback func()) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	available := s.registry.availability()
+	sources := make([]source, len(s.sources))
+	for i, urls := range s.sources {
+		sources[i] = source{available: available[i], size: len(urls)}
+		metrics.Publish(metricsRegistry.NewSourceAddressEvent(s.registry.references[i].name, s.url.ServiceKey(), len(urls)))
+	}
+	merged := make(map[string]struct{})
+	events := make([]*registry.ServiceEvent, 0)
+	for _, i := range selectSources(s.registry.policy, sources) {
+		for key, url := range s.sources[i] {
+			if _, ok := merged[key]; ok {
+				continue
+			}
+			merged[key] = struct{}{}
+			events = append(events, &registry.ServiceEvent{Action: remoting.EventTypeUpdate, Service: url.Clone()})
+		}
+	}
+	if callback == nil {
+		callback = func() {}
+	}
+	s.listener.NotifyAll(events, callback)
+}
+
+// sourceListener receives the addresses from a reference registry
+type sourceListener struct {
+	subscription *subscription
+	index        int
+}
+
+// Notify updates the address of the event
+func (l *sourceListener) Notify(event *registry.ServiceEvent) {
+	l.subscription.update(l.index, func(urls map[string]*common.URL) {
+		if event.Action == remoting.EventTypeDel {
+			delete(urls, event.Service.Key())
+		} else {
+			urls[event.Service.Key()] = event.Service
+		}
+	}, nil)
+}
+
+// NotifyAll replaces the addresses of the registry
+func (l *sourceListener) NotifyAll(events []*registry.ServiceEvent, callback func()) {
+	l.subscription.update(l.index, func(urls map[string]*common.URL) {
+		for key := range urls {
+			delete(urls, key)
+		}
+		for _, event := range events {
+			urls[event.Service.Key()] = event.Service
+		}
+	}, callback)
+}
@@ -0,0 +1,346 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package multiple
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+import (
+	perrors "github.com/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.uber.org/atomic"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/common/extension"
+	"dubbo.apache.org/dubbo-go/v3/registry"
+	"dubbo.apache.org/dubbo-go/v3/remoting"
+)
+
+var (
+	mockRegistriesLock sync.Mutex
+	mockRegistries     = make(map[string]*mockRegistry)
+)
+
+func init() {
+	extension.SetRegistry("mock", func(url *common.URL) (registry.Registry, error) {
+		if url.Location == "127.0.0.1:0" {
+			return nil, perrors.New("unreachable")
+		}
+		r := &mockRegistry{URL: url, listeners: make(map[registry.NotifyListener]*common.URL)}
+		r.available.Store(true)
+		mockRegistriesLock.Lock()
+		mockRegistries[url.Location] = r
+		mockRegistriesLock.Unlock()
+		return r, nil
+	})
+}
+
+func getMockRegistry(location string) *mockRegistry {
+	mockRegistriesLock.Lock()
+	defer mockRegistriesLock.Unlock()
+	return mockRegistries[location]
+}
+
+func newTestRegistry(t *testing.T, params string) *multipleRegistry {
+	url, err := common.NewURL("multiple://127.0.0.1?" + params)
+	assert.Nil(t, err)
+	r, err := newMultipleRegistry(url)
+	assert.Nil(t, err)
+	t.Cleanup(r.Destroy)
+	return r.(*multipleRegistry)
+}
+
+func newProviderURL(location string) *common.URL {
+	url, _ := common.NewURL("tri://" + location + "/org.apache.dubbo.Greeter?interface=org.apache.dubbo.Greeter")
+	return url
+}
+
+func newConsumerURL() *common.URL {
+	url, _ := common.NewURL("consumer://127.0.0.1/org.apache.dubbo.Greeter?interface=org.apache.dubbo.Greeter")
+	return url
+}
+
+func TestGetSubURLs(t *testing.T) {
+	url, _ := common.NewURL("multiple://127.0.0.1?" + constant.MultipleServiceRegistryKey + "=mock://127.0.0.1:1001?timeout=5s," +
+		"mock://127.0.0.1:1002&timeout=3s&" + constant.RegistryKey + "=multiple")
+	services, references, err := getSubURLs(url)
+	assert.Nil(t, err)
+	assert.Len(t, services, 2)
+	assert.Equal(t, services, references)
+	assert.Equal(t, "5s", services[0].GetParam("timeout", ""))
+	assert.Equal(t, "3s", services[1].GetParam("timeout", ""))
+	assert.Equal(t, "mock", services[1].GetParam(constant.RegistryKey, ""))
+	assert.Equal(t, "mock://127.0.0.1:1002", getName(services[1]))
+
+	url.SetParam(constant.MultipleReferenceRegistryKey, "mock://127.0.0.1:1003")
+	_, references, err = getSubURLs(url)
+	assert.Nil(t, err)
+	assert.Len(t, references, 1)
+
+	url, _ = common.NewURL("multiple://127.0.0.1")
+	_, _, err = getSubURLs(url)
+	assert.NotNil(t, err)
+
+	url.SetParam(constant.MultipleMergePolicyKey, "unknown")
+	_, err = getPolicy(url)
+	assert.NotNil(t, err)
+}
+
+func TestSelectSources(t *testing.T) {
+	sources := []source{{available: true}, {available: false, size: 1}, {available: true, size: 2}}
+	assert.Equal(t, []int{0, 1, 2}, selectSources(UnionPolicy, sources))
+	assert.Equal(t, []int{2}, selectSources(PreferredPolicy, sources))
+	sources[2].size = 0
+	assert.Equal(t, []int{1}, selectSources(PreferredPolicy, sources))
+	sources[1].size = 0
+	assert.Empty(t, selectSources(PreferredPolicy, sources))
+}
+
+func TestNewMultipleRegistry(t *testing.T) {
+	url, _ := common.NewURL("multiple://127.0.0.1?" + constant.MultipleServiceRegistryKey + "=mock://127.0.0.1:1101,mock://127.0.0.1:0")
+	_, err := newMultipleRegistry(url)
+	assert.NotNil(t, err)
+
+	r := newTestRegistry(t, constant.MultipleServiceRegistryKey+"=mock://127.0.0.1:1102&"+
+		constant.MultipleReferenceRegistryKey+"=mock://127.0.0.1:1102,mock://127.0.0.1:1103")
+	assert.Len(t, r.services, 1)
+	assert.Len(t, r.references, 2)
+	// the registry in both of the lists is shared
+	assert.Same(t, r.services[0], r.references[0])
+	assert.True(t, r.IsAvailable())
+
+	r.Destroy()
+	assert.False(t, r.IsAvailable())
+	assert.True(t, getMockRegistry("127.0.0.1:1102").destroyed.Load())
+	assert.True(t, getMockRegistry("127.0.0.1:1103").destroyed.Load())
+}
+
+func TestRegister(t *testing.T) {
+	r := newTestRegistry(t, constant.MultipleServiceRegistryKey+"=mock://127.0.0.1:1201,mock://127.0.0.1:1202")
+	first, second := getMockRegistry("127.0.0.1:1201"), getMockRegistry("127.0.0.1:1202")
+
+	assert.Nil(t, r.Register(newProviderURL("127.0.0.1:20000")))
+	assert.Len(t, first.registered(), 1)
+	assert.Len(t, second.registered(), 1)
+
+	// the registration fails only if all the registries fail
+	first.fail.Store(true)
+	assert.Nil(t, r.UnRegister(newProviderURL("127.0.0.1:20000")))
+	assert.Len(t, second.registered(), 0)
+	second.fail.Store(true)
+	assert.NotNil(t, r.Register(newProviderURL("127.0.0.1:20000")))
+}
+
+func TestSubscribeUnion(t *testing.T) {
+	r := newTestRegistry(t, constant.MultipleReferenceRegistryKey+"=mock://127.0.0.1:1301,mock://127.0.0.1:1302")
+	first, second := getMockRegistry("127.0.0.1:1301"), getMockRegistry("127.0.0.1:1302")
+	first.notifyAll(newProviderURL("127.0.0.1:20000"))
+	second.notifyAll(newProviderURL("127.0.0.1:20000"), newProviderURL("127.0.0.1:20001"))
+
+	listener := &mockNotifyListener{}
+	assert.Nil(t, r.LoadSubscribeInstances(newConsumerURL(), listener))
+	// the addresses are loaded and notified once
+	assert.Equal(t, 1, listener.count())
+	assert.ElementsMatch(t, []string{"20000", "20001"}, listener.ports())
+
+	assert.Nil(t, r.Subscribe(newConsumerURL(), listener))
+	// the duplicated address is removed from one of the registries
+	second.notify(remoting.EventTypeDel, newProviderURL("127.0.0.1:20000"))
+	assert.ElementsMatch(t, []string{"20000", "20001"}, listener.ports())
+	first.notify(remoting.EventTypeAdd, newProviderURL("127.0.0.1:20002"))
+	assert.ElementsMatch(t, []string{"20000", "20001", "20002"}, listener.ports())
+
+	assert.Nil(t, r.UnSubscribe(newConsumerURL(), listener))
+	count := listener.count()
+	first.notify(remoting.EventTypeAdd, newProviderURL("127.0.0.1:20003"))
+	assert.Equal(t, count, listener.count())
+}
+
+func TestSubscribePreferred(t *testing.T) {
+	r := newTestRegistry(t, constant.MultipleReferenceRegistryKey+"=mock://127.0.0.1:1401,mock://127.0.0.1:1402&"+
+		constant.MultipleMergePolicyKey+"="+PreferredPolicy+"&"+constant.MultipleHealthCheckIntervalKey+"=10ms")
+	first, second := getMockRegistry("127.0.0.1:1401"), getMockRegistry("127.0.0.1:1402")
+	second.notifyAll(newProviderURL("127.0.0.1:20001"))
+
+	listener := &mockNotifyListener{}
+	assert.Nil(t, r.Subscribe(newConsumerURL(), listener))
+	// the preferred registry has no address
+	assert.Equal(t, []string{"20001"}, listener.ports())
+
+	first.notify(remoting.EventTypeAdd, newProviderURL("127.0.0.1:20000"))
+	assert.Equal(t, []string{"20000"}, listener.ports())
+
+	// fall back to the next registry once the preferred one is unreachable
+	first.available.Store(false)
+	assert.Eventually(t, func() bool {
+		ports := listener.ports()
+		return len(ports) == 1 && ports[0] == "20001"
+	}, time.Second, 10*time.Millisecond)
+	first.available.Store(true)
+	assert.Eventually(t, func() bool {
+		ports := listener.ports()
+		return len(ports) == 1 && ports[0] == "20000"
+	}, time.Second, 10*time.Millisecond)
+}
+
+type mockRegistry struct {
+	*common.URL
+	available atomic.Bool
+	destroyed atomic.Bool
+	fail      atomic.Bool
+
+	lock      sync.Mutex
+	urls      []*common.URL
+	providers []*common.URL
+	listeners map[registry.NotifyListener]*common.URL
+}
+
+func (m *mockRegistry) Register(url *common.URL) error {
+	if m.fail.Load() {
+		return perrors.New("mock failure")
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.urls = append(m.urls, url)
+	return nil
+}
+
+func (m *mockRegistry) UnRegister(url *common.URL) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	for i, u := range m.urls {
+		if u.Key() == url.Key() {
+			m.urls = append(m.urls[:i], m.urls[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (m *mockRegistry) registered() []*common.URL {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.urls
+}
+
+func (m *mockRegistry) Subscribe(url *common.URL, listener registry.NotifyListener) error {
+	m.lock.Lock()
+	m.listeners[listener] = url
+	m.lock.Unlock()
+	return m.LoadSubscribeInstances(url, listener)
+}
+
+func (m *mockRegistry) UnSubscribe(_ *common.URL, listener registry.NotifyListener) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	delete(m.listeners, listener)
+	return nil
+}
+
+func (m *mockRegistry) LoadSubscribeInstances(_ *common.URL, listener registry.NotifyListener) error {
+	m.lock.Lock()
+	providers := m.providers
+	m.lock.Unlock()
+	for _, p := range providers {
+		listener.Notify(&registry.ServiceEvent{Action: remoting.EventTypeAdd, Service: p})
+	}
+	return nil
+}
+
+func (m *mockRegistry) notify(action remoting.EventType, url *common.URL) {
+	m.lock.Lock()
+	listeners := make([]registry.NotifyListener, 0, len(m.listeners))
+	for l := range m.listeners {
+		listeners = append(listeners, l)
+	}
+	m.lock.Unlock()
+	for _, l := range listeners {
+		l.Notify(&registry.ServiceEvent{Action: action, Service: url})
+	}
+}
+
+func (m *mockRegistry) notifyAll(urls ...*common.URL) {
+	m.lock.Lock()
+	m.providers = urls
+	listeners := make([]registry.NotifyListener, 0, len(m.listeners))
+	for l := range m.listeners {
+		listeners = append(listeners, l)
+	}
+	m.lock.Unlock()
+	events := make([]*registry.ServiceEvent, 0, len(urls))
+	for _, url := range urls {
+		events = append(events, &registry.ServiceEvent{Action: remoting.EventTypeUpdate, Service: url})
+	}
+	for _, l := range listeners {
+		l.NotifyAll(events, func() {})
+	}
+}
+
+func (m *mockRegistry) GetURL() *common.URL {
+	return m.URL
+}
+
+func (m *mockRegistry) IsAvailable() bool {
+	return m.available.Load() && !m.destroyed.Load()
+}
+
+func (m *mockRegistry) Destroy() {
+	m.destroyed.Store(true)
+}
+
+type mockNotifyListener struct {
+	lock   sync.Mutex
+	times  int
+	events []*registry.ServiceEvent
+}
+
+func (m *mockNotifyListener) Notify(*registry.ServiceEvent) {
+	panic("the multiple registry always notifies the complete list")
+}
+
+func (m *mockNotifyListener) NotifyAll(events []*registry.ServiceEvent, f func()) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.times++
+	m.events = events
+	f()
+}
+
+func (m *mockNotifyListener) count() int {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.times
+}
+
+func (m *mockNotifyListener) ports() []string {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	ports := make([]string, 0, len(m.events))
+	for _, e := range m.events {
+		ports = append(ports, e.Service.Port)
+	}
+	return ports
+}
@@ -0,0 +1,325 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package multiple
+
+import (
+	"strings"
+	"sync"
+)
+
+import (
+	gxset "github.com/dubbogo/gost/container/set"
+	"github.com/dubbogo/gost/gof/observer"
+	gxpage "github.com/dubbogo/gost/hash/page"
+	"github.com/dubbogo/gost/log/logger"
+
+	perrors "github.com/pkg/errors"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/common/extension"
+	"dubbo.apache.org/dubbo-go/v3/metrics"
+	metricsRegistry "dubbo.apache.org/dubbo-go/v3/metrics/registry"
+	"dubbo.apache.org/dubbo-go/v3/registry"
+)
+
+func init() {
+	extension.SetServiceDiscovery(constant.MultipleKey, newMultipleServiceDiscovery)
+}
+
+// multipleServiceDiscovery registers the instances to all the service discoveries of the service registries,
+// and merges the instances from the service discoveries of the reference registries by the merge policy. The
+// service discoveries don't report their availability, so the preferred policy falls back to the next one if
+// the preferred one has no instance.
+type multipleServiceDiscovery struct {
+	policy     string
+	services   []*subDiscovery
+	references []*subDiscovery
+}
+
+type subDiscovery struct {
+	registry.ServiceDiscovery
+	name string
+}
+
+func newMultipleServiceDiscovery(url *common.URL) (registry.ServiceDiscovery, error) {
+	policy, err := getPolicy(url)
+	if err != nil {
+		return nil, err
+	}
+	serviceURLs, referenceURLs, err := getSubURLs(url)
+	if err != nil {
+		return nil, err
+	}
+	sd := &multipleServiceDiscovery{policy: policy}
+	created := make(map[string]*subDiscovery)
+	getOrCreate := func(sub *common.URL) (*subDiscovery, error) {
+		if discovery, ok := created[sub.String()]; ok {
+			return discovery, nil
+		}
+		discovery, err := extension.GetServiceDiscovery(sub)
+		if err != nil {
+			for _, d := range created {
+				_ = d.Destroy()
+			}
+			return nil, perrors.WithMessagef(err, "create the service discovery %s", getName(sub))
+		}
+		created[sub.String()] = &subDiscovery{ServiceDiscovery: discovery, name: getName(sub)}
+		return created[sub.String()], nil
+	}
+	for _, sub := range serviceURLs {
+		discovery, err := getOrCreate(sub)
+		if err != nil {
+			return nil, err
+		}
+		sd.services = append(sd.services, discovery)
+	}
+	for _, sub := range referenceURLs {
+		discovery, err := getOrCreate(sub)
+		if err != nil {
+			return nil, err
+		}
+		sd.references = append(sd.references, discovery)
+	}
+	return sd, nil
+}
+
+func (m *multipleServiceDiscovery) String() string {
+	names := make([]string, 0, len(m.references))
+	for _, d := range m.references {
+		names = append(names, d.name)
+	}
+	return "multiple service discovery: [" + strings.Join(names, ", ") + "]"
+}
+
+// Destroy destroys all the service discoveries
+func (m *multipleServiceDiscovery) Destroy() error {
+	var errs []string
+	for _, d := range m.all() {
+		if err := d.Destroy(); err != nil {
+			errs = append(errs, d.name+": "+err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return perrors.Errorf("failed to destroy the service discoveries, errors: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// all returns the distinct service discoveries of the service registries and the reference registries
+func (m *multipleServiceDiscovery) all() []*subDiscovery {
+	var discoveries []*subDiscovery
+	seen := make(map[*subDiscovery]struct{})
+	for _, list := range [][]*subDiscovery{m.services, m.references} {
+		for _, d := range list {
+			if _, ok := seen[d]; !ok {
+				seen[d] = struct{}{}
+				discoveries = append(discoveries, d)
+			}
+		}
+	}
+	return discoveries
+}
+
+// Register registers the instance to all the service discoveries of the service registries, it fails only if
+// all of them fail.
+func (m *multipleServiceDiscovery) Register(instance registry.ServiceInstance) error {
+	return m.forEach("register "+instance.GetID(), func(d registry.ServiceDiscovery) error {
+		return d.Register(instance)
+	})
+}
+
+// Update updates the instance in all the service discoveries of the service registries
+func (m *multipleServiceDiscovery) Update(instance registry.ServiceInstance) error {
+	return m.forEach("update "+instance.GetID(), func(d registry.ServiceDiscovery) error {
+		return d.Update(instance)
+	})
+}
+
+// Unregister unregisters the instance from all the service discoveries of the service registries
+func (m *multipleServiceDiscovery) Unregister(instance registry.ServiceInstance) error {
+	return m.forEach("unregister "+instance.GetID(), func(d registry.ServiceDiscovery) error {
+		return d.Unregister(instance)
+	})
+}
+
+func (m *multipleServiceDiscovery) forEach(action string, f func(registry.ServiceDiscovery) error) error {
+	var errs []string
+	for _, d := range m.services {
+		if err := f(d.ServiceDiscovery); err != nil {
+			logger.Warnf("[Multiple Registry] Failed to %s with the service discovery %s, err: %v", action, d.name, err)
+			errs = append(errs, d.name+": "+err.Error())
+		}
+	}
+	if len(m.services) > 0 && len(errs) == len(m.services) {
+		return perrors.Errorf("failed to %s with all the service discoveries, errors: %s", action, strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// GetDefaultPageSize returns the default page size
+func (m *multipleServiceDiscovery) GetDefaultPageSize() int {
+	return registry.DefaultPageSize
+}
+
+// GetServices returns the union of the service names of the reference registries
+func (m *multipleServiceDiscovery) GetServices() *gxset.HashSet {
+	res := gxset.NewSet()
+	for _, d := range m.references {
+		res.Add(d.GetServices().Values()...)
+	}
+	return res
+}
+
+// GetInstances returns the instances of the reference registries merged by the policy
+func (m *multipleServiceDiscovery) GetInstances(serviceName string) []registry.ServiceInstance {
+	sources := make([][]registry.ServiceInstance, 0, len(m.references))
+	for _, d := range m.references {
+		sources = append(sources, d.GetInstances(serviceName))
+	}
+	return mergeInstances(m.policy, sources)
+}
+
+// mergeInstances merges the instances of the sources by the policy, the instances are deduplicated by the address
+func mergeInstances(policy string, sources [][]registry.ServiceInstance) []registry.ServiceInstance {
+	states := make([]source, len(sources))
+	for i, instances := range sources {
+		states[i] = source{available: true, size: len(instances)}
+	}
+	res := make([]registry.ServiceInstance, 0)
+	merged := make(map[string]struct{})
+	for _, i := range selectSources(policy, states) {
+		for _, instance := range sources[i] {
+			if _, ok := merged[instance.GetAddress()]; ok {
+				continue
+			}
+			merged[instance.GetAddress()] = struct{}{}
+			res = append(res, instance)
+		}
+	}
+	return res
+}
+
+// GetInstancesByPage will return a page containing instances of ServiceInstance with the serviceName
+// the page will start at offset
+func (m *multipleServiceDiscovery) GetInstancesByPage(serviceName string, offset int, pageSize int) gxpage.Pager {
+	all := m.GetInstances(serviceName)
+	res := make([]any, 0, pageSize)
+	for i := offset; i < len(all) && i < offset+pageSize; i++ {
+		res = append(res, all[i])
+	}
+	return gxpage.NewPage(offset, pageSize, res, len(all))
+}
+
+// GetHealthyInstancesByPage will return a page containing instances of ServiceInstance.
+// The param healthy indices that the instance should be healthy or not.
+// The page will start at offset
+func (m *multipleServiceDiscovery) GetHealthyInstancesByPage(serviceName string, offset int, pageSize int, healthy bool) gxpage.Pager {
+	all := m.GetInstances(serviceName)
+	res := make([]any, 0, pageSize)
+	var (
+		i     = offset
+		count = 0
+	)
+	for i < len(all) && count < pageSize {
+		if all[i].IsHealthy() == healthy {
+			res = append(res, all[i])
+			count++
+		}
+		i++
+	}
+	return gxpage.NewPage(offset, pageSize, res, len(all))
+}
+
+// GetRequestInstances Batch get all instances by the specified service names
+func (m *multipleServiceDiscovery) GetRequestInstances(serviceNames []string, offset int, requestedSize int) map[string]gxpage.Pager {
+	res := make(map[string]gxpage.Pager, len(serviceNames))
+	for _, name := range serviceNames {
+		res[name] = m.GetInstancesByPage(name, offset, requestedSize)
+	}
+	return res
+}
+
+// AddListener adds the listener to all the service discoveries of the reference registries, the listener is
+// notified of the merged instances once any of them notifies.
+func (m *multipleServiceDiscovery) AddListener(listener registry.ServiceInstancesChangedListener) error {
+	merger := &instancesMerger{
+		policy:   m.policy,
+		names:    make([]string, len(m.references)),
+		listener: listener,
+		sources:  make([]map[string][]registry.ServiceInstance, len(m.references)),
+	}
+	var errs []string
+	for i, d := range m.references {
+		merger.names[i] = d.name
+		merger.sources[i] = make(map[string][]registry.ServiceInstance)
+		err := d.AddListener(&sourceInstancesListener{ServiceInstancesChangedListener: listener, merger: merger, index: i})
+		if err != nil {
+			logger.Warnf("[Multiple Registry] Failed to add the listener to the service discovery %s, err: %v", d.name, err)
+			errs = append(errs, d.name+": "+err.Error())
+		}
+	}
+	if len(errs) == len(m.references) {
+		return perrors.Errorf("failed to add the listener to all the service discoveries, errors: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// instancesMerger merges the instances notified by the service discoveries of the reference registries
+type instancesMerger struct {
+	policy   string
+	names    []string
+	listener registry.ServiceInstancesChangedListener
+
+	lock sync.Mutex
+	// sources are the instances notified by the service discoveries, keyed by the service name
+	sources []map[string][]registry.ServiceInstance
+}
+
+// onEvent updates the instances of the source, and notifies the listener of the merged instances, the lock
+// serializes the notifications.
+func (m *instancesMerger) onEvent(index int, event *registry.ServiceInstancesChangedEvent) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.sources[index][event.ServiceName] = event.Instances
+	metrics.Publish(metricsRegistry.NewSourceAddressEvent(m.names[index], event.ServiceName, len(event.Instances)))
+	sources := make([][]registry.ServiceInstance, 0, len(m.sources))
+	for _, s := range m.sources {
+		sources = append(sources, s[event.ServiceName])
+	}
+	instances := mergeInstances(m.policy, sources)
+	return m.listener.OnEvent(registry.NewServiceInstancesChangedEvent(event.ServiceName, instances))
+}
+
+// sourceInstancesListener receives the instances from a service discovery of the reference registries
+type sourceInstancesListener struct {
+	registry.ServiceInstancesChangedListener
+	merger *instancesMerger
+	index  int
+}
+
+// OnEvent merges the instances of the event with the instances from the other service discoveries
+func (l *sourceInstancesListener) OnEvent(e observer.Event) error {
+	event, ok := e.(*registry.ServiceInstancesChangedEvent)
+	if !ok {
+		return nil
+	}
+	return l.merger.onEvent(l.index, event)
+}
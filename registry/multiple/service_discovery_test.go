@@ -0,0 +1,254 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package multiple
+
+import (
+	"sync"
+	"testing"
+)
+
+import (
+	gxset "github.com/dubbogo/gost/container/set"
+	"github.com/dubbogo/gost/gof/observer"
+	gxpage "github.com/dubbogo/gost/hash/page"
+
+	perrors "github.com/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/common/extension"
+	"dubbo.apache.org/dubbo-go/v3/registry"
+)
+
+var (
+	mockDiscoveriesLock sync.Mutex
+	mockDiscoveries     = make(map[string]*mockServiceDiscovery)
+)
+
+func init() {
+	extension.SetServiceDiscovery("mock", func(url *common.URL) (registry.ServiceDiscovery, error) {
+		if url.Location == "127.0.0.1:0" {
+			return nil, perrors.New("unreachable")
+		}
+		sd := &mockServiceDiscovery{instances: make(map[string][]registry.ServiceInstance)}
+		mockDiscoveriesLock.Lock()
+		mockDiscoveries[url.Location] = sd
+		mockDiscoveriesLock.Unlock()
+		return sd, nil
+	})
+}
+
+func getMockServiceDiscovery(location string) *mockServiceDiscovery {
+	mockDiscoveriesLock.Lock()
+	defer mockDiscoveriesLock.Unlock()
+	return mockDiscoveries[location]
+}
+
+func newTestServiceDiscovery(t *testing.T, params string) *multipleServiceDiscovery {
+	url, err := common.NewURL("service-discovery-registry://127.0.0.1?" + constant.RegistryKey + "=multiple&" + params)
+	assert.Nil(t, err)
+	sd, err := newMultipleServiceDiscovery(url)
+	assert.Nil(t, err)
+	t.Cleanup(func() {
+		_ = sd.Destroy()
+	})
+	return sd.(*multipleServiceDiscovery)
+}
+
+func newInstance(host string, port int) registry.ServiceInstance {
+	return &registry.DefaultServiceInstance{
+		ID:          host,
+		ServiceName: "greeter",
+		Host:        host,
+		Port:        port,
+		Enable:      true,
+		Healthy:     true,
+	}
+}
+
+func TestNewMultipleServiceDiscovery(t *testing.T) {
+	url, _ := common.NewURL("service-discovery-registry://127.0.0.1?" + constant.MultipleServiceRegistryKey +
+		"=mock://127.0.0.1:2101,mock://127.0.0.1:0")
+	_, err := newMultipleServiceDiscovery(url)
+	assert.NotNil(t, err)
+	assert.True(t, getMockServiceDiscovery("127.0.0.1:2101").destroyed)
+
+	sd := newTestServiceDiscovery(t, constant.MultipleServiceRegistryKey+"=mock://127.0.0.1:2102,mock://127.0.0.1:2103")
+	assert.Equal(t, "multiple service discovery: [mock://127.0.0.1:2102, mock://127.0.0.1:2103]", sd.String())
+	assert.Equal(t, registry.DefaultPageSize, sd.GetDefaultPageSize())
+	assert.Nil(t, sd.Register(newInstance("127.0.0.1", 20000)))
+	assert.Len(t, getMockServiceDiscovery("127.0.0.1:2102").GetInstances("greeter"), 1)
+	assert.Len(t, getMockServiceDiscovery("127.0.0.1:2103").GetInstances("greeter"), 1)
+	assert.Nil(t, sd.Destroy())
+	assert.True(t, getMockServiceDiscovery("127.0.0.1:2103").destroyed)
+}
+
+func TestGetInstances(t *testing.T) {
+	sd := newTestServiceDiscovery(t, constant.MultipleReferenceRegistryKey+"=mock://127.0.0.1:2201,mock://127.0.0.1:2202")
+	first, second := getMockServiceDiscovery("127.0.0.1:2201"), getMockServiceDiscovery("127.0.0.1:2202")
+	assert.Nil(t, first.Register(newInstance("127.0.0.1", 20000)))
+	assert.Nil(t, second.Register(newInstance("127.0.0.1", 20000)))
+	assert.Nil(t, second.Register(newInstance("127.0.0.2", 20000)))
+
+	assert.True(t, sd.GetServices().Contains("greeter"))
+	assert.Len(t, sd.GetInstances("greeter"), 2)
+	assert.Equal(t, 1, sd.GetInstancesByPage("greeter", 1, 10).GetDataSize())
+	assert.Equal(t, 2, sd.GetHealthyInstancesByPage("greeter", 0, 10, true).GetDataSize())
+	assert.Equal(t, 2, sd.GetRequestInstances([]string{"greeter"}, 0, 10)["greeter"].GetDataSize())
+
+	sd.policy = PreferredPolicy
+	assert.Len(t, sd.GetInstances("greeter"), 1)
+	assert.Nil(t, first.Unregister(newInstance("127.0.0.1", 20000)))
+	assert.Len(t, sd.GetInstances("greeter"), 2)
+}
+
+func TestAddListener(t *testing.T) {
+	sd := newTestServiceDiscovery(t, constant.MultipleReferenceRegistryKey+"=mock://127.0.0.1:2301,mock://127.0.0.1:2302")
+	first, second := getMockServiceDiscovery("127.0.0.1:2301"), getMockServiceDiscovery("127.0.0.1:2302")
+	listener := &mockInstancesListener{services: gxset.NewSet("greeter")}
+	assert.Nil(t, sd.AddListener(listener))
+
+	first.notify("greeter", newInstance("127.0.0.1", 20000))
+	assert.Len(t, listener.last(), 1)
+	second.notify("greeter", newInstance("127.0.0.1", 20000), newInstance("127.0.0.2", 20000))
+	assert.Len(t, listener.last(), 2)
+	first.notify("greeter")
+	assert.Len(t, listener.last(), 2)
+	second.notify("greeter", newInstance("127.0.0.2", 20000))
+	assert.Len(t, listener.last(), 1)
+	assert.Equal(t, "127.0.0.2", listener.last()[0].GetHost())
+}
+
+type mockServiceDiscovery struct {
+	lock      sync.Mutex
+	instances map[string][]registry.ServiceInstance
+	listeners []registry.ServiceInstancesChangedListener
+	destroyed bool
+}
+
+func (m *mockServiceDiscovery) String() string {
+	return "mock"
+}
+
+func (m *mockServiceDiscovery) Destroy() error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.destroyed = true
+	return nil
+}
+
+func (m *mockServiceDiscovery) Register(instance registry.ServiceInstance) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.instances[instance.GetServiceName()] = append(m.instances[instance.GetServiceName()], instance)
+	return nil
+}
+
+func (m *mockServiceDiscovery) Update(registry.ServiceInstance) error {
+	return nil
+}
+
+func (m *mockServiceDiscovery) Unregister(instance registry.ServiceInstance) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	instances := m.instances[instance.GetServiceName()]
+	for i, ins := range instances {
+		if ins.GetAddress() == instance.GetAddress() {
+			m.instances[instance.GetServiceName()] = append(instances[:i], instances[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (m *mockServiceDiscovery) GetDefaultPageSize() int {
+	return registry.DefaultPageSize
+}
+
+func (m *mockServiceDiscovery) GetServices() *gxset.HashSet {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	res := gxset.NewSet()
+	for name := range m.instances {
+		res.Add(name)
+	}
+	return res
+}
+
+func (m *mockServiceDiscovery) GetInstances(serviceName string) []registry.ServiceInstance {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.instances[serviceName]
+}
+
+func (m *mockServiceDiscovery) GetInstancesByPage(string, int, int) gxpage.Pager {
+	return nil
+}
+
+func (m *mockServiceDiscovery) GetHealthyInstancesByPage(string, int, int, bool) gxpage.Pager {
+	return nil
+}
+
+func (m *mockServiceDiscovery) GetRequestInstances([]string, int, int) map[string]gxpage.Pager {
+	return nil
+}
+
+func (m *mockServiceDiscovery) AddListener(listener registry.ServiceInstancesChangedListener) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.listeners = append(m.listeners, listener)
+	return nil
+}
+
+func (m *mockServiceDiscovery) notify(serviceName string, instances ...registry.ServiceInstance) {
+	m.lock.Lock()
+	listeners := m.listeners
+	m.lock.Unlock()
+	for _, l := range listeners {
+		_ = l.OnEvent(registry.NewServiceInstancesChangedEvent(serviceName, instances))
+	}
+}
+
+type mockInstancesListener struct {
+	registry.ServiceInstancesChangedListener
+	services *gxset.HashSet
+
+	lock      sync.Mutex
+	instances []registry.ServiceInstance
+}
+
+func (m *mockInstancesListener) GetServiceNames() *gxset.HashSet {
+	return m.services
+}
+
+func (m *mockInstancesListener) OnEvent(e observer.Event) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.instances = e.(*registry.ServiceInstancesChangedEvent).Instances
+	return nil
+}
+
+func (m *mockInstancesListener) last() []registry.ServiceInstance {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.instances
+}
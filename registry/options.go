@@ -57,6 +57,12 @@ func NewOptions(opts ...Option) *Options {
 
 type Option func(*Options)
 
+func WithConsul() Option {
+	return func(opts *Options) {
+		opts.Registry.Protocol = constant.ConsulKey
+	}
+}
+
 func WithEtcdV3() Option {
 	return func(opts *Options) {
 		// todo(DMwangnima): move etcdv3 to constant
@@ -32,6 +32,8 @@ import (
 )
 
 import (
+	"dubbo.apache.org/dubbo-go/v3/cluster/healthcheck"
+	"dubbo.apache.org/dubbo-go/v3/cluster/outlier"
 	"dubbo.apache.org/dubbo-go/v3/common"
 	"dubbo.apache.org/dubbo-go/v3/common/constant"
 	"dubbo.apache.org/dubbo-go/v3/common/extension"
@@ -172,7 +174,7 @@ func (proto *registryProtocol) Refer(url *common.URL) base.Invoker {
 	if err != nil {
 		panic(err)
 	}
-	invoker := cluster.Join(dic)
+	invoker := cluster.Join(outlier.NewDirectory(healthcheck.NewDirectory(dic, serviceUrl), serviceUrl))
 	return invoker
 }
 
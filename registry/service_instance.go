@@ -176,6 +176,7 @@ func (d *DefaultServiceInstance) ToURLs(service *info.ServiceInfo) []*common.URL
 					common.WithPath(service.Name), common.WithInterface(service.Name),
 					common.WithMethods(service.GetMethods()), common.WithParams(service.GetParams()),
 					common.WithParams(url2.Values{constant.Tagkey: {d.Tag}}),
+					common.WithParams(d.getLocality()),
 					common.WithWeight(d.GetWeight()))
 				urls = append(urls, url)
 			}
@@ -186,12 +187,24 @@ func (d *DefaultServiceInstance) ToURLs(service *info.ServiceInfo) []*common.URL
 			common.WithPath(service.Name), common.WithInterface(service.Name),
 			common.WithMethods(service.GetMethods()), common.WithParams(service.GetParams()),
 			common.WithParams(url2.Values{constant.Tagkey: {d.Tag}}),
+			common.WithParams(d.getLocality()),
 			common.WithWeight(d.GetWeight()))
 		urls = append(urls, url)
 	}
 	return urls
 }
 
+// getLocality returns the region and zone declared in the metadata, which are used by the locality load balance
+func (d *DefaultServiceInstance) getLocality() url2.Values {
+	locality := url2.Values{}
+	for _, key := range []string{constant.LocalityRegionKey, constant.LocalityZoneKey} {
+		if v := d.Metadata[key]; v != "" {
+			locality.Set(key, v)
+		}
+	}
+	return locality
+}
+
 // GetEndPoints get end points from metadata
 func (d *DefaultServiceInstance) GetEndPoints() []*Endpoint {
 	rawEndpoints := d.Metadata[constant.ServiceInstanceEndpoints]
@@ -0,0 +1,206 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package snapshot persists the last known provider addresses of the subscribed services, so the consumers
+// starting while the registry is unreachable still have the providers.
+package snapshot
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+import (
+	perrors "github.com/pkg/errors"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+)
+
+const fileSuffix = ".json"
+
+// ErrNotFound is returned if there is no snapshot of the service
+var ErrNotFound = perrors.New("the snapshot is not found")
+
+// Snapshot is the provider urls of a service at a moment, the params of the urls carry the metadata.
+type Snapshot struct {
+	Service   string    `json:"service"`
+	Timestamp time.Time `json:"timestamp"`
+	URLs      []string  `json:"urls"`
+	Checksum  string    `json:"checksum"`
+}
+
+func (s *Snapshot) checksum() string {
+	h := sha256.New()
+	h.Write([]byte(s.Service))
+	h.Write([]byte{'\n'})
+	h.Write([]byte(strconv.FormatInt(s.Timestamp.UnixNano(), 10)))
+	for _, u := range s.URLs {
+		h.Write([]byte{'\n'})
+		h.Write([]byte(u))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Store saves the snapshots as the json files in a directory, one file per service.
+type Store struct {
+	dir    string
+	maxAge time.Duration
+	// lock serializes the writes of the same process, the files are replaced atomically for the others
+	lock sync.Mutex
+}
+
+// NewStore creates the store of the directory, the snapshots older than maxAge are not loaded, and the
+// non-positive maxAge means no limit.
+func NewStore(dir string, maxAge time.Duration) *Store {
+	return &Store{dir: dir, maxAge: maxAge}
+}
+
+// NewStoreByURL creates the store by the params of the registry url, it returns nil if the snapshots are
+// not enabled.
+func NewStoreByURL(url *common.URL) (*Store, error) {
+	if !url.GetParamBool(constant.RegistrySnapshotKey, false) {
+		return nil, nil
+	}
+	maxAge, err := time.ParseDuration(url.GetParam(constant.RegistrySnapshotMaxAgeKey, constant.DefaultSnapshotMaxAge))
+	if err != nil {
+		return nil, perrors.WithMessagef(err, "illegal %s", constant.RegistrySnapshotMaxAgeKey)
+	}
+	dir := url.GetParam(constant.RegistrySnapshotDirKey, "")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			home = os.TempDir()
+		}
+		dir = filepath.Join(home, ".dubbo", "snapshot")
+	}
+	return NewStore(dir, maxAge), nil
+}
+
+func (s *Store) path(service string) string {
+	return filepath.Join(s.dir, url.PathEscape(service)+fileSuffix)
+}
+
+// Save replaces the snapshot of the service with the urls. The file is written to a temporary file and
+// renamed, so the readers never see a partial snapshot.
+func (s *Store) Save(service string, urls []*common.URL) (*Snapshot, error) {
+	snapshot := &Snapshot{Service: service, Timestamp: time.Now(), URLs: toStrings(urls)}
+	snapshot.Checksum = snapshot.checksum()
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if err = os.MkdirAll(s.dir, 0o755); err != nil {
+		return nil, perrors.WithMessagef(err, "create the snapshot directory %s", s.dir)
+	}
+	tmp, err := os.CreateTemp(s.dir, url.PathEscape(service)+".*.tmp")
+	if err != nil {
+		return nil, perrors.WithMessage(err, "create the temporary snapshot file")
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(data); err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, perrors.WithMessagef(err, "write the snapshot of %s", service)
+	}
+	if err = os.Rename(tmp.Name(), s.path(service)); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// Load returns the urls in the snapshot of the service. It fails if the snapshot is not found, expired, or
+// the checksum doesn't match.
+func (s *Store) Load(service string) ([]*common.URL, *Snapshot, error) {
+	data, err := os.ReadFile(s.path(service))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, ErrNotFound
+		}
+		return nil, nil, err
+	}
+	snapshot := &Snapshot{}
+	if err = json.Unmarshal(data, snapshot); err != nil {
+		return nil, nil, perrors.WithMessagef(err, "decode the snapshot of %s", service)
+	}
+	if snapshot.Service != service || snapshot.Checksum != snapshot.checksum() {
+		return nil, nil, perrors.Errorf("the checksum of the snapshot of %s doesn't match", service)
+	}
+	if age := time.Since(snapshot.Timestamp); s.maxAge > 0 && age > s.maxAge {
+		return nil, nil, perrors.Errorf("the snapshot of %s is expired, it is saved %s ago", service,
+			age.Truncate(time.Second))
+	}
+	urls := make([]*common.URL, 0, len(snapshot.URLs))
+	for _, raw := range snapshot.URLs {
+		u, err := common.NewURL(raw)
+		if err != nil {
+			return nil, nil, perrors.WithMessagef(err, "parse the url %s in the snapshot of %s", raw, service)
+		}
+		urls = append(urls, u)
+	}
+	return urls, snapshot, nil
+}
+
+// Remove removes the snapshot of the service
+func (s *Store) Remove(service string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if err := os.Remove(s.path(service)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// Equal returns true if the urls are the same as the urls in the snapshot, regardless of the order
+func (s *Snapshot) Equal(urls []*common.URL) bool {
+	if s == nil || len(s.URLs) != len(urls) {
+		return false
+	}
+	raw := toStrings(urls)
+	for i := range raw {
+		if raw[i] != s.URLs[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func toStrings(urls []*common.URL) []string {
+	raw := make([]string, 0, len(urls))
+	for _, u := range urls {
+		raw = append(raw, u.String())
+	}
+	sort.Strings(raw)
+	return raw
+}
@@ -0,0 +1,124 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package snapshot
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+)
+
+const testService = "zookeeper://127.0.0.1:2181/group/org.apache.dubbo.Greeter:1.0.0"
+
+func newTestURLs() []*common.URL {
+	url1, _ := common.NewURL("tri://127.0.0.1:20001/org.apache.dubbo.Greeter?interface=org.apache.dubbo.Greeter&weight=100")
+	url2, _ := common.NewURL("tri://127.0.0.1:20000/org.apache.dubbo.Greeter?interface=org.apache.dubbo.Greeter")
+	return []*common.URL{url1, url2}
+}
+
+func TestNewStoreByURL(t *testing.T) {
+	url, _ := common.NewURL("zookeeper://127.0.0.1:2181")
+	store, err := NewStoreByURL(url)
+	assert.Nil(t, err)
+	assert.Nil(t, store)
+
+	url.SetParam(constant.RegistrySnapshotKey, "true")
+	store, err = NewStoreByURL(url)
+	assert.Nil(t, err)
+	assert.Equal(t, 24*time.Hour, store.maxAge)
+	assert.Contains(t, store.dir, "snapshot")
+
+	url.SetParam(constant.RegistrySnapshotDirKey, "/tmp/dubbo")
+	url.SetParam(constant.RegistrySnapshotMaxAgeKey, "1h")
+	store, err = NewStoreByURL(url)
+	assert.Nil(t, err)
+	assert.Equal(t, "/tmp/dubbo", store.dir)
+	assert.Equal(t, time.Hour, store.maxAge)
+
+	url.SetParam(constant.RegistrySnapshotMaxAgeKey, "1 hour")
+	_, err = NewStoreByURL(url)
+	assert.NotNil(t, err)
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "snapshot"), time.Hour)
+	_, _, err := store.Load(testService)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	saved, err := store.Save(testService, newTestURLs())
+	assert.Nil(t, err)
+	assert.True(t, saved.Equal(newTestURLs()))
+	assert.False(t, saved.Equal(newTestURLs()[:1]))
+	// no temporary file is left
+	entries, _ := os.ReadDir(store.dir)
+	assert.Len(t, entries, 1)
+
+	urls, loaded, err := store.Load(testService)
+	assert.Nil(t, err)
+	assert.Equal(t, saved.Checksum, loaded.Checksum)
+	assert.Len(t, urls, 2)
+	assert.Equal(t, "20000", urls[0].Port)
+	assert.Equal(t, "100", urls[1].GetParam("weight", ""))
+
+	assert.Nil(t, store.Remove(testService))
+	assert.Nil(t, store.Remove(testService))
+	_, _, err = store.Load(testService)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestLoadIllegalSnapshot(t *testing.T) {
+	store := NewStore(t.TempDir(), time.Hour)
+	saved, err := store.Save(testService, newTestURLs())
+	assert.Nil(t, err)
+
+	// the tampered snapshot is rejected by the checksum
+	saved.URLs = saved.URLs[:1]
+	data, _ := json.Marshal(saved)
+	assert.Nil(t, os.WriteFile(store.path(testService), data, 0o644))
+	_, _, err = store.Load(testService)
+	assert.ErrorContains(t, err, "checksum")
+
+	assert.Nil(t, os.WriteFile(store.path(testService), []byte("{"), 0o644))
+	_, _, err = store.Load(testService)
+	assert.NotNil(t, err)
+
+	// the expired snapshot is rejected
+	saved.URLs = []string{newTestURLs()[0].String()}
+	saved.Timestamp = time.Now().Add(-2 * time.Hour)
+	saved.Checksum = saved.checksum()
+	data, _ = json.Marshal(saved)
+	assert.Nil(t, os.WriteFile(store.path(testService), data, 0o644))
+	_, _, err = store.Load(testService)
+	assert.ErrorContains(t, err, "expired")
+
+	// no limit of the age
+	urls, _, err := NewStore(store.dir, 0).Load(testService)
+	assert.Nil(t, err)
+	assert.Len(t, urls, 1)
+}
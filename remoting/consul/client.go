@@ -0,0 +1,74 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package consul
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+import (
+	"github.com/hashicorp/consul/api"
+
+	perrors "github.com/pkg/errors"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+)
+
+const (
+	defaultWaitTime = 30 * time.Second
+	requestTimeout  = 10 * time.Second
+)
+
+// NewClientByURL returns the client of the consul agent with the first address of the url, e.g. 127.0.0.1:8500
+// or https://127.0.0.1:8501, the token, the datacenter and the wait time of the blocking queries are set by the
+// params of the url.
+func NewClientByURL(u *common.URL) (*api.Client, error) {
+	waitTime, err := time.ParseDuration(u.GetParam(constant.ConsulWaitTimeKey, defaultWaitTime.String()))
+	if err != nil || waitTime <= 0 {
+		return nil, perrors.Errorf("illegal %s %s", constant.ConsulWaitTimeKey, u.GetParam(constant.ConsulWaitTimeKey, ""))
+	}
+	config := api.DefaultConfig()
+	if address := strings.Split(u.Location, constant.CommaSeparator)[0]; address != "" {
+		config.Address = address
+	}
+	config.Token = u.GetParam(constant.ConsulTokenKey, "")
+	config.Datacenter = u.GetParam(constant.ConsulDatacenterKey, "")
+	config.WaitTime = waitTime
+	httpClient, err := api.NewHttpClient(config.Transport, config.TLSConfig)
+	if err != nil {
+		return nil, err
+	}
+	// consul adds a random jitter up to 1/16 of the wait time to the blocking queries
+	httpClient.Timeout = requestTimeout + waitTime + waitTime/16
+	config.HttpClient = httpClient
+	client, err := api.NewClient(config)
+	if err != nil {
+		return nil, perrors.WithMessagef(err, "new consul client of %s", u.Location)
+	}
+	return client, nil
+}
+
+// IsNotFound reports whether the resource is not found by the agent
+func IsNotFound(err error) bool {
+	var e api.StatusError
+	return perrors.As(err, &e) && e.Code == http.StatusNotFound
+}
@@ -0,0 +1,114 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package consul_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/hashicorp/consul/api"
+
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/remoting/consul"
+	"dubbo.apache.org/dubbo-go/v3/remoting/consul/fake"
+)
+
+func newTestClient(t *testing.T) (*fake.Server, *api.Client) {
+	server := fake.NewServer()
+	t.Cleanup(server.Close)
+	url, _ := common.NewURL("consul://" + server.Address() + "?consul.wait-time=1s&consul.token=secret")
+	client, err := consul.NewClientByURL(url)
+	assert.Nil(t, err)
+	return server, client
+}
+
+func TestNewClientByURL(t *testing.T) {
+	url, _ := common.NewURL("consul://127.0.0.1:8500,127.0.0.2:8500?consul.wait-time=1s")
+	client, err := consul.NewClientByURL(url)
+	assert.Nil(t, err)
+	assert.NotNil(t, client)
+
+	for _, rawURL := range []string{
+		"consul://127.0.0.1:8500?consul.wait-time=illegal",
+		"consul://127.0.0.1:8500?consul.wait-time=-1s",
+	} {
+		url, _ = common.NewURL(rawURL)
+		_, err = consul.NewClientByURL(url)
+		assert.NotNil(t, err, rawURL)
+	}
+}
+
+func TestIsNotFound(t *testing.T) {
+	_, client := newTestClient(t)
+	agent := client.Agent()
+
+	assert.Nil(t, agent.ServiceRegister(&api.AgentServiceRegistration{ID: "provider-1", Name: "provider"}))
+	assert.Nil(t, agent.ServiceDeregister("provider-1"))
+	err := agent.ServiceDeregister("provider-1")
+	assert.True(t, consul.IsNotFound(err))
+	assert.True(t, consul.IsNotFound(agent.UpdateTTL("service:provider-1", "", api.HealthPassing)))
+	assert.False(t, consul.IsNotFound(nil))
+}
+
+func TestWatch(t *testing.T) {
+	_, client := newTestClient(t)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var (
+		value   atomic.Value
+		queries int32
+		done    = make(chan struct{})
+	)
+	go func() {
+		defer close(done)
+		consul.Watch(ctx, func(ctx context.Context, index uint64) (uint64, error) {
+			atomic.AddInt32(&queries, 1)
+			pair, meta, err := client.KV().Get("dubbo/key", consul.QueryOptions(ctx, index))
+			if err != nil {
+				return 0, err
+			}
+			if pair != nil {
+				value.Store(string(pair.Value))
+			}
+			return meta.LastIndex, nil
+		})
+	}()
+
+	_, err := client.KV().Put(&api.KVPair{Key: "dubbo/key", Value: []byte("1")}, nil)
+	assert.Nil(t, err)
+	assert.Eventually(t, func() bool { return value.Load() == "1" }, time.Second, 10*time.Millisecond)
+	_, err = client.KV().Put(&api.KVPair{Key: "dubbo/key", Value: []byte("2")}, nil)
+	assert.Nil(t, err)
+	assert.Eventually(t, func() bool { return value.Load() == "2" }, time.Second, 10*time.Millisecond)
+	// the queries block until the key is changed
+	assert.Less(t, atomic.LoadInt32(&queries), int32(10))
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(3 * time.Second):
+		t.Fatal("the watch is not stopped")
+	}
+}
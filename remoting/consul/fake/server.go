@@ -0,0 +1,287 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+// Package fake implements a small in-memory consul agent for the tests, which serves the endpoints used by the
+// consul api client, including the blocking queries. All the results share a single raft index.
+package fake
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+import (
+	"github.com/hashicorp/consul/api"
+)
+
+// Server is the in-memory consul agent.
+type Server struct {
+	*httptest.Server
+
+	mu    sync.Mutex
+	index uint64
+	// changed is closed and replaced whenever anything is changed
+	changed  chan struct{}
+	services map[string]*api.ServiceEntry
+	kv       map[string]*api.KVPair
+}
+
+// NewServer starts the agent, the address of the agent is returned by Address.
+func NewServer() *Server {
+	s := &Server{
+		index:    1,
+		changed:  make(chan struct{}),
+		services: make(map[string]*api.ServiceEntry),
+		kv:       make(map[string]*api.KVPair),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("PUT /v1/agent/service/register", s.register)
+	mux.HandleFunc("PUT /v1/agent/service/deregister/{id}", s.deregister)
+	mux.HandleFunc("PUT /v1/agent/check/update/{id}", s.updateCheck)
+	mux.HandleFunc("GET /v1/health/service/{name}", s.health)
+	mux.HandleFunc("GET /v1/catalog/services", s.catalog)
+	mux.HandleFunc("/v1/kv/{key...}", s.kvHandler)
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// Address returns the address of the agent.
+func (s *Server) Address() string {
+	return s.Listener.Addr().String()
+}
+
+// SetCheckStatus sets the status of the checks of the service instance.
+func (s *Server) SetCheckStatus(serviceID, status string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if entry, ok := s.services[serviceID]; ok {
+		for _, check := range entry.Checks {
+			check.Status = status
+		}
+		s.bump()
+	}
+}
+
+// bump increases the index and wakes up the blocking queries, it must be called with the lock held.
+func (s *Server) bump() uint64 {
+	s.index++
+	close(s.changed)
+	s.changed = make(chan struct{})
+	return s.index
+}
+
+// write responds the body with the current index after the blocking query is woken up, it must be called with
+// the lock held, and the body is built with the lock held too.
+func (s *Server) write(w http.ResponseWriter, r *http.Request, body func() (any, bool)) {
+	if index, _ := strconv.ParseUint(r.URL.Query().Get("index"), 10, 64); index > 0 {
+		wait, err := time.ParseDuration(r.URL.Query().Get("wait"))
+		if err != nil {
+			wait = 5 * time.Minute
+		}
+		timeout := time.After(wait)
+		for s.index <= index {
+			changed := s.changed
+			s.mu.Unlock()
+			select {
+			case <-changed:
+			case <-timeout:
+			case <-r.Context().Done():
+			}
+			s.mu.Lock()
+			if r.Context().Err() != nil || changed == s.changed {
+				break
+			}
+		}
+	}
+	w.Header().Set("X-Consul-Index", strconv.FormatUint(s.index, 10))
+	res, found := body()
+	if !found {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(res)
+}
+
+func (s *Server) register(w http.ResponseWriter, r *http.Request) {
+	reg := &api.AgentServiceRegistration{}
+	if err := json.NewDecoder(r.Body).Decode(reg); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if reg.ID == "" {
+		reg.ID = reg.Name
+	}
+	entry := &api.ServiceEntry{
+		Node: &api.Node{Node: "fake", Address: "127.0.0.1"},
+		Service: &api.AgentService{ID: reg.ID, Service: reg.Name, Tags: reg.Tags, Address: reg.Address,
+			Port: reg.Port, Meta: reg.Meta},
+	}
+	if check := reg.Check; check != nil {
+		if check.CheckID == "" {
+			check.CheckID = "service:" + reg.ID
+		}
+		if check.Status == "" {
+			check.Status = api.HealthCritical
+		}
+		entry.Checks = api.HealthChecks{{CheckID: check.CheckID, ServiceID: reg.ID, Status: check.Status}}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.services[reg.ID] = entry
+	s.bump()
+}
+
+func (s *Server) deregister(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.services[r.PathValue("id")]; !ok {
+		http.Error(w, "Unknown service ID", http.StatusNotFound)
+		return
+	}
+	delete(s.services, r.PathValue("id"))
+	s.bump()
+}
+
+func (s *Server) updateCheck(w http.ResponseWriter, r *http.Request) {
+	update := &api.HealthCheck{}
+	if err := json.NewDecoder(r.Body).Decode(update); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, entry := range s.services {
+		for _, check := range entry.Checks {
+			if check.CheckID == r.PathValue("id") {
+				if check.Status != update.Status {
+					check.Status = update.Status
+					s.bump()
+				}
+				return
+			}
+		}
+	}
+	http.Error(w, "Unknown check ID", http.StatusNotFound)
+}
+
+func (s *Server) health(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.write(w, r, func() (any, bool) {
+		entries := make([]*api.ServiceEntry, 0)
+		for _, entry := range s.services {
+			if entry.Service.Service != r.PathValue("name") ||
+				(query.Has("tag") && !contains(entry.Service.Tags, query.Get("tag"))) ||
+				(query.Has("passing") && entry.Checks.AggregatedStatus() != api.HealthPassing) {
+				continue
+			}
+			entries = append(entries, entry)
+		}
+		sort.Slice(entries, func(i, j int) bool { return entries[i].Service.ID < entries[j].Service.ID })
+		return entries, true
+	})
+}
+
+func (s *Server) catalog(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.write(w, r, func() (any, bool) {
+		services := make(map[string][]string)
+		for _, entry := range s.services {
+			services[entry.Service.Service] = append(services[entry.Service.Service], entry.Service.Tags...)
+		}
+		return services, true
+	})
+}
+
+func (s *Server) kvHandler(w http.ResponseWriter, r *http.Request) {
+	key, query := r.PathValue("key"), r.URL.Query()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch r.Method {
+	case http.MethodGet:
+		s.write(w, r, func() (any, bool) {
+			if !query.Has("recurse") && !query.Has("keys") {
+				pair, ok := s.kv[key]
+				return []*api.KVPair{pair}, ok
+			}
+			var keys []string
+			for k := range s.kv {
+				if !strings.HasPrefix(k, key) {
+					continue
+				}
+				if sep := query.Get("separator"); sep != "" {
+					if i := strings.Index(k[len(key):], sep); i >= 0 {
+						k = k[:len(key)+i+len(sep)]
+					}
+				}
+				if !contains(keys, k) {
+					keys = append(keys, k)
+				}
+			}
+			sort.Strings(keys)
+			if query.Has("keys") {
+				return keys, len(keys) > 0
+			}
+			pairs := make([]*api.KVPair, 0, len(keys))
+			for _, k := range keys {
+				pairs = append(pairs, s.kv[k])
+			}
+			return pairs, len(pairs) > 0
+		})
+	case http.MethodPut:
+		old, ok := s.kv[key]
+		if query.Has("cas") {
+			index, _ := strconv.ParseUint(query.Get("cas"), 10, 64)
+			if (index == 0 && ok) || (index != 0 && (!ok || old.ModifyIndex != index)) {
+				_, _ = w.Write([]byte("false"))
+				return
+			}
+		}
+		value, _ := io.ReadAll(r.Body)
+		pair := &api.KVPair{Key: key, Value: value, ModifyIndex: s.bump()}
+		pair.CreateIndex = pair.ModifyIndex
+		if ok {
+			pair.CreateIndex = old.CreateIndex
+		}
+		s.kv[key] = pair
+		_, _ = w.Write([]byte("true"))
+	case http.MethodDelete:
+		if _, ok := s.kv[key]; ok {
+			delete(s.kv, key)
+			s.bump()
+		}
+		_, _ = w.Write([]byte("true"))
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package consul
+
+import (
+	"context"
+	"time"
+)
+
+import (
+	"github.com/dubbogo/gost/log/logger"
+
+	"github.com/hashicorp/consul/api"
+)
+
+const (
+	minRetryInterval = time.Second
+	maxRetryInterval = 30 * time.Second
+)
+
+// QueryOptions returns the options of the blocking query with the index, the query returns immediately if the
+// index is 0.
+func QueryOptions(ctx context.Context, index uint64) *api.QueryOptions {
+	return (&api.QueryOptions{WaitIndex: index}).WithContext(ctx)
+}
+
+// BlockingQuery sends the blocking query with the index, and returns the index of the result. The result is
+// unchanged if the returned index equals to the given one.
+type BlockingQuery func(ctx context.Context, index uint64) (uint64, error)
+
+// Watch runs the blocking query repeatedly with the index of the last result until the context is done. It
+// retries with backoff if the query fails, and resets the index if it goes backwards, e.g. the agent is
+// restarted or the raft snapshot is restored.
+func Watch(ctx context.Context, query BlockingQuery) {
+	var index uint64
+	interval := minRetryInterval
+	for ctx.Err() == nil {
+		lastIndex, err := query(ctx, index)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			logger.Warnf("[consul] Failed to watch, retry after %s, err: %v", interval, err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(interval):
+			}
+			interval *= 2
+			if interval > maxRetryInterval {
+				interval = maxRetryInterval
+			}
+			continue
+		}
+		interval = minRetryInterval
+		switch {
+		case lastIndex < index:
+			index = 0
+		case lastIndex == 0:
+			// the index must be greater than 0, otherwise the query doesn't block
+			index = 1
+		default:
+			index = lastIndex
+		}
+	}
+}
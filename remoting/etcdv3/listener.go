@@ -18,6 +18,7 @@
 package etcdv3
 
 import (
+	"context"
 	"sync"
 	"time"
 )
@@ -173,6 +174,50 @@ func (l *EventListener) ListenServiceNodeEventWithPrefix(prefix string, listener
 	}
 }
 
+// ListenConfigurationEvent listens on the spec key until the ctx is done or the client is stopped. Unlike
+// ListenServiceNodeEvent, the listener is notified of the deletion of the key, and the key keeps being listened
+// after it is deleted.
+func (l *EventListener) ListenConfigurationEvent(ctx context.Context, key string, listener remoting.DataListener) {
+	l.wg.Add(1)
+	go func() {
+		defer l.wg.Done()
+		rawClient := l.client.GetRawClient()
+		if rawClient == nil {
+			logger.Warnf("etcd client broken, stop listening key{%s}", key)
+			return
+		}
+		wc := rawClient.Watch(ctx, key)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			// client stopped
+			case <-l.client.Done():
+				logger.Warnf("etcd client stopped")
+				return
+
+			// handle etcd events
+			case e, ok := <-wc:
+				if !ok {
+					logger.Warnf("etcd watch-chan of key{%s} closed", key)
+					return
+				}
+
+				if e.Err() != nil {
+					logger.Errorf("etcd watch ERR {err: %s}", e.Err())
+					continue
+				}
+				for _, event := range e.Events {
+					if l.handleEvents(event, listener) {
+						listener.DataChange(remoting.Event{Path: key, Action: remoting.EventTypeDel})
+					}
+				}
+			}
+		}
+	}()
+}
+
 func timeSecondDuration(sec int) time.Duration {
 	return time.Duration(sec) * time.Second
 }
@@ -49,13 +49,26 @@ import (
 var (
 	errSessionNotExist   = perrors.New("session not exist")
 	errClientClosed      = perrors.New("client closed")
-	errClientReadTimeout = perrors.New("maybe the client read timeout or fail to decode tcp stream in Writer.Write")
+	errClientReadTimeout = &timeoutError{msg: "maybe the client read timeout or fail to decode tcp stream in Writer.Write"}
 
 	clientConf = GetDefaultClientConfig()
 
 	clientGrPool gxsync.GenericTaskPool
 )
 
+// timeoutError is the error of the timed out requests, which reports itself by the Timeout method like net.Error
+type timeoutError struct {
+	msg string
+}
+
+func (e *timeoutError) Error() string {
+	return e.msg
+}
+
+func (e *timeoutError) Timeout() bool {
+	return true
+}
+
 // it is init client for single protocol.
 func initClient(url *common.URL) {
 	if url.Protocol == "" {
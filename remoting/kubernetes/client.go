@@ -0,0 +1,114 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+)
+
+import (
+	perrors "github.com/pkg/errors"
+
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	k8s "k8s.io/client-go/kubernetes"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+)
+
+// Client is the clientset of the API server bound to the namespace of the pods and config maps. It is backed by
+// the fake clientset of client-go in tests.
+type Client struct {
+	k8s.Interface
+	namespace string
+}
+
+// NewClient returns the client of the clientset with the namespace.
+func NewClient(clientset k8s.Interface, namespace string) *Client {
+	return &Client{Interface: clientset, namespace: namespace}
+}
+
+// NewClientByURL returns the client with the kubeconfig set by the "kubernetes.kubeconfig" param, or the one
+// of $KUBECONFIG and ~/.kube/config, or the in-cluster config of the service account if none of them exists.
+// The address of the url overrides the host of the API server, and the namespace is set by the
+// "kubernetes.namespace" param, which is the one of the kubeconfig or the service account by default.
+func NewClientByURL(u *common.URL) (*Client, error) {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = u.GetParam(constant.KubernetesConfigKey, "")
+	loader := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, &clientcmd.ConfigOverrides{})
+	config, err := loader.ClientConfig()
+	if err != nil {
+		return nil, perrors.WithMessage(err, "load the config of kubernetes")
+	}
+	if u.Location != "" {
+		config.Host = u.Location
+		if !strings.Contains(config.Host, "://") {
+			config.Host = "https://" + config.Host
+		}
+	}
+	namespace := u.GetParam(constant.KubernetesNamespaceKey, "")
+	if namespace == "" {
+		if namespace, _, err = loader.Namespace(); err != nil {
+			return nil, perrors.WithMessage(err, "load the namespace of kubernetes")
+		}
+	}
+	clientset, err := k8s.NewForConfig(config)
+	if err != nil {
+		return nil, perrors.WithMessage(err, "new kubernetes clientset")
+	}
+	return NewClient(clientset, namespace), nil
+}
+
+// Namespace returns the namespace of the resources
+func (c *Client) Namespace() string {
+	return c.namespace
+}
+
+func (c *Client) Pods() typedcorev1.PodInterface {
+	return c.CoreV1().Pods(c.namespace)
+}
+
+func (c *Client) ConfigMaps() typedcorev1.ConfigMapInterface {
+	return c.CoreV1().ConfigMaps(c.namespace)
+}
+
+// PatchPodMetadata merges the labels and annotations into the pod, the ones with nil values are removed
+func (c *Client) PatchPodMetadata(ctx context.Context, name string, labels, annotations map[string]*string) (*corev1.Pod, error) {
+	// a nil map is omitted, otherwise all the labels or annotations would be removed
+	metadata := map[string]any{}
+	if labels != nil {
+		metadata["labels"] = labels
+	}
+	if annotations != nil {
+		metadata["annotations"] = annotations
+	}
+	patch, err := json.Marshal(map[string]any{"metadata": metadata})
+	if err != nil {
+		return nil, err
+	}
+	return c.Pods().Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{})
+}
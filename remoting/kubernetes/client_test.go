@@ -0,0 +1,184 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package kubernetes
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/stretchr/testify/assert"
+
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	k8s "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+)
+
+const testKubeconfig = `
+apiVersion: v1
+kind: Config
+clusters:
+  - name: test
+    cluster:
+      server: https://127.0.0.1:6443
+users:
+  - name: test
+    user:
+      token: token
+contexts:
+  - name: test
+    context:
+      cluster: test
+      user: test
+      namespace: dubbo
+current-context: test
+`
+
+func newTestPod(name string, labels map[string]string) *corev1.Pod {
+	return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "dubbo", Labels: labels}}
+}
+
+// waitForWatch waits until the resources are watched, since the fake clientset doesn't replay the events
+// happened before the watch.
+func waitForWatch(t *testing.T, clientset *fake.Clientset, resource string) {
+	assert.Eventually(t, func() bool {
+		for _, action := range clientset.Actions() {
+			if action.GetVerb() == "watch" && action.GetResource().Resource == resource {
+				return true
+			}
+		}
+		return false
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestNewClientByURL(t *testing.T) {
+	dir := t.TempDir()
+	// there is neither the kubeconfig nor the in-cluster config
+	t.Setenv("HOME", dir)
+	t.Setenv("KUBECONFIG", filepath.Join(dir, "missing"))
+	t.Setenv("KUBERNETES_SERVICE_HOST", "")
+	for _, rawURL := range []string{"kubernetes://", "kubernetes://10.0.0.1:6443"} {
+		url, _ := common.NewURL(rawURL)
+		_, err := NewClientByURL(url)
+		assert.NotNil(t, err, rawURL)
+	}
+	url, _ := common.NewURL("kubernetes://10.0.0.1:6443?kubernetes.kubeconfig=" + filepath.Join(dir, "missing"))
+	_, err := NewClientByURL(url)
+	assert.NotNil(t, err)
+
+	kubeconfig := filepath.Join(dir, "config")
+	assert.Nil(t, os.WriteFile(kubeconfig, []byte(testKubeconfig), 0o600))
+	t.Setenv("KUBECONFIG", kubeconfig)
+	url, _ = common.NewURL("kubernetes://")
+	client, err := NewClientByURL(url)
+	assert.Nil(t, err)
+	assert.Equal(t, "dubbo", client.Namespace())
+	assert.Equal(t, "127.0.0.1:6443", client.CoreV1().RESTClient().Get().URL().Host)
+
+	// the address and the namespace of the url override the kubeconfig
+	url, _ = common.NewURL("kubernetes://10.0.0.1:6443?kubernetes.namespace=demo&kubernetes.kubeconfig=" + kubeconfig)
+	client, err = NewClientByURL(url)
+	assert.Nil(t, err)
+	assert.Equal(t, "demo", client.Namespace())
+	assert.Equal(t, "10.0.0.1:6443", client.CoreV1().RESTClient().Get().URL().Host)
+}
+
+func TestPatchPodMetadata(t *testing.T) {
+	client := NewClient(fake.NewSimpleClientset(newTestPod("provider-0", map[string]string{"app": "demo"})), "dubbo")
+	value := "demo"
+	pod, err := client.PatchPodMetadata(context.Background(), "provider-0",
+		map[string]*string{"app": nil, "dubbo": &value}, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"dubbo": "demo"}, pod.Labels)
+
+	_, err = client.PatchPodMetadata(context.Background(), "provider-1", nil, map[string]*string{"a": &value})
+	assert.NotNil(t, err)
+}
+
+func TestInformer(t *testing.T) {
+	clientset := fake.NewSimpleClientset(newTestPod("provider-0", map[string]string{"dubbo": "demo"}))
+	client := NewClient(clientset, "dubbo")
+	changes := make(chan []string, 8)
+	informer := NewPodInformer(client, "dubbo=demo", func(objects []metav1.Object) {
+		names := make([]string, 0, len(objects))
+		for _, obj := range objects {
+			names = append(names, obj.GetName())
+		}
+		changes <- names
+	})
+	informer.Run()
+	defer informer.Stop()
+	assert.True(t, informer.WaitForSync(time.Second))
+	assert.Equal(t, []string{"provider-0"}, <-changes)
+	waitForWatch(t, clientset, "pods")
+
+	ctx := context.Background()
+	_, err := client.Pods().Create(ctx, newTestPod("provider-1", map[string]string{"dubbo": "demo"}), metav1.CreateOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"provider-0", "provider-1"}, <-changes)
+	assert.Nil(t, client.Pods().Delete(ctx, "provider-0", metav1.DeleteOptions{}))
+	assert.Equal(t, []string{"provider-1"}, <-changes)
+	assert.Len(t, informer.List(), 1)
+}
+
+func TestInformerWatchTimeout(t *testing.T) {
+	var (
+		lock    sync.Mutex
+		timeout string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("watch") != "true" {
+			_, _ = w.Write([]byte(`{"kind":"PodList","apiVersion":"v1","metadata":{"resourceVersion":"10"},"items":[]}`))
+			return
+		}
+		lock.Lock()
+		timeout = r.URL.Query().Get("timeoutSeconds")
+		lock.Unlock()
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	clientset, err := k8s.NewForConfig(&rest.Config{Host: server.URL})
+	assert.Nil(t, err)
+	informer := NewPodInformer(NewClient(clientset, "dubbo"), "dubbo=demo", func([]metav1.Object) {})
+	informer.Run()
+	defer informer.Stop()
+	assert.True(t, informer.WaitForSync(time.Second))
+	// the watch is ended by the API server before the timeout, and then the informer watches again
+	assert.Eventually(t, func() bool {
+		lock.Lock()
+		defer lock.Unlock()
+		return timeout != ""
+	}, 3*time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,103 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package kubernetes
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Informer caches the resources by the shared informer of client-go, which relists and watches the resources
+// again when the watch is ended, and calls the handler with all the cached resources whenever they are changed.
+type Informer struct {
+	informer cache.SharedIndexInformer
+	stop     chan struct{}
+	once     sync.Once
+}
+
+func newInformer(informer cache.SharedIndexInformer, handler func([]metav1.Object)) *Informer {
+	i := &Informer{informer: informer, stop: make(chan struct{})}
+	notify := func() {
+		handler(i.List())
+	}
+	_, _ = informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(any) { notify() },
+		UpdateFunc: func(any, any) { notify() },
+		DeleteFunc: func(any) { notify() },
+	})
+	return i
+}
+
+// NewPodInformer returns the informer of the pods matching the label selector.
+func NewPodInformer(client *Client, labelSelector string, handler func([]metav1.Object)) *Informer {
+	return newInformer(coreinformers.NewFilteredPodInformer(client, client.namespace, 0, cache.Indexers{},
+		func(options *metav1.ListOptions) {
+			options.LabelSelector = labelSelector
+		}), handler)
+}
+
+// NewConfigMapInformer returns the informer of the config map with the name.
+func NewConfigMapInformer(client *Client, name string, handler func([]metav1.Object)) *Informer {
+	return newInformer(coreinformers.NewFilteredConfigMapInformer(client, client.namespace, 0, cache.Indexers{},
+		func(options *metav1.ListOptions) {
+			options.FieldSelector = fields.OneTermEqualSelector("metadata.name", name).String()
+		}), handler)
+}
+
+// Run starts to list and watch the resources in the background.
+func (i *Informer) Run() {
+	go i.informer.Run(i.stop)
+}
+
+// WaitForSync waits until the resources are listed for the first time, or the timeout expires.
+func (i *Informer) WaitForSync(timeout time.Duration) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return cache.WaitForCacheSync(ctx.Done(), i.informer.HasSynced)
+}
+
+// Stop stops the informer.
+func (i *Informer) Stop() {
+	i.once.Do(func() {
+		close(i.stop)
+	})
+}
+
+// List returns the cached resources sorted by name.
+func (i *Informer) List() []metav1.Object {
+	items := i.informer.GetStore().List()
+	objects := make([]metav1.Object, 0, len(items))
+	for _, item := range items {
+		if obj, ok := item.(metav1.Object); ok {
+			objects = append(objects, obj)
+		}
+	}
+	sort.Slice(objects, func(a, b int) bool {
+		return objects[a].GetName() < objects[b].GetName()
+	})
+	return objects
+}
@@ -0,0 +1,118 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dubbo
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+)
+
+const jsonSchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// JSONSchema generates the JSON Schema of the config file from InstanceOptions, which can be used by the IDEs to
+// complete and check the config file, e.g. by the yaml.schemas setting of the YAML extension of VS Code.
+func JSONSchema() ([]byte, error) {
+	insOpts := defaultInstanceOptions()
+	schema := map[string]any{
+		"$schema":    jsonSchemaDraft,
+		"title":      "Dubbo-Go Framework schema",
+		"type":       "object",
+		"properties": map[string]any{insOpts.Prefix(): typeSchema(reflect.TypeOf(insOpts), map[reflect.Type]bool{})},
+	}
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+// typeSchema generates the schema of the type, the visiting types are used to break the recursive types
+func typeSchema(typ reflect.Type, visiting map[reflect.Type]bool) map[string]any {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	switch typ.Kind() {
+	case reflect.Struct:
+		if visiting[typ] {
+			return map[string]any{"type": "object"}
+		}
+		visiting[typ] = true
+		defer delete(visiting, typ)
+
+		properties := make(map[string]any)
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			name := yamlName(field)
+			if name == "" {
+				continue
+			}
+			property := typeSchema(field.Type, visiting)
+			if def, ok := field.Tag.Lookup("default"); ok {
+				property["default"] = defaultValue(field.Type, def)
+			}
+			properties[name] = property
+		}
+		return map[string]any{
+			"type":                 "object",
+			"properties":           properties,
+			"additionalProperties": false,
+		}
+	case reflect.Map:
+		return map[string]any{
+			"type":                 "object",
+			"additionalProperties": typeSchema(typ.Elem(), visiting),
+		}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{
+			"type":  "array",
+			"items": typeSchema(typ.Elem(), visiting),
+		}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	default:
+		// any value is allowed, e.g. the params of the protocols
+		return map[string]any{}
+	}
+}
+
+// defaultValue converts the default tag to the JSON value of the type
+func defaultValue(typ reflect.Type, def string) any {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	switch typ.Kind() {
+	case reflect.Bool:
+		if v, err := strconv.ParseBool(def); err == nil {
+			return v
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if v, err := strconv.ParseInt(def, 10, 64); err == nil {
+			return v
+		}
+	case reflect.Float32, reflect.Float64:
+		if v, err := strconv.ParseFloat(def, 64); err == nil {
+			return v
+		}
+	}
+	return def
+}
@@ -796,6 +796,18 @@ func WithWarmup(warmupDuration time.Duration) ServiceOption {
 	}
 }
 
+// WithWarmupCurve sets the curve along which the weight ramps up during warm-up, and the minimum weight
+// in percent of the full weight. The curve is constant.WarmupCurveLinear or constant.WarmupCurveAggressive.
+func WithWarmupCurve(curve string, minWeightPercent int) ServiceOption {
+	return func(opts *ServiceOptions) {
+		if opts.Service.Params == nil {
+			opts.Service.Params = make(map[string]string)
+		}
+		opts.Service.Params[constant.WarmupCurveKey] = curve
+		opts.Service.Params[constant.WarmupMinWeightPercentKey] = strconv.Itoa(minWeightPercent)
+	}
+}
+
 func WithRetries(retries int) ServiceOption {
 	return func(opts *ServiceOptions) {
 		opts.Service.Retries = strconv.Itoa(retries)
@@ -0,0 +1,400 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dubbo
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+import (
+	"github.com/knadh/koanf"
+
+	"github.com/pkg/errors"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/common/extension"
+	"dubbo.apache.org/dubbo-go/v3/global"
+)
+
+// ValidationError is an invalid config item, the path is the YAML path of the item, e.g.
+// dubbo.provider.services.GreeterProvider.registry-ids
+type ValidationError struct {
+	Path    string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Path + ": " + e.Message
+}
+
+// ValidationErrors is all the invalid config items found by a validation pass
+type ValidationErrors []*ValidationError
+
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// DryRun loads the config like Load without initializing and starting anything, and validates it. The
+// ValidationErrors is returned if the config is loaded but invalid. The extensions referenced by the config,
+// e.g. the filters and the registries, must be imported to be validated, import dubbo.apache.org/dubbo-go/v3/imports
+// for the built-in ones.
+func DryRun(opts ...LoaderConfOption) (*InstanceOptions, error) {
+	conf := NewLoaderConf(opts...)
+	if conf.opts != nil {
+		return conf.opts, conf.opts.Validate()
+	}
+
+	koan := GetConfigResolver(conf)
+	koan = conf.MergeConfig(koan)
+	insOpts := defaultInstanceOptions()
+	if err := koan.UnmarshalWithConf(insOpts.Prefix(), insOpts, koanf.UnmarshalConf{Tag: "yaml"}); err != nil {
+		return nil, errors.WithMessage(err, "unmarshal the config")
+	}
+
+	errs := unknownKeys(koan, insOpts.Prefix())
+	if err := insOpts.Validate(); err != nil {
+		errs = append(errs, err.(ValidationErrors)...)
+	}
+	if len(errs) > 0 {
+		return insOpts, errs
+	}
+	return insOpts, nil
+}
+
+// Validate checks the references between the registries, the protocols and the services, the names of the
+// extensions and the format of the config items, the ValidationErrors is returned if any config item is invalid.
+func (rc *InstanceOptions) Validate() error {
+	v := &validator{opts: rc}
+	v.validate()
+	if len(v.errs) > 0 {
+		return v.errs
+	}
+	return nil
+}
+
+type validator struct {
+	opts *InstanceOptions
+	errs ValidationErrors
+}
+
+func (v *validator) addf(path, format string, args ...any) {
+	v.errs = append(v.errs, &ValidationError{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) validate() {
+	prefix := v.opts.Prefix()
+	if app := v.opts.Application; app != nil {
+		if app.MetadataType != "" && app.MetadataType != constant.DefaultMetadataStorageType &&
+			app.MetadataType != constant.RemoteMetadataStorageType {
+			v.addf(prefix+".application.metadata-type", "unknown metadata type %s", app.MetadataType)
+		}
+	}
+	for _, id := range sortedKeys(v.opts.Registries) {
+		v.validateRegistry(prefix+".registries."+id, v.opts.Registries[id])
+	}
+	for _, id := range sortedKeys(v.opts.Protocols) {
+		if p := v.opts.Protocols[id]; p != nil && p.Name != "" {
+			v.checkExtension(prefix+".protocols."+id+".name", "protocol", p.Name, extension.HasProtocol)
+		}
+	}
+	if cc := v.opts.ConfigCenter; cc != nil && cc.Address != "" {
+		path := prefix + ".config-center"
+		v.checkExtension(path+".protocol", "config center", addressProtocol(cc.Protocol, cc.Address),
+			extension.HasConfigCenterFactory)
+		v.checkDuration(path+".timeout", cc.Timeout)
+	}
+	if mr := v.opts.MetadataReport; mr != nil && mr.Address != "" {
+		path := prefix + ".metadata-report"
+		v.checkExtension(path+".protocol", "metadata report", addressProtocol(mr.Protocol, mr.Address),
+			extension.HasMetadataReportFactory)
+		v.checkDuration(path+".timeout", mr.Timeout)
+	}
+	if v.opts.Provider != nil {
+		v.validateProvider(prefix+".provider", v.opts.Provider)
+	}
+	if v.opts.Consumer != nil {
+		v.validateConsumer(prefix+".consumer", v.opts.Consumer)
+	}
+	if s := v.opts.Shutdown; s != nil {
+		path := prefix + ".shutdown"
+		v.checkDuration(path+".timeout", s.Timeout)
+		v.checkDuration(path+".step-timeout", s.StepTimeout)
+		v.checkDuration(path+".consumer-update-wait-time", s.ConsumerUpdateWaitTime)
+		v.checkDuration(path+".offline-request-window-timeout", s.OfflineRequestWindowTimeout)
+	}
+}
+
+func (v *validator) validateRegistry(path string, c *global.RegistryConfig) {
+	if c == nil {
+		return
+	}
+	v.checkDuration(path+".timeout", c.Timeout)
+	v.checkDuration(path+".ttl", c.TTL)
+	if c.Address == "" || c.Address == constant.NotAvailable {
+		// the registry is disabled
+		return
+	}
+	protocol := addressProtocol(c.Protocol, c.Address)
+	switch c.RegistryType {
+	case constant.RegistryTypeInterface:
+		v.checkExtension(path+".protocol", "registry", protocol, extension.HasRegistry)
+	case constant.RegistryTypeAll:
+		v.checkExtension(path+".protocol", "registry", protocol, extension.HasRegistry)
+		v.checkExtension(path+".protocol", "service discovery", protocol, extension.HasServiceDiscovery)
+	case "", constant.RegistryTypeService:
+		v.checkExtension(path+".protocol", "service discovery", protocol, extension.HasServiceDiscovery)
+	default:
+		v.addf(path+".registry-type", "unknown registry type %s", c.RegistryType)
+	}
+}
+
+func (v *validator) validateProvider(path string, c *global.ProviderConfig) {
+	v.checkFilters(path+".filter", c.Filter)
+	v.checkRegistryIDs(path+".registry-ids", c.RegistryIDs)
+	v.checkProtocolIDs(path+".protocol-ids", c.ProtocolIDs)
+	if c.ProxyFactory != "" {
+		v.checkExtension(path+".proxy", "proxy factory", c.ProxyFactory, extension.HasProxyFactory)
+	}
+	for _, name := range sortedKeys(c.Services) {
+		s := c.Services[name]
+		if s == nil {
+			continue
+		}
+		servicePath := path + ".services." + name
+		v.checkFilters(servicePath+".filter", s.Filter)
+		v.checkRegistryIDs(servicePath+".registry-ids", s.RegistryIDs)
+		v.checkProtocolIDs(servicePath+".protocol-ids", s.ProtocolIDs)
+		v.checkCluster(servicePath+".cluster", s.Cluster)
+		v.checkLoadbalance(servicePath+".loadbalance", s.Loadbalance)
+		v.checkInt(servicePath+".retries", s.Retries)
+		v.validateMethods(servicePath+".methods", s.Methods)
+	}
+}
+
+func (v *validator) validateConsumer(path string, c *global.ConsumerConfig) {
+	v.checkFilters(path+".filter", c.Filter)
+	v.checkRegistryIDs(path+".registry-ids", c.RegistryIDs)
+	if c.Protocol != "" {
+		v.checkExtension(path+".protocol", "protocol", c.Protocol, extension.HasProtocol)
+	}
+	if c.ProxyFactory != "" {
+		v.checkExtension(path+".proxy", "proxy factory", c.ProxyFactory, extension.HasProxyFactory)
+	}
+	v.checkDuration(path+".request-timeout", c.RequestTimeout)
+	v.checkDuration(path+".max-wait-time-for-service-discovery", c.MaxWaitTimeForServiceDiscovery)
+	for _, name := range sortedKeys(c.References) {
+		r := c.References[name]
+		if r == nil {
+			continue
+		}
+		refPath := path + ".references." + name
+		v.checkFilters(refPath+".filter", r.Filter)
+		v.checkRegistryIDs(refPath+".registry-ids", r.RegistryIDs)
+		if r.Protocol != "" {
+			v.checkExtension(refPath+".protocol", "protocol", r.Protocol, extension.HasProtocol)
+		}
+		v.checkCluster(refPath+".cluster", r.Cluster)
+		v.checkLoadbalance(refPath+".loadbalance", r.Loadbalance)
+		v.checkInt(refPath+".retries", r.Retries)
+		v.checkDuration(refPath+".timeout", r.RequestTimeout)
+		v.checkDuration(refPath+".retry-backoff", r.RetryBackoff)
+		v.checkDuration(refPath+".retry-max-backoff", r.RetryMaxBackoff)
+		v.checkDuration(refPath+".keep-alive-interval", r.KeepAliveInterval)
+		v.checkDuration(refPath+".keep-alive-timeout", r.KeepAliveTimeout)
+		v.validateMethods(refPath+".methods", r.MethodsConfig)
+	}
+}
+
+func (v *validator) validateMethods(path string, methods []*global.MethodConfig) {
+	for i, m := range methods {
+		if m == nil {
+			continue
+		}
+		methodPath := path + "[" + strconv.Itoa(i) + "]"
+		if m.Name == "" {
+			v.addf(methodPath+".name", "the method name is empty")
+		}
+		v.checkLoadbalance(methodPath+".loadbalance", m.LoadBalance)
+		v.checkInt(methodPath+".retries", m.Retries)
+		v.checkDuration(methodPath+".timeout", m.RequestTimeout)
+		v.checkDuration(methodPath+".retry-backoff", m.RetryBackoff)
+		v.checkDuration(methodPath+".retry-max-backoff", m.RetryMaxBackoff)
+	}
+}
+
+// checkRegistryIDs checks the registry ids are configured, empty ids refer to all the registries
+func (v *validator) checkRegistryIDs(path string, ids []string) {
+	for i, id := range ids {
+		if id == "" {
+			continue
+		}
+		if _, ok := v.opts.Registries[id]; !ok {
+			v.addf(path+"["+strconv.Itoa(i)+"]", "the registry %s is not configured in %s.registries", id, v.opts.Prefix())
+		}
+	}
+}
+
+// checkProtocolIDs checks the protocol ids are configured, empty ids refer to all the protocols
+func (v *validator) checkProtocolIDs(path string, ids []string) {
+	for i, id := range ids {
+		if id == "" {
+			continue
+		}
+		if _, ok := v.opts.Protocols[id]; !ok {
+			v.addf(path+"["+strconv.Itoa(i)+"]", "the protocol %s is not configured in %s.protocols", id, v.opts.Prefix())
+		}
+	}
+}
+
+// checkFilters checks the filters separated by commas, a filter excluded by the - prefix must exist as well
+func (v *validator) checkFilters(path, filters string) {
+	for _, name := range strings.Split(filters, ",") {
+		name = strings.TrimPrefix(strings.TrimSpace(name), "-")
+		if name == "" || name == constant.DefaultKey {
+			continue
+		}
+		v.checkExtension(path, "filter", name, extension.HasFilter)
+	}
+}
+
+func (v *validator) checkCluster(path, name string) {
+	if name != "" {
+		v.checkExtension(path, "cluster", name, extension.HasCluster)
+	}
+}
+
+func (v *validator) checkLoadbalance(path, name string) {
+	if name != "" {
+		v.checkExtension(path, "loadbalance", name, extension.HasLoadbalance)
+	}
+}
+
+func (v *validator) checkExtension(path, kind, name string, exists func(string) bool) {
+	if name == "" {
+		v.addf(path, "the %s is not specified", kind)
+		return
+	}
+	if !exists(name) {
+		v.addf(path, "the %s %s does not exist, make sure you have imported the package", kind, name)
+	}
+}
+
+func (v *validator) checkDuration(path, value string) {
+	if value == "" {
+		return
+	}
+	if _, err := time.ParseDuration(value); err != nil {
+		v.addf(path, "%s is not a duration like 3s", value)
+	}
+}
+
+func (v *validator) checkInt(path, value string) {
+	if value == "" {
+		return
+	}
+	if _, err := strconv.Atoi(value); err != nil {
+		v.addf(path, "%s is not an integer", value)
+	}
+}
+
+// addressProtocol returns the protocol in the address like zookeeper://127.0.0.1:2181 if there is one
+func addressProtocol(protocol, address string) string {
+	if i := strings.Index(address, "://"); i > 0 {
+		return address[:i]
+	}
+	return protocol
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// unknownKeys finds the config keys under the prefix that don't match any field of InstanceOptions, they are
+// ignored silently when the config is unmarshalled, which usually means a typo.
+func unknownKeys(k *koanf.Koanf, prefix string) ValidationErrors {
+	var errs ValidationErrors
+	// the raw config keeps the keys containing the delimiter, e.g. tps.limit.interval
+	if raw, ok := k.Raw()[prefix].(map[string]any); ok {
+		walkUnknownKeys(reflect.TypeOf(InstanceOptions{}), raw, prefix, k.Delim(), &errs)
+	}
+	return errs
+}
+
+func walkUnknownKeys(typ reflect.Type, value any, path, delim string, errs *ValidationErrors) {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if items, ok := value.([]any); ok && (typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array) {
+		for i, item := range items {
+			walkUnknownKeys(typ.Elem(), item, path+"["+strconv.Itoa(i)+"]", delim, errs)
+		}
+		return
+	}
+	m, ok := value.(map[string]any)
+	if !ok {
+		return
+	}
+	switch typ.Kind() {
+	case reflect.Struct:
+		fields := make(map[string]reflect.Type, typ.NumField())
+		for i := 0; i < typ.NumField(); i++ {
+			if name := yamlName(typ.Field(i)); name != "" {
+				fields[strings.ToLower(name)] = typ.Field(i).Type
+			}
+		}
+		for _, key := range sortedKeys(m) {
+			fieldType, ok := fields[strings.ToLower(key)]
+			if !ok {
+				*errs = append(*errs, &ValidationError{Path: path + delim + key, Message: "unknown config"})
+				continue
+			}
+			walkUnknownKeys(fieldType, m[key], path+delim+key, delim, errs)
+		}
+	case reflect.Map:
+		for _, key := range sortedKeys(m) {
+			walkUnknownKeys(typ.Elem(), m[key], path+delim+key, delim, errs)
+		}
+	}
+}
+
+func yamlName(field reflect.StructField) string {
+	if !field.IsExported() {
+		return ""
+	}
+	name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+	if name == "-" {
+		return ""
+	}
+	return name
+}
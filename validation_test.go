@@ -0,0 +1,173 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dubbo
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+import (
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/cluster/cluster"
+	"dubbo.apache.org/dubbo-go/v3/cluster/loadbalance"
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/extension"
+	"dubbo.apache.org/dubbo-go/v3/filter"
+	"dubbo.apache.org/dubbo-go/v3/protocol/base"
+	"dubbo.apache.org/dubbo-go/v3/registry"
+)
+
+func init() {
+	extension.SetFilter("validation-filter", func() filter.Filter { return nil })
+	extension.SetCluster("validation-cluster", func() cluster.Cluster { return nil })
+	extension.SetLoadbalance("validation-loadbalance", func() loadbalance.LoadBalance { return nil })
+	extension.SetProtocol("validation-protocol", func() base.Protocol { return nil })
+	extension.SetServiceDiscovery("validation-registry", func(*common.URL) (registry.ServiceDiscovery, error) {
+		return nil, nil
+	})
+}
+
+const validConfig = `
+dubbo:
+  application:
+    name: validation
+  registries:
+    demo:
+      address: validation-registry://127.0.0.1:2181
+      timeout: 3s
+  protocols:
+    triple:
+      name: validation-protocol
+      port: 20000
+  provider:
+    filter: validation-filter,-default
+    services:
+      org.apache.dubbo.Greeter:
+        registry-ids: demo
+        protocol-ids: triple
+        cluster: validation-cluster
+        loadbalance: validation-loadbalance
+        params:
+          custom.key: value
+  consumer:
+    references:
+      Greeter:
+        protocol: validation-protocol
+        timeout: 3s
+        retries: 2
+        methods:
+          - name: Greet
+            timeout: 1s
+  custom:
+    config-map:
+      anything: goes
+`
+
+func TestDryRun(t *testing.T) {
+	insOpts, err := DryRun(WithBytes([]byte(validConfig)))
+	assert.Nil(t, err)
+	assert.Equal(t, "validation", insOpts.Application.Name)
+	assert.Equal(t, []string{"demo"}, insOpts.Provider.Services["org.apache.dubbo.Greeter"].RegistryIDs)
+}
+
+func TestDryRunInvalid(t *testing.T) {
+	conf := `
+dubbo:
+  registries:
+    demo:
+      address: unknown-registry://127.0.0.1:2181
+      timeout: 3
+      adress: 127.0.0.1:2181
+  protocols:
+    triple:
+      name: validation-protocol
+  provider:
+    filter: unknown-filter
+    services:
+      Greeter:
+        registry-ids: zk
+        protocol-ids: triple
+        loadbalance: unknown-loadbalance
+  consumer:
+    references:
+      Greeter:
+        cluster: unknown-cluster
+        retries: many
+        methods:
+          - timeout: 1s
+            timout: 1s
+`
+	_, err := DryRun(WithBytes([]byte(conf)))
+	assert.NotNil(t, err)
+	errs, ok := err.(ValidationErrors)
+	assert.True(t, ok)
+
+	paths := make([]string, 0, len(errs))
+	for _, e := range errs {
+		paths = append(paths, e.Path)
+	}
+	assert.ElementsMatch(t, []string{
+		"dubbo.registries.demo.adress",
+		"dubbo.registries.demo.timeout",
+		"dubbo.registries.demo.protocol",
+		"dubbo.provider.filter",
+		"dubbo.provider.services.Greeter.registry-ids[0]",
+		"dubbo.provider.services.Greeter.loadbalance",
+		"dubbo.consumer.references.Greeter.cluster",
+		"dubbo.consumer.references.Greeter.retries",
+		"dubbo.consumer.references.Greeter.methods[0].name",
+		"dubbo.consumer.references.Greeter.methods[0].timout",
+	}, paths)
+	assert.Contains(t, err.Error(), "dubbo.provider.services.Greeter.registry-ids[0]: the registry zk is not configured")
+}
+
+func TestValidateInstanceOptions(t *testing.T) {
+	insOpts := defaultInstanceOptions()
+	assert.Nil(t, insOpts.Validate())
+
+	insOpts.Consumer.RequestTimeout = "3 seconds"
+	insOpts.Consumer.RegistryIDs = []string{"nacos"}
+	err := insOpts.Validate()
+	assert.NotNil(t, err)
+	assert.Len(t, err.(ValidationErrors), 2)
+}
+
+func TestJSONSchema(t *testing.T) {
+	bytes, err := JSONSchema()
+	assert.Nil(t, err)
+
+	var schema map[string]any
+	assert.Nil(t, json.Unmarshal(bytes, &schema))
+	root := schema["properties"].(map[string]any)["dubbo"].(map[string]any)
+	assert.Equal(t, false, root["additionalProperties"])
+
+	registries := root["properties"].(map[string]any)["registries"].(map[string]any)
+	assert.Equal(t, "object", registries["type"])
+	registry := registries["additionalProperties"].(map[string]any)["properties"].(map[string]any)
+	assert.Equal(t, "string", registry["timeout"].(map[string]any)["type"])
+	assert.Equal(t, "integer", registry["weight"].(map[string]any)["type"])
+
+	services := root["properties"].(map[string]any)["provider"].(map[string]any)["properties"].(map[string]any)["services"]
+	service := services.(map[string]any)["additionalProperties"].(map[string]any)["properties"].(map[string]any)
+	assert.Equal(t, "failover", service["cluster"].(map[string]any)["default"])
+	assert.Equal(t, "array", service["registry-ids"].(map[string]any)["type"])
+}